@@ -0,0 +1,111 @@
+package access
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"shems/apierror"
+)
+
+// Roles a customer can have in a service location
+const (
+	RoleOwner     = "owner"
+	RoleMember    = "member"
+	RoleViewer    = "viewer"
+	RoleInstaller = "installer"
+)
+
+type Permission string
+
+// Permissions are phrased so that they complete "You do not have permission to"
+const (
+	ViewServiceLocation   Permission = "view this Service Location"
+	ManageDevices         Permission = "manage devices of this Service Location"
+	ManageServiceLocation Permission = "manage this Service Location"
+	DeleteServiceLocation Permission = "delete this Service Location"
+	ManageMembers         Permission = "manage members of this Service Location"
+)
+
+var rolePermissions = map[string][]Permission{
+	RoleOwner:     {ViewServiceLocation, ManageDevices, ManageServiceLocation, DeleteServiceLocation, ManageMembers},
+	RoleMember:    {ViewServiceLocation, ManageDevices, ManageServiceLocation},
+	RoleInstaller: {ViewServiceLocation, ManageDevices},
+	RoleViewer:    {ViewServiceLocation},
+}
+
+// Querier is satisfied by both *sql.DB and *sql.Tx
+type Querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+func HasPermission(role string, permission Permission) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// GetServiceLocationRole returns the role of the customer in the service
+// location, or an empty string when the customer is not a member
+func GetServiceLocationRole(ctx context.Context, db Querier, customerId, serviceLocationId uint32) (string, error) {
+	var role string
+	err := db.QueryRowContext(ctx, queryToGetServiceLocationRole(), serviceLocationId, customerId).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return role, err
+}
+
+// GetEnrolledDeviceRole returns the service location of the enrolled device
+// and the role of the customer in it, or an empty role when the customer is
+// not a member
+func GetEnrolledDeviceRole(ctx context.Context, db Querier, customerId, enrolledDeviceId uint32) (uint32, string, error) {
+	var serviceLocationId uint32
+	var role string
+	err := db.QueryRowContext(ctx, queryToGetEnrolledDeviceRole(), enrolledDeviceId, customerId).Scan(&serviceLocationId, &role)
+	if err == sql.ErrNoRows {
+		return 0, "", nil
+	}
+	return serviceLocationId, role, err
+}
+
+// CheckServiceLocationPermission returns the error to respond with when the
+// customer may not act on the service location, or nil when the request may
+// go ahead. Service locations the customer is not a member of are reported as
+// not existing.
+func CheckServiceLocationPermission(ctx context.Context, db Querier, customerId, serviceLocationId uint32, permission Permission) error {
+	role, err := GetServiceLocationRole(ctx, db, customerId, serviceLocationId)
+	if err != nil {
+		return err
+	}
+	return CheckRole(role, permission, "Service Location does not exist")
+}
+
+// CheckEnrolledDevicePermission does the same for the service location of an
+// enrolled device
+func CheckEnrolledDevicePermission(ctx context.Context, db Querier, customerId, enrolledDeviceId uint32, permission Permission) error {
+	_, role, err := GetEnrolledDeviceRole(ctx, db, customerId, enrolledDeviceId)
+	if err != nil {
+		return err
+	}
+	return CheckRole(role, permission, "Enrolled Device does not exist")
+}
+
+// CheckRole responds with notFoundMessage when the customer has no role, so
+// that callers can report the entity they looked up
+func CheckRole(role string, permission Permission, notFoundMessage string) error {
+	if len(role) == 0 {
+		return apierror.NotFound(notFoundMessage)
+	}
+	if !HasPermission(role, permission) {
+		return apierror.Forbidden(fmt.Sprintf("You do not have permission to %s", permission))
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package access
+
+import "shems/tracing"
+
+func init() {
+	tracing.RegisterQueries(
+		queryToGetServiceLocationRole,
+		queryToGetEnrolledDeviceRole,
+	)
+}
+
+func queryToGetServiceLocationRole() string {
+	sqlQuery := `
+	SELECT
+		slm.role
+	FROM
+		Service_Location_Members slm
+	WHERE
+		slm.service_location_id = ?
+		AND slm.customer_id = ?;
+	`
+	return sqlQuery
+}
+
+func queryToGetEnrolledDeviceRole() string {
+	sqlQuery := `
+	SELECT
+		ed.service_location_id, slm.role
+	FROM
+		Enrolled_Devices ed
+	INNER JOIN
+		Service_Location_Members slm ON slm.service_location_id = ed.service_location_id
+	WHERE
+		ed.id = ?
+		AND slm.customer_id = ?;
+	`
+	return sqlQuery
+}
@@ -0,0 +1,1009 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"shems/apierror"
+	"shems/audit"
+	"shems/model"
+	redisService "shems/redis"
+	"shems/spreadsheet"
+	"shems/users"
+	"shems/validation"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func AdminLogin(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.AdminLoginRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	// reject attempts while the email is locked out after repeated failures
+	email := users.NormalizeEmail(req.Email)
+	failuresKey := "AdminLoginFailures_Email_" + email
+	failures, err := redisService.GetKey(ctx, redisClient, failuresKey)
+	if err != nil && err != redis.Nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if count, _ := strconv.Atoi(failures); count >= maxAdminLoginFailures {
+		apierror.Write(w, r, apierror.Locked("Too many failed login attempts, please try again later"))
+		return
+	}
+
+	a, err := getAdminByEmail(ctx, db, email)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	if a.Id == 0 || a.Active == 0 || !users.CheckPasswordHash(req.Password, a.Password) {
+		_, err = redisService.IncrementKey(ctx, redisClient, failuresKey, adminLoginLockDuration)
+		if err != nil {
+			slog.ErrorContext(ctx, "error while incrementing redis key", "error", err)
+		}
+		apierror.Write(w, r, apierror.Unauthorized("Invalid email or password"))
+		return
+	}
+
+	err = redisService.DeleteKey(ctx, redisClient, failuresKey)
+	if err != nil {
+		slog.ErrorContext(ctx, "error while deleting redis key", "error", err)
+	}
+
+	sessionToken, err := createAdminSession(ctx, redisClient, a.Id)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = RecordAudit(ctx, db, r, a, "login", model.AuditEntityAdmin, a.Id, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	a.Password = ""
+	resp := model.AdminLoginResponse{
+		AdminDetails: a,
+		SessionToken: sessionToken,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func AdminLogout(w http.ResponseWriter, r *http.Request, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	err := redisService.DeleteKey(ctx, redisClient, getAdminSessionKey(r))
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Logged out successfully"})
+}
+
+func AddAdmin(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.AddAdminRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	a, ok := RequireAdmin(ctx, w, r, conn, redisClient)
+	if !ok {
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+
+	// validate the request
+	req.Email = users.NormalizeEmail(req.Email)
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+	email := req.Email
+
+	existing, err := getAdminByEmail(ctx, conn, email)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if existing.Id > 0 {
+		apierror.Write(w, r, apierror.Conflict("Admin with same email already exists"))
+		return
+	}
+
+	passwordHash, err := users.GetPasswordHash(req.Password)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	result, err := tx.ExecContext(ctx, queryToAddAdmin(), req.Name, email, passwordHash)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	adminId, err := result.LastInsertId()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = RecordAudit(ctx, tx, r, a, "add admin", model.AuditEntityAdmin, adminId, map[string]string{"name": req.Name, "email": email})
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// respond with a success message
+	json.NewEncoder(w).Encode(map[string]string{"message": "Admin added successfully"})
+}
+
+func SearchCustomers(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	a, ok := RequireAdmin(ctx, w, r, db, redisClient)
+	if !ok {
+		return
+	}
+
+	// search by id, email, phone number or name
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if len(q) == 0 {
+		apierror.Write(w, r, apierror.BadRequest("Search query cannot be empty"))
+		return
+	}
+	customerId, _ := strconv.Atoi(q)
+	pattern := "%" + escapeLike(q) + "%"
+
+	err := RecordAudit(ctx, db, r, a, "search customers", model.AuditEntityCustomer, "", map[string]string{"q": q})
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	query := queryToSearchCustomers()
+	rows, err := db.QueryContext(ctx, query, customerId, pattern, pattern, pattern, maxSearchResults)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var customers []model.AdminCustomer
+	for rows.Next() {
+		var c model.AdminCustomer
+		err = rows.Scan(&c.Id, &c.FirstName, &c.LastName, &c.PhoneNumber, &c.Email, &c.EmailVerified, &c.Active, &c.ServiceLocationsCount)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		customers = append(customers, c)
+	}
+
+	resp := model.SearchCustomersResponse{
+		Customers: customers,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func GetCustomerDetails(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	a, ok := RequireAdmin(ctx, w, r, db, redisClient)
+	if !ok {
+		return
+	}
+
+	// Get customer id from query params
+	customerIdInt, err := users.GetIdFromQueryParams(r, "customerId", "Customer Id")
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+
+	var c model.AdminCustomer
+	err = db.QueryRowContext(ctx, queryToGetCustomer(), customerIdInt).Scan(&c.Id, &c.FirstName, &c.LastName, &c.PhoneNumber, &c.Email, &c.EmailVerified, &c.Active, &c.ServiceLocationsCount)
+	if err == sql.ErrNoRows {
+		apierror.Write(w, r, apierror.NotFound("Customer does not exist"))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = RecordAudit(ctx, db, r, a, "view customer", model.AuditEntityCustomer, customerIdInt, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// get service locations the customer is a member of
+	query := queryToGetCustomerServiceLocations()
+	rows, err := db.QueryContext(ctx, query, customerIdInt)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var serviceLocations []model.ServiceLocation
+	serviceLocationsMap := make(map[uint32]string)
+	for rows.Next() {
+		var sl model.ServiceLocation
+		err = rows.Scan(&sl.Id, &sl.CustomerId, &sl.DateTakenOver, &sl.OccupantsCount, &sl.UnitNumber, &sl.Street, &sl.City, &sl.State, &sl.Zipcode, &sl.Country, &sl.SquareFootage, &sl.BedroomsCount, &sl.Active, &sl.Role)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		sl.LocationLabel = fmt.Sprint(sl.UnitNumber, ", ", sl.Street, ", ", sl.City, ", ", sl.State, ", ", sl.Zipcode, ", ", sl.Country)
+		serviceLocationsMap[sl.Id] = sl.LocationLabel
+		serviceLocations = append(serviceLocations, sl)
+	}
+
+	// get enrolled devices in those service locations
+	query = queryToGetCustomerEnrolledDevices()
+	rows, err = db.QueryContext(ctx, query, customerIdInt)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var enrolledDevices []model.EnrolledDevice
+	for rows.Next() {
+		var ed model.EnrolledDevice
+		var modelNumber string
+		err = rows.Scan(&ed.Id, &ed.ServiceLocationId, &ed.DeviceId, &ed.AliasName, &ed.RoomNumber, &ed.Active, &ed.DeviceType, &modelNumber)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		ed.Device = fmt.Sprint(modelNumber, " (", ed.DeviceType, ")")
+		ed.ServiceLocation = serviceLocationsMap[ed.ServiceLocationId]
+		enrolledDevices = append(enrolledDevices, ed)
+	}
+
+	resp := model.AdminCustomerDetailsResponse{
+		Customer:         c,
+		ServiceLocations: serviceLocations,
+		EnrolledDevices:  enrolledDevices,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func GetEvents(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	a, ok := RequireAdmin(ctx, w, r, db, redisClient)
+	if !ok {
+		return
+	}
+
+	// Get enrolled device id from query params
+	enrolledDeviceIdInt, err := users.GetIdFromQueryParams(r, "enrolledDeviceId", "Enrolled Device Id")
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+
+	// Get date range from query params, both dates are inclusive
+	startDateTime, err := time.ParseInLocation("01/02/2006", r.URL.Query().Get("startDate"), time.Local)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Start Date must be in MM/DD/YYYY format"))
+		return
+	}
+	endDate, err := time.ParseInLocation("01/02/2006", r.URL.Query().Get("endDate"), time.Local)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("End Date must be in MM/DD/YYYY format"))
+		return
+	}
+	if endDate.Before(startDateTime) {
+		apierror.Write(w, r, apierror.BadRequest("End Date cannot be before Start Date"))
+		return
+	}
+	endDateTime := endDate.AddDate(0, 0, 1).Add(-time.Second)
+
+	err = RecordAudit(ctx, db, r, a, "view events", model.AuditEntityEnrolledDevice, enrolledDeviceIdInt, map[string]string{"startDate": r.URL.Query().Get("startDate"), "endDate": r.URL.Query().Get("endDate")})
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	query := queryToGetEvents()
+	rows, err := db.QueryContext(ctx, query, enrolledDeviceIdInt, startDateTime, endDateTime, maxEvents)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var events []model.AdminEvent
+	for rows.Next() {
+		var e model.AdminEvent
+		err = rows.Scan(&e.EnrolledDeviceId, &e.Label, &e.Value, &e.CreatedAt)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		events = append(events, e)
+	}
+
+	resp := model.GetAdminEventsResponse{
+		Events: events,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func ImpersonateCustomer(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.ImpersonateCustomerRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	a, ok := RequireAdmin(ctx, w, r, db, redisClient)
+	if !ok {
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	var c model.AdminCustomer
+	err = db.QueryRowContext(ctx, queryToGetCustomer(), req.CustomerId).Scan(&c.Id, &c.FirstName, &c.LastName, &c.PhoneNumber, &c.Email, &c.EmailVerified, &c.Active, &c.ServiceLocationsCount)
+	if err == sql.ErrNoRows {
+		apierror.Write(w, r, apierror.NotFound("Customer does not exist"))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if c.Active == 0 {
+		apierror.Write(w, r, apierror.BadRequest("Customer is deactivated"))
+		return
+	}
+
+	// the audit entry is written before the session exists
+	err = RecordAudit(ctx, db, r, a, "impersonate", model.AuditEntityCustomer, req.CustomerId, map[string]string{"reason": req.Reason})
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// impersonation never satisfies two factor authentication
+	sessionToken, err := users.CreateSession(ctx, redisClient, model.Session{CustomerId: req.CustomerId, ImpersonatedBy: a.Id})
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	resp := model.ImpersonateCustomerResponse{
+		SessionToken: sessionToken,
+		ExpiresAt:    time.Now().Add(users.ImpersonationSessionExpiry).Format(time.RFC3339),
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func updateCustomerActive(ctx context.Context, w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client, active uint32) {
+	var req model.UpdateCustomerStatusRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	a, ok := RequireAdmin(ctx, w, r, conn, redisClient)
+	if !ok {
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	action := "deactivate customer"
+	if active == 1 {
+		action = "reactivate customer"
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	result, err := tx.ExecContext(ctx, queryToUpdateCustomerActive(), active, req.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if rowsAffected == 0 {
+		apierror.Write(w, r, apierror.NotFound("Customer does not exist or is already in this state"))
+		return
+	}
+
+	err = RecordAudit(ctx, tx, r, a, action, model.AuditEntityCustomer, req.CustomerId, map[string]string{"reason": req.Reason})
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// deactivated customers are logged out everywhere
+	if active == 0 {
+		err = users.DeleteCustomerSessions(ctx, redisClient, req.CustomerId)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// respond with a success message
+	message := "Customer reactivated successfully"
+	if active == 0 {
+		message = "Customer deactivated successfully"
+	}
+	json.NewEncoder(w).Encode(map[string]string{"message": message})
+}
+
+func DeactivateCustomer(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	updateCustomerActive(ctx, w, r, conn, redisClient, 0)
+}
+
+func ReactivateCustomer(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	updateCustomerActive(ctx, w, r, conn, redisClient, 1)
+}
+
+func GetDevices(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	_, ok := RequireAdmin(ctx, w, r, db, redisClient)
+	if !ok {
+		return
+	}
+
+	query := queryToGetAllDevices()
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var devices []model.Device
+	for rows.Next() {
+		var d model.Device
+		err = rows.Scan(&d.Id, &d.Type, &d.ModelNumber)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		d.DeviceName = fmt.Sprint(d.ModelNumber, " (", d.Type, ")")
+		devices = append(devices, d)
+	}
+
+	resp := model.GetDevicesResponse{
+		Devices: devices,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func AddDevice(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.DeviceRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	a, ok := RequireAdmin(ctx, w, r, conn, redisClient)
+	if !ok {
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	var checkId uint32
+	err = conn.QueryRowContext(ctx, queryToCheckIfDeviceExistsByModel(), req.Type, req.ModelNumber).Scan(&checkId)
+	if err != nil && err != sql.ErrNoRows {
+		apierror.Write(w, r, err)
+		return
+	}
+	if checkId > 0 {
+		apierror.Write(w, r, apierror.Conflict("Device already exists"))
+		return
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	result, err := tx.ExecContext(ctx, queryToAddDevice(), req.Type, req.ModelNumber)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	deviceId, err := result.LastInsertId()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = RecordChange(ctx, tx, r, a, "add device", model.AuditEntityDevice, deviceId, nil, req)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// respond with a success message
+	json.NewEncoder(w).Encode(map[string]string{"message": "Device added successfully"})
+}
+
+func UpdateDevice(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.DeviceRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	a, ok := RequireAdmin(ctx, w, r, conn, redisClient)
+	if !ok {
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+
+	// validate the request
+	errs := validation.Validate(req)
+	if req.Id == 0 {
+		errs = append(errs, validation.FieldError{Field: "id", Message: "is required"})
+	}
+	if len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+	if len(req.Type) == 0 {
+		apierror.Write(w, r, apierror.BadRequest("Type cannot be empty"))
+		return
+	}
+	if len(req.ModelNumber) == 0 {
+		apierror.Write(w, r, apierror.BadRequest("Model Number cannot be empty"))
+		return
+	}
+
+	var before model.Device
+	err = conn.QueryRowContext(ctx, queryToGetDevice(), req.Id).Scan(&before.Id, &before.Type, &before.ModelNumber)
+	if err == sql.ErrNoRows {
+		apierror.Write(w, r, apierror.NotFound("Device does not exist"))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	_, err = tx.ExecContext(ctx, queryToUpdateDevice(), req.Type, req.ModelNumber, req.Id)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = RecordChange(ctx, tx, r, a, "update device", model.AuditEntityDevice, req.Id, before, req)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// respond with a success message
+	json.NewEncoder(w).Encode(map[string]string{"message": "Device updated successfully"})
+}
+
+func GetPrices(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	_, ok := RequireAdmin(ctx, w, r, db, redisClient)
+	if !ok {
+		return
+	}
+
+	// Get zipcode from query params
+	zipcode := validation.NormalizePostalCode(r.URL.Query().Get("zipcode"))
+	if len(zipcode) == 0 {
+		apierror.Write(w, r, apierror.BadRequest("Zipcode cannot be empty"))
+		return
+	}
+
+	query := queryToGetPricesByZipcode()
+	rows, err := db.QueryContext(ctx, query, zipcode)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var prices []model.Price
+	for rows.Next() {
+		var p model.Price
+		err = rows.Scan(&p.Zipcode, &p.Hour, &p.Value)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		prices = append(prices, p)
+	}
+
+	resp := model.GetPricesResponse{
+		Prices: prices,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func UpdatePrices(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.UpdatePricesRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	a, ok := RequireAdmin(ctx, w, r, conn, redisClient)
+	if !ok {
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+
+	// validate the request
+	req.Zipcode = validation.NormalizePostalCode(req.Zipcode)
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	redisKey := "UpdatePrices_Zipcode_" + fmt.Sprint(req.Zipcode)
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		// release redis lock
+		redisService.ReleaseLock(ctx, redisClient, redisKey)
+
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// take redis lock to avoid concurrent updates of the same tariff
+	err = users.TakeRedisLock(ctx, redisClient, redisKey)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// current prices are kept in the audit entry
+	rows, err := tx.QueryContext(ctx, queryToGetPricesByZipcode(), req.Zipcode)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	before := make(map[uint32]float32)
+	for rows.Next() {
+		var p model.Price
+		err = rows.Scan(&p.Zipcode, &p.Hour, &p.Value)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		before[p.Hour] = p.Value
+	}
+	rows.Close()
+
+	// update hours which have a price, add the others
+	for _, p := range req.Prices {
+		query := queryToAddPrice()
+		args := []interface{}{req.Zipcode, p.Hour, p.Value}
+		if _, ok := before[p.Hour]; ok {
+			query = queryToUpdatePrice()
+			args = []interface{}{p.Value, req.Zipcode, p.Hour}
+		}
+		_, err = tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	}
+
+	err = RecordChange(ctx, tx, r, a, "update prices", model.AuditEntityPrices, req.Zipcode, before, req.Prices)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// respond with a success message
+	json.NewEncoder(w).Encode(map[string]string{"message": "Prices updated successfully"})
+}
+
+func GetAuditLogs(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	_, ok := RequireAdmin(ctx, w, r, db, redisClient)
+	if !ok {
+		return
+	}
+
+	filter, err := getAuditLogFilter(r)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+
+	limit := defaultAuditLogsPageSize
+	if limitStr := r.URL.Query().Get("limit"); len(limitStr) > 0 {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxAuditLogsPageSize {
+			apierror.Write(w, r, apierror.BadRequest(fmt.Sprintf("Limit must be between 1 and %d", maxAuditLogsPageSize)))
+			return
+		}
+	}
+
+	logs, err := audit.GetLogs(ctx, db, filter, limit)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	resp := model.GetAuditLogsResponse{
+		AuditLogs: logs,
+	}
+	if len(logs) == limit {
+		resp.NextBeforeId = logs[len(logs)-1].Id
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func ExportAuditLogs(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	a, ok := RequireAdmin(ctx, w, r, db, redisClient)
+	if !ok {
+		return
+	}
+
+	filter, err := getAuditLogFilter(r)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if len(format) == 0 {
+		format = "csv"
+	}
+	if format != "csv" && format != "jsonl" {
+		apierror.Write(w, r, apierror.BadRequest("Format must be csv or jsonl"))
+		return
+	}
+
+	// exports of the audit log are themselves audited
+	err = RecordAudit(ctx, db, r, a, "export audit logs", model.AuditEntityAuditLogs, "", filter)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	filename := fmt.Sprintf("audit_logs_%s.%s", time.Now().Format("20060102150405"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+
+	var csvWriter *csv.Writer
+	var jsonEncoder *json.Encoder
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv")
+		csvWriter = csv.NewWriter(w)
+		csvWriter.Write([]string{"Id", "CreatedAt", "ActorType", "ActorId", "ImpersonatedBy", "Action", "EntityType", "EntityId", "Before", "After", "Details", "Ip", "RequestId"})
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		jsonEncoder = json.NewEncoder(w)
+	}
+
+	// entries are read newest first in batches, errors can only be logged
+	// once the response has started
+	for {
+		logs, err := audit.GetLogs(ctx, db, filter, auditLogsExportBatchSize)
+		if err != nil {
+			slog.ErrorContext(ctx, "error while reading audit logs export", "error", err)
+			return
+		}
+
+		for _, entry := range logs {
+			if csvWriter != nil {
+				err = csvWriter.Write(spreadsheet.EscapeFormulas([]string{fmt.Sprint(entry.Id), entry.CreatedAt, entry.ActorType, fmt.Sprint(entry.ActorId), fmt.Sprint(entry.ImpersonatedBy), entry.Action, entry.EntityType, entry.EntityId, jsonString(entry.Before), jsonString(entry.After), jsonString(entry.Details), entry.Ip, entry.RequestId}))
+			} else {
+				err = jsonEncoder.Encode(entry)
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "error while writing audit logs export", "error", err)
+				return
+			}
+		}
+		if csvWriter != nil {
+			csvWriter.Flush()
+		}
+
+		if len(logs) < auditLogsExportBatchSize {
+			break
+		}
+		filter.BeforeId = logs[len(logs)-1].Id
+	}
+
+	if csvWriter != nil {
+		err = csvWriter.Error()
+		if err != nil {
+			slog.ErrorContext(ctx, "error while writing audit logs export", "error", err)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+package admin
+
+import "shems/tracing"
+
+func init() {
+	tracing.RegisterQueries(
+		queryToGetAdminByEmail,
+		queryToGetAdminById,
+		queryToAddAdmin,
+		queryToSearchCustomers,
+		queryToGetCustomer,
+		queryToGetCustomerServiceLocations,
+		queryToGetCustomerEnrolledDevices,
+		queryToGetEvents,
+		queryToUpdateCustomerActive,
+		queryToGetAllDevices,
+		queryToGetDevice,
+		queryToCheckIfDeviceExistsByModel,
+		queryToAddDevice,
+		queryToUpdateDevice,
+		queryToGetPricesByZipcode,
+		queryToUpdatePrice,
+		queryToAddPrice,
+	)
+}
+
+func queryToGetAdminByEmail() string {
+	sqlQuery := `
+	SELECT
+		id, name, email, password, active, created_at
+	FROM
+		Admins
+	WHERE
+		email = ?;
+	`
+	return sqlQuery
+}
+
+func queryToGetAdminById() string {
+	sqlQuery := `
+	SELECT
+		id, name, email, password, active, created_at
+	FROM
+		Admins
+	WHERE
+		id = ?;
+	`
+	return sqlQuery
+}
+
+func queryToAddAdmin() string {
+	sqlQuery := `
+				INSERT INTO Admins
+					(name, email, password)
+				VALUES
+					(?, ?, ?);
+				`
+	return sqlQuery
+}
+
+func queryToSearchCustomers() string {
+	sqlQuery := `
+	SELECT
+		c.id, c.first_name, c.last_name, c.phone_number, c.email, c.email_verified, c.active, COUNT(slm.id) AS service_locations_count
+	FROM
+		Customers c
+	LEFT JOIN
+		Service_Location_Members slm ON slm.customer_id = c.id
+	WHERE
+		c.id = ?
+		OR c.email LIKE ?
+		OR c.phone_number LIKE ?
+		OR CONCAT(c.first_name, ' ', c.last_name) LIKE ?
+	GROUP BY
+		1, 2, 3, 4, 5, 6, 7
+	ORDER BY
+		c.id
+	LIMIT ?;
+	`
+	return sqlQuery
+}
+
+func queryToGetCustomer() string {
+	sqlQuery := `
+	SELECT
+		c.id, c.first_name, c.last_name, c.phone_number, c.email, c.email_verified, c.active, COUNT(slm.id) AS service_locations_count
+	FROM
+		Customers c
+	LEFT JOIN
+		Service_Location_Members slm ON slm.customer_id = c.id
+	WHERE
+		c.id = ?
+	GROUP BY
+		1, 2, 3, 4, 5, 6, 7;
+	`
+	return sqlQuery
+}
+
+func queryToGetCustomerServiceLocations() string {
+	sqlQuery := `
+	SELECT
+		sl.id, sl.customer_id, sl.date_taken_over, sl.occupants_count, l.unit_number, l.street, l.city, l.state, l.zipcode, l.country, l.square_footage, l.bedrooms_count, sl.active, slm.role
+	FROM
+		Service_Locations sl
+	INNER JOIN
+		Locations l ON l.id = sl.location_id
+	INNER JOIN
+		Service_Location_Members slm ON slm.service_location_id = sl.id
+	WHERE
+		slm.customer_id = ?
+	ORDER BY
+		sl.id;
+	`
+	return sqlQuery
+}
+
+func queryToGetCustomerEnrolledDevices() string {
+	sqlQuery := `
+	SELECT
+		ed.id, ed.service_location_id, ed.device_id, ed.alias_name, ed.room_number, ed.active, d.type, d.model_number
+	FROM
+		Enrolled_Devices ed
+	INNER JOIN
+		Devices d ON d.id = ed.device_id
+	INNER JOIN
+		Service_Location_Members slm ON slm.service_location_id = ed.service_location_id
+	WHERE
+		slm.customer_id = ?
+	ORDER BY
+		ed.service_location_id, ed.id;
+	`
+	return sqlQuery
+}
+
+func queryToGetEvents() string {
+	sqlQuery := `
+	SELECT
+		enrolled_device_id, label, value, created_at
+	FROM
+		Events
+	WHERE
+		enrolled_device_id = ?
+		AND created_at >= ?
+		AND created_at <= ?
+	ORDER BY
+		created_at
+	LIMIT ?;
+	`
+	return sqlQuery
+}
+
+func queryToUpdateCustomerActive() string {
+	sqlQuery := `
+				UPDATE
+					Customers
+				SET
+					active = ?
+				WHERE
+					id = ?;
+				`
+	return sqlQuery
+}
+
+func queryToGetAllDevices() string {
+	sqlQuery := `
+	SELECT
+		id, type, model_number
+	FROM
+		Devices
+	ORDER BY
+		type, model_number;
+	`
+	return sqlQuery
+}
+
+func queryToGetDevice() string {
+	sqlQuery := `
+	SELECT
+		id, type, model_number
+	FROM
+		Devices
+	WHERE
+		id = ?;
+	`
+	return sqlQuery
+}
+
+func queryToCheckIfDeviceExistsByModel() string {
+	sqlQuery := `
+	SELECT
+		id
+	FROM
+		Devices
+	WHERE
+		type = ?
+		AND model_number = ?;
+	`
+	return sqlQuery
+}
+
+func queryToAddDevice() string {
+	sqlQuery := `
+				INSERT INTO Devices
+					(type, model_number)
+				VALUES
+					(?, ?);
+				`
+	return sqlQuery
+}
+
+func queryToUpdateDevice() string {
+	sqlQuery := `
+				UPDATE
+					Devices
+				SET
+					type = ?,
+					model_number = ?
+				WHERE
+					id = ?;
+				`
+	return sqlQuery
+}
+
+func queryToGetPricesByZipcode() string {
+	sqlQuery := `
+	SELECT
+		zipcode, hour, value
+	FROM
+		Prices
+	WHERE
+		zipcode = ?
+	ORDER BY
+		hour;
+	`
+	return sqlQuery
+}
+
+func queryToUpdatePrice() string {
+	sqlQuery := `
+				UPDATE
+					Prices
+				SET
+					value = ?
+				WHERE
+					zipcode = ?
+					AND hour = ?;
+				`
+	return sqlQuery
+}
+
+func queryToAddPrice() string {
+	sqlQuery := `
+				INSERT INTO Prices
+					(zipcode, hour, value)
+				VALUES
+					(?, ?, ?);
+				`
+	return sqlQuery
+}
@@ -0,0 +1,249 @@
+package admin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"shems/apierror"
+	"shems/audit"
+	"shems/dbutil"
+	"shems/model"
+	redisService "shems/redis"
+	"shems/users"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	adminSessionExpiry = 8 * time.Hour
+
+	// Failed logins of an admin email within adminLoginLockDuration before it gets locked
+	maxAdminLoginFailures  = 5
+	adminLoginLockDuration = 15 * time.Minute
+
+	minAdminPasswordLength = 12
+
+	// Most rows returned by search and event endpoints
+	maxSearchResults = 100
+	maxEvents        = 5000
+
+	// Audit logs are returned in pages, exports read them in batches
+	defaultAuditLogsPageSize = 100
+	maxAuditLogsPageSize     = 1000
+	auditLogsExportBatchSize = 1000
+)
+
+var errNoAdminSession = errors.New("Admin session is missing or has expired")
+
+func getAdminByEmail(ctx context.Context, db *sql.DB, email string) (model.Admin, error) {
+	var a model.Admin
+	err := db.QueryRowContext(ctx, queryToGetAdminByEmail(), email).Scan(&a.Id, &a.Name, &a.Email, &a.Password, &a.Active, &a.CreatedAt)
+	if err == sql.ErrNoRows {
+		return a, nil
+	}
+	return a, err
+}
+
+func getAdminById(ctx context.Context, db *sql.DB, adminId uint32) (model.Admin, error) {
+	var a model.Admin
+	err := db.QueryRowContext(ctx, queryToGetAdminById(), adminId).Scan(&a.Id, &a.Name, &a.Email, &a.Password, &a.Active, &a.CreatedAt)
+	if err == sql.ErrNoRows {
+		return a, nil
+	}
+	return a, err
+}
+
+func createAdminSession(ctx context.Context, redisClient *redis.Client, adminId uint32) (string, error) {
+	value, err := json.Marshal(model.AdminSession{AdminId: adminId, CreatedAt: time.Now().Format(time.RFC3339)})
+	if err != nil {
+		return "", err
+	}
+
+	token := users.GenerateToken()
+	err = redisService.SetKeyWithExpiry(ctx, redisClient, "AdminSession_"+users.HashToken(token), value, adminSessionExpiry)
+	return token, err
+}
+
+func getAdminSessionKey(r *http.Request) string {
+	authorization := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, "Bearer ") {
+		return ""
+	}
+	return "AdminSession_" + users.HashToken(strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer ")))
+}
+
+func getAdminSession(ctx context.Context, redisClient *redis.Client, r *http.Request) (model.AdminSession, error) {
+	var session model.AdminSession
+	sessionKey := getAdminSessionKey(r)
+	if len(sessionKey) == 0 {
+		return session, errNoAdminSession
+	}
+
+	value, err := redisService.GetKey(ctx, redisClient, sessionKey)
+	if err == redis.Nil {
+		return session, errNoAdminSession
+	}
+	if err != nil {
+		return session, err
+	}
+
+	err = json.Unmarshal([]byte(value), &session)
+	return session, err
+}
+
+// RequireAdmin returns the admin calling the endpoint, writing the error
+// response itself when there is no valid admin session
+func RequireAdmin(ctx context.Context, w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) (model.Admin, bool) {
+	session, err := getAdminSession(ctx, redisClient, r)
+	if err == errNoAdminSession {
+		apierror.Write(w, r, apierror.Unauthorized(err.Error()))
+		return model.Admin{}, false
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return model.Admin{}, false
+	}
+
+	// admins who were deactivated lose access straight away
+	a, err := getAdminById(ctx, db, session.AdminId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return model.Admin{}, false
+	}
+	if a.Id == 0 || a.Active == 0 {
+		apierror.Write(w, r, apierror.Unauthorized(errNoAdminSession.Error()))
+		return model.Admin{}, false
+	}
+
+	a.Password = ""
+	return a, true
+}
+
+// RecordAudit writes an audit log entry of an admin action
+func RecordAudit(ctx context.Context, db audit.Execer, r *http.Request, a model.Admin, action, entityType string, entityId interface{}, details interface{}) error {
+	return audit.Record(ctx, db, model.AuditLog{
+		ActorType:  model.AuditActorAdmin,
+		ActorId:    a.Id,
+		Action:     action,
+		EntityType: entityType,
+		EntityId:   fmt.Sprint(entityId),
+		Details:    details,
+		Ip:         users.GetClientIp(r),
+		RequestId:  audit.GetRequestId(r),
+	})
+}
+
+// RecordChange writes an audit log entry of an admin change with snapshots of
+// the entity before and after it
+func RecordChange(ctx context.Context, db audit.Execer, r *http.Request, a model.Admin, action, entityType string, entityId interface{}, before, after interface{}) error {
+	return audit.Record(ctx, db, model.AuditLog{
+		ActorType:  model.AuditActorAdmin,
+		ActorId:    a.Id,
+		Action:     action,
+		EntityType: entityType,
+		EntityId:   fmt.Sprint(entityId),
+		Before:     before,
+		After:      after,
+		Ip:         users.GetClientIp(r),
+		RequestId:  audit.GetRequestId(r),
+	})
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+// EnsureAdmin creates an admin with the given email when it does not exist,
+// so that the first admin can be configured through the environment
+func EnsureAdmin(ctx context.Context, db *sql.DB, name, email, password string) error {
+	email = users.NormalizeEmail(email)
+	existing, err := getAdminByEmail(ctx, db, email)
+	if err != nil {
+		return err
+	}
+	if existing.Id > 0 {
+		return nil
+	}
+	if len(password) < minAdminPasswordLength {
+		return fmt.Errorf("admin password must be at least %d characters long", minAdminPasswordLength)
+	}
+
+	passwordHash, err := users.GetPasswordHash(password)
+	if err != nil {
+		return err
+	}
+	_, err = db.ExecContext(ctx, queryToAddAdmin(), name, email, passwordHash)
+	return err
+}
+
+// getAuditLogFilter reads the audit log filters from query params. Dates are
+// in MM/DD/YYYY format and both are inclusive.
+func getAuditLogFilter(r *http.Request) (model.AuditLogFilter, error) {
+	query := r.URL.Query()
+	filter := model.AuditLogFilter{
+		ActorType:  query.Get("actorType"),
+		Action:     query.Get("action"),
+		EntityType: query.Get("entityType"),
+		EntityId:   query.Get("entityId"),
+		RequestId:  query.Get("requestId"),
+	}
+
+	if actorIdStr := query.Get("actorId"); len(actorIdStr) > 0 {
+		actorId, err := strconv.ParseUint(actorIdStr, 10, 32)
+		if err != nil {
+			return filter, errors.New("Actor Id must be a number")
+		}
+		filter.ActorId = uint32(actorId)
+	}
+	if impersonatedByStr := query.Get("impersonatedBy"); len(impersonatedByStr) > 0 {
+		impersonatedBy, err := strconv.ParseUint(impersonatedByStr, 10, 32)
+		if err != nil {
+			return filter, errors.New("Impersonated By must be a number")
+		}
+		filter.ImpersonatedBy = uint32(impersonatedBy)
+	}
+	if beforeIdStr := query.Get("beforeId"); len(beforeIdStr) > 0 {
+		beforeId, err := strconv.ParseUint(beforeIdStr, 10, 64)
+		if err != nil {
+			return filter, errors.New("Before Id must be a number")
+		}
+		filter.BeforeId = beforeId
+	}
+
+	var startDate time.Time
+	if startDateStr := query.Get("startDate"); len(startDateStr) > 0 {
+		date, err := time.ParseInLocation("01/02/2006", startDateStr, time.Local)
+		if err != nil {
+			return filter, errors.New("Start Date must be in MM/DD/YYYY format")
+		}
+		startDate = date
+		filter.StartDate = date.Format(dbutil.TimeLayout)
+	}
+	if endDateStr := query.Get("endDate"); len(endDateStr) > 0 {
+		date, err := time.ParseInLocation("01/02/2006", endDateStr, time.Local)
+		if err != nil {
+			return filter, errors.New("End Date must be in MM/DD/YYYY format")
+		}
+		if !startDate.IsZero() && date.Before(startDate) {
+			return filter, errors.New("End Date cannot be before Start Date")
+		}
+		filter.EndDate = date.AddDate(0, 0, 1).Add(-time.Second).Format(dbutil.TimeLayout)
+	}
+	return filter, nil
+}
+
+// jsonString returns the JSON stored in an audit log column, or an empty
+// string when the column is NULL
+func jsonString(value interface{}) string {
+	if raw, ok := value.(json.RawMessage); ok {
+		return string(raw)
+	}
+	return ""
+}
@@ -0,0 +1,266 @@
+package apierror
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"reflect"
+	"shems/audit"
+	"shems/validation"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/redis/go-redis/v9"
+)
+
+// Codes let clients tell errors apart without parsing the message
+const (
+	CodeBadRequest           = "bad_request"
+	CodeValidation           = "validation_failed"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeNotFound             = "not_found"
+	CodeMethodNotAllowed     = "method_not_allowed"
+	CodeConflict             = "conflict"
+	CodeLocked               = "locked"
+	CodeTooManyRequests      = "too_many_requests"
+	CodePreconditionFailed   = "precondition_failed"
+	CodePreconditionRequired = "precondition_required"
+	CodeInternal             = "internal_error"
+	CodeUnavailable          = "service_unavailable"
+	CodeTimeout              = "timeout"
+)
+
+// MySQL error numbers that are caused by the request rather than the server
+const (
+	mysqlDuplicateEntry       = 1062
+	mysqlRowIsReferenced      = 1451
+	mysqlNoReferencedRow      = 1452
+	mysqlDataTooLong          = 1406
+	mysqlOutOfRangeValue      = 1264
+	mysqlTruncatedWrongValue  = 1292
+	mysqlIncorrectValueForCol = 1366
+)
+
+// Error is a domain error with everything needed to respond with it. Err is
+// the underlying cause, which is logged but never sent to the client.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Fields  validation.Errors
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Response is the body of every error response
+type Response struct {
+	Code      string                  `json:"code"`
+	Message   string                  `json:"message"`
+	Errors    []validation.FieldError `json:"errors,omitempty"`
+	RequestId string                  `json:"requestId"`
+}
+
+// New returns an error with the code that belongs to the status
+func New(status int, message string) *Error {
+	return &Error{Status: status, Code: codeForStatus(status), Message: message}
+}
+
+func BadRequest(message string) *Error {
+	return New(http.StatusBadRequest, message)
+}
+
+func Unauthorized(message string) *Error {
+	return New(http.StatusUnauthorized, message)
+}
+
+func Forbidden(message string) *Error {
+	return New(http.StatusForbidden, message)
+}
+
+func NotFound(message string) *Error {
+	return New(http.StatusNotFound, message)
+}
+
+func Conflict(message string) *Error {
+	return New(http.StatusConflict, message)
+}
+
+func Locked(message string) *Error {
+	return New(http.StatusLocked, message)
+}
+
+func TooManyRequests(message string) *Error {
+	return New(http.StatusTooManyRequests, message)
+}
+
+// PreconditionFailed is returned when the resource changed since the client
+// fetched it
+func PreconditionFailed(message string) *Error {
+	return New(http.StatusPreconditionFailed, message)
+}
+
+func PreconditionRequired(message string) *Error {
+	return New(http.StatusPreconditionRequired, message)
+}
+
+func Validation(fields validation.Errors) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: CodeValidation, Message: "Validation failed", Fields: fields}
+}
+
+// InvalidJSON is returned when the request body cannot be decoded. A value of
+// the wrong type is reported on its field, the message of the decoder itself
+// is never sent to the client.
+func InvalidJSON(err error) *Error {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return Validation(validation.Errors{{Field: typeErr.Field, Message: "must be " + jsonTypeName(typeErr.Type)}})
+	}
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return New(http.StatusRequestEntityTooLarge, "Request body is too large")
+	}
+	return BadRequest("Request body must be valid JSON")
+}
+
+// jsonTypeName describes the JSON value a field of type t expects
+func jsonTypeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "a whole number"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a non negative whole number"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.String:
+		return "a string"
+	case reflect.Slice, reflect.Array:
+		return "a list"
+	}
+	return "an object"
+}
+
+// Timeout is returned when the deadline of the request passed before it was
+// done
+func Timeout(err error) *Error {
+	return &Error{Status: http.StatusGatewayTimeout, Code: CodeTimeout, Message: "Request timed out", Err: err}
+}
+
+// Unavailable is returned when the database or redis cannot be reached, or
+// the request was canceled before it was done
+func Unavailable(err error) *Error {
+	return &Error{Status: http.StatusServiceUnavailable, Code: CodeUnavailable, Message: "Service is temporarily unavailable", Err: err}
+}
+
+func Internal(err error) *Error {
+	return &Error{Status: http.StatusInternalServerError, Code: CodeInternal, Message: "Internal server error", Err: err}
+}
+
+// From turns any error into a domain error. Database errors caused by the
+// request are mapped to client errors, everything else is an internal error.
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	var fields validation.Errors
+	if errors.As(err, &fields) {
+		return Validation(fields)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return Timeout(err)
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, sql.ErrConnDone) || errors.Is(err, driver.ErrBadConn) || errors.Is(err, mysql.ErrInvalidConn) || errors.Is(err, redis.ErrClosed) {
+		return Unavailable(err)
+	}
+	var netErr *net.OpError
+	if errors.As(err, &netErr) {
+		return Unavailable(err)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return &Error{Status: http.StatusNotFound, Code: CodeNotFound, Message: "Resource not found", Err: err}
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case mysqlDuplicateEntry:
+			return &Error{Status: http.StatusConflict, Code: CodeConflict, Message: "Resource already exists", Err: err}
+		case mysqlRowIsReferenced:
+			return &Error{Status: http.StatusConflict, Code: CodeConflict, Message: "Resource is still in use", Err: err}
+		case mysqlNoReferencedRow:
+			return &Error{Status: http.StatusNotFound, Code: CodeNotFound, Message: "Referenced resource does not exist", Err: err}
+		case mysqlDataTooLong, mysqlOutOfRangeValue, mysqlTruncatedWrongValue, mysqlIncorrectValueForCol:
+			return &Error{Status: http.StatusBadRequest, Code: CodeBadRequest, Message: "Invalid value", Err: err}
+		}
+	}
+	return Internal(err)
+}
+
+// Write responds with err as the JSON error body. This is the only place
+// error responses are rendered, so that every endpoint answers the same way.
+func Write(w http.ResponseWriter, r *http.Request, err error) {
+	e := From(err)
+	requestId := audit.GetRequestId(r)
+	if e.Err != nil {
+		slog.ErrorContext(r.Context(), "request failed", "error", e.Err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(e.Status)
+	json.NewEncoder(w).Encode(Response{
+		Code:      e.Code,
+		Message:   e.Message,
+		Errors:    e.Fields,
+		RequestId: requestId,
+	})
+}
+
+func codeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeBadRequest
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusLocked:
+		return CodeLocked
+	case http.StatusTooManyRequests:
+		return CodeTooManyRequests
+	case http.StatusPreconditionFailed:
+		return CodePreconditionFailed
+	case http.StatusPreconditionRequired:
+		return CodePreconditionRequired
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	case http.StatusGatewayTimeout:
+		return CodeTimeout
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
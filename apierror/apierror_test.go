@@ -0,0 +1,68 @@
+package apierror_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"shems/apierror"
+	"shems/validation"
+	"strings"
+	"testing"
+)
+
+func TestInvalidJSON(t *testing.T) {
+	type address struct {
+		Street uint32 `json:"street"`
+	}
+	type request struct {
+		Name    string   `json:"name"`
+		Count   int      `json:"count"`
+		Active  bool     `json:"active"`
+		Tags    []string `json:"tags"`
+		Address address  `json:"address"`
+	}
+
+	tests := []struct {
+		name    string
+		body    string
+		status  int
+		message string
+		fields  validation.Errors
+	}{
+		{name: "empty body", body: "", status: http.StatusBadRequest, message: "Request body must be valid JSON"},
+		{name: "syntax error", body: `{"name": }`, status: http.StatusBadRequest, message: "Request body must be valid JSON"},
+		{name: "string for a number", body: `{"count": "3"}`, status: http.StatusBadRequest, message: "Validation failed", fields: validation.Errors{{Field: "count", Message: "must be a whole number"}}},
+		{name: "number for a string", body: `{"name": 3}`, status: http.StatusBadRequest, message: "Validation failed", fields: validation.Errors{{Field: "name", Message: "must be a string"}}},
+		{name: "string for a bool", body: `{"active": "yes"}`, status: http.StatusBadRequest, message: "Validation failed", fields: validation.Errors{{Field: "active", Message: "must be true or false"}}},
+		{name: "object for a list", body: `{"tags": {}}`, status: http.StatusBadRequest, message: "Validation failed", fields: validation.Errors{{Field: "tags", Message: "must be a list"}}},
+		{name: "negative unsigned number", body: `{"address": {"street": -1}}`, status: http.StatusBadRequest, message: "Validation failed", fields: validation.Errors{{Field: "address.street", Message: "must be a non negative whole number"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req request
+			err := json.NewDecoder(strings.NewReader(tt.body)).Decode(&req)
+			if err == nil {
+				t.Fatal("Decode() returned no error")
+			}
+			e := apierror.InvalidJSON(err)
+			if e.Status != tt.status || e.Message != tt.message || !reflect.DeepEqual(e.Fields, tt.fields) {
+				t.Errorf("InvalidJSON() = %d %q %v, want %d %q %v", e.Status, e.Message, e.Fields, tt.status, tt.message, tt.fields)
+			}
+		})
+	}
+}
+
+func TestInvalidJSONTooLarge(t *testing.T) {
+	w := httptest.NewRecorder()
+	body := http.MaxBytesReader(w, io.NopCloser(strings.NewReader(`{"name": "a long name"}`)), 4)
+	var req struct {
+		Name string `json:"name"`
+	}
+	err := json.NewDecoder(body).Decode(&req)
+	if e := apierror.InvalidJSON(err); e.Status != http.StatusRequestEntityTooLarge {
+		t.Errorf("InvalidJSON() status = %d, want %d", e.Status, http.StatusRequestEntityTooLarge)
+	}
+}
@@ -0,0 +1,132 @@
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"shems/logging"
+	"shems/model"
+)
+
+const requestIdHeader = "X-Request-Id"
+
+// Incoming request ids are kept when they look like ids, anything else is
+// replaced so that the audit log cannot be filled with arbitrary text
+var requestIdPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// Execer is satisfied by both *sql.DB and *sql.Tx, so that an entry can be
+// written in the same transaction as the change it describes
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// Querier is satisfied by both *sql.DB and *sql.Tx
+type Querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// WithRequestId makes sure every request carries an id, which is echoed in
+// the response, stored with the audit log entries of the request and added
+// to the log records of its context
+func WithRequestId(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestId := SetRequestId(r)
+		w.Header().Set(requestIdHeader, requestId)
+		next.ServeHTTP(w, r.WithContext(logging.WithRequestId(r.Context(), requestId)))
+	})
+}
+
+// SetRequestId gives the request a new id unless it carries a valid one, and
+// returns the id
+func SetRequestId(r *http.Request) string {
+	requestId := r.Header.Get(requestIdHeader)
+	if !requestIdPattern.MatchString(requestId) {
+		bytes := make([]byte, 16)
+		rand.Read(bytes)
+		requestId = hex.EncodeToString(bytes)
+		r.Header.Set(requestIdHeader, requestId)
+	}
+	return requestId
+}
+
+func GetRequestId(r *http.Request) string {
+	return r.Header.Get(requestIdHeader)
+}
+
+func toJSON(value interface{}) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return string(bytes), nil
+}
+
+// Record writes an audit log entry. Details and the before and after
+// snapshots are stored as JSON.
+func Record(ctx context.Context, db Execer, entry model.AuditLog) error {
+	details, err := toJSON(entry.Details)
+	if err != nil {
+		return err
+	}
+	before, err := toJSON(entry.Before)
+	if err != nil {
+		return err
+	}
+	after, err := toJSON(entry.After)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.ExecContext(ctx, queryToAddAuditLog(), entry.ActorType, entry.ActorId, entry.ImpersonatedBy, entry.Action, entry.EntityType, entry.EntityId, details, before, after, entry.Ip, entry.RequestId)
+	return err
+}
+
+// GetLogs returns up to limit entries matching the filter, newest first
+func GetLogs(ctx context.Context, db Querier, filter model.AuditLogFilter, limit int) ([]model.AuditLog, error) {
+	rows, err := db.QueryContext(ctx, queryToGetAuditLogs(),
+		filter.ActorType, filter.ActorType,
+		filter.ActorId, filter.ActorId,
+		filter.ImpersonatedBy, filter.ImpersonatedBy,
+		filter.Action, filter.Action,
+		filter.EntityType, filter.EntityType,
+		filter.EntityId, filter.EntityId,
+		filter.RequestId, filter.RequestId,
+		filter.StartDate, filter.StartDate,
+		filter.EndDate, filter.EndDate,
+		filter.BeforeId, filter.BeforeId,
+		limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logs []model.AuditLog
+	for rows.Next() {
+		var entry model.AuditLog
+		var details, before, after sql.NullString
+		err = rows.Scan(&entry.Id, &entry.ActorType, &entry.ActorId, &entry.ImpersonatedBy, &entry.Action, &entry.EntityType, &entry.EntityId, &details, &before, &after, &entry.Ip, &entry.RequestId, &entry.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		// JSON columns are passed through as they are stored
+		if details.Valid {
+			entry.Details = json.RawMessage(details.String)
+		}
+		if before.Valid {
+			entry.Before = json.RawMessage(before.String)
+		}
+		if after.Valid {
+			entry.After = json.RawMessage(after.String)
+		}
+		logs = append(logs, entry)
+	}
+	return logs, rows.Err()
+}
@@ -0,0 +1,44 @@
+package audit
+
+import "shems/tracing"
+
+func init() {
+	tracing.RegisterQueries(
+		queryToAddAuditLog,
+		queryToGetAuditLogs,
+	)
+}
+
+func queryToAddAuditLog() string {
+	sqlQuery := `
+				INSERT INTO Audit_Logs
+					(actor_type, actor_id, impersonated_by, action, entity_type, entity_id, details, before_data, after_data, ip, request_id)
+				VALUES
+					(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+				`
+	return sqlQuery
+}
+
+func queryToGetAuditLogs() string {
+	sqlQuery := `
+	SELECT
+		id, actor_type, actor_id, impersonated_by, action, entity_type, entity_id, details, before_data, after_data, ip, request_id, created_at
+	FROM
+		Audit_Logs
+	WHERE
+		(? = '' OR actor_type = ?)
+		AND (? = 0 OR actor_id = ?)
+		AND (? = 0 OR impersonated_by = ?)
+		AND (? = '' OR action = ?)
+		AND (? = '' OR entity_type = ?)
+		AND (? = '' OR entity_id = ?)
+		AND (? = '' OR request_id = ?)
+		AND (? = '' OR created_at >= ?)
+		AND (? = '' OR created_at <= ?)
+		AND (? = 0 OR id < ?)
+	ORDER BY
+		id DESC
+	LIMIT ?;
+	`
+	return sqlQuery
+}
@@ -0,0 +1,270 @@
+package carbon
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"shems/admin"
+	"shems/apierror"
+	"shems/model"
+	redisService "shems/redis"
+	"shems/users"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func ImportCarbonIntensities(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	// the intensities are shared by the emissions of every customer
+	a, ok := admin.RequireAdmin(ctx, w, r, conn, redisClient)
+	if !ok {
+		return
+	}
+
+	// CSV data is either uploaded as a multipart file or sent as the raw body
+	var reader io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			apierror.Write(w, r, apierror.BadRequest("File is required"))
+			return
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	intensities, err := parseCarbonIntensities(reader)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+	if len(intensities) == 0 {
+		apierror.Write(w, r, apierror.BadRequest("No carbon intensities found in the file"))
+		return
+	}
+
+	redisKey := "ImportCarbonIntensities"
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		// release redis lock
+		redisService.ReleaseLock(ctx, redisClient, redisKey)
+
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// take redis lock to avoid concurrent imports
+	err = users.TakeRedisLock(ctx, redisClient, redisKey)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// upsert carbon intensities
+	query := queryToUpsertCarbonIntensity()
+	for _, ci := range intensities {
+		_, err = tx.ExecContext(ctx, query, ci.Zipcode, ci.Hour, ci.Value)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	}
+
+	err = admin.RecordAudit(ctx, tx, r, a, "import carbon intensities", model.AuditEntityCarbonIntensities, "", map[string]int{"importedCount": len(intensities)})
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	resp := model.ImportCarbonIntensitiesResponse{
+		ImportedCount: uint32(len(intensities)),
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func GetCarbonReport(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	// the customer is the one the session belongs to
+	session, ok := users.GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+	customerId := session.CustomerId
+
+	currentDate := r.URL.Query().Get("currentDate")
+	startDateTime, err := users.GetStartOfMonth(currentDate)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+	endDateTime := startDateTime.AddDate(0, 1, 0).Add(-time.Second)
+
+	resp := model.CarbonReportResponse{
+		Month: startDateTime.Format("January 2006"),
+	}
+
+	// get carbon emissions by service locations
+	query := queryToFetchCarbonEmissionsByServiceLocations()
+	rows, err := db.QueryContext(ctx, query, startDateTime, startDateTime, endDateTime, customerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var sle model.ServiceLocationEmissions
+		var unitNumber, street uint32
+		var city, state, zipcode, country string
+		err = rows.Scan(&sle.LocationId, &unitNumber, &street, &city, &state, &zipcode, &country, &sle.EnergyConsumption, &sle.CarbonEmissions)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		sle.LocationLabel = fmt.Sprint(unitNumber, ", ", street, ", ", city, ", ", state, ", ", zipcode, ", ", country)
+		if sle.EnergyConsumption > 0 {
+			// emissions are in kg, intensity in g per kWh
+			sle.AverageCarbonIntensity = sle.CarbonEmissions * 1000 / sle.EnergyConsumption
+		}
+
+		resp.ServiceLocationEmissions = append(resp.ServiceLocationEmissions, sle)
+		resp.TotalEnergyConsumption += sle.EnergyConsumption
+		resp.TotalCarbonEmissions += sle.CarbonEmissions
+	}
+
+	// get carbon emissions by devices
+	query = queryToFetchCarbonEmissionsByDevices()
+	rows, err = db.QueryContext(ctx, query, startDateTime, endDateTime, customerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ede model.EnrolledDeviceEmissions
+		var edId uint32
+		var dType, dModelNumber, edAliasName string
+		err = rows.Scan(&edId, &dType, &dModelNumber, &edAliasName, &ede.EnergyConsumption, &ede.CarbonEmissions)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		ede.DeviceLabel = fmt.Sprint(edAliasName, " (", dType, " - ", dModelNumber, ")")
+		resp.EnrolledDeviceEmissions = append(resp.EnrolledDeviceEmissions, ede)
+	}
+
+	json.NewEncoder(w).Encode(resp)
+}
+
+func GetLowCarbonWindows(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	// the customer is the one the session belongs to
+	session, ok := users.GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+	customerId := session.CustomerId
+
+	// Get service location id from query params
+	serviceLocationIdInt, err := users.GetIdFromQueryParams(r, "serviceLocationId", "Service Location Id")
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+
+	// window length in hours, defaults to 1
+	duration := 1
+	if durationStr := r.URL.Query().Get("duration"); len(durationStr) > 0 {
+		duration, err = strconv.Atoi(durationStr)
+		if err != nil || duration < 1 || duration > 24 {
+			apierror.Write(w, r, apierror.BadRequest("Duration must be between 1 and 24 hours"))
+			return
+		}
+	}
+
+	// number of windows to recommend, defaults to 3
+	count := 3
+	if countStr := r.URL.Query().Get("count"); len(countStr) > 0 {
+		count, err = strconv.Atoi(countStr)
+		if err != nil || count < 1 {
+			apierror.Write(w, r, apierror.BadRequest("Count must be greater than 0"))
+			return
+		}
+	}
+
+	// validation: check if service location is visible to the customer
+	query := queryToGetServiceLocationZipcode()
+	rows, err := db.QueryContext(ctx, query, serviceLocationIdInt, customerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var checkId int
+	var zipcode string
+	for rows.Next() {
+		err = rows.Scan(&checkId, &zipcode)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	}
+	if checkId != serviceLocationIdInt {
+		apierror.Write(w, r, apierror.NotFound("Service Location does not exist"))
+		return
+	}
+
+	intensities, err := getHourlyValues(ctx, db, queryToGetCarbonIntensitiesByZipcode(), zipcode)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if len(intensities) == 0 {
+		apierror.Write(w, r, apierror.BadRequest("No carbon intensity data for this zipcode"))
+		return
+	}
+
+	prices, err := getHourlyValues(ctx, db, queryToGetPricesByZipcode(), zipcode)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	resp := model.GetLowCarbonWindowsResponse{
+		Zipcode:          zipcode,
+		LowCarbonWindows: findLowCarbonWindows(intensities, prices, duration, count),
+	}
+	json.NewEncoder(w).Encode(resp)
+}
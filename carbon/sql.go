@@ -0,0 +1,134 @@
+package carbon
+
+import "shems/tracing"
+
+func init() {
+	tracing.RegisterQueries(
+		queryToUpsertCarbonIntensity,
+		queryToGetServiceLocationZipcode,
+		queryToGetCarbonIntensitiesByZipcode,
+		queryToGetPricesByZipcode,
+		queryToFetchCarbonEmissionsByServiceLocations,
+		queryToFetchCarbonEmissionsByDevices,
+	)
+}
+
+func queryToUpsertCarbonIntensity() string {
+	sqlQuery := `
+				INSERT INTO Carbon_Intensities
+					(zipcode, hour, value)
+				VALUES
+					(?, ?, ?)
+				ON DUPLICATE KEY UPDATE
+					value = VALUES(value);
+				`
+	return sqlQuery
+}
+
+func queryToGetServiceLocationZipcode() string {
+	sqlQuery := `
+	SELECT
+		sl.id, l.zipcode
+	FROM
+		Service_Locations sl
+	INNER JOIN
+		Locations l ON l.id = sl.location_id
+	WHERE
+		sl.id = ?
+		AND sl.id IN (SELECT service_location_id FROM Service_Location_Members WHERE customer_id = ?);
+	`
+	return sqlQuery
+}
+
+func queryToGetCarbonIntensitiesByZipcode() string {
+	sqlQuery := `
+	SELECT
+		hour, value
+	FROM
+		Carbon_Intensities
+	WHERE
+		zipcode = ?;
+	`
+	return sqlQuery
+}
+
+func queryToGetPricesByZipcode() string {
+	sqlQuery := `
+	SELECT
+		hour, value
+	FROM
+		Prices
+	WHERE
+		zipcode = ?;
+	`
+	return sqlQuery
+}
+
+func queryToFetchCarbonEmissionsByServiceLocations() string {
+	sqlQuery := `
+	SELECT
+		l.id AS location_id,
+		l.unit_number,
+		l.street,
+		l.city,
+		l.state,
+		l.zipcode,
+		l.country,
+		SUM(CASE WHEN e.value IS NOT NULL THEN e.value ELSE 0 END) AS total_energy_consumption,
+		SUM(CASE WHEN e.value IS NOT NULL AND ci.value IS NOT NULL THEN e.value * ci.value ELSE 0 END) / 1000 AS total_carbon_emissions
+	FROM
+		Service_Locations sl
+	INNER JOIN
+		Service_Location_History slh ON slh.service_location_id = sl.id AND (slh.valid_to IS NULL OR slh.valid_to > ?)
+	INNER JOIN
+		Locations l ON l.id = slh.location_id
+	LEFT JOIN
+		Enrolled_Device_History edh ON edh.service_location_id = sl.id
+	LEFT JOIN
+		Events e ON e.enrolled_device_id = edh.enrolled_device_id AND e.label = 'energy use' AND e.created_at >= ? AND e.created_at <= ?
+			AND e.created_at >= edh.valid_from AND (edh.valid_to IS NULL OR e.created_at < edh.valid_to)
+			AND e.created_at >= slh.valid_from AND (slh.valid_to IS NULL OR e.created_at < slh.valid_to)
+	LEFT JOIN
+		Carbon_Intensities ci ON ci.zipcode = l.zipcode AND ci.hour = HOUR(e.created_at) + 1
+	WHERE
+		sl.id IN (SELECT service_location_id FROM Service_Location_Members WHERE customer_id = ?)
+	GROUP BY
+		1, 2, 3, 4, 5, 6, 7;
+	`
+	return sqlQuery
+}
+
+func queryToFetchCarbonEmissionsByDevices() string {
+	sqlQuery := `
+	SELECT
+		ed.id AS enrolled_device_id,
+		d.type,
+		d.model_number,
+		ed.alias_name,
+		SUM(CASE WHEN e.value IS NOT NULL THEN e.value ELSE 0 END) AS total_energy_consumption,
+		SUM(CASE WHEN e.value IS NOT NULL AND ci.value IS NOT NULL THEN e.value * ci.value ELSE 0 END) / 1000 AS total_carbon_emissions
+	FROM
+		Enrolled_Devices ed
+	INNER JOIN
+		Enrolled_Device_History edh ON edh.enrolled_device_id = ed.id
+	INNER JOIN
+		Service_Locations sl ON sl.id = edh.service_location_id
+	INNER JOIN
+		Service_Location_History slh ON slh.service_location_id = sl.id
+	INNER JOIN
+		Locations l ON l.id = slh.location_id
+	INNER JOIN
+		Devices d ON d.id = ed.device_id
+	LEFT JOIN
+		Events e ON e.enrolled_device_id = ed.id AND e.label = 'energy use' AND e.created_at >= ? AND e.created_at <= ?
+			AND e.created_at >= edh.valid_from AND (edh.valid_to IS NULL OR e.created_at < edh.valid_to)
+			AND e.created_at >= slh.valid_from AND (slh.valid_to IS NULL OR e.created_at < slh.valid_to)
+	LEFT JOIN
+		Carbon_Intensities ci ON ci.zipcode = l.zipcode AND ci.hour = HOUR(e.created_at) + 1
+	WHERE
+		sl.id IN (SELECT service_location_id FROM Service_Location_Members WHERE customer_id = ?)
+	GROUP BY
+		1, 2, 3, 4;
+	`
+	return sqlQuery
+}
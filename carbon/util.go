@@ -0,0 +1,135 @@
+package carbon
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"shems/model"
+	"shems/validation"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// parseCarbonIntensities reads zipcode,hour,value records where value is the
+// grid carbon intensity in gCO2/kWh. A leading header row is skipped.
+func parseCarbonIntensities(reader io.Reader) ([]model.CarbonIntensity, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = 3
+	csvReader.TrimLeadingSpace = true
+
+	var intensities []model.CarbonIntensity
+	for line := 1; ; line++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: must have zipcode, hour and value", line)
+		}
+
+		// the header row is the only one without an hour number
+		if _, err := strconv.ParseUint(strings.TrimSpace(record[1]), 10, 32); err != nil && line == 1 {
+			continue
+		}
+
+		zipcode := validation.NormalizePostalCode(record[0])
+		if len(zipcode) == 0 || len(zipcode) > 16 {
+			return nil, fmt.Errorf("line %d: invalid zipcode %q", line, record[0])
+		}
+		hour, err := strconv.ParseUint(strings.TrimSpace(record[1]), 10, 32)
+		if err != nil || hour < 1 || hour > 24 {
+			return nil, fmt.Errorf("line %d: hour must be between 1 and 24", line)
+		}
+		value, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 32)
+		if err != nil || value < 0 {
+			return nil, fmt.Errorf("line %d: invalid carbon intensity %q", line, record[2])
+		}
+
+		intensities = append(intensities, model.CarbonIntensity{
+			Zipcode: zipcode,
+			Hour:    uint32(hour),
+			Value:   float32(value),
+		})
+	}
+	return intensities, nil
+}
+
+// getHourlyValues loads an hour -> value map from Prices or Carbon_Intensities
+func getHourlyValues(ctx context.Context, db *sql.DB, query string, zipcode string) (map[uint32]float32, error) {
+	rows, err := db.QueryContext(ctx, query, zipcode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make(map[uint32]float32)
+	for rows.Next() {
+		var hour uint32
+		var value float32
+		err = rows.Scan(&hour, &value)
+		if err != nil {
+			return nil, err
+		}
+		values[hour] = value
+	}
+	return values, rows.Err()
+}
+
+// findLowCarbonWindows ranks every window of duration consecutive hours of the
+// day (wrapping around midnight) by average carbon intensity and returns up
+// to count non overlapping windows, lowest intensity first. Hours run from 1
+// to 24 like in Prices and EndHour is the last hour inside the window.
+func findLowCarbonWindows(intensities, prices map[uint32]float32, duration, count int) []model.LowCarbonWindow {
+	var windows []model.LowCarbonWindow
+	for start := 1; start <= 24; start++ {
+		var intensitySum, priceSum float32
+		for i := 0; i < duration; i++ {
+			hour := uint32((start-1+i)%24 + 1)
+			intensitySum += intensities[hour]
+			priceSum += prices[hour]
+		}
+
+		windows = append(windows, model.LowCarbonWindow{
+			StartHour:              uint32(start),
+			EndHour:                uint32((start-2+duration)%24 + 1),
+			AverageCarbonIntensity: intensitySum / float32(duration),
+			AveragePrice:           priceSum / float32(duration),
+		})
+	}
+
+	// lowest intensity first, cheaper windows win ties
+	sort.SliceStable(windows, func(i, j int) bool {
+		if windows[i].AverageCarbonIntensity != windows[j].AverageCarbonIntensity {
+			return windows[i].AverageCarbonIntensity < windows[j].AverageCarbonIntensity
+		}
+		return windows[i].AveragePrice < windows[j].AveragePrice
+	})
+
+	usedHours := make(map[uint32]bool)
+	var lowCarbonWindows []model.LowCarbonWindow
+	for _, window := range windows {
+		if len(lowCarbonWindows) == count {
+			break
+		}
+
+		overlaps := false
+		for i := 0; i < duration; i++ {
+			if usedHours[(window.StartHour-1+uint32(i))%24+1] {
+				overlaps = true
+				break
+			}
+		}
+		if overlaps {
+			continue
+		}
+
+		for i := 0; i < duration; i++ {
+			usedHours[(window.StartHour-1+uint32(i))%24+1] = true
+		}
+		lowCarbonWindows = append(lowCarbonWindows, window)
+	}
+	return lowCarbonWindows
+}
@@ -0,0 +1,6 @@
+package charging
+
+type ChargerEvent = chargerEvent
+
+var DetectSessions = detectChargingSessions
+var ScheduleCharging = scheduleCharging
@@ -0,0 +1,563 @@
+package charging
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"shems/access"
+	"shems/apierror"
+	"shems/dbutil"
+	"shems/model"
+	redisService "shems/redis"
+	"shems/users"
+	"shems/validation"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func GetChargingSessions(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	// the customer is the one the session belongs to
+	session, ok := users.GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+	customerId := session.CustomerId
+
+	currentDate := r.URL.Query().Get("currentDate")
+	startDateTime, err := users.GetStartOfMonth(currentDate)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+	endDateTime := startDateTime.AddDate(0, 1, 0).Add(-time.Second)
+
+	// get charging sessions of the month
+	query := queryToGetChargingSessions()
+	rows, err := db.QueryContext(ctx, query, customerId, startDateTime, endDateTime)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var chargingSessions []model.ChargingSession
+	var cs model.ChargingSession
+	for rows.Next() {
+		err = rows.Scan(&cs.Id, &cs.EnrolledDeviceId, &cs.ServiceLocationId, &cs.VehicleLabel, &cs.StartedAt, &cs.EndedAt, &cs.EnergyDelivered, &cs.PeakPower, &cs.Cost)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		chargingSessions = append(chargingSessions, cs)
+	}
+
+	resp := model.GetChargingSessionsResponse{
+		ChargingSessions: chargingSessions,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func DetectChargingSessions(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.DetectChargingSessionsRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := users.GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+	req.CustomerId = session.CustomerId
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	// validation: check if customer can manage devices of the charger's service location
+	err = access.CheckEnrolledDevicePermission(ctx, conn, req.CustomerId, req.EnrolledDeviceId, access.ManageDevices)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// validation: check if enrolled device is a charger
+	query := queryToGetCharger()
+	rows, err := conn.QueryContext(ctx, query, req.EnrolledDeviceId, model.EVChargerDeviceType)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var enrolledDeviceId, serviceLocationId uint32
+	var zipcode string
+	for rows.Next() {
+		err = rows.Scan(&enrolledDeviceId, &serviceLocationId, &zipcode)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	}
+	if enrolledDeviceId != req.EnrolledDeviceId {
+		apierror.Write(w, r, apierror.NotFound("EV Charger does not exist"))
+		return
+	}
+
+	redisKey := "DetectChargingSessions_EnrolledDeviceId_" + fmt.Sprint(req.EnrolledDeviceId)
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		// release redis lock
+		redisService.ReleaseLock(ctx, redisClient, redisKey)
+
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// take redis lock to avoid detecting the same sessions twice
+	err = users.TakeRedisLock(ctx, redisClient, redisKey)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// get hourly prices of the location's tariff
+	prices, err := getPricesByZipcode(ctx, tx, zipcode)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// only look at events after the last detected session
+	query = queryToGetLastChargingSessionEnd()
+	rows, err = tx.QueryContext(ctx, query, req.EnrolledDeviceId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var lastSessionEnd string
+	for rows.Next() {
+		err = rows.Scan(&lastSessionEnd)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	}
+
+	query = queryToGetChargerEvents()
+	rows, err = tx.QueryContext(ctx, query, req.EnrolledDeviceId, lastSessionEnd)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var events []chargerEvent
+	for rows.Next() {
+		var e chargerEvent
+		var createdAt string
+		err = rows.Scan(&e.Value, &createdAt)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		e.CreatedAt, err = time.ParseInLocation(dbutil.TimeLayout, createdAt, time.Local)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		events = append(events, e)
+	}
+
+	chargingSessions := detectChargingSessions(events, prices, time.Now())
+
+	// insert query to add detected sessions
+	query = queryToAddChargingSession()
+	for i := range chargingSessions {
+		chargingSessions[i].EnrolledDeviceId = req.EnrolledDeviceId
+		chargingSessions[i].ServiceLocationId = serviceLocationId
+		chargingSessions[i].VehicleLabel = req.VehicleLabel
+
+		cs := chargingSessions[i]
+		result, err := tx.ExecContext(ctx, query, cs.EnrolledDeviceId, cs.VehicleLabel, cs.StartedAt, cs.EndedAt, cs.EnergyDelivered, cs.PeakPower, cs.Cost)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		chargingSessions[i].Id = uint32(id)
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	resp := model.DetectChargingSessionsResponse{
+		ChargingSessions: chargingSessions,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func UpdateChargingSession(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.UpdateChargingSessionRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := users.GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+	req.CustomerId = session.CustomerId
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	// validation: check if charging session exists in a service location where
+	// the customer can manage devices
+	var enrolledDeviceId uint32
+	query := queryToGetChargingSessionEnrolledDevice()
+	err = db.QueryRowContext(ctx, query, req.ChargingSessionId).Scan(&enrolledDeviceId)
+	if err != nil && err != sql.ErrNoRows {
+		apierror.Write(w, r, err)
+		return
+	}
+	_, role, err := access.GetEnrolledDeviceRole(ctx, db, req.CustomerId, enrolledDeviceId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	err = access.CheckRole(role, access.ManageDevices, "Charging Session does not exist")
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// update charging session
+	query = queryToUpdateChargingSession()
+	_, err = db.ExecContext(ctx, query, req.VehicleLabel, req.ChargingSessionId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// respond with a success message
+	json.NewEncoder(w).Encode(map[string]string{"message": "Charging session updated successfully"})
+}
+
+func AddChargingTarget(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.AddChargingTargetRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := users.GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+	req.CustomerId = session.CustomerId
+
+	// validate the request
+	errs := validation.Validate(req)
+	if req.TargetChargePercent <= req.CurrentChargePercent {
+		errs = append(errs, validation.FieldError{Field: "targetChargePercent", Message: "must be above currentChargePercent"})
+	}
+	if len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	now := time.Now()
+	deadline, err := getDeadline(req.TargetTime, now)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Target Time must be in HH:MM format"))
+		return
+	}
+
+	// validation: check if customer can manage devices of the charger's service location
+	err = access.CheckEnrolledDevicePermission(ctx, conn, req.CustomerId, req.EnrolledDeviceId, access.ManageDevices)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// validation: check if enrolled device is a charger
+	query := queryToGetCharger()
+	rows, err := conn.QueryContext(ctx, query, req.EnrolledDeviceId, model.EVChargerDeviceType)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var enrolledDeviceId, serviceLocationId uint32
+	var zipcode string
+	for rows.Next() {
+		err = rows.Scan(&enrolledDeviceId, &serviceLocationId, &zipcode)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	}
+	if enrolledDeviceId != req.EnrolledDeviceId {
+		apierror.Write(w, r, apierror.NotFound("EV Charger does not exist"))
+		return
+	}
+
+	redisKey := "AddChargingTarget_CustomerId_" + fmt.Sprint(req.CustomerId)
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		// release redis lock
+		redisService.ReleaseLock(ctx, redisClient, redisKey)
+
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// take redis lock to avoid concurrent access or double clicking
+	err = users.TakeRedisLock(ctx, redisClient, redisKey)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// get hourly prices of the location's tariff
+	prices, err := getPricesByZipcode(ctx, tx, zipcode)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// schedule charging into the cheapest hours before the deadline
+	energyRequired := req.BatteryCapacity * (req.TargetChargePercent - req.CurrentChargePercent) / 100
+	slots, err := scheduleCharging(now, deadline, energyRequired, req.ChargerPower, prices)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+
+	target := model.ChargingTarget{
+		EnrolledDeviceId:     req.EnrolledDeviceId,
+		VehicleLabel:         req.VehicleLabel,
+		BatteryCapacity:      req.BatteryCapacity,
+		CurrentChargePercent: req.CurrentChargePercent,
+		TargetChargePercent:  req.TargetChargePercent,
+		ChargerPower:         req.ChargerPower,
+		Deadline:             deadline.Format(dbutil.TimeLayout),
+		EnergyRequired:       energyRequired,
+		Slots:                slots,
+	}
+	for _, s := range slots {
+		target.EstimatedCost += s.Cost
+	}
+
+	// insert query to add charging target
+	query = queryToAddChargingTarget()
+	result, err := tx.ExecContext(ctx, query, target.EnrolledDeviceId, target.VehicleLabel, target.BatteryCapacity, target.CurrentChargePercent, target.TargetChargePercent, target.ChargerPower, target.Deadline, target.EnergyRequired, target.EstimatedCost)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	targetId, err := result.LastInsertId()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	target.Id = uint32(targetId)
+
+	// insert query to add the charging schedule
+	query = queryToAddChargingSchedule()
+	for _, s := range slots {
+		_, err = tx.ExecContext(ctx, query, target.Id, s.StartTime, s.EndTime, s.Energy, s.Price)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	json.NewEncoder(w).Encode(target)
+}
+
+func GetChargingTargets(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	// the customer is the one the session belongs to
+	session, ok := users.GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+	customerId := session.CustomerId
+
+	// only targets which have not passed yet
+	now := time.Now().Format(dbutil.TimeLayout)
+
+	// get charging targets
+	query := queryToGetChargingTargets()
+	rows, err := db.QueryContext(ctx, query, customerId, now)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var chargingTargets []model.ChargingTarget
+	var ct model.ChargingTarget
+	for rows.Next() {
+		err = rows.Scan(&ct.Id, &ct.EnrolledDeviceId, &ct.VehicleLabel, &ct.BatteryCapacity, &ct.CurrentChargePercent, &ct.TargetChargePercent, &ct.ChargerPower, &ct.Deadline, &ct.EnergyRequired, &ct.EstimatedCost)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		chargingTargets = append(chargingTargets, ct)
+	}
+
+	// get charging schedules of the targets
+	query = queryToGetChargingSchedules()
+	rows, err = db.QueryContext(ctx, query, customerId, now)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	slotsMap := make(map[uint32][]model.ChargingSlot)
+	for rows.Next() {
+		var targetId uint32
+		var s model.ChargingSlot
+		err = rows.Scan(&targetId, &s.StartTime, &s.EndTime, &s.Energy, &s.Price)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		s.Cost = s.Energy * s.Price
+		slotsMap[targetId] = append(slotsMap[targetId], s)
+	}
+
+	for i := range chargingTargets {
+		chargingTargets[i].Slots = slotsMap[chargingTargets[i].Id]
+	}
+
+	resp := model.GetChargingTargetsResponse{
+		ChargingTargets: chargingTargets,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func GetChargingReport(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	// the customer is the one the session belongs to
+	session, ok := users.GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+	customerId := session.CustomerId
+
+	currentDate := r.URL.Query().Get("currentDate")
+	startDateTime, err := users.GetStartOfMonth(currentDate)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+	endDateTime := startDateTime.AddDate(0, 1, 0).Add(-time.Second)
+
+	// get charging costs by service locations
+	query := queryToFetchChargingCostsByServiceLocations()
+	rows, err := db.QueryContext(ctx, query, startDateTime, startDateTime, endDateTime, customerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	resp := model.ChargingReportResponse{
+		Month: startDateTime.Format("January 2006"),
+	}
+	for rows.Next() {
+		var slc model.ServiceLocationChargingCost
+		var unitNumber, street uint32
+		var city, state, zipcode, country string
+		err = rows.Scan(&slc.ServiceLocationId, &unitNumber, &street, &city, &state, &zipcode, &country, &slc.SessionsCount, &slc.EnergyDelivered, &slc.Cost)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		slc.LocationLabel = fmt.Sprint(unitNumber, ", ", street, ", ", city, ", ", state, ", ", zipcode, ", ", country)
+		resp.ServiceLocationCosts = append(resp.ServiceLocationCosts, slc)
+		resp.TotalEnergyDelivered += slc.EnergyDelivered
+		resp.TotalCost += slc.Cost
+	}
+
+	json.NewEncoder(w).Encode(resp)
+}
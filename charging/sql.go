@@ -0,0 +1,227 @@
+package charging
+
+import "shems/tracing"
+
+func init() {
+	tracing.RegisterQueries(
+		queryToGetCharger,
+		queryToGetPricesByZipcode,
+		queryToGetLastChargingSessionEnd,
+		queryToGetChargerEvents,
+		queryToAddChargingSession,
+		queryToGetChargingSessions,
+		queryToGetChargingSessionEnrolledDevice,
+		queryToUpdateChargingSession,
+		queryToAddChargingTarget,
+		queryToAddChargingSchedule,
+		queryToGetChargingTargets,
+		queryToGetChargingSchedules,
+		queryToFetchChargingCostsByServiceLocations,
+	)
+}
+
+func queryToGetCharger() string {
+	sqlQuery := `
+	SELECT
+		ed.id, sl.id, l.zipcode
+	FROM
+		Enrolled_Devices ed
+	INNER JOIN
+		Service_Locations sl ON ed.service_location_id = sl.id
+	INNER JOIN
+		Locations l ON l.id = sl.location_id
+	INNER JOIN
+		Devices d ON d.id = ed.device_id
+	WHERE
+		ed.id = ?
+		AND d.type = ?;
+	`
+	return sqlQuery
+}
+
+func queryToGetPricesByZipcode() string {
+	sqlQuery := `
+	SELECT
+		hour, value
+	FROM
+		Prices
+	WHERE
+		zipcode = ?;
+	`
+	return sqlQuery
+}
+
+func queryToGetLastChargingSessionEnd() string {
+	sqlQuery := `
+	SELECT
+		COALESCE(MAX(ended_at), '1970-01-01 00:00:00')
+	FROM
+		Charging_Sessions
+	WHERE
+		enrolled_device_id = ?;
+	`
+	return sqlQuery
+}
+
+func queryToGetChargerEvents() string {
+	sqlQuery := `
+	SELECT
+		e.value, e.created_at
+	FROM
+		Events e
+	WHERE
+		e.enrolled_device_id = ?
+		AND e.label = 'energy use'
+		AND e.created_at > ?
+	ORDER BY
+		e.created_at;
+	`
+	return sqlQuery
+}
+
+func queryToAddChargingSession() string {
+	sqlQuery := `
+				INSERT INTO Charging_Sessions
+					(enrolled_device_id, vehicle_label, started_at, ended_at, energy_delivered, peak_power, cost)
+				VALUES
+					(?, ?, ?, ?, ?, ?, ?);
+				`
+	return sqlQuery
+}
+
+func queryToGetChargingSessions() string {
+	sqlQuery := `
+	SELECT
+		cs.id, cs.enrolled_device_id, ed.service_location_id, cs.vehicle_label, cs.started_at, cs.ended_at, cs.energy_delivered, cs.peak_power, cs.cost
+	FROM
+		Charging_Sessions cs
+	INNER JOIN
+		Enrolled_Devices ed ON ed.id = cs.enrolled_device_id
+	INNER JOIN
+		Service_Locations sl ON sl.id = ed.service_location_id
+	WHERE
+		sl.id IN (SELECT service_location_id FROM Service_Location_Members WHERE customer_id = ?)
+		AND cs.started_at >= ?
+		AND cs.started_at <= ?
+	ORDER BY
+		cs.started_at DESC;
+	`
+	return sqlQuery
+}
+
+func queryToGetChargingSessionEnrolledDevice() string {
+	sqlQuery := `
+	SELECT
+		enrolled_device_id
+	FROM
+		Charging_Sessions
+	WHERE
+		id = ?;
+	`
+	return sqlQuery
+}
+
+func queryToUpdateChargingSession() string {
+	sqlQuery := `
+				UPDATE
+					Charging_Sessions
+				SET
+					vehicle_label = ?
+				WHERE
+					id = ?;
+				`
+	return sqlQuery
+}
+
+func queryToAddChargingTarget() string {
+	sqlQuery := `
+				INSERT INTO Charging_Targets
+					(enrolled_device_id, vehicle_label, battery_capacity, current_charge_percent, target_charge_percent, charger_power, deadline, energy_required, estimated_cost)
+				VALUES
+					(?, ?, ?, ?, ?, ?, ?, ?, ?);
+				`
+	return sqlQuery
+}
+
+func queryToAddChargingSchedule() string {
+	sqlQuery := `
+				INSERT INTO Charging_Schedules
+					(charging_target_id, start_time, end_time, energy, price)
+				VALUES
+					(?, ?, ?, ?, ?);
+				`
+	return sqlQuery
+}
+
+func queryToGetChargingTargets() string {
+	sqlQuery := `
+	SELECT
+		ct.id, ct.enrolled_device_id, ct.vehicle_label, ct.battery_capacity, ct.current_charge_percent, ct.target_charge_percent, ct.charger_power, ct.deadline, ct.energy_required, ct.estimated_cost
+	FROM
+		Charging_Targets ct
+	INNER JOIN
+		Enrolled_Devices ed ON ed.id = ct.enrolled_device_id
+	INNER JOIN
+		Service_Locations sl ON sl.id = ed.service_location_id
+	WHERE
+		sl.id IN (SELECT service_location_id FROM Service_Location_Members WHERE customer_id = ?)
+		AND ct.deadline >= ?
+	ORDER BY
+		ct.deadline;
+	`
+	return sqlQuery
+}
+
+func queryToGetChargingSchedules() string {
+	sqlQuery := `
+	SELECT
+		cs.charging_target_id, cs.start_time, cs.end_time, cs.energy, cs.price
+	FROM
+		Charging_Schedules cs
+	INNER JOIN
+		Charging_Targets ct ON ct.id = cs.charging_target_id
+	INNER JOIN
+		Enrolled_Devices ed ON ed.id = ct.enrolled_device_id
+	INNER JOIN
+		Service_Locations sl ON sl.id = ed.service_location_id
+	WHERE
+		sl.id IN (SELECT service_location_id FROM Service_Location_Members WHERE customer_id = ?)
+		AND ct.deadline >= ?
+	ORDER BY
+		cs.start_time;
+	`
+	return sqlQuery
+}
+
+func queryToFetchChargingCostsByServiceLocations() string {
+	sqlQuery := `
+	SELECT
+		sl.id AS service_location_id,
+		l.unit_number,
+		l.street,
+		l.city,
+		l.state,
+		l.zipcode,
+		l.country,
+		COUNT(cs.id) AS sessions_count,
+		SUM(CASE WHEN cs.energy_delivered IS NOT NULL THEN cs.energy_delivered ELSE 0 END) AS energy_delivered,
+		SUM(CASE WHEN cs.cost IS NOT NULL THEN cs.cost ELSE 0 END) AS cost
+	FROM
+		Service_Locations sl
+	INNER JOIN
+		Service_Location_History slh ON slh.service_location_id = sl.id AND (slh.valid_to IS NULL OR slh.valid_to > ?)
+	INNER JOIN
+		Locations l ON l.id = slh.location_id
+	LEFT JOIN
+		Enrolled_Device_History edh ON edh.service_location_id = sl.id
+	LEFT JOIN
+		Charging_Sessions cs ON cs.enrolled_device_id = edh.enrolled_device_id AND cs.started_at >= ? AND cs.started_at <= ?
+			AND cs.started_at >= edh.valid_from AND (edh.valid_to IS NULL OR cs.started_at < edh.valid_to)
+			AND cs.started_at >= slh.valid_from AND (slh.valid_to IS NULL OR cs.started_at < slh.valid_to)
+	WHERE
+		sl.id IN (SELECT service_location_id FROM Service_Location_Members WHERE customer_id = ?)
+	GROUP BY
+		1, 2, 3, 4, 5, 6, 7;
+	`
+	return sqlQuery
+}
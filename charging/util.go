@@ -0,0 +1,173 @@
+package charging
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"shems/dbutil"
+	"shems/model"
+	"sort"
+	"time"
+)
+
+// Charger readings further apart than this belong to different sessions
+const sessionGap = 30 * time.Minute
+
+type chargerEvent struct {
+	Value     float32
+	CreatedAt time.Time
+}
+
+// Prices are stored per zipcode for hours 1 to 24
+func getPrice(prices map[uint32]float32, t time.Time) float32 {
+	return prices[uint32(t.Hour()+1)]
+}
+
+// detectChargingSessions groups consecutive energy readings of a charger into
+// sessions. Sessions which could still be in progress at the given time are
+// left out so that they get picked up completely on a later run.
+func detectChargingSessions(events []chargerEvent, prices map[uint32]float32, now time.Time) []model.ChargingSession {
+	var sessions []model.ChargingSession
+	var current []chargerEvent
+
+	closeSession := func() {
+		if len(current) == 0 {
+			return
+		}
+
+		session := model.ChargingSession{
+			StartedAt: current[0].CreatedAt.Format(dbutil.TimeLayout),
+			EndedAt:   current[len(current)-1].CreatedAt.Format(dbutil.TimeLayout),
+		}
+
+		// peak power is the highest energy delivered within a single clock hour
+		hourlyEnergy := make(map[time.Time]float32)
+		for _, e := range current {
+			session.EnergyDelivered += e.Value
+			session.Cost += e.Value * getPrice(prices, e.CreatedAt)
+			hourlyEnergy[e.CreatedAt.Truncate(time.Hour)] += e.Value
+		}
+		for _, energy := range hourlyEnergy {
+			if energy > session.PeakPower {
+				session.PeakPower = energy
+			}
+		}
+
+		sessions = append(sessions, session)
+		current = nil
+	}
+
+	for _, e := range events {
+		// zero readings mean the charger is idle
+		if e.Value <= 0 {
+			closeSession()
+			continue
+		}
+		if len(current) > 0 && e.CreatedAt.Sub(current[len(current)-1].CreatedAt) > sessionGap {
+			closeSession()
+		}
+		current = append(current, e)
+	}
+
+	if len(current) > 0 && now.Sub(current[len(current)-1].CreatedAt) > sessionGap {
+		closeSession()
+	}
+	return sessions
+}
+
+// getDeadline returns the next occurrence of a "15:04" formatted time after now
+func getDeadline(targetTime string, now time.Time) (time.Time, error) {
+	t, err := time.Parse("15:04", targetTime)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	deadline := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+	if !deadline.After(now) {
+		deadline = deadline.AddDate(0, 0, 1)
+	}
+	return deadline, nil
+}
+
+// scheduleCharging spreads the required energy over the cheapest hours between
+// from and deadline, charging at full charger power within every chosen hour.
+func scheduleCharging(from, deadline time.Time, energyRequired, chargerPower float32, prices map[uint32]float32) ([]model.ChargingSlot, error) {
+	// the length of every slot is its energy divided by the charger power
+	if chargerPower <= 0 {
+		return nil, errors.New("Charger Power must be greater than 0")
+	}
+
+	type slot struct {
+		start time.Time
+		end   time.Time
+		price float32
+	}
+
+	var slots []slot
+	for cursor := from; cursor.Before(deadline); {
+		end := cursor.Truncate(time.Hour).Add(time.Hour)
+		if end.After(deadline) {
+			end = deadline
+		}
+		slots = append(slots, slot{start: cursor, end: end, price: getPrice(prices, cursor)})
+		cursor = end
+	}
+
+	// cheapest hours first, earlier hours win ties
+	sort.SliceStable(slots, func(i, j int) bool {
+		return slots[i].price < slots[j].price
+	})
+
+	var chargingSlots []model.ChargingSlot
+	remaining := energyRequired
+	for _, s := range slots {
+		if remaining <= 0 {
+			break
+		}
+
+		energy := chargerPower * float32(s.end.Sub(s.start).Hours())
+		if energy > remaining {
+			energy = remaining
+		}
+		duration := time.Duration(float64(energy/chargerPower) * float64(time.Hour))
+
+		chargingSlots = append(chargingSlots, model.ChargingSlot{
+			StartTime: s.start.Format(dbutil.TimeLayout),
+			EndTime:   s.start.Add(duration).Format(dbutil.TimeLayout),
+			Energy:    energy,
+			Price:     s.price,
+			Cost:      energy * s.price,
+		})
+		remaining -= energy
+	}
+
+	// allow for floating point leftovers
+	if remaining > 0.001 {
+		return nil, errors.New("Target charge cannot be reached before the target time")
+	}
+
+	sort.Slice(chargingSlots, func(i, j int) bool {
+		return chargingSlots[i].StartTime < chargingSlots[j].StartTime
+	})
+	return chargingSlots, nil
+}
+
+func getPricesByZipcode(ctx context.Context, tx *sql.Tx, zipcode string) (map[uint32]float32, error) {
+	rows, err := tx.QueryContext(ctx, queryToGetPricesByZipcode(), zipcode)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := make(map[uint32]float32)
+	for rows.Next() {
+		var hour uint32
+		var value float32
+		err = rows.Scan(&hour, &value)
+		if err != nil {
+			return nil, err
+		}
+		prices[hour] = value
+	}
+	return prices, rows.Err()
+}
@@ -0,0 +1,222 @@
+package charging_test
+
+import (
+	"math"
+	"shems/charging"
+	"testing"
+	"time"
+)
+
+func at(hour, minute int) time.Time {
+	return time.Date(2024, time.March, 10, hour, minute, 0, 0, time.UTC)
+}
+
+func almostEqual(a, b float32) bool {
+	return math.Abs(float64(a-b)) < 0.0001
+}
+
+func TestDetectChargingSessions(t *testing.T) {
+	// prices are keyed by hour 1 to 24, so 10:xx uses 11
+	prices := map[uint32]float32{11: 0.1, 12: 0.2}
+
+	type session struct {
+		startedAt, endedAt string
+		energy, cost, peak float32
+	}
+	tests := []struct {
+		name   string
+		events []charging.ChargerEvent
+		now    time.Time
+		want   []session
+	}{
+		{
+			name: "no events",
+			now:  at(12, 0),
+		},
+		{
+			name: "readings within the gap form one session",
+			events: []charging.ChargerEvent{
+				{Value: 1, CreatedAt: at(10, 0)},
+				{Value: 2, CreatedAt: at(10, 20)},
+				{Value: 3, CreatedAt: at(10, 40)},
+				{Value: 4, CreatedAt: at(11, 0)},
+			},
+			now: at(12, 0),
+			want: []session{
+				{startedAt: "2024-03-10 10:00:00", endedAt: "2024-03-10 11:00:00", energy: 10, cost: 1.4, peak: 6},
+			},
+		},
+		{
+			name: "an idle reading ends the session",
+			events: []charging.ChargerEvent{
+				{Value: 1, CreatedAt: at(10, 0)},
+				{Value: 0, CreatedAt: at(10, 10)},
+				{Value: 2, CreatedAt: at(10, 20)},
+			},
+			now: at(12, 0),
+			want: []session{
+				{startedAt: "2024-03-10 10:00:00", endedAt: "2024-03-10 10:00:00", energy: 1, cost: 0.1, peak: 1},
+				{startedAt: "2024-03-10 10:20:00", endedAt: "2024-03-10 10:20:00", energy: 2, cost: 0.2, peak: 2},
+			},
+		},
+		{
+			name: "a gap longer than 30 minutes ends the session",
+			events: []charging.ChargerEvent{
+				{Value: 1, CreatedAt: at(10, 0)},
+				{Value: 2, CreatedAt: at(10, 31)},
+			},
+			now: at(12, 0),
+			want: []session{
+				{startedAt: "2024-03-10 10:00:00", endedAt: "2024-03-10 10:00:00", energy: 1, cost: 0.1, peak: 1},
+				{startedAt: "2024-03-10 10:31:00", endedAt: "2024-03-10 10:31:00", energy: 2, cost: 0.2, peak: 2},
+			},
+		},
+		{
+			name: "a session which could still be in progress is left out",
+			events: []charging.ChargerEvent{
+				{Value: 1, CreatedAt: at(10, 0)},
+				{Value: 2, CreatedAt: at(11, 0)},
+				{Value: 3, CreatedAt: at(11, 10)},
+			},
+			now: at(11, 30),
+			want: []session{
+				{startedAt: "2024-03-10 10:00:00", endedAt: "2024-03-10 10:00:00", energy: 1, cost: 0.1, peak: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := charging.DetectSessions(tt.events, prices, tt.now)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d sessions, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, want := range tt.want {
+				s := got[i]
+				if s.StartedAt != want.startedAt || s.EndedAt != want.endedAt {
+					t.Errorf("session %d: got %s to %s, want %s to %s", i, s.StartedAt, s.EndedAt, want.startedAt, want.endedAt)
+				}
+				if !almostEqual(s.EnergyDelivered, want.energy) || !almostEqual(s.Cost, want.cost) || !almostEqual(s.PeakPower, want.peak) {
+					t.Errorf("session %d: got energy %v, cost %v, peak %v, want %v, %v, %v", i, s.EnergyDelivered, s.Cost, s.PeakPower, want.energy, want.cost, want.peak)
+				}
+			}
+		})
+	}
+}
+
+func TestScheduleCharging(t *testing.T) {
+	nightPrices := map[uint32]float32{23: 0.5, 24: 0.4, 1: 0.1, 2: 0.2}
+	flatPrices := map[uint32]float32{11: 0.3, 12: 0.3, 13: 0.3}
+
+	type slot struct {
+		startTime string
+		energy    float32
+		price     float32
+	}
+	tests := []struct {
+		name           string
+		from, deadline time.Time
+		energyRequired float32
+		chargerPower   float32
+		prices         map[uint32]float32
+		want           []slot
+		wantErr        bool
+	}{
+		{
+			name:           "cheapest hours are used and returned in order",
+			from:           at(22, 30),
+			deadline:       at(22, 30).Add(210 * time.Minute),
+			energyRequired: 10,
+			chargerPower:   7,
+			prices:         nightPrices,
+			want: []slot{
+				{startTime: "2024-03-11 00:00:00", energy: 7, price: 0.1},
+				{startTime: "2024-03-11 01:00:00", energy: 3, price: 0.2},
+			},
+		},
+		{
+			name:           "earlier hours win ties",
+			from:           at(10, 0),
+			deadline:       at(13, 0),
+			energyRequired: 7,
+			chargerPower:   7,
+			prices:         flatPrices,
+			want: []slot{
+				{startTime: "2024-03-10 10:00:00", energy: 7, price: 0.3},
+			},
+		},
+		{
+			name:           "a partial hour only holds part of the charger power",
+			from:           at(10, 30),
+			deadline:       at(11, 0),
+			energyRequired: 5,
+			chargerPower:   10,
+			prices:         flatPrices,
+			want: []slot{
+				{startTime: "2024-03-10 10:30:00", energy: 5, price: 0.3},
+			},
+		},
+		{
+			name:           "no energy needs no slots",
+			from:           at(10, 0),
+			deadline:       at(13, 0),
+			energyRequired: 0,
+			chargerPower:   7,
+			prices:         flatPrices,
+		},
+		{
+			name:           "target cannot be reached before the deadline",
+			from:           at(10, 0),
+			deadline:       at(12, 0),
+			energyRequired: 20,
+			chargerPower:   7,
+			prices:         flatPrices,
+			wantErr:        true,
+		},
+		{
+			name:           "zero charger power",
+			from:           at(10, 0),
+			deadline:       at(13, 0),
+			energyRequired: 7,
+			chargerPower:   0,
+			prices:         flatPrices,
+			wantErr:        true,
+		},
+		{
+			name:           "negative charger power",
+			from:           at(10, 0),
+			deadline:       at(13, 0),
+			energyRequired: 7,
+			chargerPower:   -7,
+			prices:         flatPrices,
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := charging.ScheduleCharging(tt.from, tt.deadline, tt.energyRequired, tt.chargerPower, tt.prices)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d slots, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, want := range tt.want {
+				s := got[i]
+				if s.StartTime != want.startTime || !almostEqual(s.Energy, want.energy) || !almostEqual(s.Price, want.price) {
+					t.Errorf("slot %d: got %s, %v kWh at %v, want %s, %v kWh at %v", i, s.StartTime, s.Energy, s.Price, want.startTime, want.energy, want.price)
+				}
+				if !almostEqual(s.Cost, s.Energy*s.Price) {
+					t.Errorf("slot %d: cost %v is not energy times price", i, s.Cost)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+type Config struct {
+	Port     int
+	GrpcPort int
+
+	// Prometheus scrapes /metrics on MetricsPort, which like GrpcPort is not
+	// meant to be reachable from the public network
+	MetricsPort int
+
+	DatabaseDSN   string
+	RedisAddr     string
+	RedisPassword string
+	AllowedOrigin string
+
+	// The gRPC server can be listed by clients like grpcurl when
+	// GrpcReflection is set, which is meant for development
+	GrpcReflection bool
+
+	// Requests are canceled after RequestTimeout, imports and exports after
+	// TransferTimeout. gRPC calls without a deadline get RequestTimeout.
+	RequestTimeout  time.Duration
+	TransferTimeout time.Duration
+
+	// MySQL and Redis have StartupTimeout to become available. On shutdown,
+	// requests and background workers have ShutdownTimeout to finish.
+	StartupTimeout  time.Duration
+	ShutdownTimeout time.Duration
+
+	// Pending migrations are applied on startup when MigrateOnStartup is set.
+	// A database which does not track migrations yet gets all of them, unless
+	// it was migrated by hand and records the migrations up to
+	// MigrationsBaseline, like 012_postal_codes, as applied first.
+	MigrateOnStartup   bool
+	MigrationsBaseline string
+
+	// Records of LogLevel (debug, info, warn or error) and above are logged
+	// as LogFormat (json or text)
+	LogLevel  string
+	LogFormat string
+
+	// Spans are sent to the OTLP endpoint over HTTP or printed to stdout
+	// depending on TracingExporter, and not recorded when it is empty.
+	// TracingSampleRatio of the traces started by the server are kept.
+	TracingExporter    string
+	OTLPEndpoint       string
+	TracingSampleRatio float64
+
+	// Base url of the frontend, used for links in emails
+	AppBaseURL string
+
+	// Emails are written to MailOutboxDir unless an SMTP server is configured
+	MailOutboxDir string
+	SMTPAddr      string
+	SMTPUsername  string
+	SMTPPassword  string
+	MailFrom      string
+
+	// The first admin is created on startup when AdminEmail is set
+	AdminName     string
+	AdminEmail    string
+	AdminPassword string
+
+	// Deleted enrolled devices and service locations are purged after RetentionDays
+	RetentionDays int
+
+	// Data exports are written to DataExportDir, confirmed account erasures
+	// are carried out after ErasureGraceDays
+	DataExportDir    string
+	ErasureGraceDays int
+}
+
+// Load reads the configuration from environment variables, falling back to
+// defaults for local development
+func Load() Config {
+	return Config{
+		Port:               getEnvInt("SHEMS_PORT", 8000),
+		GrpcPort:           getEnvInt("SHEMS_GRPC_PORT", 9000),
+		MetricsPort:        getEnvInt("SHEMS_METRICS_PORT", 9100),
+		GrpcReflection:     getEnvBool("SHEMS_GRPC_REFLECTION", false),
+		DatabaseDSN:        getEnv("SHEMS_DATABASE_DSN", "root:cricket97@tcp(localhost:3306)/Project"),
+		RedisAddr:          getEnv("SHEMS_REDIS_ADDR", "localhost:6379"),
+		RedisPassword:      getEnv("SHEMS_REDIS_PASSWORD", ""),
+		AllowedOrigin:      getEnv("SHEMS_ALLOWED_ORIGIN", "http://localhost:3000"),
+		RequestTimeout:     getEnvSeconds("SHEMS_REQUEST_TIMEOUT_SECONDS", 30),
+		TransferTimeout:    getEnvSeconds("SHEMS_TRANSFER_TIMEOUT_SECONDS", 300),
+		StartupTimeout:     getEnvSeconds("SHEMS_STARTUP_TIMEOUT_SECONDS", 60),
+		ShutdownTimeout:    getEnvSeconds("SHEMS_SHUTDOWN_TIMEOUT_SECONDS", 30),
+		MigrateOnStartup:   getEnvBool("SHEMS_MIGRATE_ON_STARTUP", false),
+		MigrationsBaseline: getEnv("SHEMS_MIGRATIONS_BASELINE", ""),
+		LogLevel:           getEnv("SHEMS_LOG_LEVEL", "info"),
+		LogFormat:          getEnv("SHEMS_LOG_FORMAT", "text"),
+		TracingExporter:    getEnv("SHEMS_TRACING_EXPORTER", ""),
+		OTLPEndpoint:       getEnv("SHEMS_OTLP_ENDPOINT", "http://localhost:4318"),
+		TracingSampleRatio: getEnvFloat("SHEMS_TRACING_SAMPLE_RATIO", 1),
+		AppBaseURL:         getEnv("SHEMS_APP_BASE_URL", "http://localhost:3000"),
+		MailOutboxDir:      getEnv("SHEMS_MAIL_OUTBOX_DIR", "mail_outbox"),
+		SMTPAddr:           getEnv("SHEMS_SMTP_ADDR", ""),
+		SMTPUsername:       getEnv("SHEMS_SMTP_USERNAME", ""),
+		SMTPPassword:       getEnv("SHEMS_SMTP_PASSWORD", ""),
+		MailFrom:           getEnv("SHEMS_MAIL_FROM", "no-reply@shems.local"),
+		AdminName:          getEnv("SHEMS_ADMIN_NAME", "Admin"),
+		AdminEmail:         getEnv("SHEMS_ADMIN_EMAIL", ""),
+		AdminPassword:      getEnv("SHEMS_ADMIN_PASSWORD", ""),
+		RetentionDays:      getEnvInt("SHEMS_RETENTION_DAYS", 30),
+		DataExportDir:      getEnv("SHEMS_DATA_EXPORT_DIR", "data_exports"),
+		ErasureGraceDays:   getEnvInt("SHEMS_ERASURE_GRACE_DAYS", 14),
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+func getEnvSeconds(key string, defaultValue int) time.Duration {
+	return time.Duration(getEnvInt(key, defaultValue)) * time.Second
+}
@@ -0,0 +1,30 @@
+package dbutil
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Layout of DATETIME values, both as sent to the database and as returned by
+// the mysql driver
+const TimeLayout = "2006-01-02 15:04:05"
+
+// GetIds returns the ids selected by a query which selects a single id column
+func GetIds(ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]uint32, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uint32
+	for rows.Next() {
+		var id uint32
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
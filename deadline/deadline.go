@@ -0,0 +1,36 @@
+package deadline
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// WithTimeout gives every request a deadline. The database and redis calls of
+// a request which is still running at its deadline, or whose client went
+// away, are canceled and it responds with 504 or 503. Paths in overrides get
+// their own timeout, and a timeout of 0 means no deadline.
+func WithTimeout(next http.Handler, timeout time.Duration, overrides map[string]time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t := timeout
+		if override, ok := overrides[r.URL.Path]; ok {
+			t = override
+		}
+		if t <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), t)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ForCall gives a call the timeout unless the caller already set a deadline
+func ForCall(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
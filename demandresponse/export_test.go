@@ -0,0 +1,3 @@
+package demandresponse
+
+var GetBaselineWindows = getBaselineWindows
@@ -0,0 +1,618 @@
+package demandresponse
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"shems/access"
+	"shems/admin"
+	"shems/apierror"
+	"shems/dbutil"
+	"shems/model"
+	redisService "shems/redis"
+	"shems/users"
+	"shems/validation"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func AddDRProgram(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.AddDRProgramRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	a, ok := admin.RequireAdmin(ctx, w, r, conn, redisClient)
+	if !ok {
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// insert query to add program
+	query := queryToAddDRProgram()
+	result, err := tx.ExecContext(ctx, query, req.Name, req.Description, req.CreditPerKwh)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	programId, err := result.LastInsertId()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = admin.RecordChange(ctx, tx, r, a, "add demand response program", model.AuditEntityDRProgram, programId, nil, req)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// respond with a success message
+	json.NewEncoder(w).Encode(map[string]string{"message": "Demand response program added successfully"})
+}
+
+func GetDRPrograms(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	// get all programs
+	query := queryToGetDRPrograms()
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var programs []model.DRProgram
+	var p model.DRProgram
+	for rows.Next() {
+		err = rows.Scan(&p.Id, &p.Name, &p.Description, &p.CreditPerKwh, &p.Active)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		programs = append(programs, p)
+	}
+
+	resp := model.GetDRProgramsResponse{
+		Programs: programs,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func AddDREvent(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.AddDREventRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	a, ok := admin.RequireAdmin(ctx, w, r, conn, redisClient)
+	if !ok {
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+
+	// validate the request
+	for i := range req.Zipcodes {
+		req.Zipcodes[i] = validation.NormalizePostalCode(req.Zipcodes[i])
+	}
+	errs := validation.Validate(req)
+	if len(req.Zipcodes) == 0 && len(req.States) == 0 {
+		errs = append(errs, validation.FieldError{Field: "zipcodes", Message: "must not be empty when no states are given"})
+	}
+	if len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	// the formats are validated above, so only the times themselves are checked
+	startsAt, _ := time.ParseInLocation(requestTimeLayout, req.StartsAt, time.Local)
+	endsAt, _ := time.ParseInLocation(requestTimeLayout, req.EndsAt, time.Local)
+	if !endsAt.After(startsAt) {
+		apierror.Write(w, r, validation.Errors{{Field: "endsAt", Message: "must be after startsAt"}})
+		return
+	}
+	if !startsAt.After(time.Now()) {
+		apierror.Write(w, r, validation.Errors{{Field: "startsAt", Message: "must be in the future"}})
+		return
+	}
+
+	// validation: check if program exists
+	query := queryToCheckIfDRProgramExists()
+	rows, err := conn.QueryContext(ctx, query, req.ProgramId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var checkId uint32
+	for rows.Next() {
+		err = rows.Scan(&checkId)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	}
+	if checkId != req.ProgramId {
+		apierror.Write(w, r, apierror.NotFound("Demand response program does not exist"))
+		return
+	}
+
+	redisKey := "AddDREvent_ProgramId_" + fmt.Sprint(req.ProgramId)
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		// release redis lock
+		redisService.ReleaseLock(ctx, redisClient, redisKey)
+
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// take redis lock to avoid concurrent access or double clicking
+	err = users.TakeRedisLock(ctx, redisClient, redisKey)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// insert query to add event
+	query = queryToAddDREvent()
+	result, err := tx.ExecContext(ctx, query, req.ProgramId, startsAt.Format(dbutil.TimeLayout), endsAt.Format(dbutil.TimeLayout), model.DREventStatusScheduled)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	eventId, err := result.LastInsertId()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// insert query to add targeted regions
+	query = queryToAddDREventRegion()
+	for _, zipcode := range req.Zipcodes {
+		_, err = tx.ExecContext(ctx, query, eventId, zipcode, nil)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	}
+	for _, state := range req.States {
+		_, err = tx.ExecContext(ctx, query, eventId, nil, state)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	}
+
+	err = admin.RecordChange(ctx, tx, r, a, "add demand response event", model.AuditEntityDREvent, eventId, nil, req)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// respond with a success message
+	json.NewEncoder(w).Encode(map[string]string{"message": "Demand response event added successfully"})
+}
+
+func GetAllDREvents(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	// only events which have not ended yet
+	now := time.Now().Format(dbutil.TimeLayout)
+
+	// get events
+	query := queryToGetDREvents()
+	rows, err := db.QueryContext(ctx, query, now)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var events []model.DREvent
+	for rows.Next() {
+		var ev model.DREvent
+		err = rows.Scan(&ev.Id, &ev.ProgramId, &ev.ProgramName, &ev.StartsAt, &ev.EndsAt, &ev.Status)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		events = append(events, ev)
+	}
+
+	// get targeted regions of the events
+	query = queryToGetDREventRegions()
+	rows, err = db.QueryContext(ctx, query, now)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	zipcodesMap := make(map[uint32][]string)
+	statesMap := make(map[uint32][]string)
+	for rows.Next() {
+		var eventId uint32
+		var zipcode, state sql.NullString
+		err = rows.Scan(&eventId, &zipcode, &state)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		if zipcode.Valid {
+			zipcodesMap[eventId] = append(zipcodesMap[eventId], zipcode.String)
+		}
+		if state.Valid {
+			statesMap[eventId] = append(statesMap[eventId], state.String)
+		}
+	}
+
+	for i := range events {
+		events[i].Zipcodes = zipcodesMap[events[i].Id]
+		events[i].States = statesMap[events[i].Id]
+	}
+
+	resp := model.GetAllDREventsResponse{
+		Events: events,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func CancelDREvent(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	a, ok := admin.RequireAdmin(ctx, w, r, conn, redisClient)
+	if !ok {
+		return
+	}
+
+	// Get event id from query params
+	eventIdInt, err := users.GetIdFromQueryParams(r, "eventId", "Event Id")
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// validation: only scheduled or active events can be cancelled
+	var status string
+	err = tx.QueryRowContext(ctx, queryToGetDREventStatus(), eventIdInt).Scan(&status)
+	if err == sql.ErrNoRows {
+		apierror.Write(w, r, apierror.NotFound("Demand response event does not exist"))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if status != model.DREventStatusScheduled && status != model.DREventStatusActive {
+		apierror.Write(w, r, apierror.BadRequest("Demand response event is already "+status))
+		return
+	}
+
+	// release curtailed devices before cancelling
+	query := queryToRestoreCurtailments()
+	_, err = tx.ExecContext(ctx, query, time.Now().Format(dbutil.TimeLayout), eventIdInt)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	query = queryToUpdateDREventStatus()
+	_, err = tx.ExecContext(ctx, query, model.DREventStatusCancelled, eventIdInt)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = admin.RecordChange(ctx, tx, r, a, "cancel demand response event", model.AuditEntityDREvent, eventIdInt, map[string]string{"status": status}, map[string]string{"status": model.DREventStatusCancelled})
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// respond with a success message
+	json.NewEncoder(w).Encode(map[string]string{"message": "Demand response event cancelled successfully"})
+}
+
+func GetDREvents(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	// the customer is the one the session belongs to
+	session, ok := users.GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+	customerId := session.CustomerId
+
+	// include events of the past month so that performance can be shown
+	since := time.Now().AddDate(0, -1, 0).Format(dbutil.TimeLayout)
+
+	// get events targeting the customer's service locations
+	query := queryToGetDREventsOfCustomer()
+	rows, err := db.QueryContext(ctx, query, customerId, since)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var events []model.DRServiceLocationEvent
+	for rows.Next() {
+		var sle model.DRServiceLocationEvent
+		var hasPerformance bool
+		var perf model.DREventPerformance
+		err = rows.Scan(&sle.ServiceLocationId, &sle.OptedIn, &sle.Event.Id, &sle.Event.ProgramId, &sle.Event.ProgramName, &sle.Event.StartsAt, &sle.Event.EndsAt, &sle.Event.Status, &hasPerformance, &perf.Baseline, &perf.Actual, &perf.Reduction, &perf.Credit)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		if hasPerformance {
+			perf.EventId = sle.Event.Id
+			perf.ServiceLocationId = sle.ServiceLocationId
+			sle.Performance = &perf
+		}
+		events = append(events, sle)
+	}
+
+	resp := model.GetDREventsResponse{
+		Events: events,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func UpdateDREnrollment(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.UpdateDREnrollmentRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := users.GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+	req.CustomerId = session.CustomerId
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	// validation: check if customer can manage the service location
+	err = access.CheckServiceLocationPermission(ctx, db, req.CustomerId, req.ServiceLocationId, access.ManageServiceLocation)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// validation: check if program exists
+	query := queryToCheckIfDRProgramExists()
+	rows, err := db.QueryContext(ctx, query, req.ProgramId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var checkId uint32
+	for rows.Next() {
+		err = rows.Scan(&checkId)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	}
+	if checkId != req.ProgramId {
+		apierror.Write(w, r, apierror.NotFound("Demand response program does not exist"))
+		return
+	}
+
+	redisKey := "UpdateDREnrollment_CustomerId_" + fmt.Sprint(req.CustomerId)
+	rollback := true
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		// release redis lock
+		redisService.ReleaseLock(ctx, redisClient, redisKey)
+
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// take redis lock to avoid concurrent access or double clicking
+	err = users.TakeRedisLock(ctx, redisClient, redisKey)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// the enrollment before the change, none when the service location was
+	// never enrolled in the program
+	var before interface{}
+	var beforeOptedIn uint32
+	err = tx.QueryRowContext(ctx, queryToGetDREnrollmentForUpdate(), req.ProgramId, req.ServiceLocationId).Scan(&beforeOptedIn)
+	if err != nil && err != sql.ErrNoRows {
+		apierror.Write(w, r, err)
+		return
+	}
+	if err == nil {
+		before = drEnrollmentSnapshot{ProgramId: req.ProgramId, ServiceLocationId: req.ServiceLocationId, OptedIn: beforeOptedIn}
+	}
+
+	after := drEnrollmentSnapshot{ProgramId: req.ProgramId, ServiceLocationId: req.ServiceLocationId}
+	if req.OptedIn {
+		after.OptedIn = 1
+	}
+
+	// upsert enrollment
+	query = queryToUpsertDREnrollment()
+	_, err = tx.ExecContext(ctx, query, req.ProgramId, req.ServiceLocationId, after.OptedIn)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = users.RecordAudit(ctx, tx, r, session, "update", model.AuditEntityDREnrollment, req.ServiceLocationId, before, after)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// respond with a success message
+	json.NewEncoder(w).Encode(map[string]string{"message": "Demand response enrollment updated successfully"})
+}
+
+func GetDRPerformance(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	// the customer is the one the session belongs to
+	session, ok := users.GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+	customerId := session.CustomerId
+
+	currentDate := r.URL.Query().Get("currentDate")
+	startDateTime, err := users.GetStartOfMonth(currentDate)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+	endDateTime := startDateTime.AddDate(0, 1, 0).Add(-time.Second)
+
+	// get performance of events in the month
+	query := queryToGetDRPerformances()
+	rows, err := db.QueryContext(ctx, query, customerId, startDateTime, endDateTime)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var resp model.GetDRPerformanceResponse
+	var perf model.DREventPerformance
+	for rows.Next() {
+		err = rows.Scan(&perf.EventId, &perf.ServiceLocationId, &perf.Baseline, &perf.Actual, &perf.Reduction, &perf.Credit)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		resp.Performances = append(resp.Performances, perf)
+		resp.TotalCredit += perf.Credit
+	}
+
+	json.NewEncoder(w).Encode(resp)
+}
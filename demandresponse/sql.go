@@ -0,0 +1,364 @@
+package demandresponse
+
+import "shems/tracing"
+
+func init() {
+	tracing.RegisterQueries(
+		queryToAddDRProgram,
+		queryToGetDRPrograms,
+		queryToCheckIfDRProgramExists,
+		queryToAddDREvent,
+		queryToAddDREventRegion,
+		queryToGetDREvents,
+		queryToGetDREventRegions,
+		queryToGetDREventStatus,
+		queryToUpdateDREventStatus,
+		queryToGetDREventsOfCustomer,
+		queryToGetDREnrollmentForUpdate,
+		queryToUpsertDREnrollment,
+		queryToGetDRPerformances,
+		queryToGetDREventsToStart,
+		queryToGetDREventsToEnd,
+		queryToGetParticipatingServiceLocations,
+		queryToAddCurtailments,
+		queryToRestoreCurtailments,
+		queryToGetCurtailedServiceLocations,
+		queryToGetDREventDates,
+		queryToGetServiceLocationConsumption,
+		queryToUpsertDREventPerformance,
+	)
+}
+
+func queryToAddDRProgram() string {
+	sqlQuery := `
+				INSERT INTO DR_Programs
+					(name, description, credit_per_kwh)
+				VALUES
+					(?, ?, ?);
+				`
+	return sqlQuery
+}
+
+func queryToGetDRPrograms() string {
+	sqlQuery := `
+	SELECT
+		id, name, description, credit_per_kwh, active
+	FROM
+		DR_Programs
+	ORDER BY
+		id;
+	`
+	return sqlQuery
+}
+
+func queryToCheckIfDRProgramExists() string {
+	sqlQuery := `
+	SELECT
+		id
+	FROM
+		DR_Programs
+	WHERE
+		id = ?
+		AND active = 1;
+	`
+	return sqlQuery
+}
+
+func queryToAddDREvent() string {
+	sqlQuery := `
+				INSERT INTO DR_Events
+					(program_id, starts_at, ends_at, status)
+				VALUES
+					(?, ?, ?, ?);
+				`
+	return sqlQuery
+}
+
+func queryToAddDREventRegion() string {
+	sqlQuery := `
+				INSERT INTO DR_Event_Regions
+					(event_id, zipcode, state)
+				VALUES
+					(?, ?, ?);
+				`
+	return sqlQuery
+}
+
+func queryToGetDREvents() string {
+	sqlQuery := `
+	SELECT
+		ev.id, ev.program_id, p.name, ev.starts_at, ev.ends_at, ev.status
+	FROM
+		DR_Events ev
+	INNER JOIN
+		DR_Programs p ON p.id = ev.program_id
+	WHERE
+		ev.ends_at >= ?
+	ORDER BY
+		ev.starts_at;
+	`
+	return sqlQuery
+}
+
+func queryToGetDREventRegions() string {
+	sqlQuery := `
+	SELECT
+		r.event_id, r.zipcode, r.state
+	FROM
+		DR_Event_Regions r
+	INNER JOIN
+		DR_Events ev ON ev.id = r.event_id
+	WHERE
+		ev.ends_at >= ?;
+	`
+	return sqlQuery
+}
+
+func queryToGetDREventStatus() string {
+	sqlQuery := `
+	SELECT
+		status
+	FROM
+		DR_Events
+	WHERE
+		id = ?;
+	`
+	return sqlQuery
+}
+
+func queryToUpdateDREventStatus() string {
+	sqlQuery := `
+				UPDATE
+					DR_Events
+				SET
+					status = ?
+				WHERE
+					id = ?;
+				`
+	return sqlQuery
+}
+
+func queryToGetDREventsOfCustomer() string {
+	sqlQuery := `
+	SELECT DISTINCT
+		sl.id,
+		COALESCE(en.opted_in, 0),
+		ev.id,
+		ev.program_id,
+		p.name,
+		ev.starts_at,
+		ev.ends_at,
+		ev.status,
+		perf.id IS NOT NULL,
+		COALESCE(perf.baseline, 0),
+		COALESCE(perf.actual, 0),
+		COALESCE(perf.reduction, 0),
+		COALESCE(perf.credit, 0)
+	FROM
+		Service_Locations sl
+	INNER JOIN
+		Locations l ON l.id = sl.location_id
+	INNER JOIN
+		DR_Event_Regions r ON r.zipcode = l.zipcode OR r.state = l.state
+	INNER JOIN
+		DR_Events ev ON ev.id = r.event_id
+	INNER JOIN
+		DR_Programs p ON p.id = ev.program_id
+	LEFT JOIN
+		DR_Enrollments en ON en.program_id = ev.program_id AND en.service_location_id = sl.id
+	LEFT JOIN
+		DR_Event_Performances perf ON perf.event_id = ev.id AND perf.service_location_id = sl.id
+	WHERE
+		sl.id IN (SELECT service_location_id FROM Service_Location_Members WHERE customer_id = ?)
+		AND sl.active = 1
+		AND ev.ends_at >= ?
+	ORDER BY
+		ev.starts_at, sl.id;
+	`
+	return sqlQuery
+}
+
+func queryToGetDREnrollmentForUpdate() string {
+	sqlQuery := `
+	SELECT
+		opted_in
+	FROM
+		DR_Enrollments
+	WHERE
+		program_id = ?
+		AND service_location_id = ?
+	FOR UPDATE;
+	`
+	return sqlQuery
+}
+
+func queryToUpsertDREnrollment() string {
+	sqlQuery := `
+				INSERT INTO DR_Enrollments
+					(program_id, service_location_id, opted_in)
+				VALUES
+					(?, ?, ?)
+				ON DUPLICATE KEY UPDATE
+					opted_in = VALUES(opted_in);
+				`
+	return sqlQuery
+}
+
+func queryToGetDRPerformances() string {
+	sqlQuery := `
+	SELECT
+		perf.event_id, perf.service_location_id, perf.baseline, perf.actual, perf.reduction, perf.credit
+	FROM
+		DR_Event_Performances perf
+	INNER JOIN
+		Service_Locations sl ON sl.id = perf.service_location_id
+	INNER JOIN
+		DR_Events ev ON ev.id = perf.event_id
+	WHERE
+		sl.id IN (SELECT service_location_id FROM Service_Location_Members WHERE customer_id = ?)
+		AND ev.starts_at >= ?
+		AND ev.starts_at <= ?
+	ORDER BY
+		ev.starts_at, sl.id;
+	`
+	return sqlQuery
+}
+
+func queryToGetDREventsToStart() string {
+	sqlQuery := `
+	SELECT
+		id
+	FROM
+		DR_Events
+	WHERE
+		status = 'scheduled'
+		AND starts_at <= ?;
+	`
+	return sqlQuery
+}
+
+func queryToGetDREventsToEnd() string {
+	sqlQuery := `
+	SELECT
+		ev.id, ev.starts_at, ev.ends_at, p.credit_per_kwh
+	FROM
+		DR_Events ev
+	INNER JOIN
+		DR_Programs p ON p.id = ev.program_id
+	WHERE
+		ev.status = 'active'
+		AND ev.ends_at <= ?;
+	`
+	return sqlQuery
+}
+
+func queryToGetParticipatingServiceLocations() string {
+	sqlQuery := `
+	SELECT DISTINCT
+		sl.id
+	FROM
+		DR_Event_Regions r
+	INNER JOIN
+		DR_Events ev ON ev.id = r.event_id
+	INNER JOIN
+		Locations l ON r.zipcode = l.zipcode OR r.state = l.state
+	INNER JOIN
+		Service_Locations sl ON sl.location_id = l.id AND sl.active = 1
+	INNER JOIN
+		DR_Enrollments en ON en.program_id = ev.program_id AND en.service_location_id = sl.id AND en.opted_in = 1
+	WHERE
+		r.event_id = ?;
+	`
+	return sqlQuery
+}
+
+func queryToAddCurtailments() string {
+	sqlQuery := `
+				INSERT IGNORE INTO DR_Curtailments
+					(event_id, enrolled_device_id, curtailed_at)
+				SELECT
+					?, ed.id, ?
+				FROM
+					Enrolled_Devices ed
+				WHERE
+					ed.service_location_id = ?
+					AND ed.active = 1;
+				`
+	return sqlQuery
+}
+
+func queryToRestoreCurtailments() string {
+	sqlQuery := `
+				UPDATE
+					DR_Curtailments
+				SET
+					restored_at = ?
+				WHERE
+					event_id = ?
+					AND restored_at IS NULL;
+				`
+	return sqlQuery
+}
+
+func queryToGetCurtailedServiceLocations() string {
+	sqlQuery := `
+	SELECT DISTINCT
+		ed.service_location_id
+	FROM
+		DR_Curtailments c
+	INNER JOIN
+		Enrolled_Devices ed ON ed.id = c.enrolled_device_id
+	WHERE
+		c.event_id = ?;
+	`
+	return sqlQuery
+}
+
+func queryToGetDREventDates() string {
+	sqlQuery := `
+	SELECT DISTINCT
+		DATE_FORMAT(starts_at, '%Y-%m-%d')
+	FROM
+		DR_Events
+	WHERE
+		status != 'cancelled'
+		AND starts_at >= ?;
+	`
+	return sqlQuery
+}
+
+func queryToGetServiceLocationConsumption() string {
+	sqlQuery := `
+	SELECT
+		COALESCE(SUM(e.value), 0)
+	FROM
+		Events e
+	INNER JOIN
+		Enrolled_Device_History edh ON edh.enrolled_device_id = e.enrolled_device_id
+			AND e.created_at >= edh.valid_from AND (edh.valid_to IS NULL OR e.created_at < edh.valid_to)
+	INNER JOIN
+		Service_Location_History slh ON slh.service_location_id = edh.service_location_id
+			AND e.created_at >= slh.valid_from AND (slh.valid_to IS NULL OR e.created_at < slh.valid_to)
+	WHERE
+		edh.service_location_id = ?
+		AND e.label = 'energy use'
+		AND e.created_at >= ?
+		AND e.created_at < ?;
+	`
+	return sqlQuery
+}
+
+func queryToUpsertDREventPerformance() string {
+	sqlQuery := `
+				INSERT INTO DR_Event_Performances
+					(event_id, service_location_id, baseline, actual, reduction, credit)
+				VALUES
+					(?, ?, ?, ?, ?, ?)
+				ON DUPLICATE KEY UPDATE
+					baseline = VALUES(baseline),
+					actual = VALUES(actual),
+					reduction = VALUES(reduction),
+					credit = VALUES(credit);
+				`
+	return sqlQuery
+}
@@ -0,0 +1,253 @@
+package demandresponse
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"shems/dbutil"
+	"shems/model"
+	redisService "shems/redis"
+	"shems/users"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Layout of event start and end times in requests
+const requestTimeLayout = "01/02/2006 15:04"
+
+// Baseline is the average of this many prior similar days
+const baselineDaysCount = 10
+
+// How far back to look for similar days
+const baselineLookbackDays = 45
+
+// drEnrollmentSnapshot is an enrollment as stored in audit log entries
+type drEnrollmentSnapshot struct {
+	ProgramId         uint32
+	ServiceLocationId uint32
+	OptedIn           uint32
+}
+
+func isWeekend(t time.Time) bool {
+	return t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+}
+
+// getBaselineWindows returns the event window shifted back to prior days of
+// the same kind (weekday or weekend), skipping days which had an event
+func getBaselineWindows(startsAt, endsAt time.Time, excludedDates map[string]bool) [][2]time.Time {
+	var windows [][2]time.Time
+	for i := 1; i <= baselineLookbackDays && len(windows) < baselineDaysCount; i++ {
+		dayStart := startsAt.AddDate(0, 0, -i)
+		if isWeekend(dayStart) != isWeekend(startsAt) || excludedDates[dayStart.Format("2006-01-02")] {
+			continue
+		}
+		windows = append(windows, [2]time.Time{dayStart, endsAt.AddDate(0, 0, -i)})
+	}
+	return windows
+}
+
+func getServiceLocationConsumption(ctx context.Context, db *sql.DB, serviceLocationId uint32, from, to time.Time) (float32, error) {
+	var consumption float32
+	err := db.QueryRowContext(ctx, queryToGetServiceLocationConsumption(), serviceLocationId, from.Format(dbutil.TimeLayout), to.Format(dbutil.TimeLayout)).Scan(&consumption)
+	return consumption, err
+}
+
+// calculateBaseline averages the consumption of a service location over the
+// event window on prior similar days
+func calculateBaseline(ctx context.Context, db *sql.DB, serviceLocationId uint32, startsAt, endsAt time.Time, excludedDates map[string]bool) (float32, error) {
+	windows := getBaselineWindows(startsAt, endsAt, excludedDates)
+	if len(windows) == 0 {
+		return 0, nil
+	}
+
+	var total float32
+	for _, window := range windows {
+		consumption, err := getServiceLocationConsumption(ctx, db, serviceLocationId, window[0], window[1])
+		if err != nil {
+			return 0, err
+		}
+		total += consumption
+	}
+	return total / float32(len(windows)), nil
+}
+
+// startDREvents curtails enrolled devices of opted in service locations for
+// every scheduled event whose start time has passed
+func startDREvents(ctx context.Context, db *sql.DB, now time.Time) error {
+	eventIds, err := dbutil.GetIds(ctx, db, queryToGetDREventsToStart(), now.Format(dbutil.TimeLayout))
+	if err != nil {
+		return err
+	}
+
+	for _, eventId := range eventIds {
+		serviceLocationIds, err := dbutil.GetIds(ctx, db, queryToGetParticipatingServiceLocations(), eventId)
+		if err != nil {
+			return err
+		}
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		for _, serviceLocationId := range serviceLocationIds {
+			_, err = tx.ExecContext(ctx, queryToAddCurtailments(), eventId, now.Format(dbutil.TimeLayout), serviceLocationId)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+		_, err = tx.ExecContext(ctx, queryToUpdateDREventStatus(), model.DREventStatusActive, eventId)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		err = tx.Commit()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// endDREvents restores curtailed devices of every active event whose end
+// time has passed and computes the performance of each participant
+func endDREvents(ctx context.Context, db *sql.DB, now time.Time) error {
+	rows, err := db.QueryContext(ctx, queryToGetDREventsToEnd(), now.Format(dbutil.TimeLayout))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	type drEvent struct {
+		id           uint32
+		startsAt     time.Time
+		endsAt       time.Time
+		creditPerKwh float32
+	}
+
+	var events []drEvent
+	for rows.Next() {
+		var ev drEvent
+		var startsAt, endsAt string
+		err = rows.Scan(&ev.id, &startsAt, &endsAt, &ev.creditPerKwh)
+		if err != nil {
+			return err
+		}
+		ev.startsAt, err = time.ParseInLocation(dbutil.TimeLayout, startsAt, time.Local)
+		if err != nil {
+			return err
+		}
+		ev.endsAt, err = time.ParseInLocation(dbutil.TimeLayout, endsAt, time.Local)
+		if err != nil {
+			return err
+		}
+		events = append(events, ev)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for _, ev := range events {
+		// days with events are not representative of normal consumption
+		lookbackStart := ev.startsAt.AddDate(0, 0, -baselineLookbackDays)
+		rows, err := db.QueryContext(ctx, queryToGetDREventDates(), lookbackStart.Format(dbutil.TimeLayout))
+		if err != nil {
+			return err
+		}
+		excludedDates := make(map[string]bool)
+		for rows.Next() {
+			var date string
+			err = rows.Scan(&date)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			excludedDates[date] = true
+		}
+		rows.Close()
+
+		serviceLocationIds, err := dbutil.GetIds(ctx, db, queryToGetCurtailedServiceLocations(), ev.id)
+		if err != nil {
+			return err
+		}
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		for _, serviceLocationId := range serviceLocationIds {
+			baseline, err := calculateBaseline(ctx, db, serviceLocationId, ev.startsAt, ev.endsAt, excludedDates)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+			actual, err := getServiceLocationConsumption(ctx, db, serviceLocationId, ev.startsAt, ev.endsAt)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+
+			var reduction float32
+			if baseline > actual {
+				reduction = baseline - actual
+			}
+
+			_, err = tx.ExecContext(ctx, queryToUpsertDREventPerformance(), ev.id, serviceLocationId, baseline, actual, reduction, reduction*ev.creditPerKwh)
+			if err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, queryToRestoreCurtailments(), now.Format(dbutil.TimeLayout), ev.id)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		_, err = tx.ExecContext(ctx, queryToUpdateDREventStatus(), model.DREventStatusCompleted, ev.id)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+		err = tx.Commit()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunScheduler starts and ends demand response events every interval until
+// the context is cancelled. A redis lock makes sure only one server instance
+// processes events at a time.
+func RunScheduler(ctx context.Context, db *sql.DB, redisClient *redis.Client, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	redisKey := "DemandResponseScheduler"
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := users.TakeRedisLock(ctx, redisClient, redisKey)
+		if err != nil {
+			continue
+		}
+
+		now := time.Now()
+		err = startDREvents(ctx, db, now)
+		if err != nil {
+			slog.ErrorContext(ctx, "error while starting demand response events", "error", err)
+		}
+		err = endDREvents(ctx, db, now)
+		if err != nil {
+			slog.ErrorContext(ctx, "error while ending demand response events", "error", err)
+		}
+
+		redisService.ReleaseLock(ctx, redisClient, redisKey)
+	}
+}
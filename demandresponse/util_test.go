@@ -0,0 +1,84 @@
+package demandresponse_test
+
+import (
+	"reflect"
+	"shems/demandresponse"
+	"testing"
+	"time"
+)
+
+func TestGetBaselineWindows(t *testing.T) {
+	day := func(month time.Month, d, hour int) time.Time {
+		return time.Date(2024, month, d, hour, 0, 0, 0, time.UTC)
+	}
+	dates := func(windows [][2]time.Time) []string {
+		var days []string
+		for _, w := range windows {
+			days = append(days, w[0].Format("2006-01-02"))
+		}
+		return days
+	}
+
+	tests := []struct {
+		name             string
+		startsAt, endsAt time.Time
+		excludedDates    map[string]bool
+		want             []string
+	}{
+		{
+			name:     "weekday event uses prior weekdays",
+			startsAt: day(time.March, 13, 14),
+			endsAt:   day(time.March, 13, 18),
+			want:     []string{"2024-03-12", "2024-03-11", "2024-03-08", "2024-03-07", "2024-03-06", "2024-03-05", "2024-03-04", "2024-03-01", "2024-02-29", "2024-02-28"},
+		},
+		{
+			name:          "days which had an event are skipped",
+			startsAt:      day(time.March, 13, 14),
+			endsAt:        day(time.March, 13, 18),
+			excludedDates: map[string]bool{"2024-03-11": true},
+			want:          []string{"2024-03-12", "2024-03-08", "2024-03-07", "2024-03-06", "2024-03-05", "2024-03-04", "2024-03-01", "2024-02-29", "2024-02-28", "2024-02-27"},
+		},
+		{
+			name:     "weekend event uses prior weekend days",
+			startsAt: day(time.March, 16, 14),
+			endsAt:   day(time.March, 16, 18),
+			want:     []string{"2024-03-10", "2024-03-09", "2024-03-03", "2024-03-02", "2024-02-25", "2024-02-24", "2024-02-18", "2024-02-17", "2024-02-11", "2024-02-10"},
+		},
+		{
+			name:     "only days within the lookback are used",
+			startsAt: day(time.March, 16, 14),
+			endsAt:   day(time.March, 16, 18),
+			excludedDates: map[string]bool{
+				"2024-03-10": true, "2024-03-09": true, "2024-03-03": true, "2024-03-02": true,
+				"2024-02-25": true, "2024-02-24": true, "2024-02-18": true,
+			},
+			want: []string{"2024-02-17", "2024-02-11", "2024-02-10", "2024-02-04", "2024-02-03"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			windows := demandresponse.GetBaselineWindows(tt.startsAt, tt.endsAt, tt.excludedDates)
+			if got := dates(windows); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			for _, w := range windows {
+				if w[1].Sub(w[0]) != tt.endsAt.Sub(tt.startsAt) || w[0].Hour() != tt.startsAt.Hour() {
+					t.Errorf("window %v to %v does not match the event window", w[0], w[1])
+				}
+			}
+		})
+	}
+}
+
+// a window which crosses midnight keeps its end on the following day
+func TestGetBaselineWindowsAcrossMidnight(t *testing.T) {
+	startsAt := time.Date(2024, time.March, 13, 22, 0, 0, 0, time.UTC)
+	endsAt := time.Date(2024, time.March, 14, 2, 0, 0, 0, time.UTC)
+
+	windows := demandresponse.GetBaselineWindows(startsAt, endsAt, nil)
+	want := [2]time.Time{time.Date(2024, time.March, 12, 22, 0, 0, 0, time.UTC), time.Date(2024, time.March, 13, 2, 0, 0, 0, time.UTC)}
+	if len(windows) == 0 || windows[0] != want {
+		t.Errorf("got %v, want the first window to be %v", windows, want)
+	}
+}
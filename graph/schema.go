@@ -0,0 +1,288 @@
+package graph
+
+import (
+	"database/sql"
+	"fmt"
+	"shems/apierror"
+	"shems/model"
+	"shems/validation"
+
+	"github.com/graphql-go/graphql"
+)
+
+// The schema starts at the customer of the session. Lists under it are
+// resolved with thunks of the request's loaders so that each level of a
+// query costs one query to the database however many items it has.
+
+var granularityEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name:        "Granularity",
+	Description: "Length of the usage intervals",
+	Values: graphql.EnumValueConfigMap{
+		"HOUR":  &graphql.EnumValueConfig{Value: "HOUR"},
+		"DAY":   &graphql.EnumValueConfig{Value: "DAY"},
+		"MONTH": &graphql.EnumValueConfig{Value: "MONTH"},
+	},
+})
+
+var statusEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name:        "Status",
+	Description: "Whether deleted items are included",
+	Values: graphql.EnumValueConfigMap{
+		"ACTIVE":   &graphql.EnumValueConfig{Value: "active"},
+		"INACTIVE": &graphql.EnumValueConfig{Value: "inactive"},
+		"ALL":      &graphql.EnumValueConfig{Value: "all"},
+	},
+})
+
+var priceType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Price",
+	Fields: graphql.Fields{
+		"zipcode": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"hour":    &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Description: "Hour of the day, 1 to 24"},
+		"value":   &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Description: "Price of a kWh"},
+	},
+})
+
+var usagePointType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "UsagePoint",
+	Fields: graphql.Fields{
+		"intervalStart":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"energyConsumption": &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Description: "kWh"},
+		"energyCost":        &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"carbonEmissions":   &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Description: "kg of CO2"},
+	},
+})
+
+var usageArgs = graphql.FieldConfigArgument{
+	"startDate":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String), Description: "First day, MM/DD/YYYY"},
+	"endDate":     &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String), Description: "Last day, MM/DD/YYYY"},
+	"granularity": &graphql.ArgumentConfig{Type: granularityEnum, DefaultValue: "DAY"},
+}
+
+var statusArgs = graphql.FieldConfigArgument{
+	"status": &graphql.ArgumentConfig{Type: statusEnum, DefaultValue: "active"},
+}
+
+var enrolledDeviceType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "EnrolledDevice",
+	Fields: graphql.Fields{
+		"id":                &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"serviceLocationId": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"deviceId":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"aliasName":         &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"roomNumber":        &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"deviceType":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"device":            &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"active":            &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Resolve: resolveActive},
+		"deletedAt":         &graphql.Field{Type: graphql.String, Resolve: resolveDeletedAt},
+		"usage": &graphql.Field{
+			Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(usagePointType))),
+			Args:    usageArgs,
+			Resolve: resolveUsage("device"),
+		},
+	},
+})
+
+var serviceLocationType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "ServiceLocation",
+	Fields: graphql.Fields{
+		"id":             &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"dateTakenOver":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"occupantsCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"unitNumber":     &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"street":         &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"city":           &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"state":          &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"zipcode":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"country":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"squareFootage":  &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+		"bedroomsCount":  &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"role":           &graphql.Field{Type: graphql.NewNonNull(graphql.String), Description: "Role of the customer"},
+		"active":         &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Resolve: resolveActive},
+		"deletedAt":      &graphql.Field{Type: graphql.String, Resolve: resolveDeletedAt},
+		"enrolledDevices": &graphql.Field{
+			Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(enrolledDeviceType))),
+			Args:    statusArgs,
+			Resolve: resolveEnrolledDevices,
+		},
+		"usage": &graphql.Field{
+			Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(usagePointType))),
+			Args:    usageArgs,
+			Resolve: resolveUsage("location"),
+		},
+		"prices": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(priceType))),
+			Description: "Hourly prices of the zipcode",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				l, err := getLoaders(p.Context)
+				if err != nil {
+					return nil, err
+				}
+				return l.prices.load(string(p.Source.(model.ServiceLocation).Zipcode)), nil
+			},
+		},
+	},
+})
+
+var customerType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Customer",
+	Fields: graphql.Fields{
+		"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+		"firstName":   &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"lastName":    &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"phoneNumber": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"email":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"serviceLocations": &graphql.Field{
+			Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(serviceLocationType))),
+			Args:    statusArgs,
+			Resolve: resolveServiceLocations,
+		},
+	},
+})
+
+var queryType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Query",
+	Fields: graphql.Fields{
+		"customer": &graphql.Field{
+			Type:        graphql.NewNonNull(customerType),
+			Description: "Customer of the session",
+			Resolve:     resolveCustomer,
+		},
+		"prices": &graphql.Field{
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(priceType))),
+			Description: "Hourly prices of a zipcode",
+			Args: graphql.FieldConfigArgument{
+				"zipcode": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+			},
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				l, err := getLoaders(p.Context)
+				if err != nil {
+					return nil, err
+				}
+				return l.prices.load(validation.NormalizePostalCode(p.Args["zipcode"].(string))), nil
+			},
+		},
+	},
+})
+
+var schema = mustSchema()
+
+func mustSchema() graphql.Schema {
+	s, err := graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func resolveActive(p graphql.ResolveParams) (interface{}, error) {
+	switch source := p.Source.(type) {
+	case model.ServiceLocation:
+		return source.Active == 1, nil
+	case model.EnrolledDevice:
+		return source.Active == 1, nil
+	}
+	return nil, nil
+}
+
+func resolveDeletedAt(p graphql.ResolveParams) (interface{}, error) {
+	var deletedAt string
+	switch source := p.Source.(type) {
+	case model.ServiceLocation:
+		deletedAt = source.DeletedAt
+	case model.EnrolledDevice:
+		deletedAt = source.DeletedAt
+	}
+	if len(deletedAt) == 0 {
+		return nil, nil
+	}
+	return deletedAt, nil
+}
+
+func resolveCustomer(p graphql.ResolveParams) (interface{}, error) {
+	l, err := getLoaders(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	var customer model.Customer
+	err = l.db.QueryRowContext(p.Context, queryToGetCustomer(), l.customerId).Scan(&customer.Id, &customer.FirstName, &customer.LastName, &customer.PhoneNumber, &customer.Email)
+	if err == sql.ErrNoRows {
+		return nil, apierror.NotFound("Customer does not exist")
+	}
+	return customer, err
+}
+
+// getStatus reads the status argument of lists
+func getStatus(p graphql.ResolveParams) (string, uint32) {
+	status, _ := p.Args["status"].(string)
+	if status == "inactive" || status == "all" {
+		return status, 0
+	}
+	return "active", 1
+}
+
+func resolveServiceLocations(p graphql.ResolveParams) (interface{}, error) {
+	l, err := getLoaders(p.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	status, active := getStatus(p)
+	rows, err := l.db.QueryContext(p.Context, queryToFetchServiceLocations(), l.customerId, status, active)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	serviceLocations := []model.ServiceLocation{}
+	for rows.Next() {
+		var sl model.ServiceLocation
+		var deletedAt sql.NullString
+		err = rows.Scan(&sl.Id, &sl.CustomerId, &sl.DateTakenOver, &sl.OccupantsCount, &sl.UnitNumber, &sl.Street, &sl.City, &sl.State, &sl.Zipcode, &sl.Country, &sl.SquareFootage, &sl.BedroomsCount, &sl.Active, &deletedAt, &sl.Role)
+		if err != nil {
+			return nil, err
+		}
+		sl.DeletedAt = deletedAt.String
+		serviceLocations = append(serviceLocations, sl)
+	}
+	return serviceLocations, rows.Err()
+}
+
+func resolveEnrolledDevices(p graphql.ResolveParams) (interface{}, error) {
+	l, err := getLoaders(p.Context)
+	if err != nil {
+		return nil, err
+	}
+	status, active := getStatus(p)
+	return l.enrolledDevicesLoader(status, active).load(p.Source.(model.ServiceLocation).Id), nil
+}
+
+// resolveUsage resolves the usage of service locations or of devices
+func resolveUsage(level string) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		l, err := getLoaders(p.Context)
+		if err != nil {
+			return nil, err
+		}
+
+		startDate, _ := p.Args["startDate"].(string)
+		endDate, _ := p.Args["endDate"].(string)
+		granularity, _ := p.Args["granularity"].(string)
+		ur, err := getUsageRange(startDate, endDate, granularity)
+		if err != nil {
+			return nil, err
+		}
+
+		var id uint32
+		switch source := p.Source.(type) {
+		case model.ServiceLocation:
+			id = source.Id
+		case model.EnrolledDevice:
+			id = source.Id
+		default:
+			return nil, fmt.Errorf("usage of %T is not supported", p.Source)
+		}
+		return l.usageLoader(level, ur).load(id), nil
+	}
+}
@@ -0,0 +1,191 @@
+package graph
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"shems/apierror"
+	"shems/audit"
+	"shems/model"
+	redisService "shems/redis"
+	"shems/users"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Longest query text accepted
+	maxQueryLength = 20000
+
+	// Persisted queries which are not sent again in this time are forgotten
+	persistedQueryExpiry = 30 * 24 * time.Hour
+)
+
+// Clients that send only the hash of a query which is not persisted resend
+// it along with the query. The message and code are the ones of the Apollo
+// automatic persisted queries protocol which clients look for.
+var errPersistedQueryNotFound = model.GraphQLError{
+	Message:    "PersistedQueryNotFound",
+	Extensions: map[string]interface{}{"code": "PERSISTED_QUERY_NOT_FOUND"},
+}
+
+// toGraphQLError renders an error of the business logic the way apierror.Write
+// does, so that the cause of internal errors is logged but never sent
+func toGraphQLError(ctx context.Context, requestId string, err error) model.GraphQLError {
+	e := apierror.From(err)
+	if e.Err != nil {
+		slog.ErrorContext(ctx, "request failed", "error", e.Err)
+	}
+	return model.GraphQLError{
+		Message:    e.Message,
+		Extensions: map[string]interface{}{"code": e.Code, "requestId": requestId},
+	}
+}
+
+// fromFormattedErrors turns the errors of graphql-go into GraphQL errors.
+// Errors returned by resolvers go through toGraphQLError, the others are
+// syntax and validation errors of the query.
+func fromFormattedErrors(ctx context.Context, requestId string, errs []gqlerrors.FormattedError) []model.GraphQLError {
+	var graphQLErrors []model.GraphQLError
+	for _, formatted := range errs {
+		gqlErr := model.GraphQLError{
+			Message:    formatted.Message,
+			Extensions: map[string]interface{}{"code": apierror.CodeBadRequest, "requestId": requestId},
+		}
+		if located, ok := formatted.OriginalError().(*gqlerrors.Error); ok && located.OriginalError != nil {
+			gqlErr = toGraphQLError(ctx, requestId, located.OriginalError)
+		}
+		gqlErr.Path = formatted.Path
+		for _, l := range formatted.Locations {
+			gqlErr.Locations = append(gqlErr.Locations, model.GraphQLLocation{Line: l.Line, Column: l.Column})
+		}
+		graphQLErrors = append(graphQLErrors, gqlErr)
+	}
+	return graphQLErrors
+}
+
+// getQuery returns the text of the query, looking up persisted queries by
+// their hash. The second return value tells whether the query has to be
+// persisted once it is known to be valid.
+func getQuery(ctx context.Context, redisClient *redis.Client, req model.GraphQLRequest) (string, bool, error) {
+	persistedQuery := req.Extensions.PersistedQuery
+	if persistedQuery == nil {
+		return req.Query, false, nil
+	}
+	if persistedQuery.Version != 1 {
+		return "", false, apierror.BadRequest("Persisted query version is not supported")
+	}
+	hash := strings.ToLower(persistedQuery.Sha256Hash)
+
+	if len(req.Query) > 0 {
+		sum := sha256.Sum256([]byte(req.Query))
+		if hex.EncodeToString(sum[:]) != hash {
+			return "", false, apierror.BadRequest("Persisted query hash does not match the query")
+		}
+		return req.Query, true, nil
+	}
+
+	query, err := redisService.GetKey(ctx, redisClient, "PersistedQuery_"+hash)
+	if err == redis.Nil {
+		return "", false, nil
+	}
+	return query, false, err
+}
+
+func writeResponse(w http.ResponseWriter, resp model.GraphQLResponse) {
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(resp)
+}
+
+func Query(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.GraphQLRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	session, err := users.GetSession(ctx, redisClient, r)
+	if err == users.ErrNoSession {
+		apierror.Write(w, r, apierror.Unauthorized(err.Error()))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+
+	// validate the request
+	if len(req.Query) > maxQueryLength {
+		apierror.Write(w, r, apierror.BadRequest(fmt.Sprintf("Query cannot be longer than %d characters", maxQueryLength)))
+		return
+	}
+	query, persist, err := getQuery(ctx, redisClient, req)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if len(query) == 0 {
+		if req.Extensions.PersistedQuery != nil {
+			writeResponse(w, model.GraphQLResponse{Errors: []model.GraphQLError{errPersistedQueryNotFound}})
+			return
+		}
+		apierror.Write(w, r, apierror.BadRequest("Query cannot be empty"))
+		return
+	}
+
+	// parse and validate the query against the schema
+	requestId := audit.GetRequestId(r)
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		writeResponse(w, model.GraphQLResponse{Errors: fromFormattedErrors(ctx, requestId, gqlerrors.FormatErrors(err))})
+		return
+	}
+	validationResult := graphql.ValidateDocument(&schema, doc, nil)
+	if !validationResult.IsValid {
+		writeResponse(w, model.GraphQLResponse{Errors: fromFormattedErrors(ctx, requestId, validationResult.Errors)})
+		return
+	}
+
+	// validation: queries too deep or too expensive are not executed
+	complexity, err := queryComplexity(doc)
+	if err == nil && complexity > maxQueryComplexity {
+		err = apierror.BadRequest(fmt.Sprintf("Query complexity %d exceeds the limit of %d", complexity, maxQueryComplexity))
+	}
+	if err != nil {
+		writeResponse(w, model.GraphQLResponse{Errors: []model.GraphQLError{toGraphQLError(ctx, requestId, err)}})
+		return
+	}
+
+	// only valid queries are persisted
+	if persist {
+		err = redisService.SetKeyWithExpiry(ctx, redisClient, "PersistedQuery_"+strings.ToLower(req.Extensions.PersistedQuery.Sha256Hash), query, persistedQueryExpiry)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	}
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       context.WithValue(ctx, loadersKey{}, newLoaders(ctx, db, session.CustomerId)),
+	})
+	writeResponse(w, model.GraphQLResponse{Data: result.Data, Errors: fromFormattedErrors(ctx, requestId, result.Errors)})
+}
@@ -0,0 +1,153 @@
+package graph
+
+import (
+	"fmt"
+	"shems/tracing"
+	"strings"
+)
+
+func init() {
+	tracing.RegisterQueries(
+		queryToGetCustomer,
+		queryToFetchServiceLocations,
+	)
+}
+
+// placeholders returns the placeholders of an IN list of count values
+func placeholders(count int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", count), ", ")
+}
+
+func queryToGetCustomer() string {
+	sqlQuery := `
+	SELECT
+		id, first_name, last_name, phone_number, email
+	FROM
+		Customers
+	WHERE
+		id = ?;
+	`
+	return sqlQuery
+}
+
+func queryToFetchServiceLocations() string {
+	sqlQuery := `
+	SELECT
+		sl.id, sl.customer_id, sl.date_taken_over, sl.occupants_count, l.unit_number, l.street, l.city, l.state, l.zipcode, l.country, l.square_footage, l.bedrooms_count, sl.active, sl.deleted_at, slm.role
+	FROM
+		Service_Locations sl
+	INNER JOIN
+		Locations l ON l.id = sl.location_id
+	INNER JOIN
+		Service_Location_Members slm ON slm.service_location_id = sl.id
+	WHERE
+		slm.customer_id = ?
+		AND (? = 'all' OR sl.active = ?)
+	ORDER BY
+		sl.id;
+	`
+	return sqlQuery
+}
+
+func queryToFetchEnrolledDevicesByServiceLocations(count int) string {
+	sqlQuery := `
+	SELECT
+		ed.id, ed.service_location_id, ed.device_id, ed.alias_name, ed.room_number, ed.active, ed.deleted_at, d.type, d.model_number
+	FROM
+		Enrolled_Devices ed
+	INNER JOIN
+		Devices d ON d.id = ed.device_id
+	WHERE
+		ed.service_location_id IN (%s)
+		AND (? = 'all' OR ed.active = ?)
+	ORDER BY
+		ed.id;
+	`
+	return fmt.Sprintf(sqlQuery, placeholders(count))
+}
+
+func queryToFetchUsageByServiceLocations(count int) string {
+	sqlQuery := `
+	SELECT
+		sl.id AS service_location_id,
+		DATE_FORMAT(e.created_at, ?) AS interval_start,
+		SUM(e.value) AS energy_consumption,
+		SUM(CASE WHEN p.value IS NOT NULL THEN e.value * p.value ELSE 0 END) AS energy_cost,
+		SUM(CASE WHEN ci.value IS NOT NULL THEN e.value * ci.value ELSE 0 END) / 1000 AS carbon_emissions
+	FROM
+		Service_Locations sl
+	INNER JOIN
+		Enrolled_Device_History edh ON edh.service_location_id = sl.id
+	INNER JOIN
+		Service_Location_History slh ON slh.service_location_id = sl.id
+	INNER JOIN
+		Locations l ON l.id = slh.location_id
+	INNER JOIN
+		Events e ON e.enrolled_device_id = edh.enrolled_device_id AND e.label = 'energy use' AND e.created_at >= ? AND e.created_at <= ?
+			AND e.created_at >= edh.valid_from AND (edh.valid_to IS NULL OR e.created_at < edh.valid_to)
+			AND e.created_at >= slh.valid_from AND (slh.valid_to IS NULL OR e.created_at < slh.valid_to)
+	LEFT JOIN
+		Prices p ON p.zipcode = l.zipcode AND p.hour = HOUR(e.created_at) + 1
+	LEFT JOIN
+		Carbon_Intensities ci ON ci.zipcode = l.zipcode AND ci.hour = HOUR(e.created_at) + 1
+	WHERE
+		sl.id IN (SELECT service_location_id FROM Service_Location_Members WHERE customer_id = ?)
+		AND sl.id IN (%s)
+	GROUP BY
+		1, 2
+	ORDER BY
+		1, 2;
+	`
+	return fmt.Sprintf(sqlQuery, placeholders(count))
+}
+
+func queryToFetchUsageByDevices(count int) string {
+	sqlQuery := `
+	SELECT
+		ed.id AS enrolled_device_id,
+		DATE_FORMAT(e.created_at, ?) AS interval_start,
+		SUM(e.value) AS energy_consumption,
+		SUM(CASE WHEN p.value IS NOT NULL THEN e.value * p.value ELSE 0 END) AS energy_cost,
+		SUM(CASE WHEN ci.value IS NOT NULL THEN e.value * ci.value ELSE 0 END) / 1000 AS carbon_emissions
+	FROM
+		Enrolled_Devices ed
+	INNER JOIN
+		Enrolled_Device_History edh ON edh.enrolled_device_id = ed.id
+	INNER JOIN
+		Service_Locations sl ON sl.id = edh.service_location_id
+	INNER JOIN
+		Service_Location_History slh ON slh.service_location_id = sl.id
+	INNER JOIN
+		Locations l ON l.id = slh.location_id
+	INNER JOIN
+		Events e ON e.enrolled_device_id = ed.id AND e.label = 'energy use' AND e.created_at >= ? AND e.created_at <= ?
+			AND e.created_at >= edh.valid_from AND (edh.valid_to IS NULL OR e.created_at < edh.valid_to)
+			AND e.created_at >= slh.valid_from AND (slh.valid_to IS NULL OR e.created_at < slh.valid_to)
+	LEFT JOIN
+		Prices p ON p.zipcode = l.zipcode AND p.hour = HOUR(e.created_at) + 1
+	LEFT JOIN
+		Carbon_Intensities ci ON ci.zipcode = l.zipcode AND ci.hour = HOUR(e.created_at) + 1
+	WHERE
+		sl.id IN (SELECT service_location_id FROM Service_Location_Members WHERE customer_id = ?)
+		AND ed.id IN (%s)
+	GROUP BY
+		1, 2
+	ORDER BY
+		1, 2;
+	`
+	return fmt.Sprintf(sqlQuery, placeholders(count))
+}
+
+func queryToFetchPricesByZipcodes(count int) string {
+	sqlQuery := `
+	SELECT
+		zipcode, hour, value
+	FROM
+		Prices
+	WHERE
+		zipcode IN (%s)
+	ORDER BY
+		zipcode, hour;
+	`
+	return fmt.Sprintf(sqlQuery, placeholders(count))
+}
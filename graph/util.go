@@ -0,0 +1,339 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"shems/apierror"
+	"shems/model"
+	"time"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+const (
+	// Deepest nesting of fields in a query
+	maxQueryDepth = 10
+
+	// Largest estimated cost of a query, see queryComplexity
+	maxQueryComplexity = 20000
+)
+
+// Estimated number of items returned by list fields. The cost of the fields
+// selected under a list is multiplied by it.
+var listSizes = map[string]int{
+	"serviceLocations": 10,
+	"enrolledDevices":  20,
+	"usage":            31,
+	"prices":           24,
+}
+
+// DATE_FORMAT formats of the usage granularities, which are also the format
+// of the interval starts
+var granularityFormats = map[string]string{
+	"HOUR":  "%Y-%m-%d %H:00:00",
+	"DAY":   "%Y-%m-%d",
+	"MONTH": "%Y-%m",
+}
+
+// Longest range of days usage can be fetched for at each granularity
+var maxUsageDays = map[string]int{
+	"HOUR":  31,
+	"DAY":   366,
+	"MONTH": 3660,
+}
+
+// loader collects the keys that resolvers ask for and fetches all of them in
+// one query when the first value is needed, like a dataloader. graphql-go
+// resolves the fields of every item of a list before it runs the thunks
+// returned by the resolvers, so for example the devices of all the service
+// locations are fetched together instead of one query per service location.
+// A query is executed by a single goroutine so no locking is needed.
+type loader[K comparable, V any] struct {
+	fetch   func(keys []K) (map[K]V, error)
+	pending []K
+	values  map[K]V
+	errs    map[K]error
+}
+
+func newLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{fetch: fetch, values: make(map[K]V), errs: make(map[K]error)}
+}
+
+// load queues the key and returns a thunk resolving to its value
+func (l *loader[K, V]) load(key K) func() (interface{}, error) {
+	_, loaded := l.values[key]
+	if !loaded && l.errs[key] == nil && !l.isPending(key) {
+		l.pending = append(l.pending, key)
+	}
+
+	return func() (interface{}, error) {
+		if len(l.pending) > 0 {
+			keys := l.pending
+			l.pending = nil
+			values, err := l.fetch(keys)
+			for _, k := range keys {
+				if err != nil {
+					l.errs[k] = err
+					continue
+				}
+				l.values[k] = values[k]
+			}
+		}
+		if err := l.errs[key]; err != nil {
+			return nil, err
+		}
+		return l.values[key], nil
+	}
+}
+
+func (l *loader[K, V]) isPending(key K) bool {
+	for _, k := range l.pending {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// usageRange is the range and granularity usage is fetched for, usage of
+// different ranges is fetched separately
+type usageRange struct {
+	granularity string
+	start       time.Time
+	end         time.Time
+}
+
+// loaders batch the queries of one request. They only return data of the
+// service locations the customer is a member of.
+type loaders struct {
+	ctx             context.Context
+	db              *sql.DB
+	customerId      uint32
+	enrolledDevices map[string]*loader[uint32, []model.EnrolledDevice]
+	locationUsage   map[usageRange]*loader[uint32, []model.UsagePoint]
+	deviceUsage     map[usageRange]*loader[uint32, []model.UsagePoint]
+	prices          *loader[string, []model.Price]
+}
+
+type loadersKey struct{}
+
+func newLoaders(ctx context.Context, db *sql.DB, customerId uint32) *loaders {
+	l := &loaders{
+		ctx:             ctx,
+		db:              db,
+		customerId:      customerId,
+		enrolledDevices: make(map[string]*loader[uint32, []model.EnrolledDevice]),
+		locationUsage:   make(map[usageRange]*loader[uint32, []model.UsagePoint]),
+		deviceUsage:     make(map[usageRange]*loader[uint32, []model.UsagePoint]),
+	}
+	l.prices = newLoader(l.fetchPrices)
+	return l
+}
+
+func getLoaders(ctx context.Context) (*loaders, error) {
+	l, ok := ctx.Value(loadersKey{}).(*loaders)
+	if !ok {
+		return nil, apierror.Unauthorized("Session is required")
+	}
+	return l, nil
+}
+
+// enrolledDevicesLoader returns the loader of the enrolled devices with the
+// status, by service location
+func (l *loaders) enrolledDevicesLoader(status string, active uint32) *loader[uint32, []model.EnrolledDevice] {
+	if _, ok := l.enrolledDevices[status]; !ok {
+		l.enrolledDevices[status] = newLoader(func(serviceLocationIds []uint32) (map[uint32][]model.EnrolledDevice, error) {
+			return l.fetchEnrolledDevices(serviceLocationIds, status, active)
+		})
+	}
+	return l.enrolledDevices[status]
+}
+
+func (l *loaders) usageLoader(level string, ur usageRange) *loader[uint32, []model.UsagePoint] {
+	loaders := l.locationUsage
+	query := queryToFetchUsageByServiceLocations
+	if level == "device" {
+		loaders = l.deviceUsage
+		query = queryToFetchUsageByDevices
+	}
+
+	if _, ok := loaders[ur]; !ok {
+		loaders[ur] = newLoader(func(ids []uint32) (map[uint32][]model.UsagePoint, error) {
+			return l.fetchUsage(query(len(ids)), ids, ur)
+		})
+	}
+	return loaders[ur]
+}
+
+func (l *loaders) fetchEnrolledDevices(serviceLocationIds []uint32, status string, active uint32) (map[uint32][]model.EnrolledDevice, error) {
+	enrolledDevices := make(map[uint32][]model.EnrolledDevice)
+	args := make([]interface{}, 0, len(serviceLocationIds)+2)
+	for _, id := range serviceLocationIds {
+		enrolledDevices[id] = []model.EnrolledDevice{}
+		args = append(args, id)
+	}
+	args = append(args, status, active)
+
+	rows, err := l.db.QueryContext(l.ctx, queryToFetchEnrolledDevicesByServiceLocations(len(serviceLocationIds)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var ed model.EnrolledDevice
+		var deletedAt sql.NullString
+		var modelNumber string
+		err = rows.Scan(&ed.Id, &ed.ServiceLocationId, &ed.DeviceId, &ed.AliasName, &ed.RoomNumber, &ed.Active, &deletedAt, &ed.DeviceType, &modelNumber)
+		if err != nil {
+			return nil, err
+		}
+		ed.DeletedAt = deletedAt.String
+		ed.Device = fmt.Sprint(modelNumber, " (", ed.DeviceType, ")")
+		enrolledDevices[ed.ServiceLocationId] = append(enrolledDevices[ed.ServiceLocationId], ed)
+	}
+	return enrolledDevices, rows.Err()
+}
+
+// fetchUsage returns the usage of service locations or devices by interval
+func (l *loaders) fetchUsage(query string, ids []uint32, ur usageRange) (map[uint32][]model.UsagePoint, error) {
+	usage := make(map[uint32][]model.UsagePoint)
+	args := []interface{}{granularityFormats[ur.granularity], ur.start, ur.end, l.customerId}
+	for _, id := range ids {
+		usage[id] = []model.UsagePoint{}
+		args = append(args, id)
+	}
+
+	rows, err := l.db.QueryContext(l.ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uint32
+		var u model.UsagePoint
+		err = rows.Scan(&id, &u.IntervalStart, &u.EnergyConsumption, &u.EnergyCost, &u.CarbonEmissions)
+		if err != nil {
+			return nil, err
+		}
+		usage[id] = append(usage[id], u)
+	}
+	return usage, rows.Err()
+}
+
+func (l *loaders) fetchPrices(zipcodes []string) (map[string][]model.Price, error) {
+	prices := make(map[string][]model.Price)
+	args := make([]interface{}, 0, len(zipcodes))
+	for _, zipcode := range zipcodes {
+		prices[zipcode] = []model.Price{}
+		args = append(args, zipcode)
+	}
+
+	rows, err := l.db.QueryContext(l.ctx, queryToFetchPricesByZipcodes(len(zipcodes)), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var p model.Price
+		err = rows.Scan(&p.Zipcode, &p.Hour, &p.Value)
+		if err != nil {
+			return nil, err
+		}
+		prices[p.Zipcode] = append(prices[p.Zipcode], p)
+	}
+	return prices, rows.Err()
+}
+
+// getUsageRange parses the inclusive range of dates in MM/DD/YYYY format and
+// makes sure it is not too long for the granularity
+func getUsageRange(startDate, endDate, granularity string) (usageRange, error) {
+	ur := usageRange{granularity: granularity}
+	start, err := time.ParseInLocation("01/02/2006", startDate, time.Local)
+	if err != nil {
+		return ur, apierror.BadRequest("Start Date must be in MM/DD/YYYY format")
+	}
+	end, err := time.ParseInLocation("01/02/2006", endDate, time.Local)
+	if err != nil {
+		return ur, apierror.BadRequest("End Date must be in MM/DD/YYYY format")
+	}
+	if end.Before(start) {
+		return ur, apierror.BadRequest("End Date cannot be before Start Date")
+	}
+	if end.Sub(start) >= time.Duration(maxUsageDays[granularity])*24*time.Hour {
+		return ur, apierror.BadRequest(fmt.Sprintf("Usage by %s can be fetched for at most %d days", granularity, maxUsageDays[granularity]))
+	}
+
+	ur.start = start
+	ur.end = end.AddDate(0, 0, 1).Add(-time.Second)
+	return ur, nil
+}
+
+// queryComplexity estimates the cost of every operation of the document and
+// returns the highest. Every field costs 1 plus the cost of its selections,
+// which is multiplied by the estimated size of lists. Introspection fields
+// are free.
+func queryComplexity(doc *ast.Document) (int, error) {
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, definition := range doc.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	highest := 0
+	for _, definition := range doc.Definitions {
+		if operation, ok := definition.(*ast.OperationDefinition); ok {
+			cost, err := selectionSetCost(operation.SelectionSet, fragments, 1)
+			if err != nil {
+				return 0, err
+			}
+			if cost > highest {
+				highest = cost
+			}
+		}
+	}
+	return highest, nil
+}
+
+// selectionSetCost adds up the cost of the selections. Fragments have been
+// validated to exist and not to spread themselves, so the recursion ends.
+func selectionSetCost(set *ast.SelectionSet, fragments map[string]*ast.FragmentDefinition, depth int) (int, error) {
+	if set == nil {
+		return 0, nil
+	}
+	if depth > maxQueryDepth {
+		return 0, apierror.BadRequest(fmt.Sprintf("Query is nested deeper than %d levels", maxQueryDepth))
+	}
+
+	total := 0
+	for _, selection := range set.Selections {
+		var cost int
+		var err error
+		switch s := selection.(type) {
+		case *ast.Field:
+			if len(s.Name.Value) > 1 && s.Name.Value[:2] == "__" {
+				continue
+			}
+			cost, err = selectionSetCost(s.SelectionSet, fragments, depth+1)
+			if size, ok := listSizes[s.Name.Value]; ok {
+				cost *= size
+			}
+			cost++
+		case *ast.InlineFragment:
+			cost, err = selectionSetCost(s.SelectionSet, fragments, depth)
+		case *ast.FragmentSpread:
+			if fragment, ok := fragments[s.Name.Value]; ok {
+				cost, err = selectionSetCost(fragment.SelectionSet, fragments, depth)
+			}
+		}
+		if err != nil {
+			return 0, err
+		}
+		total += cost
+	}
+	return total, nil
+}
@@ -0,0 +1,110 @@
+package greenbutton
+
+import "encoding/xml"
+
+const (
+	atomNamespace = "http://www.w3.org/2005/Atom"
+	espiNamespace = "http://naesb.org/espi"
+)
+
+// ReadingType codes used by this server, see the ESPI specification
+const (
+	accumulationBehaviourDeltaData = 4
+	commodityElectricity           = 1
+	dataQualifierNormal            = 12
+	flowDirectionForward           = 1
+	kindEnergy                     = 12
+	phaseAll                       = 769
+	uomWh                          = 72
+	currencyUSD                    = 840
+	serviceCategoryElectricity     = 0
+)
+
+// ESPI costs are expressed in hundred thousandths of the currency
+const costMultiplier = 100000
+
+type Feed struct {
+	XMLName xml.Name `xml:"http://www.w3.org/2005/Atom feed"`
+	Id      string   `xml:"id"`
+	Title   string   `xml:"title"`
+	Updated string   `xml:"updated"`
+	Links   []Link   `xml:"link"`
+	Entries []Entry  `xml:"entry"`
+}
+
+type Link struct {
+	Rel  string `xml:"rel,attr"`
+	Href string `xml:"href,attr"`
+}
+
+type Entry struct {
+	Id        string  `xml:"id"`
+	Title     string  `xml:"title,omitempty"`
+	Published string  `xml:"published,omitempty"`
+	Updated   string  `xml:"updated"`
+	Links     []Link  `xml:"link"`
+	Content   Content `xml:"content"`
+}
+
+type Content struct {
+	UsagePoint     *UsagePoint     `xml:"http://naesb.org/espi UsagePoint,omitempty"`
+	MeterReading   *MeterReading   `xml:"http://naesb.org/espi MeterReading,omitempty"`
+	ReadingType    *ReadingType    `xml:"http://naesb.org/espi ReadingType,omitempty"`
+	IntervalBlocks []IntervalBlock `xml:"http://naesb.org/espi IntervalBlock,omitempty"`
+}
+
+type UsagePoint struct {
+	ServiceCategory      ServiceCategory       `xml:"ServiceCategory"`
+	Status               *int                  `xml:"status,omitempty"`
+	ServiceDeliveryPoint *ServiceDeliveryPoint `xml:"ServiceDeliveryPoint,omitempty"`
+}
+
+type ServiceCategory struct {
+	Kind int `xml:"kind"`
+}
+
+type ServiceDeliveryPoint struct {
+	Name          string `xml:"name,omitempty"`
+	TariffProfile string `xml:"tariffProfile,omitempty"`
+}
+
+type MeterReading struct{}
+
+type ReadingType struct {
+	AccumulationBehaviour int    `xml:"accumulationBehaviour"`
+	Commodity             int    `xml:"commodity"`
+	Currency              int    `xml:"currency,omitempty"`
+	DataQualifier         int    `xml:"dataQualifier"`
+	FlowDirection         int    `xml:"flowDirection"`
+	IntervalLength        uint32 `xml:"intervalLength"`
+	Kind                  int    `xml:"kind"`
+	Phase                 int    `xml:"phase"`
+	PowerOfTenMultiplier  int    `xml:"powerOfTenMultiplier"`
+	TimeAttribute         int    `xml:"timeAttribute"`
+	Uom                   int    `xml:"uom"`
+}
+
+type IntervalBlock struct {
+	Interval         DateTimeInterval  `xml:"interval"`
+	IntervalReadings []IntervalReading `xml:"IntervalReading"`
+}
+
+type DateTimeInterval struct {
+	Duration uint32 `xml:"duration"`
+	Start    int64  `xml:"start"`
+}
+
+type IntervalReading struct {
+	Cost       *int64            `xml:"cost,omitempty"`
+	TimePeriod *DateTimeInterval `xml:"timePeriod,omitempty"`
+	Value      int64             `xml:"value"`
+}
+
+func (e Entry) getLink(rel string) string {
+	for _, link := range e.Links {
+		if link.Rel == rel {
+			return link.Href
+		}
+	}
+	return ""
+}
@@ -0,0 +1,7 @@
+package greenbutton
+
+type HourlyUsage = hourlyUsage
+type ServiceLocationUsage = serviceLocationUsage
+
+var ParseIntervalReadings = parseIntervalReadings
+var BuildFeed = buildFeed
@@ -0,0 +1,257 @@
+package greenbutton
+
+import (
+	"database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"shems/access"
+	"shems/apierror"
+	"shems/dbutil"
+	"shems/metrics"
+	"shems/model"
+	redisService "shems/redis"
+	"shems/users"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func ImportGreenButton(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	// the customer is the one the session belongs to
+	session, ok := users.GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+	customerId := session.CustomerId
+
+	// Get service location id from query params
+	serviceLocationIdInt, err := users.GetIdFromQueryParams(r, "serviceLocationId", "Service Location Id")
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+
+	// Get enrolled device id of the meter from query params
+	enrolledDeviceIdInt, err := users.GetIdFromQueryParams(r, "enrolledDeviceId", "Enrolled Device Id")
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+
+	// XML is either uploaded as a multipart file or sent as the raw body
+	var reader io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			apierror.Write(w, r, apierror.BadRequest("File is required"))
+			return
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	var feed Feed
+	err = xml.NewDecoder(reader).Decode(&feed)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Request body must be valid Green Button XML"))
+		return
+	}
+
+	readings, skippedCount := parseIntervalReadings(feed)
+	if len(readings) == 0 {
+		apierror.Write(w, r, apierror.BadRequest("No energy readings found in the Green Button XML"))
+		return
+	}
+
+	// validation: check if customer can manage devices of the service location
+	err = access.CheckServiceLocationPermission(ctx, conn, customerId, uint32(serviceLocationIdInt), access.ManageDevices)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// validation: check if enrolled device exists in the service location
+	query := queryToCheckIfEnrolledDeviceExistsInServiceLocation()
+	rows, err := conn.QueryContext(ctx, query, enrolledDeviceIdInt, serviceLocationIdInt)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var checkId int
+	for rows.Next() {
+		err = rows.Scan(&checkId)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	}
+	if checkId != enrolledDeviceIdInt {
+		apierror.Write(w, r, apierror.NotFound("Enrolled Device does not exist in this Service Location"))
+		return
+	}
+
+	redisKey := "ImportGreenButton_EnrolledDeviceId_" + fmt.Sprint(enrolledDeviceIdInt)
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		// release redis lock
+		redisService.ReleaseLock(ctx, redisClient, redisKey)
+
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// take redis lock to avoid concurrent imports for the same meter
+	err = users.TakeRedisLock(ctx, redisClient, redisKey)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// insert readings which have not been imported before
+	resp := model.ImportGreenButtonResponse{
+		SkippedCount: skippedCount,
+	}
+	query = queryToAddEventIfNotExists()
+	for _, reading := range readings {
+		createdAt := reading.Start.Format(dbutil.TimeLayout)
+		result, err := tx.ExecContext(ctx, query, enrolledDeviceIdInt, reading.Value, createdAt, enrolledDeviceIdInt, createdAt)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		if rowsAffected > 0 {
+			resp.ImportedCount++
+		} else {
+			resp.SkippedCount++
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+	metrics.EventsIngested("greenButton", int(resp.ImportedCount))
+
+	json.NewEncoder(w).Encode(resp)
+}
+
+func ExportGreenButton(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	// the customer is the one the session belongs to
+	session, ok := users.GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+	customerId := session.CustomerId
+
+	// Get date range from query params, both dates are inclusive
+	startDateTime, err := time.ParseInLocation("01/02/2006", r.URL.Query().Get("startDate"), time.Local)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("Start Date must be in MM/DD/YYYY format"))
+		return
+	}
+	endDate, err := time.ParseInLocation("01/02/2006", r.URL.Query().Get("endDate"), time.Local)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest("End Date must be in MM/DD/YYYY format"))
+		return
+	}
+	if endDate.Before(startDateTime) {
+		apierror.Write(w, r, apierror.BadRequest("End Date cannot be before Start Date"))
+		return
+	}
+	endDateTime := endDate.AddDate(0, 0, 1).Add(-time.Second)
+
+	// get all service locations
+	query := queryToGetAllServiceLocations()
+	rows, err := db.QueryContext(ctx, query, customerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var locations []serviceLocationUsage
+	locationIndexes := make(map[uint32]int)
+	for rows.Next() {
+		var sl model.ServiceLocation
+		err = rows.Scan(&sl.Id, &sl.CustomerId, &sl.DateTakenOver, &sl.OccupantsCount, &sl.UnitNumber, &sl.Street, &sl.City, &sl.State, &sl.Zipcode, &sl.Country, &sl.SquareFootage, &sl.BedroomsCount, &sl.Active)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		sl.LocationLabel = fmt.Sprint(sl.UnitNumber, ", ", sl.Street, ", ", sl.City, ", ", sl.State, ", ", sl.Zipcode, ", ", sl.Country)
+		locationIndexes[sl.Id] = len(locations)
+		locations = append(locations, serviceLocationUsage{ServiceLocation: sl})
+	}
+
+	// get hourly usage by service locations
+	query = queryToFetchHourlyUsageByServiceLocations()
+	rows, err = db.QueryContext(ctx, query, startDateTime, endDateTime, customerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var serviceLocationId uint32
+		var hourStart string
+		var usage hourlyUsage
+		err = rows.Scan(&serviceLocationId, &hourStart, &usage.Value, &usage.Cost)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		usage.Start, err = time.ParseInLocation(dbutil.TimeLayout, hourStart, time.Local)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+
+		i, ok := locationIndexes[serviceLocationId]
+		if ok {
+			locations[i].Readings = append(locations[i].Readings, usage)
+		}
+	}
+
+	feed := buildFeed(customerId, locations, time.Now())
+
+	w.Header().Set("Content-Type", "application/atom+xml")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"green_button_%d.xml\"", customerId))
+	w.Write([]byte(xml.Header))
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	err = encoder.Encode(feed)
+	if err != nil {
+		slog.ErrorContext(ctx, "error while writing green button xml", "error", err)
+	}
+}
@@ -0,0 +1,92 @@
+package greenbutton
+
+import "shems/tracing"
+
+func init() {
+	tracing.RegisterQueries(
+		queryToCheckIfEnrolledDeviceExistsInServiceLocation,
+		queryToAddEventIfNotExists,
+		queryToGetAllServiceLocations,
+		queryToFetchHourlyUsageByServiceLocations,
+	)
+}
+
+func queryToCheckIfEnrolledDeviceExistsInServiceLocation() string {
+	sqlQuery := `
+	SELECT
+		id
+	FROM
+		Enrolled_Devices
+	WHERE
+		id = ?
+		AND service_location_id = ?;
+	`
+	return sqlQuery
+}
+
+func queryToAddEventIfNotExists() string {
+	sqlQuery := `
+				INSERT INTO Events
+					(enrolled_device_id, label, value, created_at)
+				SELECT
+					?, 'energy use', ?, ?
+				FROM
+					DUAL
+				WHERE NOT EXISTS (
+					SELECT
+						1
+					FROM
+						Events
+					WHERE
+						enrolled_device_id = ?
+						AND label = 'energy use'
+						AND created_at = ?
+				);
+				`
+	return sqlQuery
+}
+
+func queryToGetAllServiceLocations() string {
+	sqlQuery := `
+	SELECT
+		sl.id, sl.customer_id, sl.date_taken_over, sl.occupants_count, l.unit_number, l.street, l.city, l.state, l.zipcode, l.country, l.square_footage, l.bedrooms_count, sl.active
+	FROM
+		Service_Locations sl
+	INNER JOIN
+		Locations l ON l.id = sl.location_id
+	WHERE
+		sl.id IN (SELECT service_location_id FROM Service_Location_Members WHERE customer_id = ?);
+	`
+	return sqlQuery
+}
+
+func queryToFetchHourlyUsageByServiceLocations() string {
+	sqlQuery := `
+	SELECT
+		sl.id AS service_location_id,
+		DATE_FORMAT(e.created_at, '%Y-%m-%d %H:00:00') AS hour_start,
+		SUM(e.value) AS energy_consumption,
+		SUM(CASE WHEN p.value IS NOT NULL THEN e.value * p.value ELSE 0 END) AS energy_cost
+	FROM
+		Service_Locations sl
+	INNER JOIN
+		Enrolled_Device_History edh ON edh.service_location_id = sl.id
+	INNER JOIN
+		Service_Location_History slh ON slh.service_location_id = sl.id
+	INNER JOIN
+		Locations l ON l.id = slh.location_id
+	INNER JOIN
+		Events e ON e.enrolled_device_id = edh.enrolled_device_id AND e.label = 'energy use' AND e.created_at >= ? AND e.created_at <= ?
+			AND e.created_at >= edh.valid_from AND (edh.valid_to IS NULL OR e.created_at < edh.valid_to)
+			AND e.created_at >= slh.valid_from AND (slh.valid_to IS NULL OR e.created_at < slh.valid_to)
+	LEFT JOIN
+		Prices p ON p.zipcode = l.zipcode AND p.hour = HOUR(e.created_at) + 1
+	WHERE
+		sl.id IN (SELECT service_location_id FROM Service_Location_Members WHERE customer_id = ?)
+	GROUP BY
+		1, 2
+	ORDER BY
+		1, 2;
+	`
+	return sqlQuery
+}
@@ -0,0 +1,212 @@
+package greenbutton
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"math"
+	"shems/model"
+	"strings"
+	"time"
+)
+
+// Exported readings are aggregated per hour
+const intervalLength = 3600
+
+type intervalReading struct {
+	Start time.Time
+	Value float32 // kWh
+}
+
+type hourlyUsage struct {
+	Start time.Time
+	Value float32 // kWh
+	Cost  float32
+}
+
+type serviceLocationUsage struct {
+	ServiceLocation model.ServiceLocation
+	Readings        []hourlyUsage
+}
+
+// getEntryId derives a stable name based uuid from a resource href
+func getEntryId(href string) string {
+	sum := sha1.Sum([]byte(href))
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
+
+// parseIntervalReadings extracts forward energy readings in kWh from the
+// IntervalBlocks of a feed, resolving each block's ReadingType through the
+// MeterReading links. Readings which are not forward energy are skipped.
+func parseIntervalReadings(feed Feed) ([]intervalReading, uint32) {
+	readingTypes := make(map[string]ReadingType)
+	meterReadingTypes := make(map[string]string)
+	var lastReadingType *ReadingType
+
+	for _, entry := range feed.Entries {
+		if entry.Content.ReadingType != nil {
+			readingTypes[entry.getLink("self")] = *entry.Content.ReadingType
+			lastReadingType = entry.Content.ReadingType
+		}
+		if entry.Content.MeterReading != nil {
+			for _, link := range entry.Links {
+				if link.Rel == "related" && strings.Contains(link.Href, "/ReadingType/") {
+					meterReadingTypes[entry.getLink("self")] = link.Href
+				}
+			}
+		}
+	}
+
+	var readings []intervalReading
+	var skippedCount uint32
+	for _, entry := range feed.Entries {
+		if len(entry.Content.IntervalBlocks) == 0 {
+			continue
+		}
+
+		// blocks link up to <MeterReading>/IntervalBlock
+		meterReading := strings.TrimSuffix(entry.getLink("up"), "/IntervalBlock")
+		readingType, ok := readingTypes[meterReadingTypes[meterReading]]
+		if !ok {
+			if len(readingTypes) > 1 || lastReadingType == nil {
+				// default to Wh when the feed does not describe its readings
+				readingType = ReadingType{Uom: uomWh, FlowDirection: flowDirectionForward}
+			} else {
+				readingType = *lastReadingType
+			}
+		}
+
+		for _, block := range entry.Content.IntervalBlocks {
+			for _, ir := range block.IntervalReadings {
+				if readingType.Uom != uomWh || readingType.FlowDirection != flowDirectionForward || ir.TimePeriod == nil {
+					skippedCount++
+					continue
+				}
+
+				wattHours := float64(ir.Value) * math.Pow10(readingType.PowerOfTenMultiplier)
+				readings = append(readings, intervalReading{
+					Start: time.Unix(ir.TimePeriod.Start, 0),
+					Value: float32(wattHours / 1000),
+				})
+			}
+		}
+	}
+	return readings, skippedCount
+}
+
+// buildFeed describes every service location as a UsagePoint with one hourly
+// MeterReading of forward energy in Wh and its cost in USD
+func buildFeed(customerId uint32, locations []serviceLocationUsage, now time.Time) Feed {
+	updated := now.UTC().Format(time.RFC3339)
+	customerHref := fmt.Sprintf("/espi/1_1/resource/RetailCustomer/%d", customerId)
+	readingTypeHref := "/espi/1_1/resource/ReadingType/1"
+
+	feed := Feed{
+		Id:      getEntryId(customerHref),
+		Title:   "Green Button Usage Feed",
+		Updated: updated,
+		Links:   []Link{{Rel: "self", Href: customerHref + "/UsagePoint"}},
+	}
+
+	feed.Entries = append(feed.Entries, Entry{
+		Id:      getEntryId(readingTypeHref),
+		Title:   "Hourly Electricity Consumption",
+		Updated: updated,
+		Links: []Link{
+			{Rel: "self", Href: readingTypeHref},
+			{Rel: "up", Href: "/espi/1_1/resource/ReadingType"},
+		},
+		Content: Content{
+			ReadingType: &ReadingType{
+				AccumulationBehaviour: accumulationBehaviourDeltaData,
+				Commodity:             commodityElectricity,
+				Currency:              currencyUSD,
+				DataQualifier:         dataQualifierNormal,
+				FlowDirection:         flowDirectionForward,
+				IntervalLength:        intervalLength,
+				Kind:                  kindEnergy,
+				Phase:                 phaseAll,
+				PowerOfTenMultiplier:  0,
+				TimeAttribute:         0,
+				Uom:                   uomWh,
+			},
+		},
+	})
+
+	for _, slu := range locations {
+		sl := slu.ServiceLocation
+		usagePointHref := fmt.Sprintf("%s/UsagePoint/%d", customerHref, sl.Id)
+		meterReadingHref := usagePointHref + "/MeterReading/1"
+
+		status := 0
+		if sl.Active == 1 {
+			status = 1
+		}
+
+		feed.Entries = append(feed.Entries, Entry{
+			Id:      getEntryId(usagePointHref),
+			Title:   sl.LocationLabel,
+			Updated: updated,
+			Links: []Link{
+				{Rel: "self", Href: usagePointHref},
+				{Rel: "up", Href: customerHref + "/UsagePoint"},
+				{Rel: "related", Href: usagePointHref + "/MeterReading"},
+			},
+			Content: Content{
+				UsagePoint: &UsagePoint{
+					ServiceCategory: ServiceCategory{Kind: serviceCategoryElectricity},
+					Status:          &status,
+					ServiceDeliveryPoint: &ServiceDeliveryPoint{
+						Name:          sl.LocationLabel,
+						TariffProfile: fmt.Sprint("Zipcode ", sl.Zipcode),
+					},
+				},
+			},
+		})
+
+		feed.Entries = append(feed.Entries, Entry{
+			Id:      getEntryId(meterReadingHref),
+			Title:   "Hourly Electricity Consumption",
+			Updated: updated,
+			Links: []Link{
+				{Rel: "self", Href: meterReadingHref},
+				{Rel: "up", Href: usagePointHref + "/MeterReading"},
+				{Rel: "related", Href: meterReadingHref + "/IntervalBlock"},
+				{Rel: "related", Href: readingTypeHref},
+			},
+			Content: Content{MeterReading: &MeterReading{}},
+		})
+
+		if len(slu.Readings) == 0 {
+			continue
+		}
+
+		block := IntervalBlock{
+			Interval: DateTimeInterval{
+				Start:    slu.Readings[0].Start.Unix(),
+				Duration: uint32(slu.Readings[len(slu.Readings)-1].Start.Unix()-slu.Readings[0].Start.Unix()) + intervalLength,
+			},
+		}
+		for _, reading := range slu.Readings {
+			cost := int64(math.Round(float64(reading.Cost) * costMultiplier))
+			block.IntervalReadings = append(block.IntervalReadings, IntervalReading{
+				Cost:       &cost,
+				TimePeriod: &DateTimeInterval{Start: reading.Start.Unix(), Duration: intervalLength},
+				Value:      int64(math.Round(float64(reading.Value) * 1000)),
+			})
+		}
+
+		intervalBlockHref := meterReadingHref + "/IntervalBlock/1"
+		feed.Entries = append(feed.Entries, Entry{
+			Id:      getEntryId(intervalBlockHref),
+			Updated: updated,
+			Links: []Link{
+				{Rel: "self", Href: intervalBlockHref},
+				{Rel: "up", Href: meterReadingHref + "/IntervalBlock"},
+			},
+			Content: Content{IntervalBlocks: []IntervalBlock{block}},
+		})
+	}
+	return feed
+}
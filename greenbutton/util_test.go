@@ -0,0 +1,157 @@
+package greenbutton_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"math"
+	"shems/greenbutton"
+	"shems/model"
+	"testing"
+	"time"
+)
+
+const (
+	meterReadingHref = "/espi/1_1/resource/RetailCustomer/1/UsagePoint/1/MeterReading/1"
+	readingTypeHref  = "/espi/1_1/resource/ReadingType/1"
+)
+
+func energyReadingType(powerOfTenMultiplier, flowDirection int) *greenbutton.ReadingType {
+	return &greenbutton.ReadingType{FlowDirection: flowDirection, PowerOfTenMultiplier: powerOfTenMultiplier, Uom: 72, IntervalLength: 3600}
+}
+
+// readingsFeed returns a feed with a meter reading, its interval block and,
+// when given, a reading type which the meter reading links to if linked
+func readingsFeed(readingType *greenbutton.ReadingType, linked bool, readings ...greenbutton.IntervalReading) greenbutton.Feed {
+	var feed greenbutton.Feed
+	if readingType != nil {
+		feed.Entries = append(feed.Entries, greenbutton.Entry{
+			Links:   []greenbutton.Link{{Rel: "self", Href: readingTypeHref}},
+			Content: greenbutton.Content{ReadingType: readingType},
+		})
+	}
+
+	meterReadingLinks := []greenbutton.Link{{Rel: "self", Href: meterReadingHref}}
+	if linked {
+		meterReadingLinks = append(meterReadingLinks, greenbutton.Link{Rel: "related", Href: readingTypeHref})
+	}
+	feed.Entries = append(feed.Entries,
+		greenbutton.Entry{
+			Links:   meterReadingLinks,
+			Content: greenbutton.Content{MeterReading: &greenbutton.MeterReading{}},
+		},
+		greenbutton.Entry{
+			Links:   []greenbutton.Link{{Rel: "up", Href: meterReadingHref + "/IntervalBlock"}},
+			Content: greenbutton.Content{IntervalBlocks: []greenbutton.IntervalBlock{{IntervalReadings: readings}}},
+		},
+	)
+	return feed
+}
+
+func reading(start int64, value int64) greenbutton.IntervalReading {
+	return greenbutton.IntervalReading{TimePeriod: &greenbutton.DateTimeInterval{Start: start, Duration: 3600}, Value: value}
+}
+
+func TestParseIntervalReadings(t *testing.T) {
+	tests := []struct {
+		name        string
+		feed        greenbutton.Feed
+		wantValues  []float32
+		wantSkipped uint32
+	}{
+		{
+			name:       "linked reading type scales the values",
+			feed:       readingsFeed(energyReadingType(3, 1), true, reading(0, 2), reading(3600, 5)),
+			wantValues: []float32{2, 5},
+		},
+		{
+			name:       "feed without reading types is read as Wh",
+			feed:       readingsFeed(nil, false, reading(0, 1500)),
+			wantValues: []float32{1.5},
+		},
+		{
+			name:       "the only reading type applies to unlinked blocks",
+			feed:       readingsFeed(energyReadingType(-1, 1), false, reading(0, 25000)),
+			wantValues: []float32{2.5},
+		},
+		{
+			name:        "reverse energy is skipped",
+			feed:        readingsFeed(energyReadingType(0, 19), true, reading(0, 1000), reading(3600, 2000)),
+			wantSkipped: 2,
+		},
+		{
+			name:        "readings without a time period are skipped",
+			feed:        readingsFeed(energyReadingType(0, 1), true, reading(0, 1000), greenbutton.IntervalReading{Value: 2000}),
+			wantValues:  []float32{1},
+			wantSkipped: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			readings, skipped := greenbutton.ParseIntervalReadings(tt.feed)
+			if skipped != tt.wantSkipped {
+				t.Errorf("got %d skipped readings, want %d", skipped, tt.wantSkipped)
+			}
+			if len(readings) != len(tt.wantValues) {
+				t.Fatalf("got %d readings, want %d", len(readings), len(tt.wantValues))
+			}
+			for i, want := range tt.wantValues {
+				if math.Abs(float64(readings[i].Value-want)) > 0.0001 {
+					t.Errorf("reading %d: got %v kWh, want %v", i, readings[i].Value, want)
+				}
+			}
+		})
+	}
+}
+
+// an exported feed imports back to the same hourly readings
+func TestBuildFeedRoundTrip(t *testing.T) {
+	start := time.Date(2024, time.March, 10, 0, 0, 0, 0, time.UTC)
+	locations := []greenbutton.ServiceLocationUsage{
+		{
+			ServiceLocation: model.ServiceLocation{Id: 1, Active: 1, LocationLabel: "1, Main Street", Zipcode: "02139"},
+			Readings: []greenbutton.HourlyUsage{
+				{Start: start, Value: 1.25, Cost: 0.3},
+				{Start: start.Add(time.Hour), Value: 0.5, Cost: 0.1},
+			},
+		},
+		{
+			ServiceLocation: model.ServiceLocation{Id: 2, Active: 1, LocationLabel: "2, Main Street", Zipcode: "02139"},
+			Readings: []greenbutton.HourlyUsage{
+				{Start: start.Add(2 * time.Hour), Value: 3.75, Cost: 0.9},
+			},
+		},
+		{
+			ServiceLocation: model.ServiceLocation{Id: 3, LocationLabel: "3, Main Street", Zipcode: "02139"},
+		},
+	}
+
+	var body bytes.Buffer
+	err := xml.NewEncoder(&body).Encode(greenbutton.BuildFeed(7, locations, start))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var feed greenbutton.Feed
+	err = xml.NewDecoder(&body).Decode(&feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readings, skipped := greenbutton.ParseIntervalReadings(feed)
+	if skipped != 0 {
+		t.Errorf("got %d skipped readings, want none", skipped)
+	}
+
+	var want []greenbutton.HourlyUsage
+	for _, slu := range locations {
+		want = append(want, slu.Readings...)
+	}
+	if len(readings) != len(want) {
+		t.Fatalf("got %d readings, want %d", len(readings), len(want))
+	}
+	for i, w := range want {
+		if !readings[i].Start.Equal(w.Start) || math.Abs(float64(readings[i].Value-w.Value)) > 0.001 {
+			t.Errorf("reading %d: got %v kWh at %v, want %v kWh at %v", i, readings[i].Value, readings[i].Start, w.Value, w.Start)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package grpcapi
+
+import (
+	"context"
+	"net/http"
+	"shems/apierror"
+	"shems/audit"
+	"shems/deadline"
+	"shems/logging"
+	"shems/metrics"
+	"shems/users"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Reflection describes the services to any client, without a session
+const reflectionPrefix = "/grpc.reflection."
+
+// newRequest translates a call into the request the business logic reads the
+// bearer token, client ip and request id from. Metadata becomes headers, so
+// the session is sent as "authorization: Bearer <token>" like over HTTP.
+func newRequest(ctx context.Context, method string) (*http.Request, error) {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, method, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	for key, values := range md {
+		// pseudo headers and binary metadata have no HTTP counterpart
+		if strings.HasPrefix(key, ":") || strings.HasSuffix(key, "-bin") {
+			continue
+		}
+		for _, value := range values {
+			r.Header.Add(key, value)
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		r.RemoteAddr = p.Addr.String()
+	}
+	return r, nil
+}
+
+// authenticate returns the context the services find the call in. The request
+// id is sent back in the header metadata, like the X-Request-Id response
+// header.
+func authenticate(ctx context.Context, redisClient *redis.Client, method string) (context.Context, string, error) {
+	r, err := newRequest(ctx, method)
+	if err != nil {
+		return ctx, "", err
+	}
+	requestId := audit.SetRequestId(r)
+	grpc.SetHeader(ctx, metadata.Pairs("x-request-id", requestId))
+	ctx = logging.WithRequestId(ctx, requestId)
+
+	if strings.HasPrefix(method, reflectionPrefix) {
+		return ctx, requestId, nil
+	}
+
+	session, err := users.GetSession(ctx, redisClient, r)
+	if err == users.ErrNoSession {
+		return ctx, requestId, apierror.Unauthorized(err.Error())
+	}
+	if err != nil {
+		return ctx, requestId, err
+	}
+	return users.WithCall(ctx, r, session), requestId, nil
+}
+
+// observe records the call once its error has become a status
+func observe(method string, start time.Time, err error) {
+	metrics.ObserveCall(method, status.Code(err).String(), time.Since(start))
+}
+
+func unaryInterceptor(redisClient *redis.Client, timeout time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		start := time.Now()
+		defer func() { observe(info.FullMethod, start, err) }()
+		ctx, cancel := deadline.ForCall(ctx, timeout)
+		defer cancel()
+
+		ctx, requestId, err := authenticate(ctx, redisClient, info.FullMethod)
+		if err != nil {
+			return nil, toStatus(ctx, requestId, err)
+		}
+		resp, err = handler(ctx, req)
+		return resp, toStatus(ctx, requestId, err)
+	}
+}
+
+// serverStream carries the context of the call to streaming services
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func streamInterceptor(redisClient *redis.Client, timeout time.Duration) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		start := time.Now()
+		defer func() { observe(info.FullMethod, start, err) }()
+		ctx, cancel := deadline.ForCall(stream.Context(), timeout)
+		defer cancel()
+
+		ctx, requestId, err := authenticate(ctx, redisClient, info.FullMethod)
+		if err != nil {
+			return toStatus(ctx, requestId, err)
+		}
+		err = handler(srv, &serverStream{ServerStream: stream, ctx: ctx})
+		return toStatus(ctx, requestId, err)
+	}
+}
@@ -0,0 +1,38 @@
+package grpcapi
+
+import (
+	"database/sql"
+	shemsv1 "shems/proto/shems/v1"
+	"shems/usage"
+	"shems/users"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// NewServer returns the gRPC server of internal services and the metering
+// gateway. Every call needs the bearer session of a customer, except for
+// reflection, which is only registered when enabled. Calls without a deadline
+// get the timeout. Calls are traced, continuing the trace of the traceparent
+// metadata.
+func NewServer(db *sql.DB, redisClient *redis.Client, timeout time.Duration, enableReflection bool) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(unaryInterceptor(redisClient, timeout)),
+		grpc.StreamInterceptor(streamInterceptor(redisClient, timeout)),
+		grpc.StatsHandler(otelgrpc.NewServerHandler()),
+	)
+
+	shemsv1.RegisterCustomerServiceServer(server, users.NewCustomerServer(db, redisClient))
+	shemsv1.RegisterServiceLocationServiceServer(server, users.NewServiceLocationServer(db, redisClient))
+	shemsv1.RegisterEnrolledDeviceServiceServer(server, users.NewEnrolledDeviceServer(db, redisClient))
+	shemsv1.RegisterUsageServiceServer(server, usage.NewUsageServer(db))
+	shemsv1.RegisterEventServiceServer(server, usage.NewEventServer(db, redisClient))
+
+	if enableReflection {
+		reflection.Register(server)
+	}
+	return server
+}
@@ -0,0 +1,90 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"shems/model"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Monitor runs the background workers and tracks whether they are still
+// running, and whether the server is draining before it shuts down
+type Monitor struct {
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+	running  map[string]bool
+	draining bool
+}
+
+func NewMonitor() *Monitor {
+	return &Monitor{running: make(map[string]bool)}
+}
+
+// Go runs a background worker until ctx is canceled. A worker which returns
+// before that makes the server not ready.
+func (m *Monitor) Go(ctx context.Context, name string, run func(ctx context.Context)) {
+	m.mu.Lock()
+	m.running[name] = true
+	m.mu.Unlock()
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		defer func() {
+			m.mu.Lock()
+			m.running[name] = false
+			m.mu.Unlock()
+		}()
+		run(ctx)
+	}()
+}
+
+// Drain makes the server not ready so that no new traffic is sent to it
+func (m *Monitor) Drain() {
+	m.mu.Lock()
+	m.draining = true
+	m.mu.Unlock()
+}
+
+// Wait waits for the workers to return after their context was canceled, at
+// most for the timeout
+func (m *Monitor) Wait(timeout time.Duration) error {
+	done := make(chan struct{})
+	go func() {
+		m.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+		return fmt.Errorf("background workers did not stop within %s", timeout)
+	}
+}
+
+func (m *Monitor) check() []model.HealthCheck {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var checks []model.HealthCheck
+	if m.draining {
+		checks = append(checks, unavailable("server", "shutting down"))
+	}
+
+	names := make([]string, 0, len(m.running))
+	for name := range m.running {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !m.running[name] {
+			checks = append(checks, unavailable("worker "+name, "stopped"))
+			continue
+		}
+		checks = append(checks, model.HealthCheck{Name: "worker " + name, Status: statusOk})
+	}
+	return checks
+}
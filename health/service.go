@@ -0,0 +1,115 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"shems/migrations"
+	"shems/model"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	statusOk          = "ok"
+	statusUnavailable = "unavailable"
+
+	// Longest time a readiness check waits for a dependency
+	checkTimeout = 2 * time.Second
+)
+
+// ping fails when MySQL or Redis cannot be reached
+func ping(ctx context.Context, db *sql.DB, redisClient *redis.Client) error {
+	err := db.PingContext(ctx)
+	if err != nil {
+		return fmt.Errorf("database: %w", err)
+	}
+	err = redisClient.Ping(ctx).Err()
+	if err != nil {
+		return fmt.Errorf("redis: %w", err)
+	}
+	return nil
+}
+
+// WaitForDependencies pings MySQL and Redis until both answer, waiting longer
+// after every failed attempt, and gives up after the timeout
+func WaitForDependencies(ctx context.Context, db *sql.DB, redisClient *redis.Client, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	backoff := 500 * time.Millisecond
+	for attempt := 1; ; attempt++ {
+		err := ping(ctx, db, redisClient)
+		if err == nil {
+			return nil
+		}
+		slog.WarnContext(ctx, "dependencies are not available yet", "attempt", attempt, "error", err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("dependencies are not available after %s: %w", timeout, err)
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, 10*time.Second)
+	}
+}
+
+// check reports a failed check with a fixed message. The readiness endpoint
+// is public, so the error itself is only logged.
+func check(ctx context.Context, name string, err error) model.HealthCheck {
+	if err != nil {
+		slog.WarnContext(ctx, "readiness check failed", "check", name, "error", err)
+		return unavailable(name, statusUnavailable)
+	}
+	return model.HealthCheck{Name: name, Status: statusOk}
+}
+
+func unavailable(name, message string) model.HealthCheck {
+	return model.HealthCheck{Name: name, Status: statusUnavailable, Message: message}
+}
+
+// GetLiveness answers as long as the process can serve requests
+func GetLiveness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(model.HealthResponse{Status: statusOk})
+}
+
+// GetReadiness answers with 503 while the server should not get traffic:
+// when MySQL or Redis cannot be reached, migrations are pending, a
+// background worker stopped, or the server is shutting down
+func GetReadiness(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client, monitor *Monitor) {
+	ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+	defer cancel()
+	w.Header().Set("Content-Type", "application/json")
+
+	var checks []model.HealthCheck
+	checks = append(checks, check(ctx, "database", db.PingContext(ctx)))
+	checks = append(checks, check(ctx, "redis", redisClient.Ping(ctx).Err()))
+
+	// the schema is behind the code while migrations are pending, which
+	// includes databases which do not track them yet
+	pending, err := migrations.Pending(ctx, db)
+	if err == nil && len(pending) > 0 {
+		slog.WarnContext(ctx, "readiness check failed", "check", "migrations", "pending", pending)
+		checks = append(checks, unavailable("migrations", "migrations pending"))
+	} else {
+		checks = append(checks, check(ctx, "migrations", err))
+	}
+
+	checks = append(checks, monitor.check()...)
+
+	resp := model.HealthResponse{Status: statusOk, Checks: checks}
+	for _, c := range checks {
+		if c.Status != statusOk {
+			resp.Status = statusUnavailable
+		}
+	}
+	if resp.Status != statusOk {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(resp)
+}
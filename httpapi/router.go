@@ -0,0 +1,525 @@
+package httpapi
+
+import (
+	"database/sql"
+	"net/http"
+	"shems/admin"
+	"shems/apierror"
+	"shems/carbon"
+	"shems/charging"
+	"shems/config"
+	"shems/demandresponse"
+	"shems/graph"
+	"shems/greenbutton"
+	"shems/health"
+	"shems/mail"
+	"shems/openapi"
+	"shems/privacy"
+	"shems/tracing"
+	"shems/usage"
+	"shems/users"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/redis/go-redis/v9"
+)
+
+// NewRouter returns the router of every endpoint of the HTTP API. Each route
+// has to be described in openapi.Routes, which the tests of the openapi
+// package check against this router.
+func NewRouter(db *sql.DB, redisClient *redis.Client, mailSender mail.Sender, monitor *health.Monitor, cfg config.Config) *mux.Router {
+	router := mux.NewRouter()
+
+	// POST API endpoint to login
+	router.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		users.LoginUser(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to complete login with a two factor authentication code
+	router.HandleFunc("/login/verifyMfa", func(w http.ResponseWriter, r *http.Request) {
+		users.VerifyMfaLogin(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to logout
+	router.HandleFunc("/logout", func(w http.ResponseWriter, r *http.Request) {
+		users.LogoutUser(w, r, redisClient)
+	})
+
+	// POST API endpoint to start two factor authentication enrollment
+	router.HandleFunc("/mfa/enroll", func(w http.ResponseWriter, r *http.Request) {
+		users.EnrollMfa(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to confirm two factor authentication enrollment
+	router.HandleFunc("/mfa/confirm", func(w http.ResponseWriter, r *http.Request) {
+		users.ConfirmMfa(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to disable two factor authentication
+	router.HandleFunc("/mfa/disable", func(w http.ResponseWriter, r *http.Request) {
+		users.DisableMfa(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to regenerate recovery codes
+	router.HandleFunc("/mfa/regenerateRecoveryCodes", func(w http.ResponseWriter, r *http.Request) {
+		users.RegenerateRecoveryCodes(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to register
+	router.HandleFunc("/register", func(w http.ResponseWriter, r *http.Request) {
+		users.RegisterUser(w, r, db, redisClient, mailSender, cfg.AppBaseURL)
+	})
+
+	// POST API endpoint to verify email with the token sent on registration
+	router.HandleFunc("/verifyEmail", func(w http.ResponseWriter, r *http.Request) {
+		users.VerifyEmail(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to resend the verification email
+	router.HandleFunc("/resendVerificationEmail", func(w http.ResponseWriter, r *http.Request) {
+		users.ResendVerificationEmail(w, r, db, redisClient, mailSender, cfg.AppBaseURL)
+	})
+
+	// POST API endpoint to send a password reset email
+	router.HandleFunc("/forgotPassword", func(w http.ResponseWriter, r *http.Request) {
+		users.ForgotPassword(w, r, db, redisClient, mailSender, cfg.AppBaseURL)
+	})
+
+	// POST API endpoint to reset password with the token from the reset email
+	router.HandleFunc("/resetPassword", func(w http.ResponseWriter, r *http.Request) {
+		users.ResetPassword(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to fetch the profile and billing address of a customer
+	router.HandleFunc("/profile/getProfile", func(w http.ResponseWriter, r *http.Request) {
+		users.GetProfile(w, r, db, redisClient)
+	})
+
+	// PUT API endpoint to update name and phone number
+	router.HandleFunc("/profile/updateProfile", func(w http.ResponseWriter, r *http.Request) {
+		users.UpdateProfile(w, r, db, redisClient)
+	})
+
+	// PUT API endpoint to change password with the current password
+	router.HandleFunc("/profile/changePassword", func(w http.ResponseWriter, r *http.Request) {
+		users.ChangePassword(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to change email, which sends a verification email to the new email
+	router.HandleFunc("/profile/changeEmail", func(w http.ResponseWriter, r *http.Request) {
+		users.ChangeEmail(w, r, db, redisClient, mailSender, cfg.AppBaseURL)
+	})
+
+	// POST API endpoint to confirm an email change with the emailed token
+	router.HandleFunc("/profile/confirmEmailChange", func(w http.ResponseWriter, r *http.Request) {
+		users.ConfirmEmailChange(w, r, db, redisClient, mailSender)
+	})
+
+	// PUT API endpoint to point the billing address at a service location or a new address
+	router.HandleFunc("/profile/updateBillingAddress", func(w http.ResponseWriter, r *http.Request) {
+		users.UpdateBillingAddress(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to fetch dashboard details
+	router.HandleFunc("/dashboard", func(w http.ResponseWriter, r *http.Request) {
+		users.GetDashboardData(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to fetch enrolled devices
+	router.HandleFunc("/dashboard/getEnrolledDevices", func(w http.ResponseWriter, r *http.Request) {
+		users.GetEnrolledDevices(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to add enrolled device
+	router.HandleFunc("/dashboard/addEnrolledDevice", func(w http.ResponseWriter, r *http.Request) {
+		users.AddEnrolledDevice(w, r, db, redisClient)
+	})
+
+	// PUT API endpoint to update enrolled device
+	router.HandleFunc("/dashboard/updateEnrolledDevice", func(w http.ResponseWriter, r *http.Request) {
+		users.UpdateEnrolledDevice(w, r, db, redisClient)
+	})
+
+	// DELETE API endpoint to delete enrolled device
+	router.HandleFunc("/dashboard/deleteEnrolledDevice", func(w http.ResponseWriter, r *http.Request) {
+		users.DeleteEnrolledDevice(w, r, db, redisClient)
+	})
+
+	// PUT API endpoint to restore deleted enrolled device
+	router.HandleFunc("/dashboard/restoreEnrolledDevice", func(w http.ResponseWriter, r *http.Request) {
+		users.RestoreEnrolledDevice(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to fetch service locations
+	router.HandleFunc("/dashboard/getServiceLocations", func(w http.ResponseWriter, r *http.Request) {
+		users.GetServiceLocations(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to add service location
+	router.HandleFunc("/dashboard/addServiceLocation", func(w http.ResponseWriter, r *http.Request) {
+		users.AddServiceLocation(w, r, db, redisClient)
+	})
+
+	// PUT API endpoint to update service location
+	router.HandleFunc("/dashboard/updateServiceLocation", func(w http.ResponseWriter, r *http.Request) {
+		users.UpdateServiceLocation(w, r, db, redisClient)
+	})
+
+	// DELETE API endpoint to delete service location
+	router.HandleFunc("/dashboard/deleteServiceLocation", func(w http.ResponseWriter, r *http.Request) {
+		users.DeleteServiceLocation(w, r, db, redisClient)
+	})
+
+	// PUT API endpoint to restore deleted service location along with its devices
+	router.HandleFunc("/dashboard/restoreServiceLocation", func(w http.ResponseWriter, r *http.Request) {
+		users.RestoreServiceLocation(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to fetch members and pending invitations of a service location
+	router.HandleFunc("/dashboard/getServiceLocationMembers", func(w http.ResponseWriter, r *http.Request) {
+		users.GetServiceLocationMembers(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to invite a member to a service location by email
+	router.HandleFunc("/dashboard/inviteServiceLocationMember", func(w http.ResponseWriter, r *http.Request) {
+		users.InviteServiceLocationMember(w, r, db, redisClient, mailSender, cfg.AppBaseURL)
+	})
+
+	// POST API endpoint to accept an invitation to a service location
+	router.HandleFunc("/dashboard/acceptServiceLocationInvitation", func(w http.ResponseWriter, r *http.Request) {
+		users.AcceptServiceLocationInvitation(w, r, db, redisClient)
+	})
+
+	// PUT API endpoint to change the role of a service location member
+	router.HandleFunc("/dashboard/updateServiceLocationMember", func(w http.ResponseWriter, r *http.Request) {
+		users.UpdateServiceLocationMember(w, r, db, redisClient)
+	})
+
+	// DELETE API endpoint to remove a member from a service location
+	router.HandleFunc("/dashboard/removeServiceLocationMember", func(w http.ResponseWriter, r *http.Request) {
+		users.RemoveServiceLocationMember(w, r, db, redisClient)
+	})
+
+	// DELETE API endpoint to delete a pending invitation
+	router.HandleFunc("/dashboard/deleteServiceLocationInvitation", func(w http.ResponseWriter, r *http.Request) {
+		users.DeleteServiceLocationInvitation(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to fetch charging sessions of a month
+	router.HandleFunc("/charging/getChargingSessions", func(w http.ResponseWriter, r *http.Request) {
+		charging.GetChargingSessions(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to detect charging sessions from charger events
+	router.HandleFunc("/charging/detectChargingSessions", func(w http.ResponseWriter, r *http.Request) {
+		charging.DetectChargingSessions(w, r, db, redisClient)
+	})
+
+	// PUT API endpoint to update charging session
+	router.HandleFunc("/charging/updateChargingSession", func(w http.ResponseWriter, r *http.Request) {
+		charging.UpdateChargingSession(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to fetch upcoming smart charging targets
+	router.HandleFunc("/charging/getChargingTargets", func(w http.ResponseWriter, r *http.Request) {
+		charging.GetChargingTargets(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to add smart charging target
+	router.HandleFunc("/charging/addChargingTarget", func(w http.ResponseWriter, r *http.Request) {
+		charging.AddChargingTarget(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to fetch monthly charging costs by service locations
+	router.HandleFunc("/charging/getChargingReport", func(w http.ResponseWriter, r *http.Request) {
+		charging.GetChargingReport(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to fetch monthly carbon emissions by service locations and devices
+	router.HandleFunc("/carbon/getCarbonReport", func(w http.ResponseWriter, r *http.Request) {
+		carbon.GetCarbonReport(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to fetch recommended low carbon windows of a service location
+	router.HandleFunc("/carbon/getLowCarbonWindows", func(w http.ResponseWriter, r *http.Request) {
+		carbon.GetLowCarbonWindows(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to fetch demand response programs
+	router.HandleFunc("/demandResponse/getPrograms", func(w http.ResponseWriter, r *http.Request) {
+		demandresponse.GetDRPrograms(w, r, db)
+	})
+
+	// GET API endpoint to fetch all upcoming and active demand response events
+	router.HandleFunc("/demandResponse/getAllEvents", func(w http.ResponseWriter, r *http.Request) {
+		demandresponse.GetAllDREvents(w, r, db)
+	})
+
+	// GET API endpoint to fetch demand response events of a customer's service locations
+	router.HandleFunc("/demandResponse/getEvents", func(w http.ResponseWriter, r *http.Request) {
+		demandresponse.GetDREvents(w, r, db, redisClient)
+	})
+
+	// PUT API endpoint to opt a service location in or out of a demand response program
+	router.HandleFunc("/demandResponse/updateEnrollment", func(w http.ResponseWriter, r *http.Request) {
+		demandresponse.UpdateDREnrollment(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to fetch monthly demand response performance and credits
+	router.HandleFunc("/demandResponse/getPerformance", func(w http.ResponseWriter, r *http.Request) {
+		demandresponse.GetDRPerformance(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to import Green Button XML readings into a service location
+	router.HandleFunc("/greenButton/import", func(w http.ResponseWriter, r *http.Request) {
+		greenbutton.ImportGreenButton(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to export service locations usage as Green Button XML
+	router.HandleFunc("/greenButton/export", func(w http.ResponseWriter, r *http.Request) {
+		greenbutton.ExportGreenButton(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to export hourly usage and cost as CSV or XLSX
+	router.HandleFunc("/usage/export", func(w http.ResponseWriter, r *http.Request) {
+		usage.ExportUsage(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to bulk import CSV interval readings, optionally as a dry run
+	router.HandleFunc("/usage/import", func(w http.ResponseWriter, r *http.Request) {
+		usage.ImportUsage(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to request an export of all personal data
+	router.HandleFunc("/privacy/requestDataExport", func(w http.ResponseWriter, r *http.Request) {
+		privacy.RequestDataExport(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to fetch data exports of a customer
+	router.HandleFunc("/privacy/getDataExports", func(w http.ResponseWriter, r *http.Request) {
+		privacy.GetDataExports(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to download a data export as a ZIP file, which asks for the password again
+	router.HandleFunc("/privacy/downloadDataExport", func(w http.ResponseWriter, r *http.Request) {
+		privacy.DownloadDataExport(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to request account erasure, which sends a confirmation email
+	router.HandleFunc("/privacy/requestErasure", func(w http.ResponseWriter, r *http.Request) {
+		privacy.RequestErasure(w, r, db, redisClient, mailSender, cfg.AppBaseURL)
+	})
+
+	// POST API endpoint to confirm account erasure, which is carried out after the grace period
+	router.HandleFunc("/privacy/confirmErasure", func(w http.ResponseWriter, r *http.Request) {
+		privacy.ConfirmErasure(w, r, db, redisClient, mailSender, time.Duration(cfg.ErasureGraceDays)*24*time.Hour)
+	})
+
+	// POST API endpoint to cancel a scheduled account erasure
+	router.HandleFunc("/privacy/cancelErasure", func(w http.ResponseWriter, r *http.Request) {
+		privacy.CancelErasure(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to fetch when the account is scheduled to be erased
+	router.HandleFunc("/privacy/getErasureStatus", func(w http.ResponseWriter, r *http.Request) {
+		privacy.GetErasureStatus(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to login as an admin
+	router.HandleFunc("/admin/login", func(w http.ResponseWriter, r *http.Request) {
+		admin.AdminLogin(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to logout an admin
+	router.HandleFunc("/admin/logout", func(w http.ResponseWriter, r *http.Request) {
+		admin.AdminLogout(w, r, redisClient)
+	})
+
+	// POST API endpoint to add another admin
+	router.HandleFunc("/admin/addAdmin", func(w http.ResponseWriter, r *http.Request) {
+		admin.AddAdmin(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to search customers by id, email, phone number or name
+	router.HandleFunc("/admin/searchCustomers", func(w http.ResponseWriter, r *http.Request) {
+		admin.SearchCustomers(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to fetch a customer with their service locations and enrolled devices
+	router.HandleFunc("/admin/getCustomerDetails", func(w http.ResponseWriter, r *http.Request) {
+		admin.GetCustomerDetails(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to fetch events of an enrolled device
+	router.HandleFunc("/admin/getEvents", func(w http.ResponseWriter, r *http.Request) {
+		admin.GetEvents(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to start a short lived session as a customer
+	router.HandleFunc("/admin/impersonateCustomer", func(w http.ResponseWriter, r *http.Request) {
+		admin.ImpersonateCustomer(w, r, db, redisClient)
+	})
+
+	// PUT API endpoint to deactivate a customer account
+	router.HandleFunc("/admin/deactivateCustomer", func(w http.ResponseWriter, r *http.Request) {
+		admin.DeactivateCustomer(w, r, db, redisClient)
+	})
+
+	// PUT API endpoint to reactivate a customer account
+	router.HandleFunc("/admin/reactivateCustomer", func(w http.ResponseWriter, r *http.Request) {
+		admin.ReactivateCustomer(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to fetch the devices catalog
+	router.HandleFunc("/admin/getDevices", func(w http.ResponseWriter, r *http.Request) {
+		admin.GetDevices(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to add a device to the catalog
+	router.HandleFunc("/admin/addDevice", func(w http.ResponseWriter, r *http.Request) {
+		admin.AddDevice(w, r, db, redisClient)
+	})
+
+	// PUT API endpoint to update a device in the catalog
+	router.HandleFunc("/admin/updateDevice", func(w http.ResponseWriter, r *http.Request) {
+		admin.UpdateDevice(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to fetch hourly prices of a zipcode
+	router.HandleFunc("/admin/getPrices", func(w http.ResponseWriter, r *http.Request) {
+		admin.GetPrices(w, r, db, redisClient)
+	})
+
+	// PUT API endpoint to update hourly prices of a zipcode
+	router.HandleFunc("/admin/updatePrices", func(w http.ResponseWriter, r *http.Request) {
+		admin.UpdatePrices(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to import hourly carbon intensities from CSV
+	router.HandleFunc("/admin/importCarbonIntensities", func(w http.ResponseWriter, r *http.Request) {
+		carbon.ImportCarbonIntensities(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to add demand response program
+	router.HandleFunc("/admin/addDRProgram", func(w http.ResponseWriter, r *http.Request) {
+		demandresponse.AddDRProgram(w, r, db, redisClient)
+	})
+
+	// POST API endpoint to add demand response event for zipcodes and states
+	router.HandleFunc("/admin/addDREvent", func(w http.ResponseWriter, r *http.Request) {
+		demandresponse.AddDREvent(w, r, db, redisClient)
+	})
+
+	// PUT API endpoint to cancel demand response event
+	router.HandleFunc("/admin/cancelDREvent", func(w http.ResponseWriter, r *http.Request) {
+		demandresponse.CancelDREvent(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to query audit logs with filters, newest first
+	router.HandleFunc("/admin/getAuditLogs", func(w http.ResponseWriter, r *http.Request) {
+		admin.GetAuditLogs(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to export audit logs as CSV or JSON lines
+	router.HandleFunc("/admin/exportAuditLogs", func(w http.ResponseWriter, r *http.Request) {
+		admin.ExportAuditLogs(w, r, db, redisClient)
+	})
+
+	// GET API endpoint to list service locations of the session's customer
+	router.HandleFunc("/v2/service-locations", func(w http.ResponseWriter, r *http.Request) {
+		users.ListServiceLocationsV2(w, r, db, redisClient)
+	}).Methods(http.MethodGet)
+
+	// POST API endpoint to add a service location
+	router.HandleFunc("/v2/service-locations", func(w http.ResponseWriter, r *http.Request) {
+		users.AddServiceLocationV2(w, r, db, redisClient)
+	}).Methods(http.MethodPost)
+
+	// GET API endpoint to get a service location
+	router.HandleFunc("/v2/service-locations/{id:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {
+		users.GetServiceLocationV2(w, r, db, redisClient)
+	}).Methods(http.MethodGet)
+
+	// PUT API endpoint to update a service location
+	router.HandleFunc("/v2/service-locations/{id:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {
+		users.UpdateServiceLocationV2(w, r, db, redisClient)
+	}).Methods(http.MethodPut)
+
+	// DELETE API endpoint to delete a service location along with its devices
+	router.HandleFunc("/v2/service-locations/{id:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {
+		users.DeleteServiceLocationV2(w, r, db, redisClient)
+	}).Methods(http.MethodDelete)
+
+	// POST API endpoint to restore a deleted service location
+	router.HandleFunc("/v2/service-locations/{id:[0-9]+}/restore", func(w http.ResponseWriter, r *http.Request) {
+		users.RestoreServiceLocationV2(w, r, db, redisClient)
+	}).Methods(http.MethodPost)
+
+	// GET API endpoint to list enrolled devices of a service location
+	router.HandleFunc("/v2/service-locations/{id:[0-9]+}/devices", func(w http.ResponseWriter, r *http.Request) {
+		users.ListEnrolledDevicesV2(w, r, db, redisClient)
+	}).Methods(http.MethodGet)
+
+	// POST API endpoint to enroll a device in a service location
+	router.HandleFunc("/v2/service-locations/{id:[0-9]+}/devices", func(w http.ResponseWriter, r *http.Request) {
+		users.AddEnrolledDeviceV2(w, r, db, redisClient)
+	}).Methods(http.MethodPost)
+
+	// GET API endpoint to get an enrolled device
+	router.HandleFunc("/v2/service-locations/{id:[0-9]+}/devices/{deviceId:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {
+		users.GetEnrolledDeviceV2(w, r, db, redisClient)
+	}).Methods(http.MethodGet)
+
+	// PUT API endpoint to update an enrolled device
+	router.HandleFunc("/v2/service-locations/{id:[0-9]+}/devices/{deviceId:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {
+		users.UpdateEnrolledDeviceV2(w, r, db, redisClient)
+	}).Methods(http.MethodPut)
+
+	// DELETE API endpoint to delete an enrolled device
+	router.HandleFunc("/v2/service-locations/{id:[0-9]+}/devices/{deviceId:[0-9]+}", func(w http.ResponseWriter, r *http.Request) {
+		users.DeleteEnrolledDeviceV2(w, r, db, redisClient)
+	}).Methods(http.MethodDelete)
+
+	// POST API endpoint to restore a deleted enrolled device
+	router.HandleFunc("/v2/service-locations/{id:[0-9]+}/devices/{deviceId:[0-9]+}/restore", func(w http.ResponseWriter, r *http.Request) {
+		users.RestoreEnrolledDeviceV2(w, r, db, redisClient)
+	}).Methods(http.MethodPost)
+
+	// POST API endpoint to query the session's customer, service locations, devices, usage and prices with GraphQL
+	router.HandleFunc("/graphql", func(w http.ResponseWriter, r *http.Request) {
+		graph.Query(w, r, db, redisClient)
+	}).Methods(http.MethodPost)
+
+	// GET API endpoint to get the OpenAPI document
+	router.HandleFunc("/openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		openapi.GetDocument(w, r)
+	}).Methods(http.MethodGet)
+
+	// GET API endpoint to browse the OpenAPI document
+	router.HandleFunc("/docs", func(w http.ResponseWriter, r *http.Request) {
+		openapi.GetDocs(w, r)
+	}).Methods(http.MethodGet)
+
+	// GET API endpoint to tell that the server is alive
+	router.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		health.GetLiveness(w, r)
+	}).Methods(http.MethodGet)
+
+	// GET API endpoint to tell whether the server can take traffic
+	router.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		health.GetReadiness(w, r, db, redisClient, monitor)
+	}).Methods(http.MethodGet)
+
+	// spans of requests are named after their route
+	router.Use(tracing.TagRoute)
+
+	// unknown endpoints answer with the same JSON error body as the handlers
+	router.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apierror.Write(w, r, apierror.NotFound("Endpoint not found"))
+	})
+	router.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		apierror.Write(w, r, apierror.New(http.StatusMethodNotAllowed, "Method not allowed"))
+	})
+
+	return router
+}
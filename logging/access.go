@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+)
+
+// accessEntry collects what handlers learn about a request for its access log
+type accessEntry struct {
+	customerId uint32
+}
+
+type accessEntryKey struct{}
+
+// SetCustomerId records the customer of the session in the access log of the
+// request
+func SetCustomerId(ctx context.Context, customerId uint32) {
+	if entry, ok := ctx.Value(accessEntryKey{}).(*accessEntry); ok {
+		entry.customerId = customerId
+	}
+}
+
+// responseRecorder remembers the status and size of the response
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	if rr.status == 0 {
+		rr.status = status
+	}
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	if rr.status == 0 {
+		rr.status = http.StatusOK
+	}
+	n, err := rr.ResponseWriter.Write(b)
+	rr.bytes += n
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the writer of the server
+func (rr *responseRecorder) Unwrap() http.ResponseWriter {
+	return rr.ResponseWriter
+}
+
+// WithAccessLog logs every request once it is answered, with its status,
+// latency and the customer of its session. Server errors are logged at
+// error level. The query string is left out as it may carry tokens.
+func WithAccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		entry := &accessEntry{}
+		recorder := &responseRecorder{ResponseWriter: w}
+		next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), accessEntryKey{}, entry)))
+
+		if recorder.status == 0 {
+			recorder.status = http.StatusOK
+		}
+		level := slog.LevelInfo
+		if recorder.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		remoteIp, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			remoteIp = r.RemoteAddr
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", recorder.status),
+			slog.Float64("durationMs", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", recorder.bytes),
+			slog.String("remoteIp", remoteIp),
+		}
+		if entry.customerId > 0 {
+			attrs = append(attrs, slog.Uint64("customerId", uint64(entry.customerId)))
+		}
+		slog.LogAttrs(r.Context(), level, "request", attrs...)
+	})
+}
@@ -0,0 +1,24 @@
+package logging
+
+import (
+	"log/slog"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// Attributes whose key contains one of these, in any case, are never logged
+var sensitiveKeys = []string{"password", "token", "secret", "authorization", "cookie", "recoverycode"}
+
+// redact replaces the value of sensitive attributes, including the ones in
+// groups. Values are not inspected, so secrets must not be logged under
+// other keys or inside messages.
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	key := strings.ToLower(attr.Key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return slog.String(attr.Key, redacted)
+		}
+	}
+	return attr
+}
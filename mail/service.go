@@ -0,0 +1,94 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// FileSender writes every message as an .eml file into a directory instead of
+// delivering it, for local development and tests
+type FileSender struct {
+	Dir  string
+	From string
+}
+
+func NewFileSender(dir, from string) *FileSender {
+	return &FileSender{Dir: dir, From: from}
+}
+
+func (s *FileSender) Send(ctx context.Context, msg Message) error {
+	err := os.MkdirAll(s.Dir, 0o755)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%d_%s.eml", time.Now().UnixNano(), sanitizeFileName(msg.To))
+	return os.WriteFile(filepath.Join(s.Dir, name), buildMessage(s.From, msg), 0o644)
+}
+
+// SMTPSender delivers messages through an SMTP server
+type SMTPSender struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+}
+
+func NewSMTPSender(addr, username, password, from string) *SMTPSender {
+	return &SMTPSender{Addr: addr, Username: username, Password: password, From: from}
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	var auth smtp.Auth
+	if len(s.Username) > 0 {
+		host, _, err := net.SplitHostPort(s.Addr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+	return smtp.SendMail(s.Addr, auth, s.From, []string{msg.To}, buildMessage(s.From, msg))
+}
+
+func buildMessage(from string, msg Message) []byte {
+	var sb strings.Builder
+	sb.WriteString("From: " + sanitizeHeader(from) + "\r\n")
+	sb.WriteString("To: " + sanitizeHeader(msg.To) + "\r\n")
+	sb.WriteString("Subject: " + sanitizeHeader(msg.Subject) + "\r\n")
+	sb.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	sb.WriteString("\r\n")
+	sb.WriteString(msg.Body)
+	return []byte(sb.String())
+}
+
+// sanitizeHeader drops line breaks so that values cannot inject headers
+func sanitizeHeader(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
+
+func sanitizeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ':' {
+			return '_'
+		}
+		return r
+	}, name)
+}
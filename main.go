@@ -2,102 +2,241 @@ package main
 
 import (
 	"context"
-	"database/sql"
 	"fmt"
 	"log"
+	"log/slog"
+	"net"
 	"net/http"
-
-	"shems/users"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"shems/admin"
+	"shems/audit"
+	"shems/config"
+	"shems/deadline"
+	"shems/demandresponse"
+	"shems/grpcapi"
+	"shems/health"
+	"shems/httpapi"
+	"shems/logging"
+	"shems/mail"
+	"shems/metrics"
+	"shems/migrations"
+	"shems/privacy"
+	"shems/retention"
+	"shems/tracing"
 
 	_ "github.com/go-sql-driver/mysql"
-	"github.com/gorilla/mux"
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/cors"
 )
 
+// fatal logs an error the server cannot run with and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
 func main() {
-	router := mux.NewRouter()
+	cfg := config.Load()
 
-	// MySQL database configuration
-	db, err := sql.Open("mysql", "root:cricket97@tcp(localhost:3306)/Project")
+	err := logging.Setup(cfg.LogLevel, cfg.LogFormat)
 	if err != nil {
 		log.Fatal(err)
 	}
+
+	// requests, queries and redis commands are traced when an exporter is configured
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.TracingExporter, cfg.OTLPEndpoint, cfg.TracingSampleRatio)
+	if err != nil {
+		fatal("error while setting up tracing", err)
+	}
+
+	// MySQL database configuration
+	db, err := tracing.OpenDB("mysql", cfg.DatabaseDSN)
+	if err != nil {
+		fatal("error while opening the database", err)
+	}
 	defer db.Close()
 
 	redisClient := redis.NewClient(&redis.Options{
-		Addr:     "localhost:6379",
-		Password: "", // no password set
-		DB:       0,  // use default DB
+		Addr:     cfg.RedisAddr,
+		Password: cfg.RedisPassword,
+		DB:       0, // use default DB
 	})
+	defer redisClient.Close()
 
-	ctx := context.Background()
+	// expose the connection pool stats, redis latencies and domain gauges on /metrics of the metrics port
+	metrics.RegisterDatabase(db)
+	metrics.InstrumentRedis(redisClient)
+	tracing.InstrumentRedis(redisClient)
 
-	// POST API endpoint to login
-	router.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
-		users.LoginUser(ctx, w, r, db)
-	})
-
-	// POST API endpoint to register
-	router.HandleFunc("/register", func(w http.ResponseWriter, r *http.Request) {
-		users.RegisterUser(ctx, w, r, db, redisClient)
-	})
+	// emails go to the outbox directory unless an SMTP server is configured
+	var mailSender mail.Sender = mail.NewFileSender(cfg.MailOutboxDir, cfg.MailFrom)
+	if len(cfg.SMTPAddr) > 0 {
+		mailSender = mail.NewSMTPSender(cfg.SMTPAddr, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+	}
 
-	// GET API endpoint to fetch dashboard details
-	router.HandleFunc("/dashboard", func(w http.ResponseWriter, r *http.Request) {
-		users.GetDashboardData(ctx, w, r, db)
-	})
+	// the server shuts down gracefully on SIGINT and SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// GET API endpoint to fetch enrolled devices
-	router.HandleFunc("/dashboard/getEnrolledDevices", func(w http.ResponseWriter, r *http.Request) {
-		users.GetEnrolledDevices(ctx, w, r, db)
-	})
+	// MySQL and Redis may become available after the server starts
+	err = health.WaitForDependencies(ctx, db, redisClient, cfg.StartupTimeout)
+	if err != nil {
+		fatal("dependencies are not available", err)
+	}
 
-	// POST API endpoint to add enrolled device
-	router.HandleFunc("/dashboard/addEnrolledDevice", func(w http.ResponseWriter, r *http.Request) {
-		users.AddEnrolledDevice(ctx, w, r, db, redisClient)
-	})
+	if len(cfg.MigrationsBaseline) > 0 {
+		err = migrations.Baseline(ctx, db, cfg.MigrationsBaseline)
+		if err != nil {
+			fatal("error while recording the migrations baseline", err)
+		}
+	}
 
-	// PUT API endpoint to update enrolled device
-	router.HandleFunc("/dashboard/updateEnrolledDevice", func(w http.ResponseWriter, r *http.Request) {
-		users.UpdateEnrolledDevice(ctx, w, r, db, redisClient)
-	})
+	if cfg.MigrateOnStartup {
+		err = migrations.Apply(ctx, db)
+		if err != nil {
+			fatal("error while applying migrations", err)
+		}
+	}
 
-	// DELETE API endpoint to delete enrolled device
-	router.HandleFunc("/dashboard/deleteEnrolledDevice", func(w http.ResponseWriter, r *http.Request) {
-		users.DeleteEnrolledDevice(ctx, w, r, db, redisClient)
-	})
+	// background workers are tracked for readiness and stopped after the server drained
+	monitor := health.NewMonitor()
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	// create the first admin so that the admin API can be used
+	if len(cfg.AdminEmail) > 0 {
+		err = admin.EnsureAdmin(ctx, db, cfg.AdminName, cfg.AdminEmail, cfg.AdminPassword)
+		if err != nil {
+			fatal("error while creating the first admin", err)
+		}
+	}
 
-	// GET API endpoint to fetch service locations
-	router.HandleFunc("/dashboard/getServiceLocations", func(w http.ResponseWriter, r *http.Request) {
-		users.GetServiceLocations(ctx, w, r, db)
-	})
+	// every endpoint of the HTTP API
+	router := httpapi.NewRouter(db, redisClient, mailSender, monitor, cfg)
 
-	// POST API endpoint to add service location
-	router.HandleFunc("/dashboard/addServiceLocation", func(w http.ResponseWriter, r *http.Request) {
-		users.AddServiceLocation(ctx, w, r, db, redisClient)
+	// curtail devices and compute performance of demand response events in the background
+	monitor.Go(workerCtx, "demandResponse", func(ctx context.Context) {
+		demandresponse.RunScheduler(ctx, db, redisClient, time.Minute)
 	})
 
-	// PUT API endpoint to update service location
-	router.HandleFunc("/dashboard/updateServiceLocation", func(w http.ResponseWriter, r *http.Request) {
-		users.UpdateServiceLocation(ctx, w, r, db, redisClient)
+	// permanently delete devices and service locations after the retention period
+	monitor.Go(workerCtx, "retention", func(ctx context.Context) {
+		retention.RunScheduler(ctx, db, redisClient, time.Hour, time.Duration(cfg.RetentionDays)*24*time.Hour)
 	})
 
-	// DELETE API endpoint to delete service location
-	router.HandleFunc("/dashboard/deleteServiceLocation", func(w http.ResponseWriter, r *http.Request) {
-		users.DeleteServiceLocation(ctx, w, r, db, redisClient)
+	// prepare data exports and carry out account erasures in the background
+	monitor.Go(workerCtx, "privacy", func(ctx context.Context) {
+		privacy.RunScheduler(ctx, db, redisClient, mailSender, cfg.DataExportDir, time.Minute)
 	})
 
 	c := cors.New(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:3000"},
+		AllowedOrigins:   []string{cfg.AllowedOrigin},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "X-Request-Id", "If-Match", "If-None-Match", "Traceparent", "Tracestate"},
+		ExposedHeaders:   []string{"X-Request-Id", "ETag", "Location"},
 		AllowCredentials: true,
 	})
 
-	handler := c.Handler(router)
+	// imports and exports move whole files, so they may take longer than other requests
+	transferTimeouts := make(map[string]time.Duration)
+	for _, path := range []string{"/usage/import", "/usage/export", "/greenButton/import", "/greenButton/export", "/admin/importCarbonIntensities", "/admin/exportAuditLogs", "/privacy/downloadDataExport"} {
+		transferTimeouts[path] = cfg.TransferTimeout
+	}
+
+	// every request is traced, gets an id which is stored with its audit log
+	// entries and log records, is logged and counted by route and status, and
+	// gets a deadline after which its queries are canceled
+	handler := c.Handler(tracing.WithTracing(audit.WithRequestId(logging.WithAccessLog(metrics.WithMetrics(deadline.WithTimeout(router, cfg.RequestTimeout, transferTimeouts), router)))))
 
-	port := 8000
-	fmt.Printf("Server is running on :%d...\n", port)
-	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", port), handler))
+	// internal services and the metering gateway use the gRPC API on its own port
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GrpcPort))
+	if err != nil {
+		fatal("error while listening for gRPC", err)
+	}
+	grpcServer := grpcapi.NewServer(db, redisClient, cfg.RequestTimeout, cfg.GrpcReflection)
+	go func() {
+		slog.Info("gRPC server is running", "port", cfg.GrpcPort)
+		err := grpcServer.Serve(listener)
+		if err != nil {
+			fatal("error while serving gRPC", err)
+		}
+	}()
+
+	// Prometheus scrapes metrics on their own port, away from the public API
+	metricsMux := http.NewServeMux()
+	metricsMux.HandleFunc("/metrics", metrics.GetMetrics)
+	metricsServer := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.MetricsPort),
+		Handler:           metricsMux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		slog.Info("metrics server is running", "port", cfg.MetricsPort)
+		err := metricsServer.ListenAndServe()
+		if err != nil && err != http.ErrServerClosed {
+			fatal("error while serving metrics", err)
+		}
+	}()
+
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Port),
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		slog.Info("server is running", "port", cfg.Port)
+		err := server.ListenAndServe()
+		if err != nil && err != http.ErrServerClosed {
+			fatal("error while serving HTTP", err)
+		}
+	}()
+
+	<-ctx.Done()
+	stop()
+	slog.Info("shutting down")
+
+	// stop taking traffic and let requests and calls in progress finish
+	monitor.Drain()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+
+	err = server.Shutdown(shutdownCtx)
+	if err != nil {
+		slog.Error("error while shutting down the server", "error", err)
+	}
+	err = metricsServer.Shutdown(shutdownCtx)
+	if err != nil {
+		slog.Error("error while shutting down the metrics server", "error", err)
+	}
+	select {
+	case <-grpcStopped:
+	case <-shutdownCtx.Done():
+		grpcServer.Stop()
+	}
+
+	// the workers finish their current run, which is canceled if it takes too long
+	stopWorkers()
+	err = monitor.Wait(cfg.ShutdownTimeout)
+	if err != nil {
+		slog.Error("error while stopping background workers", "error", err)
+	}
+
+	// send the spans which have not been exported yet
+	tracingCtx, cancelTracing := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancelTracing()
+	err = shutdownTracing(tracingCtx)
+	if err != nil {
+		slog.Error("error while shutting down tracing", "error", err)
+	}
+	slog.Info("server stopped")
 }
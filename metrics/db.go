@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// Longest time the domain gauges wait for the database during a scrape
+const domainTimeout = 5 * time.Second
+
+var (
+	activeEnrolledDevicesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "active_enrolled_devices"),
+		"Enrolled devices which are not deleted",
+		nil, nil,
+	)
+)
+
+// domainCollector reads the domain gauges from the database at every scrape.
+// Only small tables are counted, stored events are followed by the
+// events_ingested_total counter instead of scanning the Events table.
+type domainCollector struct {
+	db *sql.DB
+}
+
+func (c domainCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- activeEnrolledDevicesDesc
+}
+
+func (c domainCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), domainTimeout)
+	defer cancel()
+
+	var activeEnrolledDevices float64
+	err := c.db.QueryRowContext(ctx, queryToCountActiveEnrolledDevices()).Scan(&activeEnrolledDevices)
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(activeEnrolledDevicesDesc, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(activeEnrolledDevicesDesc, prometheus.GaugeValue, activeEnrolledDevices)
+}
+
+// RegisterDatabase exposes the connection pool stats of the database and the
+// domain gauges read from it
+func RegisterDatabase(db *sql.DB) {
+	registry.MustRegister(
+		collectors.NewDBStatsCollector(db, namespace),
+		domainCollector{db: db},
+	)
+}
@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "shems"
+
+// Metrics have their own registry instead of the global one, so that only
+// the metrics of the server and of the Go runtime are exposed
+var registry = prometheus.NewRegistry()
+
+var factory = promauto.With(registry)
+
+var (
+	httpRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route, method and status",
+	}, []string{"route", "method", "status"})
+
+	httpRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by route, method and status",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	grpcCalls = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_calls_total",
+		Help:      "gRPC calls by method and code",
+	}, []string{"method", "code"})
+
+	grpcCallDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_call_duration_seconds",
+		Help:      "Latency of gRPC calls by method and code",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "code"})
+
+	redisCommandDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "redis_command_duration_seconds",
+		Help:      "Latency of redis commands and pipelines by command and result",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"command", "result"})
+
+	lockConflicts = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "redis_lock_conflicts_total",
+		Help:      "Requests turned away because another request held the redis lock, by lock",
+	}, []string{"lock"})
+
+	eventsIngested = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_ingested_total",
+		Help:      "Readings stored by imports and the event ingestion API, by source",
+	}, []string{"source"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// ObserveCall records a finished gRPC call
+func ObserveCall(method, code string, duration time.Duration) {
+	grpcCalls.WithLabelValues(method, code).Inc()
+	grpcCallDuration.WithLabelValues(method, code).Observe(duration.Seconds())
+}
+
+// LockConflict counts a request which could not take a redis lock. Keys carry
+// the id of what they lock, so only the name before the first underscore is
+// used as label, e.g. ImportUsage for ImportUsage_CustomerId_1.
+func LockConflict(key string) {
+	name, _, _ := strings.Cut(key, "_")
+	lockConflicts.WithLabelValues(name).Inc()
+}
+
+// EventsIngested counts readings stored from a source, once they are committed
+func EventsIngested(source string, count int) {
+	eventsIngested.WithLabelValues(source).Add(float64(count))
+}
+
+// A failing collector, like the domain gauges while the database is down, is
+// logged and leaves out its metrics instead of failing the scrape
+var handler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+	ErrorLog:      log.Default(),
+	ErrorHandling: promhttp.ContinueOnError,
+})
+
+// GetMetrics answers in the Prometheus text format
+func GetMetrics(w http.ResponseWriter, r *http.Request) {
+	handler.ServeHTTP(w, r)
+}
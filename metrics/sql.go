@@ -0,0 +1,21 @@
+package metrics
+
+import "shems/tracing"
+
+func init() {
+	tracing.RegisterQueries(
+		queryToCountActiveEnrolledDevices,
+	)
+}
+
+func queryToCountActiveEnrolledDevices() string {
+	sqlQuery := `
+	SELECT
+		COUNT(*)
+	FROM
+		Enrolled_Devices
+	WHERE
+		active = 1;
+	`
+	return sqlQuery
+}
@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS Charging_Sessions (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	enrolled_device_id INT UNSIGNED NOT NULL,
+	vehicle_label VARCHAR(255) NOT NULL DEFAULT '',
+	started_at DATETIME NOT NULL,
+	ended_at DATETIME NOT NULL,
+	energy_delivered FLOAT NOT NULL DEFAULT 0,
+	peak_power FLOAT NOT NULL DEFAULT 0,
+	cost FLOAT NOT NULL DEFAULT 0,
+	PRIMARY KEY (id),
+	UNIQUE KEY uq_charging_sessions_device_start (enrolled_device_id, started_at),
+	FOREIGN KEY (enrolled_device_id) REFERENCES Enrolled_Devices (id)
+);
+
+CREATE TABLE IF NOT EXISTS Charging_Targets (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	enrolled_device_id INT UNSIGNED NOT NULL,
+	vehicle_label VARCHAR(255) NOT NULL DEFAULT '',
+	battery_capacity FLOAT NOT NULL,
+	current_charge_percent FLOAT NOT NULL,
+	target_charge_percent FLOAT NOT NULL,
+	charger_power FLOAT NOT NULL,
+	deadline DATETIME NOT NULL,
+	energy_required FLOAT NOT NULL,
+	estimated_cost FLOAT NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (id),
+	FOREIGN KEY (enrolled_device_id) REFERENCES Enrolled_Devices (id)
+);
+
+CREATE TABLE IF NOT EXISTS Charging_Schedules (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	charging_target_id INT UNSIGNED NOT NULL,
+	start_time DATETIME NOT NULL,
+	end_time DATETIME NOT NULL,
+	energy FLOAT NOT NULL,
+	price FLOAT NOT NULL,
+	PRIMARY KEY (id),
+	FOREIGN KEY (charging_target_id) REFERENCES Charging_Targets (id)
+);
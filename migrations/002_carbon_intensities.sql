@@ -0,0 +1,7 @@
+-- Hourly grid carbon intensity in grams of CO2 per kWh, keyed like Prices
+CREATE TABLE IF NOT EXISTS Carbon_Intensities (
+	zipcode INT UNSIGNED NOT NULL,
+	hour INT UNSIGNED NOT NULL,
+	value FLOAT NOT NULL,
+	PRIMARY KEY (zipcode, hour)
+);
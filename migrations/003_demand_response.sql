@@ -0,0 +1,71 @@
+CREATE TABLE IF NOT EXISTS DR_Programs (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	name VARCHAR(255) NOT NULL,
+	description VARCHAR(1024) NOT NULL DEFAULT '',
+	credit_per_kwh FLOAT NOT NULL,
+	active TINYINT NOT NULL DEFAULT 1,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (id)
+);
+
+-- status is one of scheduled, active, completed or cancelled
+CREATE TABLE IF NOT EXISTS DR_Events (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	program_id INT UNSIGNED NOT NULL,
+	starts_at DATETIME NOT NULL,
+	ends_at DATETIME NOT NULL,
+	status VARCHAR(16) NOT NULL DEFAULT 'scheduled',
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (id),
+	KEY idx_dr_events_status (status, starts_at),
+	FOREIGN KEY (program_id) REFERENCES DR_Programs (id)
+);
+
+-- an event targets locations by zipcode or by state, one of them is NULL
+CREATE TABLE IF NOT EXISTS DR_Event_Regions (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	event_id INT UNSIGNED NOT NULL,
+	zipcode INT UNSIGNED NULL,
+	state VARCHAR(255) NULL,
+	PRIMARY KEY (id),
+	FOREIGN KEY (event_id) REFERENCES DR_Events (id)
+);
+
+CREATE TABLE IF NOT EXISTS DR_Enrollments (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	program_id INT UNSIGNED NOT NULL,
+	service_location_id INT UNSIGNED NOT NULL,
+	opted_in TINYINT NOT NULL DEFAULT 1,
+	updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+	PRIMARY KEY (id),
+	UNIQUE KEY uq_dr_enrollments (program_id, service_location_id),
+	FOREIGN KEY (program_id) REFERENCES DR_Programs (id),
+	FOREIGN KEY (service_location_id) REFERENCES Service_Locations (id)
+);
+
+-- curtailment commands picked up by device gateways
+CREATE TABLE IF NOT EXISTS DR_Curtailments (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	event_id INT UNSIGNED NOT NULL,
+	enrolled_device_id INT UNSIGNED NOT NULL,
+	curtailed_at DATETIME NOT NULL,
+	restored_at DATETIME NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY uq_dr_curtailments (event_id, enrolled_device_id),
+	FOREIGN KEY (event_id) REFERENCES DR_Events (id),
+	FOREIGN KEY (enrolled_device_id) REFERENCES Enrolled_Devices (id)
+);
+
+CREATE TABLE IF NOT EXISTS DR_Event_Performances (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	event_id INT UNSIGNED NOT NULL,
+	service_location_id INT UNSIGNED NOT NULL,
+	baseline FLOAT NOT NULL,
+	actual FLOAT NOT NULL,
+	reduction FLOAT NOT NULL,
+	credit FLOAT NOT NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY uq_dr_event_performances (event_id, service_location_id),
+	FOREIGN KEY (event_id) REFERENCES DR_Events (id),
+	FOREIGN KEY (service_location_id) REFERENCES Service_Locations (id)
+);
@@ -0,0 +1,4 @@
+ALTER TABLE Customers ADD COLUMN email_verified TINYINT NOT NULL DEFAULT 0;
+
+-- accounts created before verification existed are trusted
+UPDATE Customers SET email_verified = 1;
@@ -0,0 +1,18 @@
+-- secret is pending until the customer confirms enrollment with a code
+CREATE TABLE IF NOT EXISTS Customer_Mfa (
+	customer_id INT UNSIGNED NOT NULL,
+	secret VARCHAR(64) NOT NULL,
+	enabled TINYINT NOT NULL DEFAULT 0,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (customer_id),
+	FOREIGN KEY (customer_id) REFERENCES Customers (id)
+);
+
+CREATE TABLE IF NOT EXISTS Mfa_Recovery_Codes (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	customer_id INT UNSIGNED NOT NULL,
+	code_hash VARCHAR(255) NOT NULL,
+	used_at DATETIME NULL,
+	PRIMARY KEY (id),
+	FOREIGN KEY (customer_id) REFERENCES Customers (id)
+);
@@ -0,0 +1,34 @@
+-- role is one of owner, member, viewer or installer
+CREATE TABLE IF NOT EXISTS Service_Location_Members (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	service_location_id INT UNSIGNED NOT NULL,
+	customer_id INT UNSIGNED NOT NULL,
+	role VARCHAR(16) NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (id),
+	UNIQUE KEY uq_service_location_members (service_location_id, customer_id),
+	KEY idx_service_location_members_customer (customer_id),
+	FOREIGN KEY (service_location_id) REFERENCES Service_Locations (id),
+	FOREIGN KEY (customer_id) REFERENCES Customers (id)
+);
+
+-- existing service locations are owned by the customer who added them
+INSERT IGNORE INTO Service_Location_Members (service_location_id, customer_id, role)
+SELECT id, customer_id, 'owner' FROM Service_Locations;
+
+-- only a hash of the invitation token is stored
+CREATE TABLE IF NOT EXISTS Service_Location_Invitations (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	service_location_id INT UNSIGNED NOT NULL,
+	email VARCHAR(255) NOT NULL,
+	role VARCHAR(16) NOT NULL,
+	token_hash CHAR(64) NOT NULL,
+	invited_by INT UNSIGNED NOT NULL,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	expires_at DATETIME NOT NULL,
+	accepted_at DATETIME NULL,
+	PRIMARY KEY (id),
+	UNIQUE KEY uq_service_location_invitations_token (token_hash),
+	FOREIGN KEY (service_location_id) REFERENCES Service_Locations (id),
+	FOREIGN KEY (invited_by) REFERENCES Customers (id)
+);
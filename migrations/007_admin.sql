@@ -0,0 +1,30 @@
+-- support staff accounts, separate from customers
+CREATE TABLE IF NOT EXISTS Admins (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	name VARCHAR(255) NOT NULL,
+	email VARCHAR(255) NOT NULL,
+	password VARCHAR(255) NOT NULL,
+	active TINYINT NOT NULL DEFAULT 1,
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (id),
+	UNIQUE KEY uq_admins_email (email)
+);
+
+-- deactivated customers cannot log in
+ALTER TABLE Customers ADD COLUMN active TINYINT NOT NULL DEFAULT 1;
+
+-- actor_type is admin or customer, details holds action specific JSON
+CREATE TABLE IF NOT EXISTS Audit_Logs (
+	id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+	actor_type VARCHAR(16) NOT NULL,
+	actor_id INT UNSIGNED NOT NULL,
+	action VARCHAR(64) NOT NULL,
+	entity_type VARCHAR(64) NOT NULL,
+	entity_id VARCHAR(64) NOT NULL,
+	details JSON NULL,
+	ip VARCHAR(64) NOT NULL DEFAULT '',
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (id),
+	KEY idx_audit_logs_entity (entity_type, entity_id),
+	KEY idx_audit_logs_actor (actor_type, actor_id)
+);
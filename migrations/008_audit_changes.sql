@@ -0,0 +1,8 @@
+-- before and after hold JSON snapshots of the changed row, request_id ties
+-- together all entries written while serving one request
+ALTER TABLE Audit_Logs
+	ADD COLUMN before_data JSON NULL AFTER details,
+	ADD COLUMN after_data JSON NULL AFTER before_data,
+	ADD COLUMN request_id VARCHAR(64) NOT NULL DEFAULT '' AFTER ip,
+	ADD KEY idx_audit_logs_request (request_id),
+	ADD KEY idx_audit_logs_created_at (created_at);
@@ -0,0 +1,38 @@
+-- where each enrolled device was over time, so that consumption is reported
+-- at the service location the device was in when it was used. valid_to is
+-- NULL for the current record and the first record of a device starts at the
+-- beginning of time so that readings imported for its past are kept.
+CREATE TABLE IF NOT EXISTS Enrolled_Device_History (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	enrolled_device_id INT UNSIGNED NOT NULL,
+	service_location_id INT UNSIGNED NOT NULL,
+	valid_from DATETIME NOT NULL,
+	valid_to DATETIME NULL,
+	PRIMARY KEY (id),
+	KEY idx_enrolled_device_history_device (enrolled_device_id, valid_from),
+	KEY idx_enrolled_device_history_service_location (service_location_id),
+	FOREIGN KEY (enrolled_device_id) REFERENCES Enrolled_Devices (id),
+	FOREIGN KEY (service_location_id) REFERENCES Service_Locations (id)
+);
+
+-- occupancy of each service location over time. The first record starts at
+-- date_taken_over and the last one ends when the location is deleted.
+CREATE TABLE IF NOT EXISTS Service_Location_History (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	service_location_id INT UNSIGNED NOT NULL,
+	location_id INT UNSIGNED NOT NULL,
+	occupants_count INT UNSIGNED NOT NULL,
+	valid_from DATETIME NOT NULL,
+	valid_to DATETIME NULL,
+	PRIMARY KEY (id),
+	KEY idx_service_location_history_service_location (service_location_id, valid_from),
+	FOREIGN KEY (service_location_id) REFERENCES Service_Locations (id)
+);
+
+-- existing enrollments and service locations get a single record, deleted
+-- ones end now since the time they were deleted is not known
+INSERT INTO Enrolled_Device_History (enrolled_device_id, service_location_id, valid_from, valid_to)
+SELECT id, service_location_id, '1000-01-01 00:00:00', CASE WHEN active = 0 THEN NOW() ELSE NULL END FROM Enrolled_Devices;
+
+INSERT INTO Service_Location_History (service_location_id, location_id, occupants_count, valid_from, valid_to)
+SELECT id, location_id, occupants_count, COALESCE(date_taken_over, '1000-01-01 00:00:00'), CASE WHEN active = 0 THEN NOW() ELSE NULL END FROM Service_Locations;
@@ -0,0 +1,25 @@
+-- deleted_at is set when an enrolled device or service location is deleted
+-- and cleared when it is restored. Devices deleted along with their service
+-- location share its deleted_at, so that restoring the location restores them.
+ALTER TABLE Enrolled_Devices
+	ADD COLUMN deleted_at DATETIME NULL,
+	ADD KEY idx_enrolled_devices_deleted_at (active, deleted_at);
+
+ALTER TABLE Service_Locations
+	ADD COLUMN deleted_at DATETIME NULL,
+	ADD KEY idx_service_locations_deleted_at (active, deleted_at);
+
+-- the retention period of entities deleted before this starts now
+UPDATE Enrolled_Devices SET deleted_at = NOW() WHERE active = 0;
+UPDATE Service_Locations SET deleted_at = NOW() WHERE active = 0;
+
+-- devices of service locations which were already deleted are deleted with them
+UPDATE Enrolled_Devices ed
+INNER JOIN Service_Locations sl ON sl.id = ed.service_location_id
+SET ed.active = 0, ed.deleted_at = sl.deleted_at
+WHERE sl.active = 0 AND ed.active = 1;
+
+UPDATE Enrolled_Device_History edh
+INNER JOIN Enrolled_Devices ed ON ed.id = edh.enrolled_device_id
+SET edh.valid_to = ed.deleted_at
+WHERE ed.active = 0 AND edh.valid_to IS NULL;
@@ -0,0 +1,21 @@
+-- exports are written to a file by a background job and kept until expires_at
+CREATE TABLE IF NOT EXISTS Data_Exports (
+	id INT UNSIGNED NOT NULL AUTO_INCREMENT,
+	customer_id INT UNSIGNED NOT NULL,
+	status VARCHAR(16) NOT NULL DEFAULT 'pending',
+	file_path VARCHAR(255) NOT NULL DEFAULT '',
+	error VARCHAR(255) NOT NULL DEFAULT '',
+	created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	completed_at DATETIME NULL,
+	expires_at DATETIME NULL,
+	PRIMARY KEY (id),
+	KEY idx_data_exports_status (status),
+	FOREIGN KEY (customer_id) REFERENCES Customers (id)
+);
+
+-- a confirmed erasure is carried out once erasure_scheduled_at has passed,
+-- unless the customer cancels it before that
+ALTER TABLE Customers
+	ADD COLUMN erasure_scheduled_at DATETIME NULL,
+	ADD COLUMN erased_at DATETIME NULL,
+	ADD KEY idx_customers_erasure_scheduled_at (erasure_scheduled_at);
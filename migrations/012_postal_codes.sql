@@ -0,0 +1,27 @@
+-- zipcodes are stored as text so that leading zeros of US zipcodes and
+-- alphanumeric postal codes of other countries survive
+ALTER TABLE Locations MODIFY zipcode VARCHAR(16) NOT NULL;
+ALTER TABLE Prices MODIFY zipcode VARCHAR(16) NOT NULL;
+ALTER TABLE Carbon_Intensities MODIFY zipcode VARCHAR(16) NOT NULL;
+ALTER TABLE DR_Event_Regions MODIFY zipcode VARCHAR(16) NULL;
+
+-- US zipcodes lost their leading zeros while they were numbers. Countries are
+-- matched like validation.CountryCode does, in any case and spelling it maps
+-- to US.
+UPDATE Locations
+SET zipcode = LPAD(zipcode, 5, '0')
+WHERE CHAR_LENGTH(zipcode) < 5 AND UPPER(TRIM(country)) IN ('US', 'USA', 'UNITED STATES', 'UNITED STATES OF AMERICA');
+
+-- prices, carbon intensities and event regions have no country, so they are
+-- padded where a US location now has the padded zipcode
+UPDATE Prices
+SET zipcode = LPAD(zipcode, 5, '0')
+WHERE CHAR_LENGTH(zipcode) < 5 AND LPAD(zipcode, 5, '0') IN (SELECT zipcode FROM Locations WHERE UPPER(TRIM(country)) IN ('US', 'USA', 'UNITED STATES', 'UNITED STATES OF AMERICA'));
+
+UPDATE Carbon_Intensities
+SET zipcode = LPAD(zipcode, 5, '0')
+WHERE CHAR_LENGTH(zipcode) < 5 AND LPAD(zipcode, 5, '0') IN (SELECT zipcode FROM Locations WHERE UPPER(TRIM(country)) IN ('US', 'USA', 'UNITED STATES', 'UNITED STATES OF AMERICA'));
+
+UPDATE DR_Event_Regions
+SET zipcode = LPAD(zipcode, 5, '0')
+WHERE CHAR_LENGTH(zipcode) < 5 AND LPAD(zipcode, 5, '0') IN (SELECT zipcode FROM Locations WHERE UPPER(TRIM(country)) IN ('US', 'USA', 'UNITED STATES', 'UNITED STATES OF AMERICA'));
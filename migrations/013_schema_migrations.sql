@@ -0,0 +1,9 @@
+-- applied migrations are recorded so that readiness can tell when the schema
+-- is behind the code and pending migrations can be applied on startup.
+-- Databases migrated by hand before this table existed record the
+-- migrations they already have with SHEMS_MIGRATIONS_BASELINE.
+CREATE TABLE IF NOT EXISTS Schema_Migrations (
+	version VARCHAR(64) NOT NULL,
+	applied_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (version)
+);
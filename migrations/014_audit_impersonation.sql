@@ -0,0 +1,5 @@
+-- changes made by a support admin impersonating the customer keep the
+-- customer as actor and record the admin here, 0 when not impersonated
+ALTER TABLE Audit_Logs
+	ADD COLUMN impersonated_by INT UNSIGNED NOT NULL DEFAULT 0 AFTER actor_id,
+	ADD KEY idx_audit_logs_impersonated_by (impersonated_by);
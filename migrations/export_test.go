@@ -0,0 +1,3 @@
+package migrations
+
+var Statements = statements
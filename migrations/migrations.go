@@ -0,0 +1,238 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// Migration which creates the Schema_Migrations table
+const tableMigration = "013_schema_migrations"
+
+// MySQL lock held while migrating, and how long to wait for it in seconds
+const (
+	lockName    = "shems_migrations"
+	lockTimeout = 600
+)
+
+// Versions returns the names of the migration files without extension, in
+// the order they are applied
+func Versions() ([]string, error) {
+	entries, err := files.ReadDir(".")
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, entry := range entries {
+		versions = append(versions, strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())))
+	}
+	sort.Strings(versions)
+	return versions, nil
+}
+
+// tracked tells whether the Schema_Migrations table exists
+func tracked(ctx context.Context, db *sql.DB) (bool, error) {
+	var exists int
+	err := db.QueryRowContext(ctx, queryToCheckMigrationsTable()).Scan(&exists)
+	return exists > 0, err
+}
+
+// Pending returns the migrations which are not recorded as applied. All of
+// them are pending while the Schema_Migrations table does not exist.
+func Pending(ctx context.Context, db *sql.DB) ([]string, error) {
+	versions, err := Versions()
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := tracked(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return versions, nil
+	}
+
+	rows, err := db.QueryContext(ctx, queryToGetAppliedMigrations())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		err = rows.Scan(&version)
+		if err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	for _, version := range versions {
+		if !applied[version] {
+			pending = append(pending, version)
+		}
+	}
+	return pending, nil
+}
+
+// statements splits a migration into its statements. Statements end with a
+// semicolon at the end of a line.
+func statements(migration string) []string {
+	var stmts []string
+	var current strings.Builder
+	for _, line := range strings.Split(migration, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(strings.TrimSpace(line), ";") {
+			stmts = append(stmts, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); len(rest) > 0 {
+		stmts = append(stmts, rest)
+	}
+	return stmts
+}
+
+// run runs the statements of a migration without recording it
+func run(ctx context.Context, db *sql.DB, version string) error {
+	migration, err := files.ReadFile(version + ".sql")
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range statements(string(migration)) {
+		_, err = db.ExecContext(ctx, stmt)
+		if err != nil {
+			return fmt.Errorf("error while applying migration %s: %w", version, err)
+		}
+	}
+	return nil
+}
+
+// withLock runs fn while holding the migrations lock, so that instances
+// starting together migrate one after the other. The lock belongs to the
+// connection which took it, so that connection is kept until it is released.
+func withLock(ctx context.Context, db *sql.DB, fn func() error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var locked sql.NullInt64
+	err = conn.QueryRowContext(ctx, queryToGetLock(), lockName, lockTimeout).Scan(&locked)
+	if err != nil {
+		return err
+	}
+	if locked.Int64 != 1 {
+		return errors.New("timed out waiting for another instance to finish migrating")
+	}
+
+	defer func() {
+		// closing the connection releases the lock as well
+		_, err := conn.ExecContext(context.WithoutCancel(ctx), queryToReleaseLock(), lockName)
+		if err != nil {
+			slog.WarnContext(ctx, "error while releasing the migrations lock", "error", err)
+		}
+	}()
+	return fn()
+}
+
+// Baseline records the migrations up to and including version as applied
+// without running them, creating the Schema_Migrations table if needed. It
+// is meant for databases which were migrated by hand before migrations were
+// tracked.
+func Baseline(ctx context.Context, db *sql.DB, version string) error {
+	versions, err := Versions()
+	if err != nil {
+		return err
+	}
+	known := false
+	for _, v := range versions {
+		if v == version {
+			known = true
+		}
+	}
+	if !known {
+		return fmt.Errorf("unknown migration %q to baseline at", version)
+	}
+
+	return withLock(ctx, db, func() error {
+		err := run(ctx, db, tableMigration)
+		if err != nil {
+			return err
+		}
+		for _, v := range versions {
+			if v > version {
+				break
+			}
+			_, err = db.ExecContext(ctx, queryToRecordMigration(), v)
+			if err != nil {
+				return err
+			}
+		}
+		slog.InfoContext(ctx, "recorded migrations baseline", "version", version)
+		return nil
+	})
+}
+
+// Apply applies the pending migrations in order and records each of them.
+// A database without the Schema_Migrations table gets every migration, so
+// databases migrated by hand record a Baseline first. MySQL commits schema
+// changes right away, so a migration which fails half way has to be fixed by
+// hand before it is applied again. Instances wait for each other, and the
+// migrations still pending are read once the lock is taken.
+func Apply(ctx context.Context, db *sql.DB) error {
+	return withLock(ctx, db, func() error {
+		exists, err := tracked(ctx, db)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			slog.InfoContext(ctx, "applied migrations are not tracked yet, applying all of them")
+			err = run(ctx, db, tableMigration)
+			if err != nil {
+				return err
+			}
+		}
+
+		pending, err := Pending(ctx, db)
+		if err != nil {
+			return err
+		}
+
+		for _, version := range pending {
+			err = run(ctx, db, version)
+			if err != nil {
+				return err
+			}
+
+			_, err = db.ExecContext(ctx, queryToRecordMigration(), version)
+			if err != nil {
+				return err
+			}
+			slog.InfoContext(ctx, "applied migration", "version", version)
+		}
+		return nil
+	})
+}
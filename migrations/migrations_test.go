@@ -0,0 +1,68 @@
+package migrations_test
+
+import (
+	"reflect"
+	"shems/migrations"
+	"sort"
+	"testing"
+)
+
+func TestStatements(t *testing.T) {
+	tests := []struct {
+		name      string
+		migration string
+		want      []string
+	}{
+		{
+			name:      "blank migration",
+			migration: "\n  \n",
+		},
+		{
+			name:      "comments are skipped",
+			migration: "-- adds the table\nCREATE TABLE A (id INT);\n  -- indented comment\n",
+			want:      []string{"CREATE TABLE A (id INT);"},
+		},
+		{
+			name:      "statements over several lines",
+			migration: "CREATE TABLE A (\n  id INT\n);\n\nALTER TABLE A\n  ADD name VARCHAR(64);\n",
+			want:      []string{"CREATE TABLE A (\n  id INT\n);", "ALTER TABLE A\n  ADD name VARCHAR(64);"},
+		},
+		{
+			name:      "semicolons inside a line do not split",
+			migration: "INSERT INTO A (name) VALUES ('a;b');\n",
+			want:      []string{"INSERT INTO A (name) VALUES ('a;b');"},
+		},
+		{
+			name:      "last statement without a semicolon",
+			migration: "DROP TABLE A;\nDROP TABLE B",
+			want:      []string{"DROP TABLE A;", "DROP TABLE B"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := migrations.Statements(tt.migration)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Statements() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersions(t *testing.T) {
+	versions, err := migrations.Versions()
+	if err != nil {
+		t.Fatalf("Versions() error = %v", err)
+	}
+	if !sort.StringsAreSorted(versions) {
+		t.Errorf("Versions() = %v, want them sorted", versions)
+	}
+
+	found := false
+	for _, version := range versions {
+		found = found || version == "013_schema_migrations"
+	}
+	if !found {
+		t.Errorf("Versions() = %v, want 013_schema_migrations", versions)
+	}
+}
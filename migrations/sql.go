@@ -0,0 +1,62 @@
+package migrations
+
+import "shems/tracing"
+
+func init() {
+	tracing.RegisterQueries(
+		queryToCheckMigrationsTable,
+		queryToGetAppliedMigrations,
+		queryToRecordMigration,
+		queryToGetLock,
+		queryToReleaseLock,
+	)
+}
+
+func queryToCheckMigrationsTable() string {
+	sqlQuery := `
+	SELECT
+		COUNT(*)
+	FROM
+		information_schema.tables
+	WHERE
+		table_schema = DATABASE()
+		AND table_name = 'Schema_Migrations';
+	`
+	return sqlQuery
+}
+
+func queryToGetAppliedMigrations() string {
+	sqlQuery := `
+	SELECT
+		version
+	FROM
+		Schema_Migrations;
+	`
+	return sqlQuery
+}
+
+func queryToRecordMigration() string {
+	sqlQuery := `
+				INSERT IGNORE INTO Schema_Migrations
+					(version)
+				VALUES
+					(?);
+				`
+	return sqlQuery
+}
+
+// queryToGetLock returns 1 once the named lock is taken, 0 when it timed out
+func queryToGetLock() string {
+	sqlQuery := `
+	SELECT
+		GET_LOCK(?, ?);
+	`
+	return sqlQuery
+}
+
+func queryToReleaseLock() string {
+	sqlQuery := `
+				DO RELEASE_LOCK(?);
+				`
+	return sqlQuery
+}
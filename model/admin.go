@@ -0,0 +1,102 @@
+package model
+
+type Admin struct {
+	Id        uint32
+	Name      string
+	Email     string
+	Password  string
+	Active    uint32
+	CreatedAt string
+}
+
+type AdminSession struct {
+	AdminId   uint32
+	CreatedAt string
+}
+
+type AdminLoginRequest struct {
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type AdminLoginResponse struct {
+	AdminDetails Admin
+	SessionToken string
+}
+
+type AddAdminRequest struct {
+	Name     string `json:"name" validate:"required,max=64"`
+	Email    string `json:"email" validate:"required,email,max=255"`
+	Password string `json:"password" validate:"required,min=12"`
+}
+
+type AdminCustomer struct {
+	Id                    uint32
+	FirstName             string
+	LastName              string
+	PhoneNumber           string
+	Email                 string
+	EmailVerified         uint32
+	Active                uint32
+	ServiceLocationsCount uint32
+}
+
+type SearchCustomersResponse struct {
+	Customers []AdminCustomer
+}
+
+type AdminCustomerDetailsResponse struct {
+	Customer         AdminCustomer
+	ServiceLocations []ServiceLocation
+	EnrolledDevices  []EnrolledDevice
+}
+
+type AdminEvent struct {
+	EnrolledDeviceId uint32
+	Label            string
+	Value            float32
+	CreatedAt        string
+}
+
+type GetAdminEventsResponse struct {
+	Events []AdminEvent
+}
+
+type ImpersonateCustomerRequest struct {
+	CustomerId uint32 `json:"customerId" validate:"required"`
+	Reason     string `json:"reason" validate:"required,max=255"`
+}
+
+type ImpersonateCustomerResponse struct {
+	SessionToken string
+	ExpiresAt    string
+}
+
+type UpdateCustomerStatusRequest struct {
+	CustomerId uint32 `json:"customerId" validate:"required"`
+	Reason     string `json:"reason" validate:"required,max=255"`
+}
+
+type DeviceRequest struct {
+	Id          uint32 `json:"id"`
+	Type        string `json:"type" validate:"required,max=64"`
+	ModelNumber string `json:"modelNumber" validate:"required,max=64"`
+}
+
+type GetDevicesResponse struct {
+	Devices []Device
+}
+
+type UpdatePricesRequest struct {
+	Zipcode PostalCode    `json:"zipcode" validate:"required,max=16"`
+	Prices  []HourlyPrice `json:"prices" validate:"required,max=24"`
+}
+
+type HourlyPrice struct {
+	Hour  uint32  `json:"hour" validate:"min=1,max=24"`
+	Value float32 `json:"value" validate:"min=0"`
+}
+
+type GetPricesResponse struct {
+	Prices []Price
+}
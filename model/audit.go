@@ -0,0 +1,70 @@
+package model
+
+// Actor types of audit log entries
+const (
+	AuditActorAdmin    = "admin"
+	AuditActorCustomer = "customer"
+
+	// changes made by background jobs
+	AuditActorSystem = "system"
+)
+
+// Entity types of audit log entries
+const (
+	AuditEntityAdmin                     = "admin"
+	AuditEntityCustomer                  = "customer"
+	AuditEntityServiceLocation           = "service location"
+	AuditEntityServiceLocationMember     = "service location member"
+	AuditEntityServiceLocationInvitation = "service location invitation"
+	AuditEntityEnrolledDevice            = "enrolled device"
+	AuditEntityDevice                    = "device"
+	AuditEntityAuditLogs                 = "audit logs"
+	AuditEntityPrices                    = "prices"
+	AuditEntityCarbonIntensities         = "carbon intensities"
+	AuditEntityDRProgram                 = "demand response program"
+	AuditEntityDREvent                   = "demand response event"
+	AuditEntityDREnrollment              = "demand response enrollment"
+	AuditEntityTwoFactorAuthentication   = "two factor authentication"
+	AuditEntityDataExport                = "data export"
+)
+
+type AuditLog struct {
+	Id        uint64
+	ActorType string
+	ActorId   uint32
+	// admin who made the change on behalf of the customer, 0 when the
+	// customer made it
+	ImpersonatedBy uint32
+	Action         string
+	EntityType     string
+	EntityId       string
+	Details        interface{}
+	Before         interface{}
+	After          interface{}
+	Ip             string
+	RequestId      string
+	CreatedAt      string
+}
+
+// AuditLogFilter narrows down audit log queries, zero values do not filter.
+// Dates are in the database layout and entries are returned newest first,
+// starting below BeforeId when it is set.
+type AuditLogFilter struct {
+	ActorType      string
+	ActorId        uint32
+	ImpersonatedBy uint32
+	Action         string
+	EntityType     string
+	EntityId       string
+	RequestId      string
+	StartDate      string
+	EndDate        string
+	BeforeId       uint64
+}
+
+type GetAuditLogsResponse struct {
+	AuditLogs []AuditLog
+
+	// pass as beforeId to fetch the next page, 0 when there are no more entries
+	NextBeforeId uint64
+}
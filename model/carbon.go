@@ -0,0 +1,45 @@
+package model
+
+type CarbonIntensity struct {
+	Zipcode string
+	Hour    uint32
+	Value   float32
+}
+
+type ImportCarbonIntensitiesResponse struct {
+	ImportedCount uint32
+}
+
+type ServiceLocationEmissions struct {
+	LocationId             uint32
+	LocationLabel          string
+	EnergyConsumption      float32
+	CarbonEmissions        float32
+	AverageCarbonIntensity float32
+}
+
+type EnrolledDeviceEmissions struct {
+	DeviceLabel       string
+	EnergyConsumption float32
+	CarbonEmissions   float32
+}
+
+type CarbonReportResponse struct {
+	Month                    string
+	ServiceLocationEmissions []ServiceLocationEmissions
+	EnrolledDeviceEmissions  []EnrolledDeviceEmissions
+	TotalEnergyConsumption   float32
+	TotalCarbonEmissions     float32
+}
+
+type LowCarbonWindow struct {
+	StartHour              uint32
+	EndHour                uint32
+	AverageCarbonIntensity float32
+	AveragePrice           float32
+}
+
+type GetLowCarbonWindowsResponse struct {
+	Zipcode          string
+	LowCarbonWindows []LowCarbonWindow
+}
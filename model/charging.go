@@ -0,0 +1,88 @@
+package model
+
+// Device type used by the Devices catalog for EV chargers
+const EVChargerDeviceType = "EV Charger"
+
+type ChargingSession struct {
+	Id                uint32
+	EnrolledDeviceId  uint32
+	ServiceLocationId uint32
+	VehicleLabel      string
+	StartedAt         string
+	EndedAt           string
+	EnergyDelivered   float32
+	PeakPower         float32
+	Cost              float32
+}
+
+type GetChargingSessionsResponse struct {
+	ChargingSessions []ChargingSession
+}
+
+type DetectChargingSessionsRequest struct {
+	CustomerId       uint32 `json:"-" validate:"required"`
+	EnrolledDeviceId uint32 `json:"enrolledDeviceId" validate:"required"`
+	VehicleLabel     string `json:"vehicleLabel" validate:"max=64"`
+}
+
+type DetectChargingSessionsResponse struct {
+	ChargingSessions []ChargingSession
+}
+
+type UpdateChargingSessionRequest struct {
+	CustomerId        uint32 `json:"-" validate:"required"`
+	ChargingSessionId uint32 `json:"chargingSessionId" validate:"required"`
+	VehicleLabel      string `json:"vehicleLabel" validate:"max=64"`
+}
+
+type ChargingSlot struct {
+	StartTime string
+	EndTime   string
+	Energy    float32
+	Price     float32
+	Cost      float32
+}
+
+type ChargingTarget struct {
+	Id                   uint32
+	EnrolledDeviceId     uint32
+	VehicleLabel         string
+	BatteryCapacity      float32
+	CurrentChargePercent float32
+	TargetChargePercent  float32
+	ChargerPower         float32
+	Deadline             string
+	EnergyRequired       float32
+	EstimatedCost        float32
+	Slots                []ChargingSlot
+}
+
+type AddChargingTargetRequest struct {
+	CustomerId           uint32  `json:"-" validate:"required"`
+	EnrolledDeviceId     uint32  `json:"enrolledDeviceId" validate:"required"`
+	VehicleLabel         string  `json:"vehicleLabel" validate:"max=64"`
+	BatteryCapacity      float32 `json:"batteryCapacity" validate:"required,min=0"`
+	CurrentChargePercent float32 `json:"currentChargePercent" validate:"min=0,max=100"`
+	TargetChargePercent  float32 `json:"targetChargePercent" validate:"required,max=100"`
+	ChargerPower         float32 `json:"chargerPower" validate:"required,min=0"`
+	TargetTime           string  `json:"targetTime" validate:"required,datetime=15:04"`
+}
+
+type GetChargingTargetsResponse struct {
+	ChargingTargets []ChargingTarget
+}
+
+type ServiceLocationChargingCost struct {
+	ServiceLocationId uint32
+	LocationLabel     string
+	SessionsCount     uint32
+	EnergyDelivered   float32
+	Cost              float32
+}
+
+type ChargingReportResponse struct {
+	Month                string
+	ServiceLocationCosts []ServiceLocationChargingCost
+	TotalEnergyDelivered float32
+	TotalCost            float32
+}
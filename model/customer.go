@@ -8,59 +8,131 @@ type Customer struct {
 	Email            string
 	BillingAddressId uint32
 	Password         string
+	EmailVerified    uint32
+	Active           uint32
 }
 
 type LoginUserRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Email    string `json:"email" validate:"required"`
+	Password string `json:"password" validate:"required"`
 }
 
 type LoginUserResponse struct {
 	CustomerDetails Customer
+	SessionToken    string
+	MfaRequired     bool
+	MfaToken        string
 }
 
 type RegisterUserRequest struct {
-	FirstName     string  `json:"firstName"`
-	LastName      string  `json:"lastName"`
-	PhoneNumber   string  `json:"phoneNumber"`
-	Email         string  `json:"email"`
-	Password      string  `json:"password"`
-	UnitNumber    uint32  `json:"unitNumber"`
-	Street        uint32  `json:"street"`
-	City          string  `json:"city"`
-	State         string  `json:"state"`
-	Zipcode       uint32  `json:"zipcode"`
-	Country       string  `json:"country"`
-	SquareFootage float32 `json:"squareFootage"`
-	BedroomsCount uint32  `json:"bedroomsCount"`
+	FirstName     string     `json:"firstName" validate:"required,max=64"`
+	LastName      string     `json:"lastName" validate:"required,max=64"`
+	PhoneNumber   string     `json:"phoneNumber" validate:"required,phone"`
+	Email         string     `json:"email" validate:"required,email,max=255"`
+	Password      string     `json:"password" validate:"required,min=8"`
+	UnitNumber    uint32     `json:"unitNumber"`
+	Street        uint32     `json:"street" validate:"required"`
+	City          string     `json:"city" validate:"required,max=64"`
+	State         string     `json:"state" validate:"required,max=64"`
+	Zipcode       PostalCode `json:"zipcode" validate:"required,postalcode=Country"`
+	Country       string     `json:"country" validate:"required,max=64"`
+	SquareFootage float32    `json:"squareFootage" validate:"min=0"`
+	BedroomsCount uint32     `json:"bedroomsCount" validate:"max=50"`
 }
 
 type RegisterUserResponse struct {
 	CustomerDetails Customer
 }
 
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type ResendVerificationEmailRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+type GetProfileResponse struct {
+	CustomerDetails Customer
+	BillingAddress  Location
+}
+
+type UpdateProfileRequest struct {
+	CustomerId  uint32 `json:"-" validate:"required"`
+	FirstName   string `json:"firstName" validate:"required,max=64"`
+	LastName    string `json:"lastName" validate:"required,max=64"`
+	PhoneNumber string `json:"phoneNumber" validate:"required,phone"`
+}
+
+type ChangePasswordRequest struct {
+	CustomerId      uint32 `json:"-" validate:"required"`
+	CurrentPassword string `json:"currentPassword" validate:"required"`
+	NewPassword     string `json:"newPassword" validate:"required,min=8"`
+}
+
+type ChangePasswordResponse struct {
+	SessionToken string
+}
+
+type ChangeEmailRequest struct {
+	CustomerId uint32 `json:"-" validate:"required"`
+	Password   string `json:"password" validate:"required"`
+	NewEmail   string `json:"newEmail" validate:"required,email,max=255"`
+}
+
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// UpdateBillingAddressRequest points the billing address at LocationId when it
+// is set, otherwise at the address given by the other fields
+type UpdateBillingAddressRequest struct {
+	CustomerId    uint32     `json:"-" validate:"required"`
+	LocationId    uint32     `json:"locationId"`
+	UnitNumber    uint32     `json:"unitNumber"`
+	Street        uint32     `json:"street"`
+	City          string     `json:"city" validate:"max=64"`
+	State         string     `json:"state" validate:"max=64"`
+	Zipcode       PostalCode `json:"zipcode" validate:"postalcode=Country"`
+	Country       string     `json:"country" validate:"max=64"`
+	SquareFootage float32    `json:"squareFootage" validate:"min=0"`
+	BedroomsCount uint32     `json:"bedroomsCount" validate:"max=50"`
+}
+
 type ServiceLocationCost struct {
 	LocationId                               uint32
 	UnitNumber                               uint32
 	Street                                   uint32
 	City                                     string
-	Zipcode                                  uint32
+	Zipcode                                  string
 	State                                    string
 	Country                                  string
 	EnergyConsumption                        float32
 	EnergyCost                               float32
+	CarbonEmissions                          float32
 	SimilarLocationsAverageEnergyConsumption float32
 }
 
 type EnrolledDevicesEnergyConsumption struct {
 	DeviceLabel       string
 	EnergyConsumption float32
+	CarbonEmissions   float32
 }
 
 type DashboardDataResponse struct {
 	ServiceLocationCosts   []ServiceLocationCost
 	TotalEnergyConsumption float32
 	TotalEnergyCost        float32
+	TotalCarbonEmissions   float32
 	HourlyPrices           []Price
 	EnrolledDevices        []EnrolledDevicesEnergyConsumption
 }
@@ -0,0 +1,87 @@
+package model
+
+const (
+	DREventStatusScheduled = "scheduled"
+	DREventStatusActive    = "active"
+	DREventStatusCompleted = "completed"
+	DREventStatusCancelled = "cancelled"
+)
+
+type DRProgram struct {
+	Id           uint32
+	Name         string
+	Description  string
+	CreditPerKwh float32
+	Active       uint32
+}
+
+type AddDRProgramRequest struct {
+	Name         string  `json:"name" validate:"required,max=64"`
+	Description  string  `json:"description" validate:"max=255"`
+	CreditPerKwh float32 `json:"creditPerKwh" validate:"required,min=0"`
+}
+
+type GetDRProgramsResponse struct {
+	Programs []DRProgram
+}
+
+type DREvent struct {
+	Id          uint32
+	ProgramId   uint32
+	ProgramName string
+	StartsAt    string
+	EndsAt      string
+	Status      string
+	Zipcodes    []string
+	States      []string
+}
+
+type GetAllDREventsResponse struct {
+	Events []DREvent
+}
+
+type AddDREventRequest struct {
+	ProgramId uint32       `json:"programId" validate:"required"`
+	StartsAt  string       `json:"startsAt" validate:"required,datetime=01/02/2006 15:04"`
+	EndsAt    string       `json:"endsAt" validate:"required,datetime=01/02/2006 15:04"`
+	Zipcodes  []PostalCode `json:"zipcodes" validate:"dive,required,max=16"`
+	States    []string     `json:"states" validate:"dive,required,max=64"`
+}
+
+type DRServiceLocationEvent struct {
+	ServiceLocationId uint32
+	OptedIn           uint32
+	Event             DREvent
+	Performance       *DREventPerformance
+}
+
+type GetDREventsResponse struct {
+	Events []DRServiceLocationEvent
+}
+
+type DREnrollment struct {
+	ProgramId         uint32
+	ServiceLocationId uint32
+	OptedIn           uint32
+}
+
+type UpdateDREnrollmentRequest struct {
+	CustomerId        uint32 `json:"-" validate:"required"`
+	ServiceLocationId uint32 `json:"serviceLocationId" validate:"required"`
+	ProgramId         uint32 `json:"programId" validate:"required"`
+	OptedIn           bool   `json:"optedIn"`
+}
+
+type DREventPerformance struct {
+	EventId           uint32
+	ServiceLocationId uint32
+	Baseline          float32
+	Actual            float32
+	Reduction         float32
+	Credit            float32
+}
+
+type GetDRPerformanceResponse struct {
+	Performances []DREventPerformance
+	TotalCredit  float32
+}
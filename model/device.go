@@ -9,15 +9,16 @@ type Device struct {
 
 type EnrolledDevice struct {
 	Id                uint32
-	ServiceLocationId uint32
-	DeviceId          uint32
-	AliasName         string
-	RoomNumber        uint32
-	CustomerId        uint32
+	ServiceLocationId uint32 `validate:"required"`
+	DeviceId          uint32 `validate:"required"`
+	AliasName         string `validate:"max=64"`
+	RoomNumber        uint32 `validate:"max=1000"`
+	CustomerId        uint32 `validate:"required"`
 	ServiceLocation   string
 	DeviceType        string
 	Device            string
 	Active            uint32
+	DeletedAt         string
 }
 
 type GetEnrolledDevicesResponse struct {
@@ -25,3 +26,8 @@ type GetEnrolledDevicesResponse struct {
 	Devices          []Device
 	ServiceLocations []ServiceLocation
 }
+
+type ListEnrolledDevicesResponse struct {
+	EnrolledDevices []EnrolledDevice
+	NextCursor      string
+}
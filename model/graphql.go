@@ -0,0 +1,43 @@
+package model
+
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	Extensions    GraphQLExtensions      `json:"extensions"`
+}
+
+type GraphQLExtensions struct {
+	PersistedQuery *PersistedQuery `json:"persistedQuery"`
+}
+
+// PersistedQuery refers to a query by the SHA-256 hash of its text, so that
+// clients only send the full query the first time
+type PersistedQuery struct {
+	Version    int    `json:"version"`
+	Sha256Hash string `json:"sha256Hash"`
+}
+
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Locations  []GraphQLLocation      `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+type GraphQLResponse struct {
+	Data   interface{}    `json:"data,omitempty"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+type UsagePoint struct {
+	IntervalStart     string
+	EnergyConsumption float32
+	EnergyCost        float32
+	CarbonEmissions   float32
+}
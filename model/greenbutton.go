@@ -0,0 +1,6 @@
+package model
+
+type ImportGreenButtonResponse struct {
+	ImportedCount uint32
+	SkippedCount  uint32
+}
@@ -0,0 +1,12 @@
+package model
+
+type HealthCheck struct {
+	Name    string
+	Status  string
+	Message string
+}
+
+type HealthResponse struct {
+	Status string
+	Checks []HealthCheck
+}
@@ -1,12 +1,37 @@
 package model
 
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+)
+
+// PostalCode is a postal code in a request body. Zipcodes were numbers
+// before they were stored as text, so v1 clients may still send a number,
+// which is taken as its digits.
+type PostalCode string
+
+func (p *PostalCode) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*p = PostalCode(s)
+		return nil
+	}
+	var n uint64
+	if err := json.Unmarshal(data, &n); err != nil {
+		return errors.New("zipcode must be a string or a positive whole number")
+	}
+	*p = PostalCode(strconv.FormatUint(n, 10))
+	return nil
+}
+
 type Location struct {
 	Id            uint32
 	UnitNumber    uint32
 	Street        uint32
 	City          string
 	State         string
-	Zipcode       uint32
+	Zipcode       string
 	Country       string
 	SquareFootage float32
 	BedroomsCount uint32
@@ -14,21 +39,28 @@ type Location struct {
 
 type ServiceLocation struct {
 	Id             uint32
-	CustomerId     uint32
-	DateTakenOver  string
-	OccupantsCount uint32
+	CustomerId     uint32 `validate:"required"`
+	DateTakenOver  string `validate:"required,datetime=2006-01-02|2006-01-02 15:04:05"`
+	OccupantsCount uint32 `validate:"max=100"`
 	UnitNumber     uint32
-	Street         uint32
-	City           string
-	State          string
-	Zipcode        uint32
-	Country        string
-	SquareFootage  float32
-	BedroomsCount  uint32
+	Street         uint32     `validate:"required"`
+	City           string     `validate:"required,max=64"`
+	State          string     `validate:"required,max=64"`
+	Zipcode        PostalCode `validate:"required,postalcode=Country"`
+	Country        string     `validate:"required,max=64"`
+	SquareFootage  float32    `validate:"min=0"`
+	BedroomsCount  uint32     `validate:"max=50"`
 	LocationLabel  string
 	Active         uint32
+	DeletedAt      string
+	Role           string
 }
 
 type GetServiceLocationsResponse struct {
 	ServiceLocations []ServiceLocation
 }
+
+type ListServiceLocationsResponse struct {
+	ServiceLocations []ServiceLocation
+	NextCursor       string
+}
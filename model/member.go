@@ -0,0 +1,43 @@
+package model
+
+type ServiceLocationMember struct {
+	CustomerId uint32
+	FirstName  string
+	LastName   string
+	Email      string
+	Role       string
+	CreatedAt  string
+}
+
+type ServiceLocationInvitation struct {
+	Id        uint32
+	Email     string
+	Role      string
+	InvitedBy uint32
+	CreatedAt string
+	ExpiresAt string
+}
+
+type GetServiceLocationMembersResponse struct {
+	Members     []ServiceLocationMember
+	Invitations []ServiceLocationInvitation
+}
+
+type InviteServiceLocationMemberRequest struct {
+	CustomerId        uint32 `json:"-" validate:"required"`
+	ServiceLocationId uint32 `json:"serviceLocationId" validate:"required"`
+	Email             string `json:"email" validate:"required,email"`
+	Role              string `json:"role" validate:"required,oneof=owner member viewer installer"`
+}
+
+type AcceptServiceLocationInvitationRequest struct {
+	CustomerId uint32 `json:"-" validate:"required"`
+	Token      string `json:"token" validate:"required"`
+}
+
+type UpdateServiceLocationMemberRequest struct {
+	CustomerId        uint32 `json:"-" validate:"required"`
+	ServiceLocationId uint32 `json:"serviceLocationId" validate:"required"`
+	MemberCustomerId  uint32 `json:"memberCustomerId" validate:"required"`
+	Role              string `json:"role" validate:"required,oneof=owner member viewer installer"`
+}
@@ -1,7 +1,7 @@
 package model
 
 type Price struct {
-	Zipcode uint32
+	Zipcode string
 	Hour    uint32
 	Value   float32
 }
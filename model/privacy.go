@@ -0,0 +1,44 @@
+package model
+
+// Statuses of data export jobs
+const (
+	DataExportPending = "pending"
+	DataExportReady   = "ready"
+	DataExportFailed  = "failed"
+)
+
+type DataExport struct {
+	Id          uint32
+	CustomerId  uint32
+	Status      string
+	CreatedAt   string
+	CompletedAt string
+	ExpiresAt   string
+}
+
+type RequestDataExportResponse struct {
+	DataExport DataExport
+}
+
+type GetDataExportsResponse struct {
+	DataExports []DataExport
+}
+
+type DownloadDataExportRequest struct {
+	ExportId uint32 `json:"exportId" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+type RequestErasureRequest struct {
+	CustomerId uint32 `json:"-" validate:"required"`
+	Password   string `json:"password" validate:"required"`
+}
+
+type ConfirmErasureRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type GetErasureStatusResponse struct {
+	// empty when no erasure is scheduled
+	ErasureScheduledAt string
+}
@@ -0,0 +1,39 @@
+package model
+
+type Session struct {
+	CustomerId   uint32
+	MfaSatisfied bool
+	CreatedAt    string
+
+	// set when a support admin opened the session on behalf of the customer
+	ImpersonatedBy uint32
+}
+
+type VerifyMfaLoginRequest struct {
+	MfaToken     string `json:"mfaToken" validate:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recoveryCode"`
+}
+
+type EnrollMfaResponse struct {
+	Secret          string
+	ProvisioningUri string
+}
+
+type ConfirmMfaRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type DisableMfaRequest struct {
+	Password     string `json:"password" validate:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recoveryCode"`
+}
+
+type RegenerateRecoveryCodesRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string
+}
@@ -0,0 +1,16 @@
+package model
+
+type UsageImportError struct {
+	Row     uint32
+	Field   string
+	Message string
+}
+
+type ImportUsageResponse struct {
+	DryRun       bool
+	TotalRows    uint32
+	ValidRows    uint32
+	InvalidRows  uint32
+	ImportedRows uint32
+	Errors       []UsageImportError
+}
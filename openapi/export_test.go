@@ -0,0 +1,3 @@
+package openapi
+
+var NormalizePath = normalizePath
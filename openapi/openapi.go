@@ -0,0 +1,314 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"shems/apierror"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+// Auth tells how an endpoint uses the bearer session
+type Auth int
+
+const (
+	NoAuth Auth = iota
+	// the session is only needed for actions which require two factor
+	// authentication, when the customer enabled it
+	OptionalAuth
+	SessionAuth
+)
+
+// Route describes one method of one endpoint. Body and Response are values
+// of the Go types which are decoded from the request and encoded in the
+// response.
+type Route struct {
+	Method  string
+	Path    string
+	Tag     string
+	Summary string
+	Auth    Auth
+	Params  []Param
+	Body    interface{}
+	// content type of a file which is sent as the raw body or uploaded as
+	// the multipart field "file"
+	Upload string
+	// success status, 200 by default
+	Status   int
+	Response interface{}
+	// content type of a response which is not JSON
+	Download string
+}
+
+type Param struct {
+	Name        string
+	In          string
+	Description string
+	Required    bool
+	Schema      *Schema
+}
+
+func queryParam(name, description string) Param {
+	return Param{Name: name, In: "query", Description: description, Schema: &Schema{Type: "string"}}
+}
+
+func requiredQuery(name, description string) Param {
+	p := queryParam(name, description)
+	p.Required = true
+	return p
+}
+
+func idQuery(name, description string) Param {
+	return Param{Name: name, In: "query", Description: description, Required: true, Schema: &Schema{Type: "integer", Format: "int32", Minimum: float(1)}}
+}
+
+func integerQuery(name, description string) Param {
+	return Param{Name: name, In: "query", Description: description, Schema: &Schema{Type: "integer", Format: "int32"}}
+}
+
+func booleanQuery(name, description string) Param {
+	return Param{Name: name, In: "query", Description: description, Schema: &Schema{Type: "boolean"}}
+}
+
+func enumQuery(name, description string, values ...string) Param {
+	return Param{Name: name, In: "query", Description: description, Schema: &Schema{Type: "string", Enum: values}}
+}
+
+func header(name, description string, required bool) Param {
+	return Param{Name: name, In: "header", Description: description, Required: required, Schema: &Schema{Type: "string"}}
+}
+
+func optional(p Param) Param {
+	p.Required = false
+	return p
+}
+
+// Path params are written as {name} or, in mux, as {name:pattern}
+var pathParamPattern = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// normalizePath turns a mux path template into an OpenAPI path
+func normalizePath(path string) string {
+	return pathParamPattern.ReplaceAllString(path, "{$1}")
+}
+
+var tags = []Tag{
+	{Name: "account", Description: "Registration, login and password recovery"},
+	{Name: "mfa", Description: "Two factor authentication"},
+	{Name: "profile", Description: "Customer profile"},
+	{Name: "dashboard", Description: "Service locations, enrolled devices and members"},
+	{Name: "charging", Description: "EV charging"},
+	{Name: "carbon", Description: "Carbon intensity of the grid"},
+	{Name: "demandResponse", Description: "Demand response programs and events"},
+	{Name: "greenButton", Description: "Green Button import and export"},
+	{Name: "usage", Description: "Usage import and export"},
+	{Name: "privacy", Description: "Personal data export and account erasure"},
+	{Name: "admin", Description: "Support and operations"},
+	{Name: "v2", Description: "Resource oriented API for the customer of the session. Ids are taken from the path and the customer from the session, the same fields in the body are ignored. Updates need the ETag of the resource in If-Match."},
+	{Name: "graphql", Description: "GraphQL endpoint for the dashboard. Lists are batched per level of the query, queries deeper than 10 levels or too expensive are rejected, and queries can be persisted by sending their SHA-256 hash in extensions.persistedQuery."},
+	{Name: "health", Description: "Liveness and readiness probes"},
+	{Name: "docs", Description: "API documentation"},
+}
+
+func jsonContent(s *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: s}}
+}
+
+func (route Route) operation(g *schemaGenerator) Operation {
+	op := Operation{
+		Tags:        []string{route.Tag},
+		Summary:     route.Summary,
+		OperationId: strings.ToLower(route.Method) + operationName(route.Path),
+		Responses:   make(map[string]Response),
+	}
+
+	for _, match := range pathParamPattern.FindAllStringSubmatch(route.Path, -1) {
+		op.Parameters = append(op.Parameters, Parameter{Name: match[1], In: "path", Required: true, Schema: &Schema{Type: "integer", Format: "int32", Minimum: float(1)}})
+	}
+	for _, p := range route.Params {
+		op.Parameters = append(op.Parameters, Parameter{Name: p.Name, In: p.In, Description: p.Description, Required: p.Required, Schema: p.Schema})
+	}
+
+	if route.Body != nil {
+		op.RequestBody = &RequestBody{Required: true, Content: jsonContent(g.schemaOf(reflect.TypeOf(route.Body)))}
+	}
+	if len(route.Upload) > 0 {
+		file := &Schema{Type: "string", Format: "binary"}
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{
+			route.Upload: {Schema: file},
+			"multipart/form-data": {Schema: &Schema{
+				Type:       "object",
+				Properties: map[string]*Schema{"file": file},
+				Required:   []string{"file"},
+			}},
+		}}
+	}
+
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := Response{Description: http.StatusText(status)}
+	if route.Response != nil {
+		success.Content = jsonContent(g.schemaOf(reflect.TypeOf(route.Response)))
+	}
+	if len(route.Download) > 0 {
+		success.Content = map[string]MediaType{route.Download: {Schema: &Schema{Type: "string", Format: "binary"}}}
+	}
+	if route.Tag == "v2" && route.Response != nil {
+		success.Headers = map[string]Header{"ETag": {Description: "Version of the resource to send in If-Match", Schema: &Schema{Type: "string"}}}
+	}
+	op.Responses[fmt.Sprint(status)] = success
+	op.Responses["default"] = Response{Description: "Error", Content: jsonContent(ref("Error"))}
+
+	switch route.Auth {
+	case SessionAuth:
+		op.Security = []map[string][]string{{"bearerAuth": {}}}
+	case OptionalAuth:
+		op.Security = []map[string][]string{{}, {"bearerAuth": {}}}
+	}
+	return op
+}
+
+// operationName turns a path into a camel case name, e.g.
+// /v2/service-locations/{id} into V2ServiceLocationsById
+func operationName(path string) string {
+	var name strings.Builder
+	for _, segment := range strings.Split(path, "/") {
+		if match := pathParamPattern.FindStringSubmatch(segment); match != nil {
+			segment = "by-" + match[1]
+		}
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '.' }) {
+			name.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return name.String()
+}
+
+// Build generates the OpenAPI document from Routes
+func Build() *Document {
+	g := newSchemaGenerator()
+	g.register("Error", reflect.TypeOf(apierror.Response{}))
+	g.register("Message", reflect.TypeOf(message{}))
+
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "Smart Home Energy Management System API",
+			Description: "Every error is answered with the Error body. Fields of request and response bodies are matched case insensitively, and are named like in the schemas.",
+			Version:     "2.0.0",
+		},
+		Tags:  tags,
+		Paths: make(map[string]map[string]Operation),
+		Components: Components{
+			Schemas: g.schemas,
+			SecuritySchemes: map[string]SecurityScheme{
+				"bearerAuth": {Type: "http", Scheme: "bearer", Description: "Session token returned by login"},
+			},
+		},
+	}
+	for _, route := range Routes {
+		path := normalizePath(route.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]Operation)
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = route.operation(g)
+	}
+	return doc
+}
+
+var document struct {
+	once sync.Once
+	body []byte
+	err  error
+}
+
+func getDocumentBody() ([]byte, error) {
+	document.once.Do(func() {
+		document.body, document.err = json.MarshalIndent(Build(), "", "  ")
+	})
+	return document.body, document.err
+}
+
+// CheckRoutes returns an error listing the endpoints which are registered on
+// the router but not described in Routes, or the other way round
+func CheckRoutes(router *mux.Router) error {
+	documented := make(map[string]bool)
+	for _, route := range Routes {
+		documented[route.Method+" "+normalizePath(route.Path)] = true
+	}
+
+	var problems []string
+	registered := make(map[string]bool)
+	err := router.Walk(func(route *mux.Route, router *mux.Router, ancestors []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return err
+		}
+		path := normalizePath(template)
+		methods, err := route.GetMethods()
+		if err != nil {
+			// routes without methods answer every method, they only need to
+			// be described with one
+			registered["* "+path] = true
+			return nil
+		}
+		for _, method := range methods {
+			key := method + " " + path
+			registered[key] = true
+			if !documented[key] {
+				problems = append(problems, "undocumented route "+key)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	describedPaths := make(map[string]bool)
+	for _, route := range Routes {
+		path := normalizePath(route.Path)
+		describedPaths[path] = true
+		if !registered[route.Method+" "+path] && !registered["* "+path] {
+			problems = append(problems, "documented route "+route.Method+" "+path+" is not registered")
+		}
+	}
+	for key := range registered {
+		if path, ok := strings.CutPrefix(key, "* "); ok && !describedPaths[path] {
+			problems = append(problems, "undocumented route "+path)
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("OpenAPI document is out of date: %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+func GetDocument(w http.ResponseWriter, r *http.Request) {
+	body, err := getDocumentBody()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+//go:embed docs.html
+var docsPage []byte
+
+// GetDocs serves a page which renders the document, with no assets from
+// outside the server
+func GetDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(docsPage)
+}
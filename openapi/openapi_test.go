@@ -0,0 +1,259 @@
+package openapi_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"shems/config"
+	"shems/health"
+	"shems/httpapi"
+	"shems/mail"
+	"shems/openapi"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// The router is built with clients which are never connected, handlers are
+// not called
+func TestRoutesMatchRouter(t *testing.T) {
+	redisClient := redis.NewClient(&redis.Options{})
+	defer redisClient.Close()
+	router := httpapi.NewRouter(nil, redisClient, mail.NewFileSender(t.TempDir(), "shems@example.com"), health.NewMonitor(), config.Config{})
+
+	err := openapi.CheckRoutes(router)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDocumentIsConsistent(t *testing.T) {
+	doc := openapi.Build()
+
+	tags := make(map[string]bool)
+	for _, tag := range doc.Tags {
+		tags[tag.Name] = true
+	}
+	operationIds := make(map[string]string)
+	for path, operations := range doc.Paths {
+		for method, op := range operations {
+			name := strings.ToUpper(method) + " " + path
+			for _, tag := range op.Tags {
+				if !tags[tag] {
+					t.Errorf("%s: tag %q is not declared", name, tag)
+				}
+			}
+			if other, ok := operationIds[op.OperationId]; ok {
+				t.Errorf("%s: operationId %q is also used by %s", name, op.OperationId, other)
+			}
+			operationIds[op.OperationId] = name
+			if _, ok := op.Responses["default"]; !ok {
+				t.Errorf("%s: no error response", name)
+			}
+		}
+	}
+
+	// every reference has to point at a component, and every required
+	// property has to be a property
+	body, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tree interface{}
+	err = json.Unmarshal(body, &tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	walk(tree, func(node map[string]interface{}) {
+		if target, ok := node["$ref"].(string); ok {
+			name, found := strings.CutPrefix(target, "#/components/schemas/")
+			if !found || doc.Components.Schemas[name] == nil {
+				t.Errorf("reference %s does not resolve", target)
+			}
+		}
+	})
+	for name, schema := range doc.Components.Schemas {
+		for _, property := range schema.Required {
+			if _, ok := schema.Properties[property]; !ok {
+				t.Errorf("schema %s requires %s which is not a property", name, property)
+			}
+		}
+	}
+}
+
+func walk(node interface{}, visit func(map[string]interface{})) {
+	switch node := node.(type) {
+	case map[string]interface{}:
+		visit(node)
+		for _, child := range node {
+			walk(child, visit)
+		}
+	case []interface{}:
+		for _, child := range node {
+			walk(child, visit)
+		}
+	}
+}
+
+// The bodies of the routes are encoded with every field set and checked
+// against the schemas, so the schemas describe what encoding/json sends,
+// including custom encodings
+func TestBodiesMatchSchemas(t *testing.T) {
+	doc := openapi.Build()
+	for _, route := range openapi.Routes {
+		op := doc.Paths[openapi.NormalizePath(route.Path)][strings.ToLower(route.Method)]
+		name := route.Method + " " + route.Path
+
+		if route.Body != nil {
+			if route.Method == "GET" {
+				t.Errorf("%s: GET requests have no body", name)
+			}
+			if op.RequestBody == nil {
+				t.Errorf("%s: no request body", name)
+			} else {
+				checkBody(t, doc, name+" request", route.Body, op.RequestBody.Content["application/json"].Schema)
+			}
+		}
+
+		if route.Response != nil {
+			status := route.Status
+			if status == 0 {
+				status = 200
+			}
+			response, ok := op.Responses[fmt.Sprint(status)]
+			if !ok || response.Content["application/json"].Schema == nil {
+				t.Errorf("%s: no %d response", name, status)
+				continue
+			}
+			checkBody(t, doc, name+" response", route.Response, response.Content["application/json"].Schema)
+		}
+	}
+}
+
+func checkBody(t *testing.T, doc *openapi.Document, name string, value interface{}, schema *openapi.Schema) {
+	t.Helper()
+	body, err := json.Marshal(filled(reflect.TypeOf(value), 0).Interface())
+	if err != nil {
+		t.Errorf("%s: %v", name, err)
+		return
+	}
+	var decoded interface{}
+	err = json.Unmarshal(body, &decoded)
+	if err != nil {
+		t.Errorf("%s: %v", name, err)
+		return
+	}
+	for _, problem := range validate(doc, decoded, schema, "") {
+		t.Errorf("%s: %s", name, problem)
+	}
+}
+
+// filled returns a value of t with every field, element and pointer set, so
+// that omitempty leaves nothing out. Recursive types stop after a few levels.
+func filled(t reflect.Type, depth int) reflect.Value {
+	v := reflect.New(t).Elem()
+	if depth > 4 {
+		return v
+	}
+	if t.String() == "time.Time" {
+		return v
+	}
+	switch t.Kind() {
+	case reflect.Ptr:
+		v.Set(filled(t.Elem(), depth+1).Addr())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				v.Field(i).Set(filled(t.Field(i).Type, depth+1))
+			}
+		}
+	case reflect.Slice:
+		v.Set(reflect.Append(reflect.MakeSlice(t, 0, 1), filled(t.Elem(), depth+1)))
+	case reflect.Map:
+		v.Set(reflect.MakeMap(t))
+		v.SetMapIndex(filled(t.Key(), depth+1), filled(t.Elem(), depth+1))
+	case reflect.String:
+		v.SetString("1")
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1.5)
+	}
+	return v
+}
+
+// validate returns the places where value, as decoded by encoding/json,
+// does not have the type or the properties of the schema
+func validate(doc *openapi.Document, value interface{}, schema *openapi.Schema, at string) []string {
+	if len(schema.Ref) > 0 {
+		schema = doc.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+		if schema == nil {
+			return nil
+		}
+	}
+	// schemas without a type accept anything
+	if len(schema.Type) == 0 {
+		return nil
+	}
+	if len(at) == 0 {
+		at = "body"
+	}
+
+	var problems []string
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if schema.Type != "object" {
+			return []string{fmt.Sprintf("%s is an object, the schema has type %s", at, schema.Type)}
+		}
+		for key, property := range value {
+			propertySchema, ok := schema.Properties[key]
+			if !ok {
+				propertySchema = schema.AdditionalProperties
+			}
+			if propertySchema == nil {
+				problems = append(problems, fmt.Sprintf("%s.%s is not in the schema", at, key))
+				continue
+			}
+			problems = append(problems, validate(doc, property, propertySchema, at+"."+key)...)
+		}
+		// interfaces cannot be filled, their properties have no type
+		var missing []string
+		for key, property := range schema.Properties {
+			if _, ok := value[key]; !ok && (len(property.Type) > 0 || len(property.Ref) > 0) {
+				missing = append(missing, key)
+			}
+		}
+		sort.Strings(missing)
+		for _, key := range missing {
+			problems = append(problems, fmt.Sprintf("%s.%s is in the schema but not encoded", at, key))
+		}
+	case []interface{}:
+		if schema.Type != "array" {
+			return []string{fmt.Sprintf("%s is an array, the schema has type %s", at, schema.Type)}
+		}
+		for i, item := range value {
+			problems = append(problems, validate(doc, item, schema.Items, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+	case string:
+		if schema.Type != "string" {
+			problems = append(problems, fmt.Sprintf("%s is a string, the schema has type %s", at, schema.Type))
+		}
+	case float64:
+		if schema.Type != "number" && (schema.Type != "integer" || value != float64(int64(value))) {
+			problems = append(problems, fmt.Sprintf("%s is the number %v, the schema has type %s", at, value, schema.Type))
+		}
+	case bool:
+		if schema.Type != "boolean" {
+			problems = append(problems, fmt.Sprintf("%s is a boolean, the schema has type %s", at, schema.Type))
+		}
+	case nil:
+		problems = append(problems, fmt.Sprintf("%s is null, the schema has type %s", at, schema.Type))
+	}
+	return problems
+}
@@ -0,0 +1,175 @@
+package openapi
+
+import (
+	"net/http"
+	"shems/model"
+)
+
+// message is the body of endpoints which only confirm that they succeeded
+type message struct {
+	Message string `json:"message"`
+}
+
+// v2 resources are changed with the ETag they were fetched with
+var ifMatch = header("If-Match", "ETag of the resource as it was fetched", true)
+
+// Routes describes every endpoint registered by httpapi.NewRouter. The tests
+// of this package fail when the two differ, so an endpoint is added in both
+// places.
+var Routes = []Route{
+	// account
+	{Method: http.MethodPost, Path: "/login", Tag: "account", Summary: "Login with a verified email", Body: model.LoginUserRequest{}, Response: model.LoginUserResponse{}},
+	{Method: http.MethodPost, Path: "/login/verifyMfa", Tag: "account", Summary: "Complete login with a two factor authentication code", Body: model.VerifyMfaLoginRequest{}, Response: model.LoginUserResponse{}},
+	{Method: http.MethodPost, Path: "/logout", Tag: "account", Summary: "Logout", Auth: SessionAuth, Response: message{}},
+	{Method: http.MethodPost, Path: "/register", Tag: "account", Summary: "Register a customer, taken emails get the same response", Body: model.RegisterUserRequest{}, Response: message{}},
+	{Method: http.MethodPost, Path: "/verifyEmail", Tag: "account", Summary: "Verify the email address of a customer", Body: model.VerifyEmailRequest{}, Response: message{}},
+	{Method: http.MethodPost, Path: "/resendVerificationEmail", Tag: "account", Summary: "Resend the verification email", Body: model.ResendVerificationEmailRequest{}, Response: message{}},
+	{Method: http.MethodPost, Path: "/forgotPassword", Tag: "account", Summary: "Send a password reset email", Body: model.ForgotPasswordRequest{}, Response: message{}},
+	{Method: http.MethodPost, Path: "/resetPassword", Tag: "account", Summary: "Reset the password with the token of a password reset email", Body: model.ResetPasswordRequest{}, Response: message{}},
+
+	// two factor authentication
+	{Method: http.MethodPost, Path: "/mfa/enroll", Tag: "mfa", Summary: "Start enrolling in two factor authentication", Auth: SessionAuth, Response: model.EnrollMfaResponse{}},
+	{Method: http.MethodPost, Path: "/mfa/confirm", Tag: "mfa", Summary: "Confirm two factor authentication with a first code", Auth: SessionAuth, Body: model.ConfirmMfaRequest{}, Response: model.RecoveryCodesResponse{}},
+	{Method: http.MethodPost, Path: "/mfa/disable", Tag: "mfa", Summary: "Disable two factor authentication", Auth: SessionAuth, Body: model.DisableMfaRequest{}, Response: message{}},
+	{Method: http.MethodPost, Path: "/mfa/regenerateRecoveryCodes", Tag: "mfa", Summary: "Replace the recovery codes", Auth: SessionAuth, Body: model.RegenerateRecoveryCodesRequest{}, Response: model.RecoveryCodesResponse{}},
+
+	// profile
+	{Method: http.MethodGet, Path: "/profile/getProfile", Tag: "profile", Summary: "Get the profile of a customer", Auth: SessionAuth, Response: model.GetProfileResponse{}},
+	{Method: http.MethodPut, Path: "/profile/updateProfile", Tag: "profile", Summary: "Update the name and phone number of a customer", Auth: SessionAuth, Body: model.UpdateProfileRequest{}, Response: message{}},
+	{Method: http.MethodPut, Path: "/profile/changePassword", Tag: "profile", Summary: "Change the password of a customer", Auth: SessionAuth, Body: model.ChangePasswordRequest{}, Response: model.ChangePasswordResponse{}},
+	{Method: http.MethodPost, Path: "/profile/changeEmail", Tag: "profile", Summary: "Send a confirmation email to a new email address", Auth: SessionAuth, Body: model.ChangeEmailRequest{}, Response: message{}},
+	{Method: http.MethodPost, Path: "/profile/confirmEmailChange", Tag: "profile", Summary: "Confirm the change of the email address", Body: model.ConfirmEmailChangeRequest{}, Response: message{}},
+	{Method: http.MethodPut, Path: "/profile/updateBillingAddress", Tag: "profile", Summary: "Update the billing address of a customer", Auth: SessionAuth, Body: model.UpdateBillingAddressRequest{}, Response: message{}},
+
+	// dashboard
+	{Method: http.MethodGet, Path: "/dashboard", Tag: "dashboard", Summary: "Get the dashboard data of a month", Auth: SessionAuth, Params: []Param{currentDate}, Response: model.DashboardDataResponse{}},
+	{Method: http.MethodGet, Path: "/dashboard/getEnrolledDevices", Tag: "dashboard", Summary: "List enrolled devices", Auth: SessionAuth, Params: []Param{status}, Response: model.GetEnrolledDevicesResponse{}},
+	{Method: http.MethodPost, Path: "/dashboard/addEnrolledDevice", Tag: "dashboard", Summary: "Enroll a device", Auth: SessionAuth, Body: model.EnrolledDevice{}, Response: message{}},
+	{Method: http.MethodPut, Path: "/dashboard/updateEnrolledDevice", Tag: "dashboard", Summary: "Update an enrolled device", Auth: SessionAuth, Body: model.EnrolledDevice{}, Response: message{}},
+	{Method: http.MethodDelete, Path: "/dashboard/deleteEnrolledDevice", Tag: "dashboard", Summary: "Delete an enrolled device", Auth: SessionAuth, Params: []Param{idQuery("enrolledDeviceId", "Id of the enrolled device")}, Response: message{}},
+	{Method: http.MethodPut, Path: "/dashboard/restoreEnrolledDevice", Tag: "dashboard", Summary: "Restore a deleted enrolled device", Auth: SessionAuth, Params: []Param{idQuery("enrolledDeviceId", "Id of the enrolled device")}, Response: message{}},
+	{Method: http.MethodGet, Path: "/dashboard/getServiceLocations", Tag: "dashboard", Summary: "List service locations", Auth: SessionAuth, Params: []Param{status}, Response: model.GetServiceLocationsResponse{}},
+	{Method: http.MethodPost, Path: "/dashboard/addServiceLocation", Tag: "dashboard", Summary: "Add a service location", Auth: SessionAuth, Body: model.ServiceLocation{}, Response: message{}},
+	{Method: http.MethodPut, Path: "/dashboard/updateServiceLocation", Tag: "dashboard", Summary: "Update a service location", Auth: SessionAuth, Body: model.ServiceLocation{}, Response: message{}},
+	{Method: http.MethodDelete, Path: "/dashboard/deleteServiceLocation", Tag: "dashboard", Summary: "Delete a service location along with its devices", Auth: SessionAuth, Params: []Param{serviceLocationId}, Response: message{}},
+	{Method: http.MethodPut, Path: "/dashboard/restoreServiceLocation", Tag: "dashboard", Summary: "Restore a deleted service location along with its devices", Auth: SessionAuth, Params: []Param{serviceLocationId}, Response: message{}},
+	{Method: http.MethodGet, Path: "/dashboard/getServiceLocationMembers", Tag: "dashboard", Summary: "List members and invitations of a service location", Auth: SessionAuth, Params: []Param{serviceLocationId}, Response: model.GetServiceLocationMembersResponse{}},
+	{Method: http.MethodPost, Path: "/dashboard/inviteServiceLocationMember", Tag: "dashboard", Summary: "Invite a member to a service location", Auth: SessionAuth, Body: model.InviteServiceLocationMemberRequest{}, Response: message{}},
+	{Method: http.MethodPost, Path: "/dashboard/acceptServiceLocationInvitation", Tag: "dashboard", Summary: "Accept an invitation to a service location", Auth: SessionAuth, Body: model.AcceptServiceLocationInvitationRequest{}, Response: message{}},
+	{Method: http.MethodPut, Path: "/dashboard/updateServiceLocationMember", Tag: "dashboard", Summary: "Change the role of a member", Auth: SessionAuth, Body: model.UpdateServiceLocationMemberRequest{}, Response: message{}},
+	{Method: http.MethodDelete, Path: "/dashboard/removeServiceLocationMember", Tag: "dashboard", Summary: "Remove a member from a service location", Auth: SessionAuth, Params: []Param{serviceLocationId, idQuery("memberCustomerId", "Id of the member to remove")}, Response: message{}},
+	{Method: http.MethodDelete, Path: "/dashboard/deleteServiceLocationInvitation", Tag: "dashboard", Summary: "Delete a pending invitation", Auth: SessionAuth, Params: []Param{idQuery("invitationId", "Id of the invitation")}, Response: message{}},
+
+	// EV charging
+	{Method: http.MethodGet, Path: "/charging/getChargingSessions", Tag: "charging", Summary: "List charging sessions of a month", Auth: SessionAuth, Params: []Param{currentDate}, Response: model.GetChargingSessionsResponse{}},
+	{Method: http.MethodPost, Path: "/charging/detectChargingSessions", Tag: "charging", Summary: "Detect charging sessions from readings", Auth: SessionAuth, Body: model.DetectChargingSessionsRequest{}, Response: model.DetectChargingSessionsResponse{}},
+	{Method: http.MethodPut, Path: "/charging/updateChargingSession", Tag: "charging", Summary: "Update a charging session", Auth: SessionAuth, Body: model.UpdateChargingSessionRequest{}, Response: message{}},
+	{Method: http.MethodGet, Path: "/charging/getChargingTargets", Tag: "charging", Summary: "List charging targets", Auth: SessionAuth, Response: model.GetChargingTargetsResponse{}},
+	{Method: http.MethodPost, Path: "/charging/addChargingTarget", Tag: "charging", Summary: "Add a charging target and schedule the cheapest charging windows", Auth: SessionAuth, Body: model.AddChargingTargetRequest{}, Response: model.ChargingTarget{}},
+	{Method: http.MethodGet, Path: "/charging/getChargingReport", Tag: "charging", Summary: "Get the charging report of a month", Auth: SessionAuth, Params: []Param{currentDate}, Response: model.ChargingReportResponse{}},
+
+	// carbon intensity
+	{Method: http.MethodGet, Path: "/carbon/getCarbonReport", Tag: "carbon", Summary: "Get the carbon report of a month", Auth: SessionAuth, Params: []Param{currentDate}, Response: model.CarbonReportResponse{}},
+	{Method: http.MethodGet, Path: "/carbon/getLowCarbonWindows", Tag: "carbon", Summary: "Find the upcoming windows with the lowest carbon intensity", Auth: SessionAuth, Params: []Param{serviceLocationId, integerQuery("duration", "Length of a window in hours, 1 to 24"), integerQuery("count", "Number of windows")}, Response: model.GetLowCarbonWindowsResponse{}},
+
+	// demand response
+	{Method: http.MethodGet, Path: "/demandResponse/getPrograms", Tag: "demandResponse", Summary: "List demand response programs", Response: model.GetDRProgramsResponse{}},
+	{Method: http.MethodGet, Path: "/demandResponse/getAllEvents", Tag: "demandResponse", Summary: "List all demand response events", Response: model.GetAllDREventsResponse{}},
+	{Method: http.MethodGet, Path: "/demandResponse/getEvents", Tag: "demandResponse", Summary: "List demand response events of the customer's service locations", Auth: SessionAuth, Response: model.GetDREventsResponse{}},
+	{Method: http.MethodPut, Path: "/demandResponse/updateEnrollment", Tag: "demandResponse", Summary: "Opt a service location in or out of a program", Auth: SessionAuth, Body: model.UpdateDREnrollmentRequest{}, Response: message{}},
+	{Method: http.MethodGet, Path: "/demandResponse/getPerformance", Tag: "demandResponse", Summary: "Get the demand response performance of a month", Auth: SessionAuth, Params: []Param{currentDate}, Response: model.GetDRPerformanceResponse{}},
+
+	// Green Button
+	{Method: http.MethodPost, Path: "/greenButton/import", Tag: "greenButton", Summary: "Import readings of a meter from Green Button XML", Auth: SessionAuth, Params: []Param{serviceLocationId, idQuery("enrolledDeviceId", "Id of the enrolled device of the meter")}, Upload: "application/xml", Response: model.ImportGreenButtonResponse{}},
+	{Method: http.MethodGet, Path: "/greenButton/export", Tag: "greenButton", Summary: "Export usage as Green Button XML", Auth: SessionAuth, Params: []Param{startDate, endDate}, Download: "application/atom+xml"},
+
+	// usage
+	{Method: http.MethodGet, Path: "/usage/export", Tag: "usage", Summary: "Export usage as CSV or XLSX", Auth: SessionAuth, Params: []Param{startDate, endDate, enumQuery("level", "Level of detail, location by default", "location", "device"), queryParam("columns", "Comma separated columns to export, all by default"), enumQuery("format", "File format, csv by default", "csv", "xlsx")}, Download: "text/csv"},
+	{Method: http.MethodPost, Path: "/usage/import", Tag: "usage", Summary: "Import readings from CSV", Auth: SessionAuth, Params: []Param{booleanQuery("dryRun", "Only validate the file")}, Upload: "text/csv", Response: model.ImportUsageResponse{}},
+
+	// privacy
+	{Method: http.MethodPost, Path: "/privacy/requestDataExport", Tag: "privacy", Summary: "Request an export of all personal data", Auth: SessionAuth, Response: model.RequestDataExportResponse{}},
+	{Method: http.MethodGet, Path: "/privacy/getDataExports", Tag: "privacy", Summary: "List data exports", Auth: SessionAuth, Response: model.GetDataExportsResponse{}},
+	{Method: http.MethodPost, Path: "/privacy/downloadDataExport", Tag: "privacy", Summary: "Download a finished data export, the password is asked again", Auth: SessionAuth, Body: model.DownloadDataExportRequest{}, Download: "application/zip"},
+	{Method: http.MethodPost, Path: "/privacy/requestErasure", Tag: "privacy", Summary: "Send a confirmation email to erase the account", Auth: SessionAuth, Body: model.RequestErasureRequest{}, Response: message{}},
+	{Method: http.MethodPost, Path: "/privacy/confirmErasure", Tag: "privacy", Summary: "Confirm the erasure of the account, which happens after a grace period", Body: model.ConfirmErasureRequest{}, Response: model.GetErasureStatusResponse{}},
+	{Method: http.MethodPost, Path: "/privacy/cancelErasure", Tag: "privacy", Summary: "Cancel a confirmed erasure during the grace period", Auth: SessionAuth, Response: message{}},
+	{Method: http.MethodGet, Path: "/privacy/getErasureStatus", Tag: "privacy", Summary: "Get the erasure status of the account", Auth: SessionAuth, Response: model.GetErasureStatusResponse{}},
+
+	// admin
+	{Method: http.MethodPost, Path: "/admin/login", Tag: "admin", Summary: "Login as an admin", Body: model.AdminLoginRequest{}, Response: model.AdminLoginResponse{}},
+	{Method: http.MethodPost, Path: "/admin/logout", Tag: "admin", Summary: "Logout an admin", Auth: SessionAuth, Response: message{}},
+	{Method: http.MethodPost, Path: "/admin/addAdmin", Tag: "admin", Summary: "Add an admin", Auth: SessionAuth, Body: model.AddAdminRequest{}, Response: message{}},
+	{Method: http.MethodGet, Path: "/admin/searchCustomers", Tag: "admin", Summary: "Search customers by name, email or phone number", Auth: SessionAuth, Params: []Param{requiredQuery("q", "Search query")}, Response: model.SearchCustomersResponse{}},
+	{Method: http.MethodGet, Path: "/admin/getCustomerDetails", Tag: "admin", Summary: "Get a customer with their service locations and devices", Auth: SessionAuth, Params: []Param{customerId}, Response: model.AdminCustomerDetailsResponse{}},
+	{Method: http.MethodGet, Path: "/admin/getEvents", Tag: "admin", Summary: "Get the events of an enrolled device", Auth: SessionAuth, Params: []Param{idQuery("enrolledDeviceId", "Id of the enrolled device"), startDate, endDate}, Response: model.GetAdminEventsResponse{}},
+	{Method: http.MethodPost, Path: "/admin/impersonateCustomer", Tag: "admin", Summary: "Open a short session on behalf of a customer", Auth: SessionAuth, Body: model.ImpersonateCustomerRequest{}, Response: model.ImpersonateCustomerResponse{}},
+	{Method: http.MethodPut, Path: "/admin/deactivateCustomer", Tag: "admin", Summary: "Deactivate a customer and end their sessions", Auth: SessionAuth, Body: model.UpdateCustomerStatusRequest{}, Response: message{}},
+	{Method: http.MethodPut, Path: "/admin/reactivateCustomer", Tag: "admin", Summary: "Reactivate a customer", Auth: SessionAuth, Body: model.UpdateCustomerStatusRequest{}, Response: message{}},
+	{Method: http.MethodGet, Path: "/admin/getDevices", Tag: "admin", Summary: "List the device catalog", Auth: SessionAuth, Response: model.GetDevicesResponse{}},
+	{Method: http.MethodPost, Path: "/admin/addDevice", Tag: "admin", Summary: "Add a device to the catalog", Auth: SessionAuth, Body: model.DeviceRequest{}, Response: message{}},
+	{Method: http.MethodPut, Path: "/admin/updateDevice", Tag: "admin", Summary: "Update a device of the catalog", Auth: SessionAuth, Body: model.DeviceRequest{}, Response: message{}},
+	{Method: http.MethodGet, Path: "/admin/getPrices", Tag: "admin", Summary: "Get the hourly energy prices of a zipcode", Auth: SessionAuth, Params: []Param{requiredQuery("zipcode", "Zipcode of the prices")}, Response: model.GetPricesResponse{}},
+	{Method: http.MethodPut, Path: "/admin/updatePrices", Tag: "admin", Summary: "Update the hourly energy prices of a zipcode", Auth: SessionAuth, Body: model.UpdatePricesRequest{}, Response: message{}},
+	{Method: http.MethodPost, Path: "/admin/importCarbonIntensities", Tag: "admin", Summary: "Import hourly carbon intensities", Auth: SessionAuth, Upload: "text/csv", Response: model.ImportCarbonIntensitiesResponse{}},
+	{Method: http.MethodPost, Path: "/admin/addDRProgram", Tag: "admin", Summary: "Add a demand response program", Auth: SessionAuth, Body: model.AddDRProgramRequest{}, Response: message{}},
+	{Method: http.MethodPost, Path: "/admin/addDREvent", Tag: "admin", Summary: "Schedule a demand response event", Auth: SessionAuth, Body: model.AddDREventRequest{}, Response: message{}},
+	{Method: http.MethodPut, Path: "/admin/cancelDREvent", Tag: "admin", Summary: "Cancel a scheduled demand response event", Auth: SessionAuth, Params: []Param{idQuery("eventId", "Id of the event")}, Response: message{}},
+	{Method: http.MethodGet, Path: "/admin/getAuditLogs", Tag: "admin", Summary: "Search audit logs, newest first", Auth: SessionAuth, Params: append(auditLogFilters, integerQuery("limit", "Page size")), Response: model.GetAuditLogsResponse{}},
+	{Method: http.MethodGet, Path: "/admin/exportAuditLogs", Tag: "admin", Summary: "Export audit logs as CSV or JSON lines", Auth: SessionAuth, Params: append(auditLogFilters, enumQuery("format", "File format, csv by default", "csv", "jsonl")), Download: "text/csv"},
+
+	// v2 service locations
+	{Method: http.MethodGet, Path: "/v2/service-locations", Tag: "v2", Summary: "List service locations of the session's customer", Auth: SessionAuth, Params: append([]Param{status, queryParam("city", "Only service locations in the city"), queryParam("state", "Only service locations in the state"), queryParam("zipcode", "Only service locations with the zipcode")}, page...), Response: model.ListServiceLocationsResponse{}},
+	{Method: http.MethodPost, Path: "/v2/service-locations", Tag: "v2", Summary: "Add a service location", Auth: SessionAuth, Body: model.ServiceLocation{}, Status: http.StatusCreated, Response: model.ServiceLocation{}},
+	{Method: http.MethodGet, Path: "/v2/service-locations/{id}", Tag: "v2", Summary: "Get a service location", Auth: SessionAuth, Response: model.ServiceLocation{}},
+	{Method: http.MethodPut, Path: "/v2/service-locations/{id}", Tag: "v2", Summary: "Update a service location", Auth: SessionAuth, Params: []Param{ifMatch}, Body: model.ServiceLocation{}, Response: model.ServiceLocation{}},
+	{Method: http.MethodDelete, Path: "/v2/service-locations/{id}", Tag: "v2", Summary: "Delete a service location along with its devices", Auth: SessionAuth, Params: []Param{optional(ifMatch)}, Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/v2/service-locations/{id}/restore", Tag: "v2", Summary: "Restore a deleted service location", Auth: SessionAuth, Response: model.ServiceLocation{}},
+
+	// v2 enrolled devices
+	{Method: http.MethodGet, Path: "/v2/service-locations/{id}/devices", Tag: "v2", Summary: "List enrolled devices of a service location", Auth: SessionAuth, Params: append([]Param{status, queryParam("deviceType", "Only devices of the type")}, page...), Response: model.ListEnrolledDevicesResponse{}},
+	{Method: http.MethodPost, Path: "/v2/service-locations/{id}/devices", Tag: "v2", Summary: "Enroll a device in a service location", Auth: SessionAuth, Body: model.EnrolledDevice{}, Status: http.StatusCreated, Response: model.EnrolledDevice{}},
+	{Method: http.MethodGet, Path: "/v2/service-locations/{id}/devices/{deviceId}", Tag: "v2", Summary: "Get an enrolled device", Auth: SessionAuth, Response: model.EnrolledDevice{}},
+	{Method: http.MethodPut, Path: "/v2/service-locations/{id}/devices/{deviceId}", Tag: "v2", Summary: "Update an enrolled device, moving it when ServiceLocationId is another service location", Auth: SessionAuth, Params: []Param{ifMatch}, Body: model.EnrolledDevice{}, Response: model.EnrolledDevice{}},
+	{Method: http.MethodDelete, Path: "/v2/service-locations/{id}/devices/{deviceId}", Tag: "v2", Summary: "Delete an enrolled device", Auth: SessionAuth, Params: []Param{optional(ifMatch)}, Status: http.StatusNoContent},
+	{Method: http.MethodPost, Path: "/v2/service-locations/{id}/devices/{deviceId}/restore", Tag: "v2", Summary: "Restore a deleted enrolled device", Auth: SessionAuth, Response: model.EnrolledDevice{}},
+
+	// GraphQL
+	{Method: http.MethodPost, Path: "/graphql", Tag: "graphql", Summary: "Query the customer, service locations, enrolled devices, usage and prices", Auth: SessionAuth, Body: model.GraphQLRequest{}, Response: model.GraphQLResponse{}},
+
+	// health
+	{Method: http.MethodGet, Path: "/healthz", Tag: "health", Summary: "Tell that the server is alive", Response: model.HealthResponse{}},
+	{Method: http.MethodGet, Path: "/readyz", Tag: "health", Summary: "Check the database, redis, migrations and background workers, answering 503 when the server should not get traffic", Response: model.HealthResponse{}},
+
+	// documentation
+	{Method: http.MethodGet, Path: "/openapi.json", Tag: "docs", Summary: "Get this OpenAPI document", Download: "application/json"},
+	{Method: http.MethodGet, Path: "/docs", Tag: "docs", Summary: "Browse this OpenAPI document", Download: "text/html"},
+}
+
+// Query params shared by many endpoints
+var (
+	customerId        = idQuery("customerId", "Id of the customer")
+	serviceLocationId = idQuery("serviceLocationId", "Id of the service location")
+	currentDate       = requiredQuery("currentDate", "Any date of the month, in MM/DD/YYYY format")
+	startDate         = requiredQuery("startDate", "First day, in MM/DD/YYYY format")
+	endDate           = requiredQuery("endDate", "Last day, in MM/DD/YYYY format")
+	status            = enumQuery("status", "Deleted entities are only listed with inactive or all, active by default", "active", "inactive", "all")
+
+	page = []Param{
+		queryParam("cursor", "NextCursor of the previous page"),
+		integerQuery("limit", "Page size, 1 to 200, 50 by default"),
+	}
+
+	auditLogFilters = []Param{
+		enumQuery("actorType", "Who made the change", model.AuditActorCustomer, model.AuditActorAdmin, model.AuditActorSystem),
+		integerQuery("actorId", "Id of the customer or admin who made the change"),
+		integerQuery("impersonatedBy", "Id of the admin who made the change while impersonating the customer"),
+		queryParam("action", "Action, for example update"),
+		queryParam("entityType", "Type of the changed entity"),
+		queryParam("entityId", "Id of the changed entity"),
+		queryParam("requestId", "Id of the request which made the change"),
+		queryParam("startDate", "First day, in MM/DD/YYYY format"),
+		queryParam("endDate", "Last day, in MM/DD/YYYY format"),
+		integerQuery("beforeId", "Only entries older than this id, for paging"),
+	}
+)
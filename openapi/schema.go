@@ -0,0 +1,209 @@
+package openapi
+
+import (
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// schemaGenerator turns Go types into schemas the way encoding/json encodes
+// them. Named structs become components which are referenced by name, and
+// the validate tags of their fields become constraints.
+type schemaGenerator struct {
+	schemas map[string]*Schema
+	names   map[reflect.Type]string
+}
+
+func newSchemaGenerator() *schemaGenerator {
+	return &schemaGenerator{
+		schemas: make(map[string]*Schema),
+		names:   make(map[reflect.Type]string),
+	}
+}
+
+func ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// register adds t as a component under name instead of its Go type name
+func (g *schemaGenerator) register(name string, t reflect.Type) {
+	g.names[t] = name
+	g.schemas[name] = g.structSchema(t)
+}
+
+// componentName is the type name, prefixed with the package name when a
+// type of another package already has it
+func (g *schemaGenerator) componentName(t reflect.Type) string {
+	name := t.Name()
+	if _, taken := g.schemas[name]; taken {
+		pkg := path.Base(t.PkgPath())
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+	return name
+}
+
+func (g *schemaGenerator) schemaOf(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if name, ok := g.names[t]; ok {
+		return ref(name)
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		// the name is taken before the fields are walked so that recursive
+		// types refer to themselves
+		name := g.componentName(t)
+		g.names[t] = name
+		g.schemas[name] = nil
+		g.schemas[name] = g.structSchema(t)
+		return ref(name)
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32", Minimum: float(0)}
+	case reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64", Minimum: float(0)}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	}
+	// interfaces can hold anything
+	return &Schema{}
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	g.addFields(s, t)
+	return s
+}
+
+// addFields adds the fields of t to s, including the fields of embedded
+// structs which encoding/json promotes
+func (g *schemaGenerator) addFields(s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if sf.Anonymous && len(name) == 0 && sf.Type.Kind() == reflect.Struct {
+			g.addFields(s, sf.Type)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if len(name) == 0 {
+			name = sf.Name
+		}
+
+		property := g.schemaOf(sf.Type)
+		if applyRules(property, sf.Tag.Get("validate")) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = property
+	}
+}
+
+// applyRules adds the validation rules of a field to its schema and reports
+// whether the field is required
+func applyRules(s *Schema, tag string) bool {
+	if len(tag) == 0 || tag == "-" {
+		return false
+	}
+
+	required := false
+	rules := strings.Split(tag, ",")
+	for i, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		// referenced schemas cannot have siblings
+		if len(s.Ref) > 0 && name != "required" {
+			continue
+		}
+		switch name {
+		case "required":
+			required = true
+			if s.Type == "string" && s.MinLength == nil {
+				s.MinLength = intPtr(1)
+			}
+		case "min", "max":
+			setBound(s, param, name == "min")
+		case "email":
+			s.Format = "email"
+		case "phone":
+			s.Description = "A phone number"
+		case "oneof":
+			s.Enum = strings.Fields(param)
+		case "datetime":
+			s.Description = "A date in the format " + strings.Join(strings.Split(param, "|"), " or ")
+		case "postalcode":
+			s.Description = "A postal code of the country in " + param
+		case "dive":
+			if s.Items != nil {
+				applyRules(s.Items, strings.Join(rules[i+1:], ","))
+			}
+			return required
+		}
+	}
+	return required
+}
+
+func setBound(s *Schema, param string, isMin bool) {
+	bound, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		return
+	}
+	switch s.Type {
+	case "string":
+		if isMin {
+			s.MinLength = intPtr(int(bound))
+		} else {
+			s.MaxLength = intPtr(int(bound))
+		}
+	case "array":
+		if isMin {
+			s.MinItems = intPtr(int(bound))
+		} else {
+			s.MaxItems = intPtr(int(bound))
+		}
+	case "integer", "number":
+		if isMin {
+			s.Minimum = float(bound)
+		} else {
+			s.Maximum = float(bound)
+		}
+	}
+}
+
+func float(f float64) *float64 {
+	return &f
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,553 @@
+package privacy
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"shems/access"
+	"shems/apierror"
+	"shems/audit"
+	"shems/dbutil"
+	"shems/mail"
+	"shems/model"
+	redisService "shems/redis"
+	"shems/retention"
+	"shems/users"
+	"shems/validation"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const erasureConfirmationTokenExpiry = 24 * time.Hour
+
+type erasureSnapshot struct {
+	ErasureScheduledAt string
+}
+
+type membership struct {
+	ServiceLocationId uint32
+	Role              string
+	OtherMembersCount uint32
+}
+
+func RequestErasure(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client, mailSender mail.Sender, appBaseURL string) {
+	ctx := r.Context()
+	var req model.RequestErasureRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := users.GetOwnSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+	req.CustomerId = session.CustomerId
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	// erasing the account needs two factor authentication when enabled
+	err = users.RequireMfa(ctx, r, db, redisClient, req.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// the password is asked again so that an open session alone cannot erase the account
+	err = users.VerifyPassword(ctx, db, redisClient, req.CustomerId, req.Password)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	var firstName, email string
+	err = db.QueryRowContext(ctx, queryToGetCustomerName(), req.CustomerId).Scan(&firstName, &email)
+	if err == sql.ErrNoRows {
+		apierror.Write(w, r, apierror.NotFound("Customer not found"))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// erasure is only scheduled once the link in the confirmation email is opened
+	token := users.GenerateToken()
+	err = redisService.SetKeyWithExpiry(ctx, redisClient, "ErasureConfirmation_"+users.HashToken(token), req.CustomerId, erasureConfirmationTokenExpiry)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	link := appBaseURL + "/confirmErasure?token=" + url.QueryEscape(token)
+	err = mailSender.Send(ctx, mail.Message{
+		To:      email,
+		Subject: "Confirm the deletion of your account",
+		Body:    fmt.Sprintf("Hi %s,\n\nPlease confirm that your account and all of your data should be deleted by opening the link below within 24 hours. If you did not ask for this, you can ignore this email.\n\n%s\n", firstName, link),
+	})
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Confirmation email sent successfully"})
+}
+
+func ConfirmErasure(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client, mailSender mail.Sender, gracePeriod time.Duration) {
+	ctx := r.Context()
+	var req model.ConfirmErasureRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	val, err := redisService.GetAndDeleteKey(ctx, redisClient, "ErasureConfirmation_"+users.HashToken(req.Token))
+	if err == redis.Nil {
+		apierror.Write(w, r, apierror.BadRequest("Confirmation link is invalid or has expired"))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	customerId, err := strconv.ParseUint(val, 10, 32)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	var id, billingAddressId uint32
+	var firstName, email string
+	var scheduledAt sql.NullString
+	err = tx.QueryRowContext(ctx, queryToGetCustomerForErasure(), customerId).Scan(&id, &firstName, &email, &billingAddressId, &scheduledAt)
+	if err == sql.ErrNoRows {
+		apierror.Write(w, r, apierror.NotFound("Customer not found"))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	erasureScheduledAt := time.Now().Add(gracePeriod).Format(dbutil.TimeLayout)
+	_, err = tx.ExecContext(ctx, queryToScheduleErasure(), erasureScheduledAt, customerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = users.RecordAudit(ctx, tx, r, model.Session{CustomerId: uint32(customerId)}, "schedule erasure", model.AuditEntityCustomer, customerId, erasureSnapshot{scheduledAt.String}, erasureSnapshot{erasureScheduledAt})
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// the customer can still change their mind until the erasure is carried out
+	err = mailSender.Send(ctx, mail.Message{
+		To:      email,
+		Subject: "Your account will be deleted",
+		Body:    fmt.Sprintf("Hi %s,\n\nYour account and all of your data will be deleted on %s. If you change your mind, log in and cancel the deletion before then.\n", firstName, erasureScheduledAt),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "error while sending erasure email", "error", err)
+	}
+
+	resp := model.GetErasureStatusResponse{
+		ErasureScheduledAt: erasureScheduledAt,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func CancelErasure(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := users.GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+	customerId := session.CustomerId
+
+	err := users.RequireMfa(ctx, r, conn, redisClient, customerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	var id, billingAddressId uint32
+	var firstName, email string
+	var scheduledAt sql.NullString
+	err = tx.QueryRowContext(ctx, queryToGetCustomerForErasure(), customerId).Scan(&id, &firstName, &email, &billingAddressId, &scheduledAt)
+	if err == sql.ErrNoRows {
+		apierror.Write(w, r, apierror.NotFound("Customer not found"))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if !scheduledAt.Valid {
+		apierror.Write(w, r, apierror.BadRequest("No erasure is scheduled"))
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, queryToScheduleErasure(), nil, customerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = users.RecordAudit(ctx, tx, r, session, "cancel erasure", model.AuditEntityCustomer, customerId, erasureSnapshot{scheduledAt.String}, erasureSnapshot{})
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Erasure cancelled successfully"})
+}
+
+func GetErasureStatus(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := users.GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	var scheduledAt sql.NullString
+	err := db.QueryRowContext(ctx, queryToGetErasureScheduledAt(), session.CustomerId).Scan(&scheduledAt)
+	if err == sql.ErrNoRows {
+		apierror.Write(w, r, apierror.NotFound("Customer not found"))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	resp := model.GetErasureStatusResponse{
+		ErasureScheduledAt: scheduledAt.String,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// leaveServiceLocation removes the customer from a service location which is
+// shared with others, handing it over to the member who joined first when the
+// customer was its last owner
+func leaveServiceLocation(ctx context.Context, tx *sql.Tx, customerId uint32, m membership) error {
+	var heirId uint32
+	err := tx.QueryRowContext(ctx, queryToGetOldestServiceLocationMember(), m.ServiceLocationId, customerId).Scan(&heirId)
+	if err != nil {
+		return err
+	}
+
+	if m.Role == access.RoleOwner {
+		var ownersCount uint32
+		err = tx.QueryRowContext(ctx, queryToGetServiceLocationOwnersCount(), m.ServiceLocationId, customerId).Scan(&ownersCount)
+		if err != nil {
+			return err
+		}
+		if ownersCount == 0 {
+			_, err = tx.ExecContext(ctx, queryToPromoteServiceLocationMember(), m.ServiceLocationId, heirId)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, queryToTransferServiceLocation(), heirId, m.ServiceLocationId, customerId)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, queryToDeleteServiceLocationMember(), m.ServiceLocationId, customerId)
+	if err != nil {
+		return err
+	}
+
+	return audit.Record(ctx, tx, model.AuditLog{
+		ActorType:  model.AuditActorSystem,
+		Action:     "remove erased customer",
+		EntityType: model.AuditEntityServiceLocationMember,
+		EntityId:   fmt.Sprint(m.ServiceLocationId, "/", customerId),
+	})
+}
+
+// anonymizeLocation blanks out an address unless someone else still uses it
+func anonymizeLocation(ctx context.Context, tx *sql.Tx, locationId, customerId uint32) (bool, error) {
+	var references uint32
+	err := tx.QueryRowContext(ctx, queryToCountLocationReferences(), locationId, customerId, locationId, locationId).Scan(&references)
+	if err != nil {
+		return false, err
+	}
+	if references > 0 {
+		return false, nil
+	}
+
+	_, err = tx.ExecContext(ctx, queryToAnonymizeLocation(), locationId)
+	return err == nil, err
+}
+
+// eraseCustomer anonymizes the customer and deletes everything that only
+// belongs to them. Service locations shared with other members are handed
+// over to them, the others are purged along with their devices and events.
+// It returns the email the customer had and the data export files to remove.
+func eraseCustomer(ctx context.Context, tx *sql.Tx, customerId uint32, now time.Time) (string, string, []string, error) {
+	var id, billingAddressId uint32
+	var firstName, email string
+	var scheduledAt sql.NullString
+	err := tx.QueryRowContext(ctx, queryToGetCustomerForErasure(), customerId).Scan(&id, &firstName, &email, &billingAddressId, &scheduledAt)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	// the erasure was cancelled in the meantime
+	if !scheduledAt.Valid {
+		return "", "", nil, nil
+	}
+
+	rows, err := tx.QueryContext(ctx, queryToGetCustomerMemberships(), customerId)
+	if err != nil {
+		return "", "", nil, err
+	}
+	defer rows.Close()
+
+	var memberships []membership
+	for rows.Next() {
+		var m membership
+		err = rows.Scan(&m.ServiceLocationId, &m.Role, &m.OtherMembersCount)
+		if err != nil {
+			return "", "", nil, err
+		}
+		memberships = append(memberships, m)
+	}
+	if err = rows.Err(); err != nil {
+		return "", "", nil, err
+	}
+	rows.Close()
+
+	var purgedLocationIds []uint32
+	for _, m := range memberships {
+		if m.OtherMembersCount > 0 {
+			err = leaveServiceLocation(ctx, tx, customerId, m)
+			if err != nil {
+				return "", "", nil, err
+			}
+			continue
+		}
+
+		var locationId uint32
+		err = tx.QueryRowContext(ctx, queryToGetServiceLocationLocationId(), m.ServiceLocationId).Scan(&locationId)
+		if err != nil {
+			return "", "", nil, err
+		}
+		err = retention.PurgeServiceLocation(ctx, tx, m.ServiceLocationId)
+		if err != nil {
+			return "", "", nil, err
+		}
+		purgedLocationIds = append(purgedLocationIds, locationId)
+	}
+
+	rows, err = tx.QueryContext(ctx, queryToGetCustomerDataExportFiles(), customerId)
+	if err != nil {
+		return "", "", nil, err
+	}
+	defer rows.Close()
+
+	var exportFiles []string
+	for rows.Next() {
+		var path string
+		err = rows.Scan(&path)
+		if err != nil {
+			return "", "", nil, err
+		}
+		exportFiles = append(exportFiles, path)
+	}
+	if err = rows.Err(); err != nil {
+		return "", "", nil, err
+	}
+	rows.Close()
+
+	for _, query := range queriesToDeleteCustomerData() {
+		_, err = tx.ExecContext(ctx, query, customerId)
+		if err != nil {
+			return "", "", nil, err
+		}
+	}
+	_, err = tx.ExecContext(ctx, queryToDeleteInvitationsByEmail(), email)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	// an address shared with other customers is left alone and the customer
+	// is pointed at a blank one instead
+	anonymized, err := anonymizeLocation(ctx, tx, billingAddressId, customerId)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if !anonymized {
+		result, err := tx.ExecContext(ctx, queryToAddAnonymizedLocation())
+		if err != nil {
+			return "", "", nil, err
+		}
+		lastInsertId, err := result.LastInsertId()
+		if err != nil {
+			return "", "", nil, err
+		}
+		billingAddressId = uint32(lastInsertId)
+	}
+	for _, locationId := range purgedLocationIds {
+		_, err = anonymizeLocation(ctx, tx, locationId, customerId)
+		if err != nil {
+			return "", "", nil, err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, queryToAnonymizeCustomer(), fmt.Sprintf("erased-%d@erased.invalid", customerId), billingAddressId, now.Format(dbutil.TimeLayout), customerId)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, queryToRedactAuditLogIps(), customerId)
+	if err != nil {
+		return "", "", nil, err
+	}
+	_, err = tx.ExecContext(ctx, queryToRedactCustomerAuditLogs(), fmt.Sprint(customerId))
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	err = audit.Record(ctx, tx, model.AuditLog{
+		ActorType:  model.AuditActorSystem,
+		Action:     "erase",
+		EntityType: model.AuditEntityCustomer,
+		EntityId:   fmt.Sprint(customerId),
+	})
+	if err != nil {
+		return "", "", nil, err
+	}
+	return firstName, email, exportFiles, nil
+}
+
+// eraseDueCustomers carries out the erasures whose grace period has passed,
+// each in its own transaction
+func eraseDueCustomers(ctx context.Context, db *sql.DB, redisClient *redis.Client, mailSender mail.Sender, now time.Time) error {
+	customerIds, err := dbutil.GetIds(ctx, db, queryToGetDueErasures(), now.Format(dbutil.TimeLayout))
+	if err != nil {
+		return err
+	}
+
+	for _, customerId := range customerIds {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+
+		firstName, email, exportFiles, err := eraseCustomer(ctx, tx, customerId, now)
+		if err != nil {
+			tx.Rollback()
+			slog.ErrorContext(ctx, "error while erasing customer", "customerId", customerId, "error", err)
+			continue
+		}
+		err = tx.Commit()
+		if err != nil {
+			slog.ErrorContext(ctx, "error while erasing customer", "customerId", customerId, "error", err)
+			continue
+		}
+		if len(email) == 0 {
+			continue
+		}
+
+		for _, path := range exportFiles {
+			err = os.Remove(path)
+			if err != nil && !os.IsNotExist(err) {
+				slog.ErrorContext(ctx, "error while removing data export", "error", err)
+			}
+		}
+
+		err = users.DeleteCustomerSessions(ctx, redisClient, customerId)
+		if err != nil {
+			slog.ErrorContext(ctx, "error while deleting customer sessions", "error", err)
+		}
+
+		err = mailSender.Send(ctx, mail.Message{
+			To:      email,
+			Subject: "Your account has been deleted",
+			Body:    fmt.Sprintf("Hi %s,\n\nYour account and all of your data have been deleted as you asked.\n", firstName),
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "error while sending erasure email", "error", err)
+		}
+	}
+	return nil
+}
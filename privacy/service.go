@@ -0,0 +1,205 @@
+package privacy
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"shems/apierror"
+	"shems/model"
+	"shems/users"
+	"shems/validation"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func RequestDataExport(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := users.GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+	customerId := session.CustomerId
+
+	// exporting personal data needs two factor authentication when enabled
+	err := users.RequireMfa(ctx, r, conn, redisClient, customerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// validation: only one export is prepared at a time
+	var pendingCount uint32
+	err = tx.QueryRowContext(ctx, queryToGetPendingDataExportsCount(), customerId).Scan(&pendingCount)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if pendingCount > 0 {
+		apierror.Write(w, r, apierror.BadRequest("A data export is already being prepared"))
+		return
+	}
+
+	result, err := tx.ExecContext(ctx, queryToAddDataExport(), customerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	exportId, err := result.LastInsertId()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	dataExport := model.DataExport{
+		Id:         uint32(exportId),
+		CustomerId: customerId,
+		Status:     model.DataExportPending,
+	}
+	err = users.RecordAudit(ctx, tx, r, session, "request", model.AuditEntityDataExport, exportId, nil, dataExport)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	resp := model.RequestDataExportResponse{
+		DataExport: dataExport,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func GetDataExports(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := users.GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	rows, err := db.QueryContext(ctx, queryToGetDataExports(), session.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	dataExports := []model.DataExport{}
+	for rows.Next() {
+		var de model.DataExport
+		var completedAt, expiresAt sql.NullString
+		err = rows.Scan(&de.Id, &de.CustomerId, &de.Status, &de.CreatedAt, &completedAt, &expiresAt)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		de.CompletedAt = completedAt.String
+		de.ExpiresAt = expiresAt.String
+		dataExports = append(dataExports, de)
+	}
+
+	resp := model.GetDataExportsResponse{
+		DataExports: dataExports,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func DownloadDataExport(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.DownloadDataExportRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := users.GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	err = users.RequireMfa(ctx, r, db, redisClient, session.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// the password is asked again so that an open session alone cannot take
+	// all of the personal data
+	err = users.VerifyPassword(ctx, db, redisClient, session.CustomerId, req.Password)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	var status, path string
+	err = db.QueryRowContext(ctx, queryToGetDataExportFile(), req.ExportId, session.CustomerId).Scan(&status, &path)
+	if err == sql.ErrNoRows {
+		apierror.Write(w, r, apierror.NotFound("Data export not found or has expired"))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if status != model.DataExportReady || len(path) == 0 {
+		apierror.Write(w, r, apierror.BadRequest("Data export is not ready"))
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer f.Close()
+
+	err = users.RecordAudit(ctx, db, r, session, "download", model.AuditEntityDataExport, req.ExportId, nil, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"shems_data_export_%d.zip\"", req.ExportId))
+	_, err = io.Copy(w, f)
+	if err != nil {
+		slog.ErrorContext(ctx, "error while writing data export", "error", err)
+	}
+}
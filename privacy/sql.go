@@ -0,0 +1,571 @@
+package privacy
+
+import "shems/tracing"
+
+func init() {
+	tracing.RegisterQueries(
+		queryToAddDataExport,
+		queryToGetPendingDataExportsCount,
+		queryToGetDataExports,
+		queryToGetDataExportFile,
+		queryToGetPendingDataExports,
+		queryToCompleteDataExport,
+		queryToGetExpiredDataExportFiles,
+		queryToClearDataExportFile,
+		queryToGetCustomerDataExportFiles,
+		queryToGetCustomerProfile,
+		queryToGetCustomerContact,
+		queryToGetCustomerServiceLocations,
+		queryToGetCustomerEnrolledDevices,
+		queryToGetCustomerEvents,
+		queryToGetCustomerBills,
+		queryToGetCustomerForErasure,
+		queryToGetCustomerName,
+		queryToGetErasureScheduledAt,
+		queryToScheduleErasure,
+		queryToGetDueErasures,
+		queryToGetCustomerMemberships,
+		queryToGetServiceLocationOwnersCount,
+		queryToGetOldestServiceLocationMember,
+		queryToPromoteServiceLocationMember,
+		queryToTransferServiceLocation,
+		queryToGetServiceLocationLocationId,
+		queryToDeleteServiceLocationMember,
+		queryToCountLocationReferences,
+		queryToAnonymizeLocation,
+		queryToAddAnonymizedLocation,
+		queryToAnonymizeCustomer,
+		queryToDeleteInvitationsByEmail,
+		queryToRedactAuditLogIps,
+		queryToRedactCustomerAuditLogs,
+	)
+}
+
+func queryToAddDataExport() string {
+	sqlQuery := `
+				INSERT INTO Data_Exports
+					(customer_id)
+				VALUES
+					(?);
+				`
+	return sqlQuery
+}
+
+func queryToGetPendingDataExportsCount() string {
+	sqlQuery := `
+	SELECT
+		COUNT(*)
+	FROM
+		Data_Exports
+	WHERE
+		customer_id = ?
+		AND status = 'pending';
+	`
+	return sqlQuery
+}
+
+func queryToGetDataExports() string {
+	sqlQuery := `
+	SELECT
+		id, customer_id, status, created_at, completed_at, expires_at
+	FROM
+		Data_Exports
+	WHERE
+		customer_id = ?
+		AND (expires_at IS NULL OR expires_at > NOW())
+	ORDER BY
+		id DESC;
+	`
+	return sqlQuery
+}
+
+func queryToGetDataExportFile() string {
+	sqlQuery := `
+	SELECT
+		status, file_path
+	FROM
+		Data_Exports
+	WHERE
+		id = ?
+		AND customer_id = ?
+		AND (expires_at IS NULL OR expires_at > NOW());
+	`
+	return sqlQuery
+}
+
+func queryToGetPendingDataExports() string {
+	sqlQuery := `
+	SELECT
+		id, customer_id
+	FROM
+		Data_Exports
+	WHERE
+		status = 'pending'
+	ORDER BY
+		id;
+	`
+	return sqlQuery
+}
+
+func queryToCompleteDataExport() string {
+	sqlQuery := `
+				UPDATE
+					Data_Exports
+				SET
+					status = ?,
+					file_path = ?,
+					error = ?,
+					completed_at = ?,
+					expires_at = ?
+				WHERE
+					id = ?;
+				`
+	return sqlQuery
+}
+
+func queryToGetExpiredDataExportFiles() string {
+	sqlQuery := `
+	SELECT
+		id, file_path
+	FROM
+		Data_Exports
+	WHERE
+		expires_at <= ?
+		AND file_path != '';
+	`
+	return sqlQuery
+}
+
+func queryToClearDataExportFile() string {
+	sqlQuery := `
+				UPDATE
+					Data_Exports
+				SET
+					file_path = ''
+				WHERE
+					id = ?;
+				`
+	return sqlQuery
+}
+
+func queryToGetCustomerDataExportFiles() string {
+	sqlQuery := `
+	SELECT
+		file_path
+	FROM
+		Data_Exports
+	WHERE
+		customer_id = ?
+		AND file_path != '';
+	`
+	return sqlQuery
+}
+
+func queryToGetCustomerProfile() string {
+	sqlQuery := `
+	SELECT
+		c.id, c.first_name, c.last_name, c.phone_number, c.email, c.email_verified, c.active,
+		l.unit_number, l.street, l.city, l.state, l.zipcode, l.country,
+		CASE WHEN m.enabled IS NOT NULL THEN m.enabled ELSE 0 END AS mfa_enabled
+	FROM
+		Customers c
+	INNER JOIN
+		Locations l ON l.id = c.billing_address_id
+	LEFT JOIN
+		Customer_Mfa m ON m.customer_id = c.id
+	WHERE
+		c.id = ?;
+	`
+	return sqlQuery
+}
+
+func queryToGetCustomerContact() string {
+	sqlQuery := `
+	SELECT
+		first_name, email
+	FROM
+		Customers
+	WHERE
+		id = ?;
+	`
+	return sqlQuery
+}
+
+func queryToGetCustomerServiceLocations() string {
+	sqlQuery := `
+	SELECT
+		sl.id, sl.date_taken_over, sl.occupants_count, sl.active, sl.deleted_at, slm.role, slm.created_at,
+		l.unit_number, l.street, l.city, l.state, l.zipcode, l.country, l.square_footage, l.bedrooms_count
+	FROM
+		Service_Location_Members slm
+	INNER JOIN
+		Service_Locations sl ON sl.id = slm.service_location_id
+	INNER JOIN
+		Locations l ON l.id = sl.location_id
+	WHERE
+		slm.customer_id = ?
+	ORDER BY
+		sl.id;
+	`
+	return sqlQuery
+}
+
+func queryToGetCustomerEnrolledDevices() string {
+	sqlQuery := `
+	SELECT
+		ed.id, ed.service_location_id, d.type, d.model_number, ed.alias_name, ed.room_number, ed.active, ed.deleted_at
+	FROM
+		Enrolled_Devices ed
+	INNER JOIN
+		Devices d ON d.id = ed.device_id
+	WHERE
+		ed.service_location_id IN (SELECT service_location_id FROM Service_Location_Members WHERE customer_id = ?)
+	ORDER BY
+		ed.service_location_id, ed.id;
+	`
+	return sqlQuery
+}
+
+func queryToGetCustomerEvents() string {
+	sqlQuery := `
+	SELECT
+		e.created_at, edh.service_location_id, e.enrolled_device_id, e.label, e.value
+	FROM
+		Service_Location_Members slm
+	INNER JOIN
+		Enrolled_Device_History edh ON edh.service_location_id = slm.service_location_id
+	INNER JOIN
+		Events e ON e.enrolled_device_id = edh.enrolled_device_id
+			AND e.created_at >= edh.valid_from AND (edh.valid_to IS NULL OR e.created_at < edh.valid_to)
+	WHERE
+		slm.customer_id = ?
+	ORDER BY
+		e.created_at, e.enrolled_device_id;
+	`
+	return sqlQuery
+}
+
+// Bills are the monthly energy costs of each service location
+func queryToGetCustomerBills() string {
+	sqlQuery := `
+	SELECT
+		DATE_FORMAT(e.created_at, '%Y-%m') AS month,
+		sl.id AS service_location_id,
+		SUM(e.value) AS energy_consumption,
+		SUM(CASE WHEN p.value IS NOT NULL THEN e.value * p.value ELSE 0 END) AS energy_cost
+	FROM
+		Service_Location_Members slm
+	INNER JOIN
+		Service_Locations sl ON sl.id = slm.service_location_id
+	INNER JOIN
+		Enrolled_Device_History edh ON edh.service_location_id = sl.id
+	INNER JOIN
+		Service_Location_History slh ON slh.service_location_id = sl.id
+	INNER JOIN
+		Locations l ON l.id = slh.location_id
+	INNER JOIN
+		Events e ON e.enrolled_device_id = edh.enrolled_device_id AND e.label = 'energy use'
+			AND e.created_at >= edh.valid_from AND (edh.valid_to IS NULL OR e.created_at < edh.valid_to)
+			AND e.created_at >= slh.valid_from AND (slh.valid_to IS NULL OR e.created_at < slh.valid_to)
+	LEFT JOIN
+		Prices p ON p.zipcode = l.zipcode AND p.hour = HOUR(e.created_at) + 1
+	WHERE
+		slm.customer_id = ?
+	GROUP BY
+		1, 2
+	ORDER BY
+		1, 2;
+	`
+	return sqlQuery
+}
+
+func queryToGetCustomerForErasure() string {
+	sqlQuery := `
+	SELECT
+		id, first_name, email, billing_address_id, erasure_scheduled_at
+	FROM
+		Customers
+	WHERE
+		id = ?
+		AND erased_at IS NULL
+	FOR UPDATE;
+	`
+	return sqlQuery
+}
+
+func queryToGetCustomerName() string {
+	sqlQuery := `
+	SELECT
+		first_name, email
+	FROM
+		Customers
+	WHERE
+		id = ?
+		AND erased_at IS NULL;
+	`
+	return sqlQuery
+}
+
+func queryToGetErasureScheduledAt() string {
+	sqlQuery := `
+	SELECT
+		erasure_scheduled_at
+	FROM
+		Customers
+	WHERE
+		id = ?;
+	`
+	return sqlQuery
+}
+
+func queryToScheduleErasure() string {
+	sqlQuery := `
+				UPDATE
+					Customers
+				SET
+					erasure_scheduled_at = ?
+				WHERE
+					id = ?;
+				`
+	return sqlQuery
+}
+
+func queryToGetDueErasures() string {
+	sqlQuery := `
+	SELECT
+		id
+	FROM
+		Customers
+	WHERE
+		erasure_scheduled_at <= ?
+		AND erased_at IS NULL;
+	`
+	return sqlQuery
+}
+
+func queryToGetCustomerMemberships() string {
+	sqlQuery := `
+	SELECT
+		slm.service_location_id,
+		slm.role,
+		(SELECT COUNT(*) FROM Service_Location_Members o WHERE o.service_location_id = slm.service_location_id AND o.customer_id != slm.customer_id) AS other_members_count
+	FROM
+		Service_Location_Members slm
+	WHERE
+		slm.customer_id = ?
+	FOR UPDATE;
+	`
+	return sqlQuery
+}
+
+func queryToGetServiceLocationOwnersCount() string {
+	sqlQuery := `
+	SELECT
+		COUNT(*)
+	FROM
+		Service_Location_Members
+	WHERE
+		service_location_id = ?
+		AND customer_id != ?
+		AND role = 'owner';
+	`
+	return sqlQuery
+}
+
+// Service locations are handed over to the owner who joined first, or to the
+// member who joined first when there is no other owner
+func queryToGetOldestServiceLocationMember() string {
+	sqlQuery := `
+	SELECT
+		customer_id
+	FROM
+		Service_Location_Members
+	WHERE
+		service_location_id = ?
+		AND customer_id != ?
+	ORDER BY
+		role = 'owner' DESC, created_at, id
+	LIMIT 1;
+	`
+	return sqlQuery
+}
+
+func queryToPromoteServiceLocationMember() string {
+	sqlQuery := `
+				UPDATE
+					Service_Location_Members
+				SET
+					role = 'owner'
+				WHERE
+					service_location_id = ?
+					AND customer_id = ?;
+				`
+	return sqlQuery
+}
+
+func queryToTransferServiceLocation() string {
+	sqlQuery := `
+				UPDATE
+					Service_Locations
+				SET
+					customer_id = ?
+				WHERE
+					id = ?
+					AND customer_id = ?;
+				`
+	return sqlQuery
+}
+
+func queryToGetServiceLocationLocationId() string {
+	sqlQuery := `
+	SELECT
+		location_id
+	FROM
+		Service_Locations
+	WHERE
+		id = ?;
+	`
+	return sqlQuery
+}
+
+func queryToDeleteServiceLocationMember() string {
+	sqlQuery := `
+				DELETE FROM
+					Service_Location_Members
+				WHERE
+					service_location_id = ?
+					AND customer_id = ?;
+				`
+	return sqlQuery
+}
+
+// Locations are shared by everyone with the same address, so they are only
+// anonymized when nothing else refers to them
+func queryToCountLocationReferences() string {
+	sqlQuery := `
+	SELECT
+		(SELECT COUNT(*) FROM Customers WHERE billing_address_id = ? AND id != ?)
+		+ (SELECT COUNT(*) FROM Service_Locations WHERE location_id = ?)
+		+ (SELECT COUNT(*) FROM Service_Location_History WHERE location_id = ?);
+	`
+	return sqlQuery
+}
+
+func queryToAnonymizeLocation() string {
+	sqlQuery := `
+				UPDATE
+					Locations
+				SET
+					unit_number = 0,
+					street = 0,
+					city = '',
+					state = '',
+					zipcode = '',
+					country = '',
+					square_footage = 0,
+					bedrooms_count = 0
+				WHERE
+					id = ?;
+				`
+	return sqlQuery
+}
+
+func queryToAddAnonymizedLocation() string {
+	sqlQuery := `
+				INSERT INTO Locations
+					(unit_number, street, city, state, zipcode, country, square_footage, bedrooms_count)
+				VALUES
+					(0, 0, '', '', '', '', 0, 0);
+				`
+	return sqlQuery
+}
+
+func queryToAnonymizeCustomer() string {
+	sqlQuery := `
+				UPDATE
+					Customers
+				SET
+					first_name = 'Erased',
+					last_name = 'Customer',
+					phone_number = '',
+					email = ?,
+					billing_address_id = ?,
+					password = '',
+					email_verified = 0,
+					active = 0,
+					erasure_scheduled_at = NULL,
+					erased_at = ?
+				WHERE
+					id = ?;
+				`
+	return sqlQuery
+}
+
+// Rows which only exist for the customer being erased
+func queriesToDeleteCustomerData() []string {
+	return []string{
+		`
+				DELETE FROM
+					Service_Location_Invitations
+				WHERE
+					invited_by = ?;
+				`,
+		`
+				DELETE FROM
+					Mfa_Recovery_Codes
+				WHERE
+					customer_id = ?;
+				`,
+		`
+				DELETE FROM
+					Customer_Mfa
+				WHERE
+					customer_id = ?;
+				`,
+		`
+				DELETE FROM
+					Data_Exports
+				WHERE
+					customer_id = ?;
+				`,
+	}
+}
+
+func queryToDeleteInvitationsByEmail() string {
+	sqlQuery := `
+				DELETE FROM
+					Service_Location_Invitations
+				WHERE
+					email = ?;
+				`
+	return sqlQuery
+}
+
+// The audit trail of the customer is kept, without the personal data in it
+func queryToRedactAuditLogIps() string {
+	sqlQuery := `
+				UPDATE
+					Audit_Logs
+				SET
+					ip = ''
+				WHERE
+					actor_type = 'customer'
+					AND actor_id = ?;
+				`
+	return sqlQuery
+}
+
+func queryToRedactCustomerAuditLogs() string {
+	sqlQuery := `
+				UPDATE
+					Audit_Logs
+				SET
+					details = NULL,
+					before_data = NULL,
+					after_data = NULL
+				WHERE
+					entity_type = 'customer'
+					AND entity_id = ?;
+				`
+	return sqlQuery
+}
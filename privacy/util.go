@@ -0,0 +1,417 @@
+package privacy
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"shems/audit"
+	"shems/dbutil"
+	"shems/mail"
+	"shems/model"
+	redisService "shems/redis"
+	"shems/spreadsheet"
+	"shems/users"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Exports can be downloaded for this long after they are ready
+	dataExportExpiry = 7 * 24 * time.Hour
+
+	auditLogsExportBatchSize = 1000
+)
+
+type profileExport struct {
+	Id             uint32
+	FirstName      string
+	LastName       string
+	PhoneNumber    string
+	Email          string
+	EmailVerified  uint32
+	Active         uint32
+	MfaEnabled     uint32
+	BillingAddress addressExport
+}
+
+type addressExport struct {
+	UnitNumber uint32
+	Street     uint32
+	City       string
+	State      string
+	Zipcode    string
+	Country    string
+}
+
+type serviceLocationExport struct {
+	Id             uint32
+	Address        addressExport
+	SquareFootage  float32
+	BedroomsCount  uint32
+	DateTakenOver  string
+	OccupantsCount uint32
+	Role           string
+	MemberSince    string
+	Active         uint32
+	DeletedAt      string
+}
+
+type enrolledDeviceExport struct {
+	Id                uint32
+	ServiceLocationId uint32
+	Type              string
+	ModelNumber       string
+	AliasName         string
+	RoomNumber        uint32
+	Active            uint32
+	DeletedAt         string
+}
+
+func writeJSON(zw *zip.Writer, name string, value interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func writeProfile(ctx context.Context, db *sql.DB, zw *zip.Writer, customerId uint32) error {
+	var p profileExport
+	a := &p.BillingAddress
+	err := db.QueryRowContext(ctx, queryToGetCustomerProfile(), customerId).Scan(&p.Id, &p.FirstName, &p.LastName, &p.PhoneNumber, &p.Email, &p.EmailVerified, &p.Active, &a.UnitNumber, &a.Street, &a.City, &a.State, &a.Zipcode, &a.Country, &p.MfaEnabled)
+	if err != nil {
+		return err
+	}
+	return writeJSON(zw, "customer.json", p)
+}
+
+func writeServiceLocations(ctx context.Context, db *sql.DB, zw *zip.Writer, customerId uint32) error {
+	rows, err := db.QueryContext(ctx, queryToGetCustomerServiceLocations(), customerId)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	serviceLocations := []serviceLocationExport{}
+	for rows.Next() {
+		var sl serviceLocationExport
+		var deletedAt sql.NullString
+		a := &sl.Address
+		err = rows.Scan(&sl.Id, &sl.DateTakenOver, &sl.OccupantsCount, &sl.Active, &deletedAt, &sl.Role, &sl.MemberSince, &a.UnitNumber, &a.Street, &a.City, &a.State, &a.Zipcode, &a.Country, &sl.SquareFootage, &sl.BedroomsCount)
+		if err != nil {
+			return err
+		}
+		sl.DeletedAt = deletedAt.String
+		serviceLocations = append(serviceLocations, sl)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	return writeJSON(zw, "service_locations.json", serviceLocations)
+}
+
+func writeEnrolledDevices(ctx context.Context, db *sql.DB, zw *zip.Writer, customerId uint32) error {
+	rows, err := db.QueryContext(ctx, queryToGetCustomerEnrolledDevices(), customerId)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	enrolledDevices := []enrolledDeviceExport{}
+	for rows.Next() {
+		var ed enrolledDeviceExport
+		var deletedAt sql.NullString
+		err = rows.Scan(&ed.Id, &ed.ServiceLocationId, &ed.Type, &ed.ModelNumber, &ed.AliasName, &ed.RoomNumber, &ed.Active, &deletedAt)
+		if err != nil {
+			return err
+		}
+		ed.DeletedAt = deletedAt.String
+		enrolledDevices = append(enrolledDevices, ed)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	return writeJSON(zw, "enrolled_devices.json", enrolledDevices)
+}
+
+// writeCSV writes every row of the query as a CSV record, values are written
+// as the driver returns them with formulas escaped
+func writeCSV(ctx context.Context, db *sql.DB, zw *zip.Writer, name string, header []string, query string, args ...interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	csvWriter := csv.NewWriter(f)
+	err = csvWriter.Write(header)
+	if err != nil {
+		return err
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	values := make([]sql.RawBytes, len(header))
+	dest := make([]interface{}, len(header))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	record := make([]string, len(header))
+	for rows.Next() {
+		err = rows.Scan(dest...)
+		if err != nil {
+			return err
+		}
+		for i, value := range values {
+			record[i] = spreadsheet.EscapeFormula(string(value))
+		}
+		err = csvWriter.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// writeAuditLogs writes the entries made by the customer and those about the
+// customer made by support admins
+func writeAuditLogs(ctx context.Context, db *sql.DB, zw *zip.Writer, customerId uint32) error {
+	filters := []model.AuditLogFilter{
+		{ActorType: model.AuditActorCustomer, ActorId: customerId},
+		{EntityType: model.AuditEntityCustomer, EntityId: fmt.Sprint(customerId)},
+	}
+
+	seen := make(map[uint64]bool)
+	auditLogs := []model.AuditLog{}
+	for _, filter := range filters {
+		for {
+			logs, err := audit.GetLogs(ctx, db, filter, auditLogsExportBatchSize)
+			if err != nil {
+				return err
+			}
+
+			for _, entry := range logs {
+				if !seen[entry.Id] {
+					seen[entry.Id] = true
+					auditLogs = append(auditLogs, entry)
+				}
+			}
+
+			if len(logs) < auditLogsExportBatchSize {
+				break
+			}
+			filter.BeforeId = logs[len(logs)-1].Id
+		}
+	}
+	return writeJSON(zw, "audit_logs.json", auditLogs)
+}
+
+// buildDataExport writes everything held on the customer into a ZIP file
+func buildDataExport(ctx context.Context, db *sql.DB, customerId uint32, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	err = writeProfile(ctx, db, zw, customerId)
+	if err != nil {
+		return err
+	}
+	err = writeServiceLocations(ctx, db, zw, customerId)
+	if err != nil {
+		return err
+	}
+	err = writeEnrolledDevices(ctx, db, zw, customerId)
+	if err != nil {
+		return err
+	}
+	err = writeCSV(ctx, db, zw, "events.csv", []string{"CreatedAt", "ServiceLocationId", "EnrolledDeviceId", "Label", "Value"}, queryToGetCustomerEvents(), customerId)
+	if err != nil {
+		return err
+	}
+	err = writeCSV(ctx, db, zw, "bills.csv", []string{"Month", "ServiceLocationId", "EnergyConsumption", "EnergyCost"}, queryToGetCustomerBills(), customerId)
+	if err != nil {
+		return err
+	}
+	err = writeAuditLogs(ctx, db, zw, customerId)
+	if err != nil {
+		return err
+	}
+
+	err = zw.Close()
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+func processDataExport(ctx context.Context, db *sql.DB, mailSender mail.Sender, exportDir string, exportId, customerId uint32) error {
+	err := os.MkdirAll(exportDir, 0o700)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	status := model.DataExportReady
+	path := filepath.Join(exportDir, fmt.Sprintf("%d_%s.zip", exportId, users.GenerateToken()[:16]))
+	var exportErr string
+
+	err = buildDataExport(ctx, db, customerId, path)
+	if err != nil {
+		slog.ErrorContext(ctx, "error while building data export", "exportId", exportId, "error", err)
+		os.Remove(path)
+		status = model.DataExportFailed
+		path = ""
+		exportErr = err.Error()
+		if len(exportErr) > 255 {
+			exportErr = exportErr[:255]
+		}
+	}
+
+	_, err = db.ExecContext(ctx, queryToCompleteDataExport(), status, path, exportErr, now.Format(dbutil.TimeLayout), now.Add(dataExportExpiry).Format(dbutil.TimeLayout), exportId)
+	if err != nil {
+		return err
+	}
+	if status != model.DataExportReady {
+		return nil
+	}
+
+	var firstName, email string
+	err = db.QueryRowContext(ctx, queryToGetCustomerContact(), customerId).Scan(&firstName, &email)
+	if err != nil {
+		return err
+	}
+	return mailSender.Send(ctx, mail.Message{
+		To:      email,
+		Subject: "Your data export is ready",
+		Body:    fmt.Sprintf("Hi %s,\n\nThe export of your data you asked for is ready. You can download it from your account settings within 7 days.\n", firstName),
+	})
+}
+
+func processDataExports(ctx context.Context, db *sql.DB, mailSender mail.Sender, exportDir string) error {
+	rows, err := db.QueryContext(ctx, queryToGetPendingDataExports())
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var exportIds, customerIds []uint32
+	for rows.Next() {
+		var exportId, customerId uint32
+		err = rows.Scan(&exportId, &customerId)
+		if err != nil {
+			return err
+		}
+		exportIds = append(exportIds, exportId)
+		customerIds = append(customerIds, customerId)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for i := range exportIds {
+		err = processDataExport(ctx, db, mailSender, exportDir, exportIds[i], customerIds[i])
+		if err != nil {
+			slog.ErrorContext(ctx, "error while processing data export", "exportId", exportIds[i], "error", err)
+		}
+	}
+	return nil
+}
+
+// removeExpiredDataExports deletes the files of exports which can no longer
+// be downloaded
+func removeExpiredDataExports(ctx context.Context, db *sql.DB, now time.Time) error {
+	rows, err := db.QueryContext(ctx, queryToGetExpiredDataExportFiles(), now.Format(dbutil.TimeLayout))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var exportIds []uint32
+	var paths []string
+	for rows.Next() {
+		var exportId uint32
+		var path string
+		err = rows.Scan(&exportId, &path)
+		if err != nil {
+			return err
+		}
+		exportIds = append(exportIds, exportId)
+		paths = append(paths, path)
+	}
+	if err = rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+
+	for i := range exportIds {
+		err = os.Remove(paths[i])
+		if err != nil && !os.IsNotExist(err) {
+			slog.ErrorContext(ctx, "error while removing data export", "exportId", exportIds[i], "error", err)
+			continue
+		}
+		_, err = db.ExecContext(ctx, queryToClearDataExportFile(), exportIds[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunScheduler prepares requested data exports, removes expired ones and
+// erases the accounts whose grace period has passed, every interval until the
+// context is cancelled. A redis lock makes sure only one server instance does
+// this at a time.
+func RunScheduler(ctx context.Context, db *sql.DB, redisClient *redis.Client, mailSender mail.Sender, exportDir string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	redisKey := "PrivacyScheduler"
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := users.TakeRedisLock(ctx, redisClient, redisKey)
+		if err != nil {
+			continue
+		}
+
+		now := time.Now()
+		err = processDataExports(ctx, db, mailSender, exportDir)
+		if err != nil {
+			slog.ErrorContext(ctx, "error while processing data exports", "error", err)
+		}
+		err = removeExpiredDataExports(ctx, db, now)
+		if err != nil {
+			slog.ErrorContext(ctx, "error while removing expired data exports", "error", err)
+		}
+		err = eraseDueCustomers(ctx, db, redisClient, mailSender, now)
+		if err != nil {
+			slog.ErrorContext(ctx, "error while erasing customers", "error", err)
+		}
+
+		redisService.ReleaseLock(ctx, redisClient, redisKey)
+	}
+}
@@ -0,0 +1,5 @@
+// Package shemsv1 holds the protobuf messages and gRPC services of the gRPC
+// API, generated from the .proto files next to it
+package shemsv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative shems/v1/customer.proto shems/v1/service_location.proto shems/v1/enrolled_device.proto shems/v1/usage.proto shems/v1/event.proto
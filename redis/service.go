@@ -2,6 +2,7 @@ package redisService
 
 import (
 	"context"
+	"log/slog"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -22,3 +23,69 @@ func DeleteKey(ctx context.Context, redisClient *redis.Client, key string) (err
 	resp := redisClient.Del(ctx, key)
 	return resp.Err()
 }
+
+// ReleaseLock deletes a lock taken by users.TakeRedisLock. Locks only expire
+// after the hour SetKey gives them, so the key is deleted even when the
+// request has been canceled. Locks are released in defers which cannot handle
+// errors, so failures are logged.
+func ReleaseLock(ctx context.Context, redisClient *redis.Client, key string) {
+	err := DeleteKey(context.WithoutCancel(ctx), redisClient, key)
+	if err != nil {
+		slog.ErrorContext(ctx, "error while releasing redis lock", "key", key, "error", err)
+	}
+}
+
+func SetKeyWithExpiry(ctx context.Context, redisClient *redis.Client, key string, value interface{}, expiry time.Duration) (err error) {
+	err = redisClient.Set(ctx, key, value, expiry).Err()
+	return
+}
+
+// SetKeyIfAbsent sets a key with an expiry unless it exists, and reports
+// whether it was set
+func SetKeyIfAbsent(ctx context.Context, redisClient *redis.Client, key string, value interface{}, expiry time.Duration) (ok bool, err error) {
+	ok, err = redisClient.SetNX(ctx, key, value, expiry).Result()
+	return
+}
+
+// GetAndDeleteKey reads a key and removes it atomically, which makes tokens single use
+func GetAndDeleteKey(ctx context.Context, redisClient *redis.Client, key string) (val string, err error) {
+	val, err = redisClient.GetDel(ctx, key).Result()
+	return
+}
+
+// IncrementKey increments a counter and starts its expiry when it is created
+func IncrementKey(ctx context.Context, redisClient *redis.Client, key string, expiry time.Duration) (count int64, err error) {
+	count, err = redisClient.Incr(ctx, key).Result()
+	if err != nil {
+		return
+	}
+	if count == 1 {
+		err = redisClient.Expire(ctx, key, expiry).Err()
+	}
+	return
+}
+
+func GetKeyTTL(ctx context.Context, redisClient *redis.Client, key string) (ttl time.Duration, err error) {
+	ttl, err = redisClient.TTL(ctx, key).Result()
+	return
+}
+
+// SetKeyKeepTTL overwrites the value of a key without changing its expiry
+func SetKeyKeepTTL(ctx context.Context, redisClient *redis.Client, key string, value interface{}) (err error) {
+	err = redisClient.SetArgs(ctx, key, value, redis.SetArgs{KeepTTL: true}).Err()
+	return
+}
+
+// AddToSet adds a member to a set and refreshes the expiry of the set
+func AddToSet(ctx context.Context, redisClient *redis.Client, key string, member interface{}, expiry time.Duration) (err error) {
+	pipe := redisClient.TxPipeline()
+	pipe.SAdd(ctx, key, member)
+	pipe.Expire(ctx, key, expiry)
+	_, err = pipe.Exec(ctx)
+	return
+}
+
+func GetSetMembers(ctx context.Context, redisClient *redis.Client, key string) (members []string, err error) {
+	members, err = redisClient.SMembers(ctx, key).Result()
+	return
+}
@@ -0,0 +1,178 @@
+package retention
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"shems/audit"
+	"shems/dbutil"
+	"shems/model"
+	redisService "shems/redis"
+	"shems/users"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func purge(ctx context.Context, tx *sql.Tx, queries []string, id uint32) error {
+	for _, query := range queries {
+		_, err := tx.ExecContext(ctx, query, id)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func purgeEnrolledDevice(ctx context.Context, tx *sql.Tx, enrolledDeviceId uint32) error {
+	err := purge(ctx, tx, queriesToPurgeEnrolledDevice(), enrolledDeviceId)
+	if err != nil {
+		return err
+	}
+
+	return audit.Record(ctx, tx, model.AuditLog{
+		ActorType:  model.AuditActorSystem,
+		Action:     "purge",
+		EntityType: model.AuditEntityEnrolledDevice,
+		EntityId:   fmt.Sprint(enrolledDeviceId),
+	})
+}
+
+// purgeEnrolledDevices permanently deletes enrolled devices which were deleted
+// before cutoff, along with their events. Each device is purged in its own
+// transaction so that one failure does not hold back the rest.
+func purgeEnrolledDevices(ctx context.Context, db *sql.DB, cutoff time.Time) error {
+	ids, err := dbutil.GetIds(ctx, db, queryToGetPurgeableEnrolledDevices(), cutoff.Format(dbutil.TimeLayout))
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+
+		err = purgeEnrolledDevice(ctx, tx, id)
+		if err != nil {
+			tx.Rollback()
+			slog.ErrorContext(ctx, "error while purging enrolled device", "id", id, "error", err)
+			continue
+		}
+		err = tx.Commit()
+		if err != nil {
+			slog.ErrorContext(ctx, "error while purging enrolled device", "id", id, "error", err)
+		}
+	}
+	return nil
+}
+
+// PurgeServiceLocation permanently deletes a service location along with its
+// enrolled devices and everything that belongs to them
+func PurgeServiceLocation(ctx context.Context, tx *sql.Tx, serviceLocationId uint32) error {
+	rows, err := tx.QueryContext(ctx, queryToGetEnrolledDeviceIdsByServiceLocation(), serviceLocationId)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var enrolledDeviceIds []uint32
+	for rows.Next() {
+		var id uint32
+		err = rows.Scan(&id)
+		if err != nil {
+			return err
+		}
+		enrolledDeviceIds = append(enrolledDeviceIds, id)
+	}
+
+	for _, enrolledDeviceId := range enrolledDeviceIds {
+		err = purgeEnrolledDevice(ctx, tx, enrolledDeviceId)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = purge(ctx, tx, queriesToPurgeServiceLocation(), serviceLocationId)
+	if err != nil {
+		return err
+	}
+
+	return audit.Record(ctx, tx, model.AuditLog{
+		ActorType:  model.AuditActorSystem,
+		Action:     "purge",
+		EntityType: model.AuditEntityServiceLocation,
+		EntityId:   fmt.Sprint(serviceLocationId),
+	})
+}
+
+// purgeServiceLocations permanently deletes service locations which were
+// deleted before cutoff, along with everything that belongs to them
+func purgeServiceLocations(ctx context.Context, db *sql.DB, cutoff time.Time) error {
+	ids, err := dbutil.GetIds(ctx, db, queryToGetPurgeableServiceLocations(), cutoff.Format(dbutil.TimeLayout))
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+
+		err = PurgeServiceLocation(ctx, tx, id)
+		if err != nil {
+			tx.Rollback()
+			slog.ErrorContext(ctx, "error while purging service location", "id", id, "error", err)
+			continue
+		}
+		err = tx.Commit()
+		if err != nil {
+			slog.ErrorContext(ctx, "error while purging service location", "id", id, "error", err)
+		}
+	}
+	return nil
+}
+
+// purgeDeleted purges enrolled devices and service locations which have been
+// deleted for longer than the retention period, unless another server
+// instance holds the lock
+func purgeDeleted(ctx context.Context, db *sql.DB, redisClient *redis.Client, redisKey string, retention time.Duration) {
+	err := users.TakeRedisLock(ctx, redisClient, redisKey)
+	if err != nil {
+		return
+	}
+	defer redisService.ReleaseLock(ctx, redisClient, redisKey)
+
+	cutoff := time.Now().Add(-retention)
+	err = purgeEnrolledDevices(ctx, db, cutoff)
+	if err != nil {
+		slog.ErrorContext(ctx, "error while purging enrolled devices", "error", err)
+	}
+	err = purgeServiceLocations(ctx, db, cutoff)
+	if err != nil {
+		slog.ErrorContext(ctx, "error while purging service locations", "error", err)
+	}
+}
+
+// RunScheduler purges enrolled devices and service locations which have been
+// deleted for longer than the retention period, once on startup and then
+// every interval until the context is cancelled. A redis lock makes sure only
+// one server instance purges at a time.
+func RunScheduler(ctx context.Context, db *sql.DB, redisClient *redis.Client, interval, retention time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	redisKey := "RetentionScheduler"
+	purgeDeleted(ctx, db, redisClient, redisKey, retention)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		purgeDeleted(ctx, db, redisClient, redisKey, retention)
+	}
+}
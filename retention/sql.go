@@ -0,0 +1,161 @@
+package retention
+
+import "shems/tracing"
+
+func init() {
+	tracing.RegisterQueries(
+		queryToGetPurgeableEnrolledDevices,
+		queryToGetPurgeableServiceLocations,
+		queryToGetEnrolledDeviceIdsByServiceLocation,
+	)
+}
+
+func queryToGetPurgeableEnrolledDevices() string {
+	sqlQuery := `
+	SELECT
+		id
+	FROM
+		Enrolled_Devices
+	WHERE
+		active = 0
+		AND deleted_at < ?;
+	`
+	return sqlQuery
+}
+
+func queryToGetPurgeableServiceLocations() string {
+	sqlQuery := `
+	SELECT
+		id
+	FROM
+		Service_Locations
+	WHERE
+		active = 0
+		AND deleted_at < ?;
+	`
+	return sqlQuery
+}
+
+func queryToGetEnrolledDeviceIdsByServiceLocation() string {
+	sqlQuery := `
+	SELECT
+		id
+	FROM
+		Enrolled_Devices
+	WHERE
+		service_location_id = ?;
+	`
+	return sqlQuery
+}
+
+// Rows referencing an enrolled device, in the order they have to be deleted
+// so that no foreign key is left dangling
+func queriesToPurgeEnrolledDevice() []string {
+	return []string{
+		`
+				DELETE cs FROM
+					Charging_Schedules cs
+				INNER JOIN
+					Charging_Targets ct ON ct.id = cs.charging_target_id
+				WHERE
+					ct.enrolled_device_id = ?;
+				`,
+		`
+				DELETE FROM
+					Charging_Targets
+				WHERE
+					enrolled_device_id = ?;
+				`,
+		`
+				DELETE FROM
+					Charging_Sessions
+				WHERE
+					enrolled_device_id = ?;
+				`,
+		`
+				DELETE FROM
+					DR_Curtailments
+				WHERE
+					enrolled_device_id = ?;
+				`,
+		`
+				DELETE FROM
+					Enrolled_Device_History
+				WHERE
+					enrolled_device_id = ?;
+				`,
+		`
+				DELETE FROM
+					Events
+				WHERE
+					enrolled_device_id = ?;
+				`,
+		`
+				DELETE FROM
+					Enrolled_Devices
+				WHERE
+					id = ?;
+				`,
+	}
+}
+
+// Rows referencing a service location, in the order they have to be deleted.
+// Its enrolled devices are purged before these. Devices which moved to
+// another service location are kept, but the events they recorded while they
+// were enrolled here go along with the history that attributes them here.
+func queriesToPurgeServiceLocation() []string {
+	return []string{
+		`
+				DELETE e FROM
+					Events e
+				INNER JOIN
+					Enrolled_Device_History edh ON edh.enrolled_device_id = e.enrolled_device_id
+				WHERE
+					edh.service_location_id = ?
+					AND e.created_at >= edh.valid_from
+					AND (edh.valid_to IS NULL OR e.created_at < edh.valid_to);
+				`,
+		`
+				DELETE FROM
+					Enrolled_Device_History
+				WHERE
+					service_location_id = ?;
+				`,
+		`
+				DELETE FROM
+					DR_Enrollments
+				WHERE
+					service_location_id = ?;
+				`,
+		`
+				DELETE FROM
+					DR_Event_Performances
+				WHERE
+					service_location_id = ?;
+				`,
+		`
+				DELETE FROM
+					Service_Location_Members
+				WHERE
+					service_location_id = ?;
+				`,
+		`
+				DELETE FROM
+					Service_Location_Invitations
+				WHERE
+					service_location_id = ?;
+				`,
+		`
+				DELETE FROM
+					Service_Location_History
+				WHERE
+					service_location_id = ?;
+				`,
+		`
+				DELETE FROM
+					Service_Locations
+				WHERE
+					id = ?;
+				`,
+	}
+}
@@ -0,0 +1,28 @@
+package spreadsheet
+
+import (
+	"strconv"
+	"strings"
+)
+
+// EscapeFormula keeps spreadsheet applications from running a cell value as
+// a formula. Values starting with a formula character are prefixed with a
+// quote, unless the whole value is a number such as -5.
+func EscapeFormula(value string) string {
+	if len(value) == 0 || !strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return value
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	return "'" + value
+}
+
+// EscapeFormulas escapes every value of a record, see EscapeFormula
+func EscapeFormulas(record []string) []string {
+	escaped := make([]string, len(record))
+	for i, value := range record {
+		escaped[i] = EscapeFormula(value)
+	}
+	return escaped
+}
@@ -0,0 +1,31 @@
+package spreadsheet_test
+
+import (
+	"shems/spreadsheet"
+	"testing"
+)
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"Kitchen fridge", "Kitchen fridge"},
+		{`=HYPERLINK("http://example.com","x")`, `'=HYPERLINK("http://example.com","x")`},
+		{"+1 555 0100", "'+1 555 0100"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"\r=1", "'\r=1"},
+		{"-5", "-5"},
+		{"+2.5", "+2.5"},
+		{"a=b", "a=b"},
+	}
+
+	for _, tt := range tests {
+		if got := spreadsheet.EscapeFormula(tt.value); got != tt.want {
+			t.Errorf("EscapeFormula(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
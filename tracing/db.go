@@ -0,0 +1,67 @@
+package tracing
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"path"
+	"reflect"
+	"runtime"
+
+	"github.com/XSAM/otelsql"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Attribute with the name of the function which built the query of a span
+const queryBuilderKey = attribute.Key("shems.db.query_builder")
+
+// queryBuilders maps the text of the queries to the name of their builder,
+// like users.queryToGetEnrolledDevices. It is only written from init, so it
+// is read without locking.
+var queryBuilders = make(map[string]string)
+
+// RegisterQueries names the spans of the queries returned by the builders
+// after the builders. Packages register their builders from init. Queries
+// of builders which take arguments, or which are changed before they are
+// run, are named after the database call instead.
+func RegisterQueries(builders ...func() string) {
+	for _, builder := range builders {
+		name := path.Base(runtime.FuncForPC(reflect.ValueOf(builder).Pointer()).Name())
+		query := builder()
+		if _, ok := queryBuilders[query]; !ok {
+			queryBuilders[query] = name
+		}
+	}
+}
+
+func queryBuilder(query string) (string, bool) {
+	name, ok := queryBuilders[query]
+	return name, ok
+}
+
+// OpenDB opens the database with a span for every query, transaction and
+// connection. Spans carry the query text with its placeholders, never the
+// arguments.
+func OpenDB(driverName, dataSourceName string) (*sql.DB, error) {
+	return otelsql.Open(driverName, dataSourceName,
+		otelsql.WithAttributes(semconv.DBSystemMySQL),
+		otelsql.WithSpanNameFormatter(func(ctx context.Context, method otelsql.Method, query string) string {
+			if name, ok := queryBuilder(query); ok {
+				return name
+			}
+			return string(method)
+		}),
+		otelsql.WithAttributesGetter(func(ctx context.Context, method otelsql.Method, query string, args []driver.NamedValue) []attribute.KeyValue {
+			if name, ok := queryBuilder(query); ok {
+				return []attribute.KeyValue{queryBuilderKey.String(name)}
+			}
+			return nil
+		}),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			DisableErrSkip:       true,
+			OmitConnResetSession: true,
+			OmitRows:             true,
+		}),
+	)
+}
@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// redisHook starts a span for every command and pipeline of a client. Keys
+// and values are left out, keys carry customer ids and values tokens.
+type redisHook struct{}
+
+// InstrumentRedis traces the commands of the client
+func InstrumentRedis(redisClient *redis.Client) {
+	redisClient.AddHook(redisHook{})
+}
+
+// endRedisSpan ends the span, recording errors. A missing key is a reply.
+func endRedisSpan(span trace.Span, err error) {
+	if err != nil && err != redis.Nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		ctx, span := startSpan(ctx, "redis dial", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(semconv.DBSystemRedis))
+		conn, err := next(ctx, network, addr)
+		endRedisSpan(span, err)
+		return conn, err
+	}
+}
+
+func (redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		operation := strings.ToUpper(cmd.Name())
+		ctx, span := startSpan(ctx, "redis "+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(semconv.DBSystemRedis, semconv.DBOperationName(operation)))
+		err := next(ctx, cmd)
+		endRedisSpan(span, err)
+		return err
+	}
+}
+
+func (redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		operations := make([]string, 0, len(cmds))
+		for _, cmd := range cmds {
+			operations = append(operations, strings.ToUpper(cmd.Name()))
+		}
+		ctx, span := startSpan(ctx, "redis pipeline", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(semconv.DBSystemRedis, semconv.DBOperationName(strings.Join(operations, " "))))
+		err := next(ctx, cmds)
+		endRedisSpan(span, err)
+		return err
+	}
+}
@@ -0,0 +1,208 @@
+package usage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"math"
+	"shems/apierror"
+	"shems/dbutil"
+	"shems/metrics"
+	"shems/model"
+	shemsv1 "shems/proto/shems/v1"
+	"shems/users"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Largest number of events ingested at once
+const maxIngestEvents = 100000
+
+type UsageServer struct {
+	shemsv1.UnimplementedUsageServiceServer
+	db *sql.DB
+}
+
+func NewUsageServer(db *sql.DB) *UsageServer {
+	return &UsageServer{db: db}
+}
+
+func (s *UsageServer) GetHourlyUsage(req *shemsv1.GetHourlyUsageRequest, stream shemsv1.UsageService_GetHourlyUsageServer) error {
+	ctx := stream.Context()
+	_, session, err := users.GetCall(ctx)
+	if err != nil {
+		return err
+	}
+
+	// both dates are inclusive
+	startDateTime, endDate, err := getDateRange(req.GetStartDate(), req.GetEndDate())
+	if err != nil {
+		return apierror.BadRequest(err.Error())
+	}
+	endDateTime := endDate.AddDate(0, 0, 1).Add(-time.Second)
+
+	level := levelLocation
+	if req.GetLevel() == shemsv1.UsageLevel_USAGE_LEVEL_DEVICE {
+		level = levelDevice
+	}
+
+	// get hourly usage at the requested level, intervals are sent as they are read
+	rows, err := getHourlyUsage(ctx, s.db, level, startDateTime, endDateTime, session.CustomerId)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		u, err := scanUsageRow(rows, level)
+		if err != nil {
+			return err
+		}
+		err = stream.Send(&shemsv1.UsageInterval{
+			IntervalStart:     u.IntervalStart,
+			ServiceLocationId: u.ServiceLocationId,
+			LocationLabel:     u.LocationLabel,
+			EnrolledDeviceId:  u.EnrolledDeviceId,
+			DeviceLabel:       u.DeviceLabel,
+			EnergyConsumption: u.EnergyConsumption,
+			EnergyCost:        u.EnergyCost,
+			CarbonEmissions:   u.CarbonEmissions,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+type EventServer struct {
+	shemsv1.UnimplementedEventServiceServer
+	db          *sql.DB
+	redisClient *redis.Client
+}
+
+func NewEventServer(db *sql.DB, redisClient *redis.Client) *EventServer {
+	return &EventServer{db: db, redisClient: redisClient}
+}
+
+// checkEvents validates events the way parseUsageReadings validates the rows
+// of an import file. Events are numbered from 1.
+func checkEvents(events []*shemsv1.Event, enrolledDeviceIds map[uint32]bool, now time.Time) ([]usageReading, []model.UsageImportError) {
+	var readings []usageReading
+	var importErrors []model.UsageImportError
+	seen := make(map[string]uint32)
+
+	for i, event := range events {
+		index := uint32(i + 1)
+		reading := usageReading{Row: index, EnrolledDeviceId: event.GetEnrolledDeviceId(), Value: event.GetValue()}
+		rowErrors := len(importErrors)
+
+		if !enrolledDeviceIds[reading.EnrolledDeviceId] {
+			importErrors = append(importErrors, model.UsageImportError{Row: index, Field: "enrolled_device_id", Message: "Enrolled Device does not exist"})
+		}
+
+		if event.GetTimestamp() == nil || event.GetTimestamp().CheckValid() != nil {
+			importErrors = append(importErrors, model.UsageImportError{Row: index, Field: "timestamp", Message: "Timestamp is required"})
+		} else {
+			createdAt := event.GetTimestamp().AsTime().In(time.Local)
+			if createdAt.After(now) {
+				importErrors = append(importErrors, model.UsageImportError{Row: index, Field: "timestamp", Message: "Timestamp cannot be in the future"})
+			}
+			reading.CreatedAt = createdAt.Format(dbutil.TimeLayout)
+		}
+
+		if reading.Value < 0 || math.IsNaN(float64(reading.Value)) || math.IsInf(float64(reading.Value), 0) {
+			importErrors = append(importErrors, model.UsageImportError{Row: index, Field: "value", Message: "Value must be a non negative number"})
+		}
+
+		if len(importErrors) > rowErrors {
+			continue
+		}
+
+		key := fmt.Sprint(reading.EnrolledDeviceId, "_", reading.CreatedAt)
+		if first, ok := seen[key]; ok {
+			importErrors = append(importErrors, model.UsageImportError{Row: index, Field: "timestamp", Message: fmt.Sprintf("Duplicate of event %d", first)})
+			continue
+		}
+		seen[key] = index
+
+		readings = append(readings, reading)
+	}
+	return readings, importErrors
+}
+
+// ingestEvents checks the events and adds the valid ones unless it is a dry run
+func (s *EventServer) ingestEvents(ctx context.Context, events []*shemsv1.Event, dryRun bool) (*shemsv1.IngestEventsResponse, error) {
+	_, session, err := users.GetCall(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// get enrolled devices the customer can add readings for
+	enrolledDeviceIds, err := getImportableDeviceIds(ctx, s.db, session.CustomerId)
+	if err != nil {
+		return nil, err
+	}
+
+	readings, importErrors := checkEvents(events, enrolledDeviceIds, time.Now())
+
+	// validation: readings which already exist are rejected, by the import
+	// itself unless it is a dry run
+	var validReadings []usageReading
+	if dryRun {
+		validReadings, importErrors, err = rejectExistingReadings(ctx, s.db, readings, importErrors)
+	} else {
+		validReadings, importErrors, err = addReadings(ctx, s.db, s.redisClient, session.CustomerId, readings, importErrors)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &shemsv1.IngestEventsResponse{
+		DryRun:        dryRun,
+		TotalEvents:   uint32(len(events)),
+		ValidEvents:   uint32(len(validReadings)),
+		InvalidEvents: uint32(len(events) - len(validReadings)),
+	}
+	for _, e := range importErrors {
+		resp.Errors = append(resp.Errors, &shemsv1.EventError{Index: e.Row, Field: e.Field, Message: e.Message})
+	}
+	if !dryRun {
+		resp.AddedEvents = uint32(len(validReadings))
+		metrics.EventsIngested("grpc", len(validReadings))
+	}
+	return resp, nil
+}
+
+func (s *EventServer) RecordEvents(ctx context.Context, req *shemsv1.RecordEventsRequest) (*shemsv1.IngestEventsResponse, error) {
+	if len(req.GetEvents()) > maxIngestEvents {
+		return nil, apierror.BadRequest(fmt.Sprintf("At most %d events can be recorded at once", maxIngestEvents))
+	}
+	return s.ingestEvents(ctx, req.GetEvents(), req.GetDryRun())
+}
+
+func (s *EventServer) IngestEvents(stream shemsv1.EventService_IngestEventsServer) error {
+	// events are checked and added together once the client closes the stream
+	var events []*shemsv1.Event
+	for {
+		event, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if len(events) == maxIngestEvents {
+			return apierror.BadRequest(fmt.Sprintf("At most %d events can be ingested at once", maxIngestEvents))
+		}
+		events = append(events, event)
+	}
+
+	resp, err := s.ingestEvents(stream.Context(), events, false)
+	if err != nil {
+		return err
+	}
+	return stream.SendAndClose(resp)
+}
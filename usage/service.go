@@ -0,0 +1,403 @@
+package usage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"shems/access"
+	"shems/apierror"
+	"shems/metrics"
+	"shems/model"
+	redisService "shems/redis"
+	"shems/users"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Largest accepted import file
+const maxImportSize = 10 << 20
+
+// Readings added by one INSERT statement
+const addReadingsBatchSize = 1000
+
+func ExportUsage(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	// the customer is the one the session belongs to
+	session, ok := users.GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+	customerId := session.CustomerId
+
+	// Get date range from query params, both dates are inclusive
+	startDateTime, endDate, err := getDateRange(r.URL.Query().Get("startDate"), r.URL.Query().Get("endDate"))
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+	endDateTime := endDate.AddDate(0, 0, 1).Add(-time.Second)
+
+	level := r.URL.Query().Get("level")
+	if len(level) == 0 {
+		level = levelLocation
+	}
+	columns, err := getColumns(level, r.URL.Query().Get("columns"))
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if len(format) == 0 {
+		format = "csv"
+	}
+	if format != "csv" && format != "xlsx" {
+		apierror.Write(w, r, apierror.BadRequest("Format must be csv or xlsx"))
+		return
+	}
+
+	// get hourly usage at the requested level
+	rows, err := getHourlyUsage(ctx, db, level, startDateTime, endDateTime, customerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	filename := fmt.Sprintf("usage_%s_%s_%s.%s", level, startDateTime.Format("20060102"), endDate.Format("20060102"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+
+	var writer rowWriter
+	if format == "xlsx" {
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		writer, err = newXlsxWriter(w)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	} else {
+		w.Header().Set("Content-Type", "text/csv")
+		writer = &csvWriter{writer: csv.NewWriter(w)}
+	}
+
+	// rows are streamed as they are read, errors can only be logged from here on
+	header := make([]interface{}, len(columns))
+	for i, column := range columns {
+		header[i] = column
+	}
+	err = writer.Write(header)
+	if err != nil {
+		slog.ErrorContext(ctx, "error while writing usage export", "error", err)
+		return
+	}
+
+	for rows.Next() {
+		u, err := scanUsageRow(rows, level)
+		if err != nil {
+			slog.ErrorContext(ctx, "error while reading usage export", "error", err)
+			return
+		}
+
+		record := make([]interface{}, len(columns))
+		for i, column := range columns {
+			record[i] = usageColumns[column](u)
+		}
+		err = writer.Write(record)
+		if err != nil {
+			slog.ErrorContext(ctx, "error while writing usage export", "error", err)
+			return
+		}
+	}
+
+	err = writer.Close()
+	if err != nil {
+		slog.ErrorContext(ctx, "error while writing usage export", "error", err)
+	}
+}
+
+func ImportUsage(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	// the customer is the one the session belongs to
+	session, ok := users.GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+	customerId := session.CustomerId
+
+	// dry run only validates the file
+	dryRun := false
+	if dryRunStr := r.URL.Query().Get("dryRun"); len(dryRunStr) > 0 {
+		var err error
+		dryRun, err = strconv.ParseBool(dryRunStr)
+		if err != nil {
+			apierror.Write(w, r, apierror.BadRequest("Dry Run must be true or false"))
+			return
+		}
+	}
+
+	// CSV data is either uploaded as a multipart file or sent as the raw body
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+	var reader io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			apierror.Write(w, r, apierror.BadRequest("File is required"))
+			return
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	// get enrolled devices the customer can import readings for
+	enrolledDeviceIds, err := getImportableDeviceIds(ctx, conn, customerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	readings, importErrors, totalRows, err := parseUsageReadings(reader, enrolledDeviceIds, time.Now())
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			apierror.Write(w, r, apierror.New(http.StatusRequestEntityTooLarge, "File is too large"))
+			return
+		}
+		apierror.Write(w, r, apierror.BadRequest("File must be a valid CSV file"))
+		return
+	}
+
+	// validation: readings which already exist are rejected, by the import
+	// itself unless it is a dry run
+	var validReadings []usageReading
+	if dryRun {
+		validReadings, importErrors, err = rejectExistingReadings(ctx, conn, readings, importErrors)
+	} else {
+		validReadings, importErrors, err = addReadings(ctx, conn, redisClient, customerId, readings, importErrors)
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	resp := model.ImportUsageResponse{
+		DryRun:      dryRun,
+		TotalRows:   totalRows,
+		ValidRows:   uint32(len(validReadings)),
+		InvalidRows: totalRows - uint32(len(validReadings)),
+		Errors:      importErrors,
+	}
+	if !dryRun {
+		resp.ImportedRows = uint32(len(validReadings))
+		metrics.EventsIngested("csv", len(validReadings))
+	}
+
+	json.NewEncoder(w).Encode(resp)
+}
+
+// getDateRange parses an inclusive range of dates in MM/DD/YYYY format
+func getDateRange(startDate, endDate string) (time.Time, time.Time, error) {
+	start, err := time.ParseInLocation("01/02/2006", startDate, time.Local)
+	if err != nil {
+		return start, start, fmt.Errorf("Start Date must be in MM/DD/YYYY format")
+	}
+	end, err := time.ParseInLocation("01/02/2006", endDate, time.Local)
+	if err != nil {
+		return start, end, fmt.Errorf("End Date must be in MM/DD/YYYY format")
+	}
+	if end.Before(start) {
+		return start, end, fmt.Errorf("End Date cannot be before Start Date")
+	}
+	return start, end, nil
+}
+
+// getHourlyUsage returns the hourly usage of the service locations the
+// customer is a member of, by service location or by device
+func getHourlyUsage(ctx context.Context, db *sql.DB, level string, startDateTime, endDateTime time.Time, customerId uint32) (*sql.Rows, error) {
+	query := queryToFetchHourlyUsageByServiceLocations()
+	if level == levelDevice {
+		query = queryToFetchHourlyUsageByDevices()
+	}
+	return db.QueryContext(ctx, query, startDateTime, endDateTime, customerId)
+}
+
+func scanUsageRow(rows *sql.Rows, level string) (usageRow, error) {
+	var u usageRow
+	var unitNumber, street uint32
+	var city, state, zipcode, country string
+	var err error
+	if level == levelDevice {
+		var dType, dModelNumber, edAliasName string
+		err = rows.Scan(&u.IntervalStart, &u.ServiceLocationId, &unitNumber, &street, &city, &state, &zipcode, &country, &u.EnrolledDeviceId, &dType, &dModelNumber, &edAliasName, &u.EnergyConsumption, &u.EnergyCost, &u.CarbonEmissions)
+		u.DeviceLabel = fmt.Sprint(edAliasName, " (", dType, " - ", dModelNumber, ")")
+	} else {
+		err = rows.Scan(&u.IntervalStart, &u.ServiceLocationId, &unitNumber, &street, &city, &state, &zipcode, &country, &u.EnergyConsumption, &u.EnergyCost, &u.CarbonEmissions)
+	}
+	u.LocationLabel = fmt.Sprint(unitNumber, ", ", street, ", ", city, ", ", state, ", ", zipcode, ", ", country)
+	return u, err
+}
+
+// getImportableDeviceIds returns the active enrolled devices the customer can
+// add readings for
+func getImportableDeviceIds(ctx context.Context, db *sql.DB, customerId uint32) (map[uint32]bool, error) {
+	rows, err := db.QueryContext(ctx, queryToGetEnrolledDeviceIds(), customerId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	enrolledDeviceIds := make(map[uint32]bool)
+	for rows.Next() {
+		var id uint32
+		var role string
+		err = rows.Scan(&id, &role)
+		if err != nil {
+			return nil, err
+		}
+		if access.HasPermission(role, access.ManageDevices) {
+			enrolledDeviceIds[id] = true
+		}
+	}
+	return enrolledDeviceIds, rows.Err()
+}
+
+// querier is satisfied by both *sql.DB and *sql.Tx
+type querier interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// rejectExistingReadings adds an error for every reading which is already
+// stored and returns the others. Stored readings are read once per device,
+// over the time range of its readings.
+func rejectExistingReadings(ctx context.Context, db querier, readings []usageReading, importErrors []model.UsageImportError) ([]usageReading, []model.UsageImportError, error) {
+	// timestamps are in the database layout, so they compare as strings
+	type timeRange struct {
+		first, last string
+	}
+	ranges := make(map[uint32]timeRange)
+	for _, reading := range readings {
+		tr, ok := ranges[reading.EnrolledDeviceId]
+		if !ok || reading.CreatedAt < tr.first {
+			tr.first = reading.CreatedAt
+		}
+		if !ok || reading.CreatedAt > tr.last {
+			tr.last = reading.CreatedAt
+		}
+		ranges[reading.EnrolledDeviceId] = tr
+	}
+
+	existing := make(map[string]bool)
+	for enrolledDeviceId, tr := range ranges {
+		rows, err := db.QueryContext(ctx, queryToGetEventTimestamps(), enrolledDeviceId, tr.first, tr.last)
+		if err != nil {
+			return nil, nil, err
+		}
+		for rows.Next() {
+			var createdAt string
+			err = rows.Scan(&createdAt)
+			if err != nil {
+				rows.Close()
+				return nil, nil, err
+			}
+			existing[fmt.Sprint(enrolledDeviceId, "_", createdAt)] = true
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var validReadings []usageReading
+	for _, reading := range readings {
+		if existing[fmt.Sprint(reading.EnrolledDeviceId, "_", reading.CreatedAt)] {
+			importErrors = append(importErrors, model.UsageImportError{Row: reading.Row, Field: "timestamp", Message: "Reading already exists"})
+			continue
+		}
+		validReadings = append(validReadings, reading)
+	}
+	return validReadings, importErrors, nil
+}
+
+// addReadings stores the readings which do not exist yet together, and
+// returns them with an error for each of the others. The devices of the
+// readings stay locked until they are stored, so that concurrent imports,
+// also by other members of the service location, cannot add them twice.
+func addReadings(ctx context.Context, conn *sql.DB, redisClient *redis.Client, customerId uint32, readings []usageReading, importErrors []model.UsageImportError) ([]usageReading, []model.UsageImportError, error) {
+	if len(readings) == 0 {
+		return nil, importErrors, nil
+	}
+
+	redisKey := "ImportUsage_CustomerId_" + fmt.Sprint(customerId)
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer func() {
+		// release redis lock
+		redisService.ReleaseLock(ctx, redisClient, redisKey)
+
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// take redis lock to avoid concurrent imports of the customer
+	err = users.TakeRedisLock(ctx, redisClient, redisKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var enrolledDeviceIds []interface{}
+	seen := make(map[uint32]bool)
+	for _, reading := range readings {
+		if !seen[reading.EnrolledDeviceId] {
+			seen[reading.EnrolledDeviceId] = true
+			enrolledDeviceIds = append(enrolledDeviceIds, reading.EnrolledDeviceId)
+		}
+	}
+	rows, err := tx.QueryContext(ctx, queryToLockEnrolledDevices(len(enrolledDeviceIds)), enrolledDeviceIds...)
+	if err != nil {
+		return nil, nil, err
+	}
+	rows.Close()
+
+	// validation: readings which already exist are rejected
+	validReadings, importErrors, err := rejectExistingReadings(ctx, tx, readings, importErrors)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for start := 0; start < len(validReadings); start += addReadingsBatchSize {
+		batch := validReadings[start:min(start+addReadingsBatchSize, len(validReadings))]
+		args := make([]interface{}, 0, 3*len(batch))
+		for _, reading := range batch {
+			args = append(args, reading.EnrolledDeviceId, reading.Value, reading.CreatedAt)
+		}
+		_, err = tx.ExecContext(ctx, queryToAddEvents(len(batch)), args...)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, nil, err
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+	return validReadings, importErrors, nil
+}
@@ -0,0 +1,160 @@
+package usage
+
+import (
+	"fmt"
+	"shems/tracing"
+	"strings"
+)
+
+func init() {
+	tracing.RegisterQueries(
+		queryToFetchHourlyUsageByServiceLocations,
+		queryToFetchHourlyUsageByDevices,
+		queryToGetEnrolledDeviceIds,
+		queryToGetEventTimestamps,
+	)
+}
+
+func queryToFetchHourlyUsageByServiceLocations() string {
+	sqlQuery := `
+	SELECT
+		DATE_FORMAT(e.created_at, '%Y-%m-%d %H:00:00') AS interval_start,
+		sl.id AS service_location_id,
+		l.unit_number,
+		l.street,
+		l.city,
+		l.state,
+		l.zipcode,
+		l.country,
+		SUM(e.value) AS energy_consumption,
+		SUM(CASE WHEN p.value IS NOT NULL THEN e.value * p.value ELSE 0 END) AS energy_cost,
+		SUM(CASE WHEN ci.value IS NOT NULL THEN e.value * ci.value ELSE 0 END) / 1000 AS carbon_emissions
+	FROM
+		Service_Locations sl
+	INNER JOIN
+		Enrolled_Device_History edh ON edh.service_location_id = sl.id
+	INNER JOIN
+		Service_Location_History slh ON slh.service_location_id = sl.id
+	INNER JOIN
+		Locations l ON l.id = slh.location_id
+	INNER JOIN
+		Events e ON e.enrolled_device_id = edh.enrolled_device_id AND e.label = 'energy use' AND e.created_at >= ? AND e.created_at <= ?
+			AND e.created_at >= edh.valid_from AND (edh.valid_to IS NULL OR e.created_at < edh.valid_to)
+			AND e.created_at >= slh.valid_from AND (slh.valid_to IS NULL OR e.created_at < slh.valid_to)
+	LEFT JOIN
+		Prices p ON p.zipcode = l.zipcode AND p.hour = HOUR(e.created_at) + 1
+	LEFT JOIN
+		Carbon_Intensities ci ON ci.zipcode = l.zipcode AND ci.hour = HOUR(e.created_at) + 1
+	WHERE
+		sl.id IN (SELECT service_location_id FROM Service_Location_Members WHERE customer_id = ?)
+	GROUP BY
+		1, 2, 3, 4, 5, 6, 7, 8
+	ORDER BY
+		1, 2;
+	`
+	return sqlQuery
+}
+
+func queryToFetchHourlyUsageByDevices() string {
+	sqlQuery := `
+	SELECT
+		DATE_FORMAT(e.created_at, '%Y-%m-%d %H:00:00') AS interval_start,
+		sl.id AS service_location_id,
+		l.unit_number,
+		l.street,
+		l.city,
+		l.state,
+		l.zipcode,
+		l.country,
+		ed.id AS enrolled_device_id,
+		d.type,
+		d.model_number,
+		ed.alias_name,
+		SUM(e.value) AS energy_consumption,
+		SUM(CASE WHEN p.value IS NOT NULL THEN e.value * p.value ELSE 0 END) AS energy_cost,
+		SUM(CASE WHEN ci.value IS NOT NULL THEN e.value * ci.value ELSE 0 END) / 1000 AS carbon_emissions
+	FROM
+		Enrolled_Devices ed
+	INNER JOIN
+		Enrolled_Device_History edh ON edh.enrolled_device_id = ed.id
+	INNER JOIN
+		Service_Locations sl ON sl.id = edh.service_location_id
+	INNER JOIN
+		Service_Location_History slh ON slh.service_location_id = sl.id
+	INNER JOIN
+		Locations l ON l.id = slh.location_id
+	INNER JOIN
+		Devices d ON d.id = ed.device_id
+	INNER JOIN
+		Events e ON e.enrolled_device_id = ed.id AND e.label = 'energy use' AND e.created_at >= ? AND e.created_at <= ?
+			AND e.created_at >= edh.valid_from AND (edh.valid_to IS NULL OR e.created_at < edh.valid_to)
+			AND e.created_at >= slh.valid_from AND (slh.valid_to IS NULL OR e.created_at < slh.valid_to)
+	LEFT JOIN
+		Prices p ON p.zipcode = l.zipcode AND p.hour = HOUR(e.created_at) + 1
+	LEFT JOIN
+		Carbon_Intensities ci ON ci.zipcode = l.zipcode AND ci.hour = HOUR(e.created_at) + 1
+	WHERE
+		sl.id IN (SELECT service_location_id FROM Service_Location_Members WHERE customer_id = ?)
+	GROUP BY
+		1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12
+	ORDER BY
+		1, 2, 9;
+	`
+	return sqlQuery
+}
+
+func queryToGetEnrolledDeviceIds() string {
+	sqlQuery := `
+	SELECT
+		ed.id, slm.role
+	FROM
+		Enrolled_Devices ed
+	INNER JOIN
+		Service_Location_Members slm ON slm.service_location_id = ed.service_location_id
+	WHERE
+		slm.customer_id = ?
+		AND ed.active = 1;
+	`
+	return sqlQuery
+}
+
+// queryToLockEnrolledDevices locks count enrolled devices, so that readings
+// of a device are checked and added by one import at a time
+func queryToLockEnrolledDevices(count int) string {
+	sqlQuery := `
+	SELECT
+		id
+	FROM
+		Enrolled_Devices
+	WHERE
+		id IN (%s)
+	FOR UPDATE;
+	`
+	return fmt.Sprintf(sqlQuery, strings.TrimSuffix(strings.Repeat("?, ", count), ", "))
+}
+
+func queryToGetEventTimestamps() string {
+	sqlQuery := `
+	SELECT
+		created_at
+	FROM
+		Events
+	WHERE
+		enrolled_device_id = ?
+		AND label = 'energy use'
+		AND created_at >= ?
+		AND created_at <= ?;
+	`
+	return sqlQuery
+}
+
+// queryToAddEvents adds count readings in one statement
+func queryToAddEvents(count int) string {
+	sqlQuery := `
+				INSERT INTO Events
+					(enrolled_device_id, label, value, created_at)
+				VALUES
+					%s;
+				`
+	return fmt.Sprintf(sqlQuery, strings.TrimSuffix(strings.Repeat("(?, 'energy use', ?, ?), ", count), ", "))
+}
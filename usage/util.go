@@ -0,0 +1,191 @@
+package usage
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"shems/dbutil"
+	"shems/model"
+	"shems/spreadsheet"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Timestamps accepted in imported files
+var importTimeLayouts = []string{dbutil.TimeLayout, time.RFC3339, "01/02/2006 15:04"}
+
+const (
+	levelLocation = "location"
+	levelDevice   = "device"
+)
+
+type usageRow struct {
+	IntervalStart     string
+	ServiceLocationId uint32
+	LocationLabel     string
+	EnrolledDeviceId  uint32
+	DeviceLabel       string
+	EnergyConsumption float32
+	EnergyCost        float32
+	CarbonEmissions   float32
+}
+
+var usageColumns = map[string]func(usageRow) interface{}{
+	"interval_start":      func(u usageRow) interface{} { return u.IntervalStart },
+	"service_location_id": func(u usageRow) interface{} { return u.ServiceLocationId },
+	"location_label":      func(u usageRow) interface{} { return u.LocationLabel },
+	"enrolled_device_id":  func(u usageRow) interface{} { return u.EnrolledDeviceId },
+	"device_label":        func(u usageRow) interface{} { return u.DeviceLabel },
+	"energy_consumption":  func(u usageRow) interface{} { return u.EnergyConsumption },
+	"energy_cost":         func(u usageRow) interface{} { return u.EnergyCost },
+	"carbon_emissions":    func(u usageRow) interface{} { return u.CarbonEmissions },
+}
+
+// Default columns of each export level, in order
+var levelColumns = map[string][]string{
+	levelLocation: {"interval_start", "service_location_id", "location_label", "energy_consumption", "energy_cost", "carbon_emissions"},
+	levelDevice:   {"interval_start", "service_location_id", "location_label", "enrolled_device_id", "device_label", "energy_consumption", "energy_cost", "carbon_emissions"},
+}
+
+// getColumns validates a comma separated column selection against the
+// columns available at the export level
+func getColumns(level, columnsParam string) ([]string, error) {
+	available, ok := levelColumns[level]
+	if !ok {
+		return nil, fmt.Errorf("Level must be %s or %s", levelLocation, levelDevice)
+	}
+	if len(columnsParam) == 0 {
+		return available, nil
+	}
+
+	var columns []string
+	for _, column := range strings.Split(columnsParam, ",") {
+		column = strings.TrimSpace(column)
+		found := false
+		for _, a := range available {
+			if a == column {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("Column %q is not available, available columns are %s", column, strings.Join(available, ", "))
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+type rowWriter interface {
+	Write(record []interface{}) error
+	Close() error
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func (c *csvWriter) Write(record []interface{}) error {
+	values := make([]string, len(record))
+	for i, value := range record {
+		values[i] = spreadsheet.EscapeFormula(fmt.Sprint(value))
+	}
+	return c.writer.Write(values)
+}
+
+func (c *csvWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+type usageReading struct {
+	Row              uint32
+	EnrolledDeviceId uint32
+	CreatedAt        string
+	Value            float32
+}
+
+func parseTimestamp(value string) (time.Time, error) {
+	var err error
+	for _, layout := range importTimeLayouts {
+		var t time.Time
+		t, err = time.ParseInLocation(layout, value, time.Local)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// parseUsageReadings validates every enrolled_device_id,timestamp,value row
+// of an import file and returns the valid readings along with errors of the
+// invalid rows. Row numbers start at 1 and include the optional header row.
+func parseUsageReadings(reader io.Reader, enrolledDeviceIds map[uint32]bool, now time.Time) ([]usageReading, []model.UsageImportError, uint32, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	var readings []usageReading
+	var importErrors []model.UsageImportError
+	var totalRows uint32
+	seen := make(map[string]uint32)
+
+	for row := uint32(1); ; row++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, 0, err
+		}
+		if row == 1 && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), "enrolled_device_id") {
+			continue
+		}
+		totalRows++
+
+		if len(record) != 3 {
+			importErrors = append(importErrors, model.UsageImportError{Row: row, Message: "Row must have enrolled_device_id, timestamp and value"})
+			continue
+		}
+
+		reading := usageReading{Row: row}
+		rowErrors := len(importErrors)
+
+		enrolledDeviceId, err := strconv.ParseUint(strings.TrimSpace(record[0]), 10, 32)
+		if err != nil {
+			importErrors = append(importErrors, model.UsageImportError{Row: row, Field: "enrolled_device_id", Message: "Enrolled Device Id must be a number"})
+		} else if !enrolledDeviceIds[uint32(enrolledDeviceId)] {
+			importErrors = append(importErrors, model.UsageImportError{Row: row, Field: "enrolled_device_id", Message: "Enrolled Device does not exist"})
+		}
+		reading.EnrolledDeviceId = uint32(enrolledDeviceId)
+
+		createdAt, err := parseTimestamp(strings.TrimSpace(record[1]))
+		if err != nil {
+			importErrors = append(importErrors, model.UsageImportError{Row: row, Field: "timestamp", Message: "Timestamp must be in YYYY-MM-DD HH:MM:SS format"})
+		} else if createdAt.After(now) {
+			importErrors = append(importErrors, model.UsageImportError{Row: row, Field: "timestamp", Message: "Timestamp cannot be in the future"})
+		}
+		reading.CreatedAt = createdAt.Format(dbutil.TimeLayout)
+
+		value, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 32)
+		if err != nil || value < 0 {
+			importErrors = append(importErrors, model.UsageImportError{Row: row, Field: "value", Message: "Value must be a non negative number"})
+		}
+		reading.Value = float32(value)
+
+		if len(importErrors) > rowErrors {
+			continue
+		}
+
+		key := fmt.Sprint(reading.EnrolledDeviceId, "_", reading.CreatedAt)
+		if firstRow, ok := seen[key]; ok {
+			importErrors = append(importErrors, model.UsageImportError{Row: row, Field: "timestamp", Message: fmt.Sprintf("Duplicate of row %d", firstRow)})
+			continue
+		}
+		seen[key] = row
+
+		readings = append(readings, reading)
+	}
+	return readings, importErrors, totalRows, nil
+}
@@ -0,0 +1,512 @@
+package users
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"shems/apierror"
+	"shems/mail"
+	"shems/model"
+	redisService "shems/redis"
+	"shems/validation"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	emailVerificationTokenExpiry = 24 * time.Hour
+	passwordResetTokenExpiry     = time.Hour
+
+	// Login attempts allowed per client ip per minute
+	maxLoginAttemptsPerIp = 20
+
+	// Requests which send an account email, like password resets, allowed
+	// per client ip per minute and per email per hour
+	maxAccountEmailRequestsPerIp = 10
+	maxAccountEmailsPerEmail     = 3
+	accountEmailsWindow          = time.Hour
+
+	// Failed logins of an email, or wrong authentication codes of a customer,
+	// before they get locked, every further failure doubles the lock duration
+	// up to maxLoginLockDuration
+	loginLockoutThreshold = 5
+	baseLoginLockDuration = time.Minute
+	maxLoginLockDuration  = 24 * time.Hour
+	failedLoginsExpiry    = 24 * time.Hour
+)
+
+var dummyPasswordHash string
+var dummyPasswordHashOnce sync.Once
+
+// getDummyPasswordHash returns a hash to compare against when the email does
+// not exist, so that unknown emails take as long as wrong passwords
+func getDummyPasswordHash() string {
+	dummyPasswordHashOnce.Do(func() {
+		dummyPasswordHash, _ = GetPasswordHash(GenerateToken())
+	})
+	return dummyPasswordHash
+}
+
+func GenerateToken() string {
+	bytes := make([]byte, 32)
+	rand.Read(bytes)
+	return hex.EncodeToString(bytes)
+}
+
+// Tokens are only stored hashed so that redis contents cannot be used to
+// verify emails or reset passwords
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NormalizeEmail returns the form of an email which accounts are looked up by
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func GetClientIp(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Rate limiting and lockout fail open when redis is unavailable
+func isRateLimited(ctx context.Context, redisClient *redis.Client, key string, limit int64, window time.Duration) bool {
+	count, err := redisService.IncrementKey(ctx, redisClient, key, window)
+	if err != nil {
+		slog.ErrorContext(ctx, "error while incrementing redis key", "error", err)
+		return false
+	}
+	return count > limit
+}
+
+func isLoginRateLimited(ctx context.Context, redisClient *redis.Client, ip string) bool {
+	return isRateLimited(ctx, redisClient, "LoginAttempts_Ip_"+ip, maxLoginAttemptsPerIp, time.Minute)
+}
+
+// isAccountEmailRateLimited limits the requests of an action which sends an
+// account email per client ip and per email, so that it can neither flood an
+// inbox nor probe many addresses. The limits are the same for known and
+// unknown emails.
+func isAccountEmailRateLimited(ctx context.Context, redisClient *redis.Client, action, ip, email string) bool {
+	return isRateLimited(ctx, redisClient, action+"_Ip_"+ip, maxAccountEmailRequestsPerIp, time.Minute) ||
+		isRateLimited(ctx, redisClient, action+"_Email_"+email, maxAccountEmailsPerEmail, accountEmailsWindow)
+}
+
+func isLocked(ctx context.Context, redisClient *redis.Client, lockKey string) bool {
+	ttl, err := redisService.GetKeyTTL(ctx, redisClient, lockKey)
+	if err != nil {
+		slog.ErrorContext(ctx, "error while getting redis key ttl", "error", err)
+		return false
+	}
+	return ttl > 0
+}
+
+// recordFailure counts a failure and takes the lock once there were
+// loginLockoutThreshold failures, for longer with every further failure
+func recordFailure(ctx context.Context, redisClient *redis.Client, failuresKey, lockKey string) {
+	failures, err := redisService.IncrementKey(ctx, redisClient, failuresKey, failedLoginsExpiry)
+	if err != nil {
+		slog.ErrorContext(ctx, "error while incrementing redis key", "error", err)
+		return
+	}
+	if failures < loginLockoutThreshold {
+		return
+	}
+
+	lockDuration := maxLoginLockDuration
+	if shift := failures - loginLockoutThreshold; shift < 12 {
+		lockDuration = baseLoginLockDuration << shift
+		if lockDuration > maxLoginLockDuration {
+			lockDuration = maxLoginLockDuration
+		}
+	}
+
+	err = redisService.SetKeyWithExpiry(ctx, redisClient, lockKey, true, lockDuration)
+	if err != nil {
+		slog.ErrorContext(ctx, "error while setting redis key", "error", err)
+	}
+}
+
+func clearFailures(ctx context.Context, redisClient *redis.Client, failuresKey, lockKey string) {
+	err := redisService.DeleteKey(ctx, redisClient, failuresKey)
+	if err != nil {
+		slog.ErrorContext(ctx, "error while deleting redis key", "error", err)
+	}
+	err = redisService.DeleteKey(ctx, redisClient, lockKey)
+	if err != nil {
+		slog.ErrorContext(ctx, "error while deleting redis key", "error", err)
+	}
+}
+
+func isLoginLocked(ctx context.Context, redisClient *redis.Client, email string) bool {
+	return isLocked(ctx, redisClient, "LoginLock_Email_"+email)
+}
+
+func recordFailedLogin(ctx context.Context, redisClient *redis.Client, email string) {
+	recordFailure(ctx, redisClient, "LoginFailures_Email_"+email, "LoginLock_Email_"+email)
+}
+
+func clearFailedLogins(ctx context.Context, redisClient *redis.Client, email string) {
+	clearFailures(ctx, redisClient, "LoginFailures_Email_"+email, "LoginLock_Email_"+email)
+}
+
+// VerifyPassword asks a signed in customer for their password again before a
+// sensitive action. Wrong passwords count towards the same lockout as logging
+// in.
+func VerifyPassword(ctx context.Context, db *sql.DB, redisClient *redis.Client, customerId uint32, password string) error {
+	customer, err := getCustomerById(ctx, db, customerId)
+	if err != nil {
+		return err
+	}
+	email := NormalizeEmail(customer.Email)
+	if isLoginLocked(ctx, redisClient, email) {
+		return apierror.Locked("Too many failed login attempts, please try again later")
+	}
+	if customer.Id == 0 || !CheckPasswordHash(password, customer.Password) {
+		recordFailedLogin(ctx, redisClient, email)
+		return apierror.Unauthorized("Invalid password")
+	}
+	clearFailedLogins(ctx, redisClient, email)
+	return nil
+}
+
+// Wrong authentication codes are counted per customer rather than per login,
+// so that logging in again with the password does not allow more guesses
+func isMfaLocked(ctx context.Context, redisClient *redis.Client, customerId uint32) bool {
+	return isLocked(ctx, redisClient, "MfaLock_CustomerId_"+fmt.Sprint(customerId))
+}
+
+func recordFailedMfa(ctx context.Context, redisClient *redis.Client, customerId uint32) {
+	recordFailure(ctx, redisClient, "MfaFailures_CustomerId_"+fmt.Sprint(customerId), "MfaLock_CustomerId_"+fmt.Sprint(customerId))
+}
+
+func clearFailedMfa(ctx context.Context, redisClient *redis.Client, customerId uint32) {
+	clearFailures(ctx, redisClient, "MfaFailures_CustomerId_"+fmt.Sprint(customerId), "MfaLock_CustomerId_"+fmt.Sprint(customerId))
+}
+
+func sendVerificationEmail(ctx context.Context, redisClient *redis.Client, mailSender mail.Sender, appBaseURL string, customer model.Customer) error {
+	token := GenerateToken()
+	err := redisService.SetKeyWithExpiry(ctx, redisClient, "EmailVerification_"+HashToken(token), customer.Id, emailVerificationTokenExpiry)
+	if err != nil {
+		return err
+	}
+
+	link := appBaseURL + "/verifyEmail?token=" + url.QueryEscape(token)
+	return mailSender.Send(ctx, mail.Message{
+		To:      customer.Email,
+		Subject: "Verify your email address",
+		Body:    fmt.Sprintf("Hi %s,\n\nPlease verify your email address by opening the link below within 24 hours.\n\n%s\n", customer.FirstName, link),
+	})
+}
+
+func sendPasswordResetEmail(ctx context.Context, redisClient *redis.Client, mailSender mail.Sender, appBaseURL string, customer model.Customer) error {
+	token := GenerateToken()
+	err := redisService.SetKeyWithExpiry(ctx, redisClient, "PasswordReset_"+HashToken(token), customer.Id, passwordResetTokenExpiry)
+	if err != nil {
+		return err
+	}
+
+	link := appBaseURL + "/resetPassword?token=" + url.QueryEscape(token)
+	return mailSender.Send(ctx, mail.Message{
+		To:      customer.Email,
+		Subject: "Reset your password",
+		Body:    fmt.Sprintf("Hi %s,\n\nYou can choose a new password by opening the link below within 1 hour. If you did not ask for this, you can ignore this email.\n\n%s\n", customer.FirstName, link),
+	})
+}
+
+// sendRegistrationNoticeEmail tells the owner of an email that someone tried
+// to register with it, instead of telling the one registering
+func sendRegistrationNoticeEmail(ctx context.Context, mailSender mail.Sender, appBaseURL string, customer model.Customer) error {
+	link := appBaseURL + "/forgotPassword"
+	return mailSender.Send(ctx, mail.Message{
+		To:      customer.Email,
+		Subject: "You already have an account",
+		Body:    fmt.Sprintf("Hi %s,\n\nSomeone tried to register with your email address, but you already have an account. If this was you, you can log in, or choose a new password by opening the link below. If it was not, you can ignore this email.\n\n%s\n", customer.FirstName, link),
+	})
+}
+
+// consumeToken returns the customer id stored for a token and deletes it
+func consumeToken(ctx context.Context, redisClient *redis.Client, prefix string, token string) (uint32, error) {
+	val, err := redisService.GetAndDeleteKey(ctx, redisClient, prefix+HashToken(token))
+	if err != nil {
+		return 0, err
+	}
+	customerId, err := strconv.ParseUint(val, 10, 32)
+	return uint32(customerId), err
+}
+
+func getCustomerByEmail(ctx context.Context, db *sql.DB, email string) (model.Customer, error) {
+	var customer model.Customer
+	err := db.QueryRowContext(ctx, queryToGetCustomerByEmail(), email).Scan(&customer.Id, &customer.FirstName, &customer.LastName, &customer.PhoneNumber, &customer.Email, &customer.BillingAddressId, &customer.Password, &customer.EmailVerified, &customer.Active)
+	if err == sql.ErrNoRows {
+		return customer, nil
+	}
+	return customer, err
+}
+
+func VerifyEmail(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.VerifyEmailRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	customerId, err := consumeToken(ctx, redisClient, "EmailVerification_", req.Token)
+	if err == redis.Nil {
+		apierror.Write(w, r, apierror.BadRequest("Verification link is invalid or has expired"))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	before, err := getCustomerSnapshot(ctx, tx, customerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, queryToVerifyCustomerEmail(), customerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	after := before
+	after.EmailVerified = 1
+	err = RecordAudit(ctx, tx, r, model.Session{CustomerId: customerId}, "verify email", model.AuditEntityCustomer, customerId, before, after)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Email verified successfully"})
+}
+
+func ResendVerificationEmail(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client, mailSender mail.Sender, appBaseURL string) {
+	ctx := r.Context()
+	var req model.ResendVerificationEmailRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+
+	// emails are stored normalized, and lookups, lockouts and rate limits all
+	// use the same form
+	req.Email = NormalizeEmail(req.Email)
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	if isAccountEmailRateLimited(ctx, redisClient, "ResendVerificationEmail", GetClientIp(r), req.Email) {
+		apierror.Write(w, r, apierror.TooManyRequests("Too many requests, please try again later"))
+		return
+	}
+
+	customer, err := getCustomerByEmail(ctx, db, req.Email)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	if customer.Id > 0 && customer.EmailVerified == 0 {
+		err = sendVerificationEmail(ctx, redisClient, mailSender, appBaseURL, customer)
+		if err != nil {
+			slog.ErrorContext(ctx, "error while sending verification email", "error", err)
+		}
+	}
+
+	// same response whether or not the email exists
+	json.NewEncoder(w).Encode(map[string]string{"message": "If the account exists and is not verified, a verification email has been sent"})
+}
+
+func ForgotPassword(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client, mailSender mail.Sender, appBaseURL string) {
+	ctx := r.Context()
+	var req model.ForgotPasswordRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+
+	// emails are stored normalized, and lookups, lockouts and rate limits all
+	// use the same form
+	req.Email = NormalizeEmail(req.Email)
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	if isAccountEmailRateLimited(ctx, redisClient, "ForgotPassword", GetClientIp(r), req.Email) {
+		apierror.Write(w, r, apierror.TooManyRequests("Too many requests, please try again later"))
+		return
+	}
+
+	customer, err := getCustomerByEmail(ctx, db, req.Email)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	if customer.Id > 0 {
+		err = sendPasswordResetEmail(ctx, redisClient, mailSender, appBaseURL, customer)
+		if err != nil {
+			slog.ErrorContext(ctx, "error while sending password reset email", "error", err)
+		}
+	}
+
+	// same response whether or not the email exists
+	json.NewEncoder(w).Encode(map[string]string{"message": "If the account exists, a password reset email has been sent"})
+}
+
+func ResetPassword(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.ResetPasswordRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	customerId, err := consumeToken(ctx, redisClient, "PasswordReset_", req.Token)
+	if err == redis.Nil {
+		apierror.Write(w, r, apierror.BadRequest("Password reset link is invalid or has expired"))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	passwordHash, err := GetPasswordHash(req.Password)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	before, err := getCustomerSnapshot(ctx, tx, customerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, queryToUpdateCustomerPassword(), passwordHash, customerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// a successful reset proves the customer owns the email
+	_, err = tx.ExecContext(ctx, queryToVerifyCustomerEmail(), customerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// password hashes are never written to the audit log
+	after := before
+	after.EmailVerified = 1
+	err = RecordAudit(ctx, tx, r, model.Session{CustomerId: customerId}, "reset password", model.AuditEntityCustomer, customerId, before, after)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// and lifts any lockout of the account
+	clearFailedLogins(ctx, redisClient, NormalizeEmail(before.Email))
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Password reset successfully"})
+}
@@ -0,0 +1,93 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"shems/audit"
+	"shems/model"
+)
+
+// Snapshots of rows as stored in audit log entries. Secrets like password
+// hashes, tokens and two factor authentication secrets are never included.
+type enrolledDeviceSnapshot struct {
+	Id                uint32
+	ServiceLocationId uint32
+	DeviceId          uint32
+	AliasName         string
+	RoomNumber        uint32
+	Active            uint32
+}
+
+type serviceLocationSnapshot struct {
+	Id             uint32
+	LocationId     uint32
+	DateTakenOver  string
+	OccupantsCount uint32
+	Active         uint32
+}
+
+type memberSnapshot struct {
+	ServiceLocationId uint32
+	CustomerId        uint32
+	Role              string
+}
+
+type invitationSnapshot struct {
+	ServiceLocationId uint32
+	Email             string
+	Role              string
+}
+
+type mfaSnapshot struct {
+	Enabled uint32
+}
+
+type customerSnapshot struct {
+	Id               uint32
+	FirstName        string
+	LastName         string
+	PhoneNumber      string
+	Email            string
+	BillingAddressId uint32
+	EmailVerified    uint32
+	Active           uint32
+}
+
+// RecordAudit writes an audit log entry of a change made in the session of a
+// customer, along with the support admin impersonating the customer if any.
+// It is called with the transaction of the change so that both are written or
+// neither is.
+func RecordAudit(ctx context.Context, db audit.Execer, r *http.Request, session model.Session, action, entityType string, entityId interface{}, before, after interface{}) error {
+	return audit.Record(ctx, db, model.AuditLog{
+		ActorType:      model.AuditActorCustomer,
+		ActorId:        session.CustomerId,
+		ImpersonatedBy: session.ImpersonatedBy,
+		Action:         action,
+		EntityType:     entityType,
+		EntityId:       fmt.Sprint(entityId),
+		Before:         before,
+		After:          after,
+		Ip:             GetClientIp(r),
+		RequestId:      audit.GetRequestId(r),
+	})
+}
+
+func getEnrolledDeviceSnapshot(ctx context.Context, tx *sql.Tx, enrolledDeviceId uint32) (enrolledDeviceSnapshot, error) {
+	var ed enrolledDeviceSnapshot
+	err := tx.QueryRowContext(ctx, queryToGetEnrolledDeviceForUpdate(), enrolledDeviceId).Scan(&ed.Id, &ed.ServiceLocationId, &ed.DeviceId, &ed.AliasName, &ed.RoomNumber, &ed.Active)
+	return ed, err
+}
+
+func getServiceLocationSnapshot(ctx context.Context, tx *sql.Tx, serviceLocationId uint32) (serviceLocationSnapshot, error) {
+	var sl serviceLocationSnapshot
+	err := tx.QueryRowContext(ctx, queryToGetServiceLocationForUpdate(), serviceLocationId).Scan(&sl.Id, &sl.LocationId, &sl.DateTakenOver, &sl.OccupantsCount, &sl.Active)
+	return sl, err
+}
+
+func getCustomerSnapshot(ctx context.Context, tx *sql.Tx, customerId uint32) (customerSnapshot, error) {
+	var c customerSnapshot
+	err := tx.QueryRowContext(ctx, queryToGetCustomerForUpdate(), customerId).Scan(&c.Id, &c.FirstName, &c.LastName, &c.PhoneNumber, &c.Email, &c.BillingAddressId, &c.EmailVerified, &c.Active)
+	return c, err
+}
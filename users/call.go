@@ -0,0 +1,35 @@
+package users
+
+import (
+	"context"
+	"net/http"
+	"shems/apierror"
+	"shems/model"
+)
+
+// The gRPC services run the same business logic as the handlers, which reads
+// the bearer token, client ip and request id from an *http.Request. Each call
+// is translated into such a request, which is carried in the context of the
+// call along with its session.
+
+type callKey struct{}
+
+type call struct {
+	r       *http.Request
+	session model.Session
+}
+
+// WithCall returns a context carrying the request a gRPC call was translated
+// into and the session of the call
+func WithCall(ctx context.Context, r *http.Request, session model.Session) context.Context {
+	return context.WithValue(ctx, callKey{}, call{r: r, session: session})
+}
+
+// GetCall returns the request and the session of a gRPC call
+func GetCall(ctx context.Context) (*http.Request, model.Session, error) {
+	c, ok := ctx.Value(callKey{}).(call)
+	if !ok {
+		return nil, model.Session{}, apierror.Unauthorized(ErrNoSession.Error())
+	}
+	return c.r, c.session, nil
+}
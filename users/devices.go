@@ -0,0 +1,115 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"shems/apierror"
+	"shems/model"
+)
+
+// addEnrolledDevice enrolls the device of the request in its service location
+// and returns the id of the enrolled device. The caller checks that the
+// customer may manage devices of the service location.
+func addEnrolledDevice(ctx context.Context, tx *sql.Tx, r *http.Request, session model.Session, req model.EnrolledDevice) (uint32, error) {
+	// validation: devices cannot be enrolled in a deleted service location
+	sl, err := getServiceLocationSnapshot(ctx, tx, req.ServiceLocationId)
+	if err != nil {
+		return 0, err
+	}
+	if sl.Active == 0 {
+		return 0, apierror.BadRequest("Service location is deleted")
+	}
+
+	// insert query
+	query := queryToAddEnrolledDevice()
+	result, err := tx.ExecContext(ctx, query, req.ServiceLocationId, req.DeviceId, req.AliasName, req.RoomNumber)
+	if err != nil {
+		return 0, err
+	}
+	lastInsertId, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	enrolledDeviceId := uint32(lastInsertId)
+
+	err = startEnrolledDeviceHistory(ctx, tx, enrolledDeviceId, req.ServiceLocationId)
+	if err != nil {
+		return 0, err
+	}
+
+	after, err := getEnrolledDeviceSnapshot(ctx, tx, enrolledDeviceId)
+	if err != nil {
+		return 0, err
+	}
+	err = RecordAudit(ctx, tx, r, session, "add", model.AuditEntityEnrolledDevice, enrolledDeviceId, nil, after)
+	if err != nil {
+		return 0, err
+	}
+	return enrolledDeviceId, nil
+}
+
+// updateEnrolledDevice changes the enrolled device of the request, moving it
+// when its service location changed. The caller checks that the customer may
+// manage devices of both service locations.
+func updateEnrolledDevice(ctx context.Context, tx *sql.Tx, r *http.Request, session model.Session, req model.EnrolledDevice) error {
+	// validation: deleted devices have to be restored before they are changed,
+	// and cannot be moved to a deleted service location
+	before, err := getEnrolledDeviceSnapshot(ctx, tx, req.Id)
+	if err != nil {
+		return err
+	}
+	if before.Active == 0 {
+		return apierror.BadRequest("Enrolled device is deleted")
+	}
+	sl, err := getServiceLocationSnapshot(ctx, tx, req.ServiceLocationId)
+	if err != nil {
+		return err
+	}
+	if sl.Active == 0 {
+		return apierror.BadRequest("Service location is deleted")
+	}
+
+	// update enrolled device
+	query := queryToUpdateEnrolledDevice()
+	_, err = tx.ExecContext(ctx, query, req.ServiceLocationId, req.DeviceId, req.AliasName, req.RoomNumber, req.Id)
+	if err != nil {
+		return err
+	}
+
+	// past consumption stays with the service location the device was in
+	if before.ServiceLocationId != req.ServiceLocationId {
+		err = moveEnrolledDeviceHistory(ctx, tx, req.Id, req.ServiceLocationId)
+		if err != nil {
+			return err
+		}
+	}
+
+	after, err := getEnrolledDeviceSnapshot(ctx, tx, req.Id)
+	if err != nil {
+		return err
+	}
+	return RecordAudit(ctx, tx, r, session, "update", model.AuditEntityEnrolledDevice, req.Id, before, after)
+}
+
+// restoreDeletedEnrolledDevice restores a device which was deleted on its own.
+// Devices of a deleted service location come back with the service location.
+func restoreDeletedEnrolledDevice(ctx context.Context, tx *sql.Tx, r *http.Request, session model.Session, enrolledDeviceId uint32) error {
+	// validation: only deleted devices of active service locations can be restored
+	ed, err := getEnrolledDeviceSnapshot(ctx, tx, enrolledDeviceId)
+	if err != nil {
+		return err
+	}
+	if ed.Active == 1 {
+		return apierror.BadRequest("Enrolled device is not deleted")
+	}
+	sl, err := getServiceLocationSnapshot(ctx, tx, ed.ServiceLocationId)
+	if err != nil {
+		return err
+	}
+	if sl.Active == 0 {
+		return apierror.BadRequest("Service location is deleted, restore it first")
+	}
+
+	return restoreEnrolledDevice(ctx, tx, r, session, enrolledDeviceId)
+}
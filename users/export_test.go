@@ -0,0 +1,5 @@
+package users
+
+var GetTotpCode = getTotpCode
+var ValidateTotpCode = validateTotpCode
+var VerifyTotpCode = verifyTotpCode
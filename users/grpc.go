@@ -0,0 +1,485 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"shems/apierror"
+	"shems/model"
+	shemsv1 "shems/proto/shems/v1"
+	"shems/validation"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// The gRPC services follow the v2 API: the customer is the one of the session
+// and updates need the ETag of the resource, which is the same as in the v2
+// API.
+
+// getPageSize is the counterpart of the limit query param of the v2 API
+func getPageSize(pageSize int32) (int, error) {
+	if pageSize == 0 {
+		return defaultPageSize, nil
+	}
+	if pageSize < 0 || pageSize > maxPageSize {
+		return 0, fmt.Errorf("Page size must be between 1 and %d", maxPageSize)
+	}
+	return int(pageSize), nil
+}
+
+func toAddressMessage(l model.Location) *shemsv1.Address {
+	if l.Id == 0 {
+		return nil
+	}
+	return &shemsv1.Address{
+		Id:            l.Id,
+		UnitNumber:    l.UnitNumber,
+		Street:        l.Street,
+		City:          l.City,
+		State:         l.State,
+		Zipcode:       l.Zipcode,
+		Country:       l.Country,
+		SquareFootage: l.SquareFootage,
+		BedroomsCount: l.BedroomsCount,
+	}
+}
+
+func toCustomerMessage(customer model.Customer, billingAddress model.Location) *shemsv1.Customer {
+	return &shemsv1.Customer{
+		Id:             customer.Id,
+		FirstName:      customer.FirstName,
+		LastName:       customer.LastName,
+		PhoneNumber:    customer.PhoneNumber,
+		Email:          customer.Email,
+		EmailVerified:  customer.EmailVerified == 1,
+		BillingAddress: toAddressMessage(billingAddress),
+	}
+}
+
+func toServiceLocationMessage(sl model.ServiceLocation) (*shemsv1.ServiceLocation, error) {
+	etag, err := getETag(sl)
+	if err != nil {
+		return nil, err
+	}
+	return &shemsv1.ServiceLocation{
+		Id:             sl.Id,
+		CustomerId:     sl.CustomerId,
+		DateTakenOver:  sl.DateTakenOver,
+		OccupantsCount: sl.OccupantsCount,
+		UnitNumber:     sl.UnitNumber,
+		Street:         sl.Street,
+		City:           sl.City,
+		State:          sl.State,
+		Zipcode:        string(sl.Zipcode),
+		Country:        sl.Country,
+		SquareFootage:  sl.SquareFootage,
+		BedroomsCount:  sl.BedroomsCount,
+		Active:         sl.Active == 1,
+		DeletedAt:      sl.DeletedAt,
+		Role:           sl.Role,
+		Etag:           etag,
+	}, nil
+}
+
+func fromServiceLocationFields(f *shemsv1.ServiceLocationFields) model.ServiceLocation {
+	return model.ServiceLocation{
+		DateTakenOver:  f.GetDateTakenOver(),
+		OccupantsCount: f.GetOccupantsCount(),
+		UnitNumber:     f.GetUnitNumber(),
+		Street:         f.GetStreet(),
+		City:           f.GetCity(),
+		State:          f.GetState(),
+		Zipcode:        validation.NormalizePostalCode(model.PostalCode(f.GetZipcode())),
+		Country:        f.GetCountry(),
+		SquareFootage:  f.GetSquareFootage(),
+		BedroomsCount:  f.GetBedroomsCount(),
+	}
+}
+
+func toEnrolledDeviceMessage(ed model.EnrolledDevice) (*shemsv1.EnrolledDevice, error) {
+	etag, err := getETag(ed)
+	if err != nil {
+		return nil, err
+	}
+	return &shemsv1.EnrolledDevice{
+		Id:                ed.Id,
+		ServiceLocationId: ed.ServiceLocationId,
+		DeviceId:          ed.DeviceId,
+		AliasName:         ed.AliasName,
+		RoomNumber:        ed.RoomNumber,
+		DeviceType:        ed.DeviceType,
+		Device:            ed.Device,
+		Active:            ed.Active == 1,
+		DeletedAt:         ed.DeletedAt,
+		Etag:              etag,
+	}, nil
+}
+
+type CustomerServer struct {
+	shemsv1.UnimplementedCustomerServiceServer
+	db          *sql.DB
+	redisClient *redis.Client
+}
+
+func NewCustomerServer(db *sql.DB, redisClient *redis.Client) *CustomerServer {
+	return &CustomerServer{db: db, redisClient: redisClient}
+}
+
+func (s *CustomerServer) GetCustomer(ctx context.Context, req *shemsv1.GetCustomerRequest) (*shemsv1.Customer, error) {
+	_, session, err := GetCall(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	customer, err := getCustomerById(ctx, s.db, session.CustomerId)
+	if err != nil {
+		return nil, err
+	}
+	if customer.Id == 0 {
+		return nil, apierror.NotFound("Customer not found")
+	}
+
+	l, err := getBillingAddress(ctx, s.db, customer)
+	if err != nil {
+		return nil, err
+	}
+	return toCustomerMessage(customer, l), nil
+}
+
+func (s *CustomerServer) UpdateCustomer(ctx context.Context, req *shemsv1.UpdateCustomerRequest) (*shemsv1.Customer, error) {
+	r, session, err := GetCall(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// validate the request
+	profile := model.UpdateProfileRequest{
+		CustomerId:  session.CustomerId,
+		FirstName:   strings.TrimSpace(req.GetFirstName()),
+		LastName:    strings.TrimSpace(req.GetLastName()),
+		PhoneNumber: strings.TrimSpace(req.GetPhoneNumber()),
+	}
+	if errs := validation.Validate(profile); len(errs) > 0 {
+		return nil, errs
+	}
+
+	customer, err := getCustomerById(ctx, s.db, session.CustomerId)
+	if err != nil {
+		return nil, err
+	}
+	if customer.Id == 0 {
+		return nil, apierror.NotFound("Customer not found")
+	}
+	l, err := getBillingAddress(ctx, s.db, customer)
+	if err != nil {
+		return nil, err
+	}
+
+	rollback := true
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	err = updateProfile(ctx, tx, r, session, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// respond with the updated customer
+	customer.FirstName = profile.FirstName
+	customer.LastName = profile.LastName
+	customer.PhoneNumber = profile.PhoneNumber
+	return toCustomerMessage(customer, l), nil
+}
+
+type ServiceLocationServer struct {
+	shemsv1.UnimplementedServiceLocationServiceServer
+	db          *sql.DB
+	redisClient *redis.Client
+}
+
+func NewServiceLocationServer(db *sql.DB, redisClient *redis.Client) *ServiceLocationServer {
+	return &ServiceLocationServer{db: db, redisClient: redisClient}
+}
+
+func (s *ServiceLocationServer) ListServiceLocations(ctx context.Context, req *shemsv1.ListServiceLocationsRequest) (*shemsv1.ListServiceLocationsResponse, error) {
+	_, session, err := GetCall(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get filters and page from the request
+	limit, err := getPageSize(req.GetPageSize())
+	if err != nil {
+		return nil, apierror.BadRequest(err.Error())
+	}
+	filter := serviceLocationFilter{
+		Status:  req.GetStatus(),
+		City:    req.GetCity(),
+		State:   req.GetState(),
+		Zipcode: req.GetZipcode(),
+	}
+
+	page, err := listServiceLocationResources(ctx, s.db, session.CustomerId, filter, req.GetPageToken(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &shemsv1.ListServiceLocationsResponse{NextPageToken: page.NextCursor}
+	for _, sl := range page.ServiceLocations {
+		message, err := toServiceLocationMessage(sl)
+		if err != nil {
+			return nil, err
+		}
+		resp.ServiceLocations = append(resp.ServiceLocations, message)
+	}
+	return resp, nil
+}
+
+func (s *ServiceLocationServer) GetServiceLocation(ctx context.Context, req *shemsv1.GetServiceLocationRequest) (*shemsv1.ServiceLocation, error) {
+	_, session, err := GetCall(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sl, err := getServiceLocation(ctx, s.db, session.CustomerId, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return toServiceLocationMessage(sl)
+}
+
+func (s *ServiceLocationServer) CreateServiceLocation(ctx context.Context, req *shemsv1.CreateServiceLocationRequest) (*shemsv1.ServiceLocation, error) {
+	r, session, err := GetCall(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// validate the request
+	sl := fromServiceLocationFields(req.GetServiceLocation())
+	sl.CustomerId = session.CustomerId
+	if errs := validation.Validate(sl); len(errs) > 0 {
+		return nil, errs
+	}
+
+	// add service location
+	sl, err = addServiceLocationResource(ctx, s.db, s.redisClient, r, session, sl)
+	if err != nil {
+		return nil, err
+	}
+	return toServiceLocationMessage(sl)
+}
+
+func (s *ServiceLocationServer) UpdateServiceLocation(ctx context.Context, req *shemsv1.UpdateServiceLocationRequest) (*shemsv1.ServiceLocation, error) {
+	r, session, err := GetCall(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// validate the request
+	sl := fromServiceLocationFields(req.GetServiceLocation())
+	sl.Id = req.GetId()
+	sl.CustomerId = session.CustomerId
+	if errs := validation.Validate(sl); len(errs) > 0 {
+		return nil, errs
+	}
+	if len(req.GetEtag()) == 0 {
+		return nil, apierror.PreconditionRequired("Etag is required")
+	}
+
+	// update service location
+	sl, err = updateServiceLocationResource(ctx, s.db, s.redisClient, r, session, sl, req.GetEtag())
+	if err != nil {
+		return nil, err
+	}
+	return toServiceLocationMessage(sl)
+}
+
+func (s *ServiceLocationServer) DeleteServiceLocation(ctx context.Context, req *shemsv1.DeleteServiceLocationRequest) (*shemsv1.DeleteServiceLocationResponse, error) {
+	r, session, err := GetCall(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// delete service location
+	err = deleteServiceLocationResource(ctx, s.db, s.redisClient, r, session, req.GetId(), req.GetEtag())
+	if err != nil {
+		return nil, err
+	}
+	return &shemsv1.DeleteServiceLocationResponse{}, nil
+}
+
+func (s *ServiceLocationServer) RestoreServiceLocation(ctx context.Context, req *shemsv1.RestoreServiceLocationRequest) (*shemsv1.ServiceLocation, error) {
+	r, session, err := GetCall(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// restore service location
+	sl, err := restoreServiceLocationResource(ctx, s.db, r, session, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return toServiceLocationMessage(sl)
+}
+
+type EnrolledDeviceServer struct {
+	shemsv1.UnimplementedEnrolledDeviceServiceServer
+	db          *sql.DB
+	redisClient *redis.Client
+}
+
+func NewEnrolledDeviceServer(db *sql.DB, redisClient *redis.Client) *EnrolledDeviceServer {
+	return &EnrolledDeviceServer{db: db, redisClient: redisClient}
+}
+
+func (s *EnrolledDeviceServer) ListEnrolledDevices(ctx context.Context, req *shemsv1.ListEnrolledDevicesRequest) (*shemsv1.ListEnrolledDevicesResponse, error) {
+	_, session, err := GetCall(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get filters and page from the request
+	limit, err := getPageSize(req.GetPageSize())
+	if err != nil {
+		return nil, apierror.BadRequest(err.Error())
+	}
+	filter := enrolledDeviceFilter{
+		Status:     req.GetStatus(),
+		DeviceType: req.GetDeviceType(),
+	}
+
+	page, err := listEnrolledDeviceResources(ctx, s.db, session.CustomerId, req.GetServiceLocationId(), filter, req.GetPageToken(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &shemsv1.ListEnrolledDevicesResponse{NextPageToken: page.NextCursor}
+	for _, ed := range page.EnrolledDevices {
+		message, err := toEnrolledDeviceMessage(ed)
+		if err != nil {
+			return nil, err
+		}
+		resp.EnrolledDevices = append(resp.EnrolledDevices, message)
+	}
+	return resp, nil
+}
+
+func (s *EnrolledDeviceServer) GetEnrolledDevice(ctx context.Context, req *shemsv1.GetEnrolledDeviceRequest) (*shemsv1.EnrolledDevice, error) {
+	_, session, err := GetCall(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ed, err := getEnrolledDeviceResource(ctx, s.db, session.CustomerId, req.GetServiceLocationId(), req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return toEnrolledDeviceMessage(ed)
+}
+
+func (s *EnrolledDeviceServer) CreateEnrolledDevice(ctx context.Context, req *shemsv1.CreateEnrolledDeviceRequest) (*shemsv1.EnrolledDevice, error) {
+	r, session, err := GetCall(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// validate the request
+	ed := model.EnrolledDevice{
+		ServiceLocationId: req.GetServiceLocationId(),
+		DeviceId:          req.GetDeviceId(),
+		AliasName:         req.GetAliasName(),
+		RoomNumber:        req.GetRoomNumber(),
+		CustomerId:        session.CustomerId,
+	}
+	if errs := validation.Validate(ed); len(errs) > 0 {
+		return nil, errs
+	}
+
+	// enroll device
+	ed, err = addEnrolledDeviceResource(ctx, s.db, s.redisClient, r, session, ed)
+	if err != nil {
+		return nil, err
+	}
+	return toEnrolledDeviceMessage(ed)
+}
+
+func (s *EnrolledDeviceServer) UpdateEnrolledDevice(ctx context.Context, req *shemsv1.UpdateEnrolledDeviceRequest) (*shemsv1.EnrolledDevice, error) {
+	r, session, err := GetCall(ctx)
+	if err != nil {
+		return nil, err
+	}
+	serviceLocationId := req.GetServiceLocationId()
+
+	// validate the request; the device stays in its service location unless
+	// another one is given
+	ed := model.EnrolledDevice{
+		Id:                req.GetId(),
+		ServiceLocationId: req.GetNewServiceLocationId(),
+		DeviceId:          req.GetDeviceId(),
+		AliasName:         req.GetAliasName(),
+		RoomNumber:        req.GetRoomNumber(),
+		CustomerId:        session.CustomerId,
+	}
+	if ed.ServiceLocationId == 0 {
+		ed.ServiceLocationId = serviceLocationId
+	}
+	if errs := validation.Validate(ed); len(errs) > 0 {
+		return nil, errs
+	}
+	if len(req.GetEtag()) == 0 {
+		return nil, apierror.PreconditionRequired("Etag is required")
+	}
+
+	// update enrolled device
+	ed, err = updateEnrolledDeviceResource(ctx, s.db, s.redisClient, r, session, serviceLocationId, ed, req.GetEtag())
+	if err != nil {
+		return nil, err
+	}
+	return toEnrolledDeviceMessage(ed)
+}
+
+func (s *EnrolledDeviceServer) DeleteEnrolledDevice(ctx context.Context, req *shemsv1.DeleteEnrolledDeviceRequest) (*shemsv1.DeleteEnrolledDeviceResponse, error) {
+	r, session, err := GetCall(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// delete enrolled device
+	err = deleteEnrolledDeviceResource(ctx, s.db, r, session, req.GetServiceLocationId(), req.GetId(), req.GetEtag())
+	if err != nil {
+		return nil, err
+	}
+	return &shemsv1.DeleteEnrolledDeviceResponse{}, nil
+}
+
+func (s *EnrolledDeviceServer) RestoreEnrolledDevice(ctx context.Context, req *shemsv1.RestoreEnrolledDeviceRequest) (*shemsv1.EnrolledDevice, error) {
+	r, session, err := GetCall(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// restore enrolled device
+	ed, err := restoreEnrolledDeviceResource(ctx, s.db, r, session, req.GetServiceLocationId(), req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return toEnrolledDeviceMessage(ed)
+}
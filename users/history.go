@@ -0,0 +1,98 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"shems/apierror"
+	"shems/dbutil"
+	"time"
+)
+
+// The first enrollment record of a device starts here, so that readings from
+// before the device was enrolled are attributed to its first service location
+const historyStart = "1000-01-01 00:00:00"
+
+// Records are closed and opened with the same timestamp so that consecutive
+// records of a device or service location neither overlap nor leave a gap
+func historyNow() string {
+	return time.Now().Format(dbutil.TimeLayout)
+}
+
+func startEnrolledDeviceHistory(ctx context.Context, tx *sql.Tx, enrolledDeviceId, serviceLocationId uint32) error {
+	_, err := tx.ExecContext(ctx, queryToAddEnrolledDeviceHistory(), enrolledDeviceId, serviceLocationId, historyStart)
+	return err
+}
+
+// moveEnrolledDeviceHistory ends the current record of the device and starts
+// one at the service location it was moved to
+func moveEnrolledDeviceHistory(ctx context.Context, tx *sql.Tx, enrolledDeviceId, serviceLocationId uint32) error {
+	now := historyNow()
+	_, err := tx.ExecContext(ctx, queryToCloseEnrolledDeviceHistory(), now, enrolledDeviceId)
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, queryToAddEnrolledDeviceHistory(), enrolledDeviceId, serviceLocationId, now)
+	return err
+}
+
+// endEnrolledDeviceHistory ends the current record of a deleted device at the
+// time it was deleted
+func endEnrolledDeviceHistory(ctx context.Context, tx *sql.Tx, enrolledDeviceId uint32, deletedAt string) error {
+	_, err := tx.ExecContext(ctx, queryToCloseEnrolledDeviceHistory(), deletedAt, enrolledDeviceId)
+	return err
+}
+
+// reopenEnrolledDeviceHistory undoes endEnrolledDeviceHistory when a device
+// is restored, so that readings from while it was deleted are kept
+func reopenEnrolledDeviceHistory(ctx context.Context, tx *sql.Tx, enrolledDeviceId uint32) error {
+	_, err := tx.ExecContext(ctx, queryToReopenEnrolledDeviceHistory(), enrolledDeviceId)
+	return err
+}
+
+// startServiceLocationHistory starts the occupancy of a service location on
+// the date it was taken over
+func startServiceLocationHistory(ctx context.Context, tx *sql.Tx, sl serviceLocationSnapshot) error {
+	_, err := tx.ExecContext(ctx, queryToAddServiceLocationHistory(), sl.Id, sl.LocationId, sl.OccupantsCount, sl.DateTakenOver)
+	return err
+}
+
+// updateServiceLocationHistory records a change of a service location. A new
+// date taken over moves the start of the occupancy, other changes end the
+// current record and start a new one. The start cannot be moved past the end
+// of the first record, which would leave it ending before it starts.
+func updateServiceLocationHistory(ctx context.Context, tx *sql.Tx, before, after serviceLocationSnapshot) error {
+	if before.DateTakenOver != after.DateTakenOver {
+		result, err := tx.ExecContext(ctx, queryToUpdateServiceLocationHistoryStart(), after.Id, after.DateTakenOver, after.DateTakenOver)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return apierror.Conflict("Date taken over cannot be after the end of the first occupancy of the service location")
+		}
+	}
+
+	if before.LocationId != after.LocationId || before.OccupantsCount != after.OccupantsCount {
+		now := historyNow()
+		_, err := tx.ExecContext(ctx, queryToCloseServiceLocationHistory(), now, after.Id)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, queryToAddServiceLocationHistory(), after.Id, after.LocationId, after.OccupantsCount, now)
+		return err
+	}
+	return nil
+}
+
+func endServiceLocationHistory(ctx context.Context, tx *sql.Tx, serviceLocationId uint32, deletedAt string) error {
+	_, err := tx.ExecContext(ctx, queryToCloseServiceLocationHistory(), deletedAt, serviceLocationId)
+	return err
+}
+
+func reopenServiceLocationHistory(ctx context.Context, tx *sql.Tx, serviceLocationId uint32) error {
+	_, err := tx.ExecContext(ctx, queryToReopenServiceLocationHistory(), serviceLocationId)
+	return err
+}
@@ -0,0 +1,250 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"shems/access"
+	"shems/apierror"
+	"shems/model"
+)
+
+func getRequestLocation(req model.ServiceLocation) model.Location {
+	return model.Location{
+		UnitNumber:    req.UnitNumber,
+		Street:        req.Street,
+		City:          req.City,
+		State:         req.State,
+		Zipcode:       string(req.Zipcode),
+		Country:       req.Country,
+		SquareFootage: req.SquareFootage,
+		BedroomsCount: req.BedroomsCount,
+	}
+}
+
+// getVisibleServiceLocationId returns the id of the service location at the
+// location which the customer is a member of, or 0 when there is none
+func getVisibleServiceLocationId(ctx context.Context, tx *sql.Tx, locationId, customerId uint32) (uint32, error) {
+	var serviceLocationId uint32
+	err := tx.QueryRowContext(ctx, queryToCheckIfServiceLocationExistsByLocationId(), locationId, customerId).Scan(&serviceLocationId)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return serviceLocationId, err
+}
+
+// addServiceLocation adds the service location of the request, owned by the
+// customer adding it, and returns its id
+func addServiceLocation(ctx context.Context, tx *sql.Tx, r *http.Request, session model.Session, req model.ServiceLocation) (uint32, error) {
+	locationId, err := getOrAddLocation(ctx, tx, getRequestLocation(req))
+	if err != nil {
+		return 0, err
+	}
+
+	// validation: check if service location at the same address is already visible to the customer
+	existingId, err := getVisibleServiceLocationId(ctx, tx, locationId, req.CustomerId)
+	if err != nil {
+		return 0, err
+	}
+	if existingId > 0 {
+		return 0, apierror.Conflict("Service Location already exists")
+	}
+
+	// insert query to add service location
+	query := queryToAddServiceLocation()
+	result, err := tx.ExecContext(ctx, query, req.CustomerId, locationId, req.DateTakenOver, req.OccupantsCount)
+	if err != nil {
+		return 0, err
+	}
+	lastInsertId, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	serviceLocationId := uint32(lastInsertId)
+
+	// the customer adding a service location owns it
+	query = queryToAddServiceLocationMember()
+	_, err = tx.ExecContext(ctx, query, serviceLocationId, req.CustomerId, access.RoleOwner)
+	if err != nil {
+		return 0, err
+	}
+
+	after, err := getServiceLocationSnapshot(ctx, tx, serviceLocationId)
+	if err != nil {
+		return 0, err
+	}
+	err = startServiceLocationHistory(ctx, tx, after)
+	if err != nil {
+		return 0, err
+	}
+	err = RecordAudit(ctx, tx, r, session, "add", model.AuditEntityServiceLocation, serviceLocationId, nil, after)
+	if err != nil {
+		return 0, err
+	}
+	err = RecordAudit(ctx, tx, r, session, "add", model.AuditEntityServiceLocationMember, serviceLocationId, nil, memberSnapshot{ServiceLocationId: serviceLocationId, CustomerId: req.CustomerId, Role: access.RoleOwner})
+	if err != nil {
+		return 0, err
+	}
+	return serviceLocationId, nil
+}
+
+// updateServiceLocation changes the service location of the request. The
+// caller checks that the customer may manage it.
+func updateServiceLocation(ctx context.Context, tx *sql.Tx, r *http.Request, session model.Session, req model.ServiceLocation) error {
+	locationId, err := getOrAddLocation(ctx, tx, getRequestLocation(req))
+	if err != nil {
+		return err
+	}
+
+	// validation: check if service location at the same address is already visible to the customer
+	existingId, err := getVisibleServiceLocationId(ctx, tx, locationId, req.CustomerId)
+	if err != nil {
+		return err
+	}
+	if existingId > 0 && existingId != req.Id {
+		return apierror.Conflict("Service Location with same address already exists")
+	}
+
+	// validation: deleted service locations have to be restored before they are changed
+	before, err := getServiceLocationSnapshot(ctx, tx, req.Id)
+	if err != nil {
+		return err
+	}
+	if before.Active == 0 {
+		return apierror.BadRequest("Service location is deleted")
+	}
+
+	// update service location
+	query := queryToUpdateServiceLocation()
+	_, err = tx.ExecContext(ctx, query, locationId, req.DateTakenOver, req.OccupantsCount, req.Id)
+	if err != nil {
+		return err
+	}
+
+	after, err := getServiceLocationSnapshot(ctx, tx, req.Id)
+	if err != nil {
+		return err
+	}
+	err = updateServiceLocationHistory(ctx, tx, before, after)
+	if err != nil {
+		return err
+	}
+	return RecordAudit(ctx, tx, r, session, "update", model.AuditEntityServiceLocation, req.Id, before, after)
+}
+
+func getEnrolledDeviceIds(ctx context.Context, tx *sql.Tx, query string, args ...interface{}) ([]uint32, error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uint32
+	for rows.Next() {
+		var id uint32
+		err = rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// deleteServiceLocation soft-deletes a service location along with its active
+// devices. The caller checks that the customer may delete it.
+func deleteServiceLocation(ctx context.Context, tx *sql.Tx, r *http.Request, session model.Session, serviceLocationId uint32) error {
+	before, err := getServiceLocationSnapshot(ctx, tx, serviceLocationId)
+	if err != nil {
+		return err
+	}
+	if before.Active == 0 {
+		return apierror.BadRequest("Service location is already deleted")
+	}
+
+	// delete service location
+	deletedAt := historyNow()
+	query := queryToDeleteServiceLocation()
+	_, err = tx.ExecContext(ctx, query, deletedAt, serviceLocationId)
+	if err != nil {
+		return err
+	}
+
+	err = endServiceLocationHistory(ctx, tx, serviceLocationId, deletedAt)
+	if err != nil {
+		return err
+	}
+
+	after := before
+	after.Active = 0
+	err = RecordAudit(ctx, tx, r, session, "delete", model.AuditEntityServiceLocation, serviceLocationId, before, after)
+	if err != nil {
+		return err
+	}
+
+	// devices of the service location are deleted with it, at the same time so
+	// that restoring the service location can find them
+	enrolledDeviceIds, err := getEnrolledDeviceIds(ctx, tx, queryToGetActiveEnrolledDeviceIdsByServiceLocation(), serviceLocationId)
+	if err != nil {
+		return err
+	}
+	for _, enrolledDeviceId := range enrolledDeviceIds {
+		_, err = deleteEnrolledDevice(ctx, tx, r, session, enrolledDeviceId, deletedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreServiceLocation undoes deleteServiceLocation. The caller checks that
+// the customer may delete the service location.
+func restoreServiceLocation(ctx context.Context, tx *sql.Tx, r *http.Request, session model.Session, serviceLocationId uint32) error {
+	// validation: service location should be deleted
+	var deletedAt sql.NullString
+	err := tx.QueryRowContext(ctx, queryToGetServiceLocationDeletedAt(), serviceLocationId).Scan(&deletedAt)
+	if err != nil {
+		return err
+	}
+	if !deletedAt.Valid {
+		return apierror.BadRequest("Service location is not deleted")
+	}
+
+	before, err := getServiceLocationSnapshot(ctx, tx, serviceLocationId)
+	if err != nil {
+		return err
+	}
+
+	// restore service location
+	query := queryToRestoreServiceLocation()
+	_, err = tx.ExecContext(ctx, query, serviceLocationId)
+	if err != nil {
+		return err
+	}
+
+	err = reopenServiceLocationHistory(ctx, tx, serviceLocationId)
+	if err != nil {
+		return err
+	}
+
+	after := before
+	after.Active = 1
+	err = RecordAudit(ctx, tx, r, session, "restore", model.AuditEntityServiceLocation, serviceLocationId, before, after)
+	if err != nil {
+		return err
+	}
+
+	// restore the devices that were deleted along with the service location;
+	// devices deleted on their own before that stay deleted
+	enrolledDeviceIds, err := getEnrolledDeviceIds(ctx, tx, queryToGetDeletedEnrolledDeviceIdsByServiceLocation(), serviceLocationId, deletedAt.String)
+	if err != nil {
+		return err
+	}
+	for _, enrolledDeviceId := range enrolledDeviceIds {
+		err = restoreEnrolledDevice(ctx, tx, r, session, enrolledDeviceId)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
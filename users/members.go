@@ -0,0 +1,646 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"shems/access"
+	"shems/apierror"
+	"shems/mail"
+	"shems/model"
+	redisService "shems/redis"
+	"shems/validation"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const invitationExpiryDays = 7
+
+func sendInvitationEmail(ctx context.Context, mailSender mail.Sender, appBaseURL string, inviter model.Customer, email, role, token string) error {
+	link := appBaseURL + "/acceptInvitation?token=" + url.QueryEscape(token)
+	return mailSender.Send(ctx, mail.Message{
+		To:      email,
+		Subject: "You have been invited to a home",
+		Body:    fmt.Sprintf("Hi,\n\n%s %s has invited you to join their home as %s. Log in or register with this email address and open the link below within %d days to accept.\n\n%s\n", inviter.FirstName, inviter.LastName, role, invitationExpiryDays, link),
+	})
+}
+
+// countOwners is used to make sure a service location always keeps an owner
+func countOwners(ctx context.Context, db access.Querier, serviceLocationId uint32) (int, error) {
+	var count int
+	err := db.QueryRowContext(ctx, queryToCountServiceLocationOwners(), serviceLocationId).Scan(&count)
+	return count, err
+}
+
+func GetServiceLocationMembers(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	// the customer is the one the session belongs to
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+	customerId := session.CustomerId
+
+	// Get service location id from query params
+	serviceLocationIdInt, err := GetIdFromQueryParams(r, "serviceLocationId", "Service Location Id")
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+
+	// validation: check if customer can view the service location
+	role, err := access.GetServiceLocationRole(ctx, db, customerId, uint32(serviceLocationIdInt))
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	err = access.CheckRole(role, access.ViewServiceLocation, "Service Location does not exist")
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// get members
+	query := queryToGetServiceLocationMembers()
+	rows, err := db.QueryContext(ctx, query, serviceLocationIdInt)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	defer rows.Close()
+
+	var members []model.ServiceLocationMember
+	for rows.Next() {
+		var m model.ServiceLocationMember
+		err = rows.Scan(&m.CustomerId, &m.FirstName, &m.LastName, &m.Email, &m.Role, &m.CreatedAt)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		members = append(members, m)
+	}
+
+	// pending invitations are only shown to members who can manage them
+	var invitations []model.ServiceLocationInvitation
+	if access.HasPermission(role, access.ManageMembers) {
+		query = queryToGetPendingServiceLocationInvitations()
+		rows, err = db.QueryContext(ctx, query, serviceLocationIdInt)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var i model.ServiceLocationInvitation
+			err = rows.Scan(&i.Id, &i.Email, &i.Role, &i.InvitedBy, &i.CreatedAt, &i.ExpiresAt)
+			if err != nil {
+				apierror.Write(w, r, err)
+				return
+			}
+			invitations = append(invitations, i)
+		}
+	}
+
+	resp := model.GetServiceLocationMembersResponse{
+		Members:     members,
+		Invitations: invitations,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func InviteServiceLocationMember(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client, mailSender mail.Sender, appBaseURL string) {
+	ctx := r.Context()
+	var req model.InviteServiceLocationMemberRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+	req.CustomerId = session.CustomerId
+
+	// validate the request
+	req.Email = NormalizeEmail(req.Email)
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+	email := req.Email
+
+	// validation: check if customer can manage members of the service location
+	err = access.CheckServiceLocationPermission(ctx, conn, req.CustomerId, req.ServiceLocationId, access.ManageMembers)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// validation: check if invited customer is already a member
+	invitee, err := getCustomerByEmail(ctx, conn, email)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if invitee.Id > 0 {
+		role, err := access.GetServiceLocationRole(ctx, conn, invitee.Id, req.ServiceLocationId)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		if len(role) > 0 {
+			apierror.Write(w, r, apierror.Conflict("Customer is already a member of this Service Location"))
+			return
+		}
+	}
+
+	inviter, err := getCustomerById(ctx, conn, req.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	redisKey := "InviteServiceLocationMember_CustomerId_" + fmt.Sprint(req.CustomerId)
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		// release redis lock
+		redisService.ReleaseLock(ctx, redisClient, redisKey)
+
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// take redis lock to avoid concurrent access or double clicking
+	err = TakeRedisLock(ctx, redisClient, redisKey)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// insert invitation
+	token := GenerateToken()
+	query := queryToAddServiceLocationInvitation()
+	result, err := tx.ExecContext(ctx, query, req.ServiceLocationId, email, req.Role, HashToken(token), req.CustomerId, invitationExpiryDays)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	invitationId, err := result.LastInsertId()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = RecordAudit(ctx, tx, r, session, "invite", model.AuditEntityServiceLocationInvitation, invitationId, nil, invitationSnapshot{ServiceLocationId: req.ServiceLocationId, Email: email, Role: req.Role})
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = sendInvitationEmail(ctx, mailSender, appBaseURL, inviter, email, req.Role, token)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// respond with a success message
+	json.NewEncoder(w).Encode(map[string]string{"message": "Invitation sent successfully"})
+}
+
+func AcceptServiceLocationInvitation(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.AcceptServiceLocationInvitationRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+	req.CustomerId = session.CustomerId
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	var invitationId, serviceLocationId uint32
+	var email, role string
+	query := queryToGetServiceLocationInvitationByToken()
+	err = conn.QueryRowContext(ctx, query, HashToken(req.Token)).Scan(&invitationId, &serviceLocationId, &email, &role)
+	if err == sql.ErrNoRows {
+		apierror.Write(w, r, apierror.BadRequest("Invitation is invalid or has expired"))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// validation: invitations can only be accepted by the invited email
+	customer, err := getCustomerById(ctx, conn, req.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if customer.Id == 0 || NormalizeEmail(customer.Email) != email {
+		apierror.Write(w, r, apierror.Forbidden("Invitation was sent to a different email"))
+		return
+	}
+
+	existingRole, err := access.GetServiceLocationRole(ctx, conn, req.CustomerId, serviceLocationId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if len(existingRole) > 0 {
+		apierror.Write(w, r, apierror.Conflict("Customer is already a member of this Service Location"))
+		return
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// the invitation can only be used once
+	result, err := tx.ExecContext(ctx, queryToAcceptServiceLocationInvitation(), invitationId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if rowsAffected == 0 {
+		apierror.Write(w, r, apierror.BadRequest("Invitation is invalid or has expired"))
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, queryToAddServiceLocationMember(), serviceLocationId, req.CustomerId, role)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = RecordAudit(ctx, tx, r, session, "accept", model.AuditEntityServiceLocationInvitation, invitationId, invitationSnapshot{ServiceLocationId: serviceLocationId, Email: email, Role: role}, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	err = RecordAudit(ctx, tx, r, session, "add", model.AuditEntityServiceLocationMember, serviceLocationId, nil, memberSnapshot{ServiceLocationId: serviceLocationId, CustomerId: req.CustomerId, Role: role})
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// respond with a success message
+	json.NewEncoder(w).Encode(map[string]string{"message": "Invitation accepted successfully"})
+}
+
+func UpdateServiceLocationMember(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.UpdateServiceLocationMemberRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+	req.CustomerId = session.CustomerId
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	// validation: check if customer can manage members of the service location
+	err = access.CheckServiceLocationPermission(ctx, conn, req.CustomerId, req.ServiceLocationId, access.ManageMembers)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// members of a service location are changed one at a time so that the
+	// last owner check cannot race
+	redisKey := "ServiceLocationMembers_ServiceLocationId_" + fmt.Sprint(req.ServiceLocationId)
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		// release redis lock
+		redisService.ReleaseLock(ctx, redisClient, redisKey)
+
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	err = TakeRedisLock(ctx, redisClient, redisKey)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// validation: check if member exists
+	memberRole, err := access.GetServiceLocationRole(ctx, tx, req.MemberCustomerId, req.ServiceLocationId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if len(memberRole) == 0 {
+		apierror.Write(w, r, apierror.NotFound("Member does not exist"))
+		return
+	}
+
+	if memberRole == access.RoleOwner && req.Role != access.RoleOwner {
+		owners, err := countOwners(ctx, tx, req.ServiceLocationId)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		if owners <= 1 {
+			apierror.Write(w, r, apierror.BadRequest("Service Location must have at least one owner"))
+			return
+		}
+	}
+
+	// update member role
+	_, err = tx.ExecContext(ctx, queryToUpdateServiceLocationMemberRole(), req.Role, req.ServiceLocationId, req.MemberCustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	before := memberSnapshot{ServiceLocationId: req.ServiceLocationId, CustomerId: req.MemberCustomerId, Role: memberRole}
+	after := memberSnapshot{ServiceLocationId: req.ServiceLocationId, CustomerId: req.MemberCustomerId, Role: req.Role}
+	err = RecordAudit(ctx, tx, r, session, "update", model.AuditEntityServiceLocationMember, req.ServiceLocationId, before, after)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// respond with a success message
+	json.NewEncoder(w).Encode(map[string]string{"message": "Member updated successfully"})
+}
+
+func RemoveServiceLocationMember(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	// the customer is the one the session belongs to
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+	customerId := session.CustomerId
+
+	// Get service location id from query params
+	serviceLocationIdInt, err := GetIdFromQueryParams(r, "serviceLocationId", "Service Location Id")
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+
+	// Get id of the member to remove from query params
+	memberCustomerIdInt, err := GetIdFromQueryParams(r, "memberCustomerId", "Member Customer Id")
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+
+	// validation: members can always leave, removing others needs permission
+	permission := access.ManageMembers
+	if uint32(memberCustomerIdInt) == customerId {
+		permission = access.ViewServiceLocation
+	}
+	err = access.CheckServiceLocationPermission(ctx, conn, customerId, uint32(serviceLocationIdInt), permission)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	redisKey := "ServiceLocationMembers_ServiceLocationId_" + fmt.Sprint(serviceLocationIdInt)
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		// release redis lock
+		redisService.ReleaseLock(ctx, redisClient, redisKey)
+
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// take redis lock so that the last owner check cannot race
+	err = TakeRedisLock(ctx, redisClient, redisKey)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// validation: check if member exists
+	memberRole, err := access.GetServiceLocationRole(ctx, tx, uint32(memberCustomerIdInt), uint32(serviceLocationIdInt))
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if len(memberRole) == 0 {
+		apierror.Write(w, r, apierror.NotFound("Member does not exist"))
+		return
+	}
+
+	if memberRole == access.RoleOwner {
+		owners, err := countOwners(ctx, tx, uint32(serviceLocationIdInt))
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		if owners <= 1 {
+			apierror.Write(w, r, apierror.BadRequest("Service Location must have at least one owner"))
+			return
+		}
+	}
+
+	// delete member
+	_, err = tx.ExecContext(ctx, queryToDeleteServiceLocationMember(), serviceLocationIdInt, memberCustomerIdInt)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	before := memberSnapshot{ServiceLocationId: uint32(serviceLocationIdInt), CustomerId: uint32(memberCustomerIdInt), Role: memberRole}
+	err = RecordAudit(ctx, tx, r, session, "remove", model.AuditEntityServiceLocationMember, serviceLocationIdInt, before, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// respond with a success message
+	json.NewEncoder(w).Encode(map[string]string{"message": "Member removed successfully"})
+}
+
+func DeleteServiceLocationInvitation(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	// the customer is the one the session belongs to
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+	customerId := session.CustomerId
+
+	// Get invitation id from query params
+	invitationIdInt, err := GetIdFromQueryParams(r, "invitationId", "Invitation Id")
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+
+	var invitation invitationSnapshot
+	err = conn.QueryRowContext(ctx, queryToGetServiceLocationInvitation(), invitationIdInt).Scan(&invitation.ServiceLocationId, &invitation.Email, &invitation.Role)
+	if err != nil && err != sql.ErrNoRows {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// validation: check if customer can manage members of the invitation's service location
+	role, err := access.GetServiceLocationRole(ctx, conn, customerId, invitation.ServiceLocationId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	err = access.CheckRole(role, access.ManageMembers, "Invitation does not exist")
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// delete invitation
+	_, err = tx.ExecContext(ctx, queryToDeleteServiceLocationInvitation(), invitationIdInt)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = RecordAudit(ctx, tx, r, session, "delete", model.AuditEntityServiceLocationInvitation, invitationIdInt, invitation, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// respond with a success message
+	json.NewEncoder(w).Encode(map[string]string{"message": "Invitation deleted successfully"})
+}
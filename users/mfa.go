@@ -0,0 +1,733 @@
+package users
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"shems/apierror"
+	"shems/model"
+	redisService "shems/redis"
+	"shems/validation"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	mfaLoginTokenExpiry = 5 * time.Minute
+
+	// Wrong codes allowed for one login before the password has to be entered
+	// again. Wrong codes also count towards the lockout of the customer.
+	maxMfaLoginAttempts = 5
+
+	recoveryCodesCount = 10
+)
+
+type customerMfa struct {
+	Secret  string
+	Enabled uint8
+}
+
+func getCustomerMfa(ctx context.Context, db *sql.DB, customerId uint32) (customerMfa, error) {
+	var mfa customerMfa
+	err := db.QueryRowContext(ctx, queryToGetCustomerMfa(), customerId).Scan(&mfa.Secret, &mfa.Enabled)
+	if err == sql.ErrNoRows {
+		return mfa, nil
+	}
+	return mfa, err
+}
+
+func getCustomerById(ctx context.Context, db *sql.DB, customerId uint32) (model.Customer, error) {
+	var customer model.Customer
+	err := db.QueryRowContext(ctx, queryToGetCustomerById(), customerId).Scan(&customer.Id, &customer.FirstName, &customer.LastName, &customer.PhoneNumber, &customer.Email, &customer.BillingAddressId, &customer.Password, &customer.EmailVerified, &customer.Active)
+	if err == sql.ErrNoRows {
+		return customer, nil
+	}
+	return customer, err
+}
+
+// Recovery codes are compared without case or dashes so that they can be
+// typed the way they are shown
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
+
+func generateRecoveryCodes() []string {
+	codes := make([]string, recoveryCodesCount)
+	for i := range codes {
+		bytes := make([]byte, 5)
+		rand.Read(bytes)
+		code := hex.EncodeToString(bytes)
+		codes[i] = code[:5] + "-" + code[5:]
+	}
+	return codes
+}
+
+// replaceRecoveryCodes invalidates all recovery codes of the customer and
+// stores hashes of the new ones
+func replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, customerId uint32, codes []string) error {
+	_, err := tx.ExecContext(ctx, queryToDeleteRecoveryCodes(), customerId)
+	if err != nil {
+		return err
+	}
+
+	query := queryToAddRecoveryCode()
+	for _, code := range codes {
+		codeHash, err := getRecoveryCodeHash(normalizeRecoveryCode(code))
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, query, customerId, codeHash)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyTotpCode checks a code and that its time step was not used before,
+// so that a code seen by someone else cannot be replayed. The time step is
+// claimed with a single SET NX which lasts as long as the code is valid, so
+// concurrent requests with the same code cannot both succeed.
+func verifyTotpCode(ctx context.Context, redisClient *redis.Client, customerId uint32, secret, code string) (bool, error) {
+	counter := validateTotpCode(secret, code, time.Now())
+	if counter == 0 {
+		return false, nil
+	}
+
+	redisKey := "MfaUsedCounter_CustomerId_" + fmt.Sprint(customerId) + "_" + fmt.Sprint(counter)
+	claimed, err := redisService.SetKeyIfAbsent(ctx, redisClient, redisKey, true, time.Duration(2*totpSkew+1)*totpPeriod*time.Second)
+	if err != nil {
+		return false, fmt.Errorf("error while setting redis key: %w", err)
+	}
+	return claimed, nil
+}
+
+// verifyRecoveryCode uses up the matching unused recovery code of the customer
+func verifyRecoveryCode(ctx context.Context, r *http.Request, db *sql.DB, customerId uint32, code string) (bool, error) {
+	rows, err := db.QueryContext(ctx, queryToGetRecoveryCodes(), customerId)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	code = normalizeRecoveryCode(code)
+	var matchedId uint32
+	for rows.Next() {
+		var id uint32
+		var codeHash string
+		err = rows.Scan(&id, &codeHash)
+		if err != nil {
+			return false, err
+		}
+		if matchedId == 0 && CheckPasswordHash(code, codeHash) {
+			matchedId = id
+		}
+	}
+	if matchedId == 0 {
+		return false, nil
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	// the code only counts if this request is the one which marked it used
+	result, err := tx.ExecContext(ctx, queryToUseRecoveryCode(), matchedId)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil || rowsAffected == 0 {
+		return false, err
+	}
+
+	err = RecordAudit(ctx, tx, r, model.Session{CustomerId: customerId}, "use recovery code", model.AuditEntityTwoFactorAuthentication, customerId, nil, nil)
+	if err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// verifySecondFactor accepts either an authenticator code or a recovery code
+func verifySecondFactor(ctx context.Context, r *http.Request, db *sql.DB, redisClient *redis.Client, customerId uint32, secret, code, recoveryCode string) (bool, error) {
+	if len(code) > 0 {
+		return verifyTotpCode(ctx, redisClient, customerId, secret, code)
+	}
+	if len(recoveryCode) > 0 {
+		return verifyRecoveryCode(ctx, r, db, customerId, recoveryCode)
+	}
+	return false, nil
+}
+
+// mfaLockedError is returned while a customer is locked out after too many
+// wrong authentication codes
+func mfaLockedError() error {
+	return apierror.Locked("Too many invalid authentication codes, please try again later")
+}
+
+// RequireMfa checks that a customer who enabled two factor authentication is
+// calling with a session in which it was satisfied. It returns the error to
+// respond with, or nil when the request may go ahead.
+func RequireMfa(ctx context.Context, r *http.Request, db *sql.DB, redisClient *redis.Client, customerId uint32) error {
+	mfa, err := getCustomerMfa(ctx, db, customerId)
+	if err != nil {
+		return err
+	}
+	if mfa.Enabled == 0 {
+		return nil
+	}
+
+	session, err := GetSession(ctx, redisClient, r)
+	if err == ErrNoSession {
+		return apierror.Unauthorized("Two factor authentication is required")
+	}
+	if err != nil {
+		return err
+	}
+	if session.CustomerId != customerId || !session.MfaSatisfied {
+		return apierror.Forbidden("Two factor authentication is required")
+	}
+	return nil
+}
+
+// startMfaLogin stores the customer id behind a short lived token which the
+// second login step exchanges for a session
+func startMfaLogin(ctx context.Context, redisClient *redis.Client, customerId uint32) (string, error) {
+	token := GenerateToken()
+	err := redisService.SetKeyWithExpiry(ctx, redisClient, "MfaLogin_"+HashToken(token), customerId, mfaLoginTokenExpiry)
+	return token, err
+}
+
+func VerifyMfaLogin(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.VerifyMfaLoginRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	redisKey := "MfaLogin_" + HashToken(req.MfaToken)
+	val, err := redisService.GetKey(ctx, redisClient, redisKey)
+	if err == redis.Nil {
+		apierror.Write(w, r, apierror.Unauthorized("Login has expired, please log in again"))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	customerId, err := strconv.ParseUint(val, 10, 32)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	customer, err := getCustomerById(ctx, db, uint32(customerId))
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	mfa, err := getCustomerMfa(ctx, db, customer.Id)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	if isMfaLocked(ctx, redisClient, customer.Id) {
+		apierror.Write(w, r, mfaLockedError())
+		return
+	}
+
+	verified, err := verifySecondFactor(ctx, r, db, redisClient, customer.Id, mfa.Secret, req.Code, req.RecoveryCode)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if !verified {
+		recordFailedMfa(ctx, redisClient, customer.Id)
+
+		// the login token is dropped after too many wrong codes
+		attempts, err := redisService.IncrementKey(ctx, redisClient, "MfaLoginAttempts_"+HashToken(req.MfaToken), mfaLoginTokenExpiry)
+		if err != nil {
+			slog.ErrorContext(ctx, "error while incrementing redis key", "error", err)
+		} else if attempts >= maxMfaLoginAttempts {
+			err = redisService.DeleteKey(context.WithoutCancel(ctx), redisClient, redisKey)
+			if err != nil {
+				apierror.Write(w, r, err)
+				return
+			}
+		}
+
+		apierror.Write(w, r, apierror.Unauthorized("Invalid authentication code"))
+		return
+	}
+
+	// the login token can only be exchanged once
+	_, err = redisService.GetAndDeleteKey(context.WithoutCancel(ctx), redisClient, redisKey)
+	if err == redis.Nil {
+		apierror.Write(w, r, apierror.Unauthorized("Login has expired, please log in again"))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	clearFailedMfa(ctx, redisClient, customer.Id)
+
+	sessionToken, err := CreateSession(ctx, redisClient, model.Session{CustomerId: customer.Id, MfaSatisfied: true})
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// Return customer details in response
+	customer.Password = ""
+	resp := model.LoginUserResponse{
+		CustomerDetails: customer,
+		SessionToken:    sessionToken,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func LogoutUser(w http.ResponseWriter, r *http.Request, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	err := DeleteSession(ctx, redisClient, r)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Logged out successfully"})
+}
+
+// GetSessionOrRespond writes the error response itself when the request has
+// no valid session
+func GetSessionOrRespond(ctx context.Context, w http.ResponseWriter, r *http.Request, redisClient *redis.Client) (model.Session, bool) {
+	session, err := GetSession(ctx, redisClient, r)
+	if err == ErrNoSession {
+		apierror.Write(w, r, apierror.Unauthorized(err.Error()))
+		return session, false
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return session, false
+	}
+	return session, true
+}
+
+// GetOwnSessionOrRespond is GetSessionOrRespond for changes which could take
+// over the account, which support admins impersonating the customer cannot
+// make
+func GetOwnSessionOrRespond(ctx context.Context, w http.ResponseWriter, r *http.Request, redisClient *redis.Client) (model.Session, bool) {
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if ok && session.ImpersonatedBy > 0 {
+		apierror.Write(w, r, apierror.Forbidden("Only the customer can make this change"))
+		return session, false
+	}
+	return session, ok
+}
+
+func EnrollMfa(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := GetOwnSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	customer, err := getCustomerById(ctx, conn, session.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	mfa, err := getCustomerMfa(ctx, conn, session.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if mfa.Enabled == 1 {
+		apierror.Write(w, r, apierror.BadRequest("Two factor authentication is already enabled"))
+		return
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// a new secret replaces any enrollment which was never confirmed
+	secret := generateTotpSecret()
+	_, err = tx.ExecContext(ctx, queryToUpsertCustomerMfa(), session.CustomerId, secret)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = RecordAudit(ctx, tx, r, session, "enroll", model.AuditEntityTwoFactorAuthentication, session.CustomerId, nil, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	resp := model.EnrollMfaResponse{
+		Secret:          secret,
+		ProvisioningUri: getTotpProvisioningUri(customer.Email, secret),
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func ConfirmMfa(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.ConfirmMfaRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	session, ok := GetOwnSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	mfa, err := getCustomerMfa(ctx, conn, session.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if len(mfa.Secret) == 0 {
+		apierror.Write(w, r, apierror.BadRequest("Two factor authentication enrollment has not been started"))
+		return
+	}
+	if mfa.Enabled == 1 {
+		apierror.Write(w, r, apierror.BadRequest("Two factor authentication is already enabled"))
+		return
+	}
+
+	if isMfaLocked(ctx, redisClient, session.CustomerId) {
+		apierror.Write(w, r, mfaLockedError())
+		return
+	}
+	verified, err := verifyTotpCode(ctx, redisClient, session.CustomerId, mfa.Secret, req.Code)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if !verified {
+		recordFailedMfa(ctx, redisClient, session.CustomerId)
+		apierror.Write(w, r, apierror.BadRequest("Invalid authentication code"))
+		return
+	}
+	clearFailedMfa(ctx, redisClient, session.CustomerId)
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	_, err = tx.ExecContext(ctx, queryToEnableCustomerMfa(), session.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	recoveryCodes := generateRecoveryCodes()
+	err = replaceRecoveryCodes(ctx, tx, session.CustomerId, recoveryCodes)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = RecordAudit(ctx, tx, r, session, "enable", model.AuditEntityTwoFactorAuthentication, session.CustomerId, mfaSnapshot{Enabled: 0}, mfaSnapshot{Enabled: 1})
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// the code just proved possession of the authenticator. The session is
+	// only marked once the enrollment is committed, and a failure does not
+	// hold back the recovery codes, the customer can log in again instead.
+	session.MfaSatisfied = true
+	err = updateSession(ctx, redisClient, r, session)
+	if err != nil {
+		slog.ErrorContext(ctx, "error while updating session", "error", err)
+	}
+
+	// recovery codes are only ever shown here
+	resp := model.RecoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func DisableMfa(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.DisableMfaRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	session, ok := GetOwnSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	customer, err := getCustomerById(ctx, conn, session.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	mfa, err := getCustomerMfa(ctx, conn, session.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if mfa.Enabled == 0 {
+		apierror.Write(w, r, apierror.BadRequest("Two factor authentication is not enabled"))
+		return
+	}
+
+	// both the password and a second factor are needed to turn it off. Wrong
+	// ones count towards the same lockouts as logging in.
+	email := NormalizeEmail(customer.Email)
+	if isLoginLocked(ctx, redisClient, email) {
+		apierror.Write(w, r, apierror.Locked("Too many failed login attempts, please try again later"))
+		return
+	}
+	if customer.Id == 0 || !CheckPasswordHash(req.Password, customer.Password) {
+		recordFailedLogin(ctx, redisClient, email)
+		apierror.Write(w, r, apierror.Unauthorized("Invalid password"))
+		return
+	}
+	if isMfaLocked(ctx, redisClient, session.CustomerId) {
+		apierror.Write(w, r, mfaLockedError())
+		return
+	}
+	verified, err := verifySecondFactor(ctx, r, conn, redisClient, session.CustomerId, mfa.Secret, req.Code, req.RecoveryCode)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if !verified {
+		recordFailedMfa(ctx, redisClient, session.CustomerId)
+		apierror.Write(w, r, apierror.Unauthorized("Invalid authentication code"))
+		return
+	}
+	clearFailedLogins(ctx, redisClient, email)
+	clearFailedMfa(ctx, redisClient, session.CustomerId)
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	_, err = tx.ExecContext(ctx, queryToDeleteRecoveryCodes(), session.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	_, err = tx.ExecContext(ctx, queryToDeleteCustomerMfa(), session.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = RecordAudit(ctx, tx, r, session, "disable", model.AuditEntityTwoFactorAuthentication, session.CustomerId, mfaSnapshot{Enabled: 1}, mfaSnapshot{Enabled: 0})
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Two factor authentication disabled successfully"})
+}
+
+func RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.RegenerateRecoveryCodesRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	session, ok := GetOwnSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	mfa, err := getCustomerMfa(ctx, conn, session.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if mfa.Enabled == 0 {
+		apierror.Write(w, r, apierror.BadRequest("Two factor authentication is not enabled"))
+		return
+	}
+	if !session.MfaSatisfied {
+		apierror.Write(w, r, apierror.Forbidden("Two factor authentication is required"))
+		return
+	}
+
+	// a fresh code is needed so that a stolen session alone cannot take over
+	// the recovery codes
+	if isMfaLocked(ctx, redisClient, session.CustomerId) {
+		apierror.Write(w, r, mfaLockedError())
+		return
+	}
+	verified, err := verifyTotpCode(ctx, redisClient, session.CustomerId, mfa.Secret, req.Code)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if !verified {
+		recordFailedMfa(ctx, redisClient, session.CustomerId)
+		apierror.Write(w, r, apierror.Unauthorized("Invalid authentication code"))
+		return
+	}
+	clearFailedMfa(ctx, redisClient, session.CustomerId)
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	recoveryCodes := generateRecoveryCodes()
+	err = replaceRecoveryCodes(ctx, tx, session.CustomerId, recoveryCodes)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = RecordAudit(ctx, tx, r, session, "regenerate recovery codes", model.AuditEntityTwoFactorAuthentication, session.CustomerId, nil, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	resp := model.RecoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
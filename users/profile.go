@@ -0,0 +1,588 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"shems/apierror"
+	"shems/mail"
+	"shems/model"
+	redisService "shems/redis"
+	"shems/validation"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// emailChange is stored behind the token sent to the new email, the email is
+// only changed once the link is opened
+type emailChange struct {
+	CustomerId uint32
+	Email      string
+}
+
+func sendEmailChangeEmail(ctx context.Context, redisClient *redis.Client, mailSender mail.Sender, appBaseURL string, customer model.Customer, email string) error {
+	value, err := json.Marshal(emailChange{CustomerId: customer.Id, Email: email})
+	if err != nil {
+		return err
+	}
+
+	token := GenerateToken()
+	err = redisService.SetKeyWithExpiry(ctx, redisClient, "EmailChange_"+HashToken(token), value, emailVerificationTokenExpiry)
+	if err != nil {
+		return err
+	}
+
+	link := appBaseURL + "/confirmEmailChange?token=" + url.QueryEscape(token)
+	return mailSender.Send(ctx, mail.Message{
+		To:      email,
+		Subject: "Confirm your new email address",
+		Body:    fmt.Sprintf("Hi %s,\n\nPlease confirm that you want to use this email address for your account by opening the link below within 24 hours.\n\n%s\n", customer.FirstName, link),
+	})
+}
+
+// getOrAddLocation returns the id of the location with the address, adding it
+// when it does not exist yet
+func getOrAddLocation(ctx context.Context, tx *sql.Tx, l model.Location) (uint32, error) {
+	var locationId uint32
+	err := tx.QueryRowContext(ctx, queryToGetLocationIdByAddress(), l.UnitNumber, l.Street, l.City, l.State, l.Zipcode, l.Country).Scan(&locationId)
+	if err == nil {
+		return locationId, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	result, err := tx.ExecContext(ctx, queryToAddLocation(), l.UnitNumber, l.Street, l.City, l.State, l.Zipcode, l.Country, l.SquareFootage, l.BedroomsCount)
+	if err != nil {
+		return 0, err
+	}
+	lastInsertId, err := result.LastInsertId()
+	return uint32(lastInsertId), err
+}
+
+// getBillingAddress returns an empty location when the customer has no
+// billing address
+func getBillingAddress(ctx context.Context, db *sql.DB, customer model.Customer) (model.Location, error) {
+	var l model.Location
+	err := db.QueryRowContext(ctx, queryToGetLocation(), customer.BillingAddressId).Scan(&l.Id, &l.UnitNumber, &l.Street, &l.City, &l.State, &l.Zipcode, &l.Country, &l.SquareFootage, &l.BedroomsCount)
+	if err == sql.ErrNoRows {
+		return l, nil
+	}
+	return l, err
+}
+
+func GetProfile(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	customer, err := getCustomerById(ctx, db, session.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if customer.Id == 0 {
+		apierror.Write(w, r, apierror.NotFound("Customer not found"))
+		return
+	}
+
+	l, err := getBillingAddress(ctx, db, customer)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	customer.Password = ""
+	resp := model.GetProfileResponse{
+		CustomerDetails: customer,
+		BillingAddress:  l,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+// updateProfile changes the name and phone number of the customer of the
+// request
+func updateProfile(ctx context.Context, tx *sql.Tx, r *http.Request, session model.Session, req model.UpdateProfileRequest) error {
+	before, err := getCustomerSnapshot(ctx, tx, req.CustomerId)
+	if err == sql.ErrNoRows {
+		return apierror.NotFound("Customer not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, queryToUpdateCustomerProfile(), req.FirstName, req.LastName, req.PhoneNumber, req.CustomerId)
+	if err != nil {
+		return err
+	}
+
+	after := before
+	after.FirstName = req.FirstName
+	after.LastName = req.LastName
+	after.PhoneNumber = req.PhoneNumber
+	return RecordAudit(ctx, tx, r, session, "update profile", model.AuditEntityCustomer, req.CustomerId, before, after)
+}
+
+func UpdateProfile(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.UpdateProfileRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+	req.CustomerId = session.CustomerId
+
+	// validate the request
+	req.FirstName = strings.TrimSpace(req.FirstName)
+	req.LastName = strings.TrimSpace(req.LastName)
+	req.PhoneNumber = strings.TrimSpace(req.PhoneNumber)
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	err = updateProfile(ctx, tx, r, session, req)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// respond with a success message
+	json.NewEncoder(w).Encode(map[string]string{"message": "Profile updated successfully"})
+}
+
+func ChangePassword(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.ChangePasswordRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := GetOwnSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+	req.CustomerId = session.CustomerId
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	// changing the password needs two factor authentication when enabled
+	err = RequireMfa(ctx, r, conn, redisClient, req.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// wrong passwords count towards the same lockout as logging in
+	err = VerifyPassword(ctx, conn, redisClient, req.CustomerId, req.CurrentPassword)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	passwordHash, err := GetPasswordHash(req.NewPassword)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	before, err := getCustomerSnapshot(ctx, tx, req.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, queryToUpdateCustomerPassword(), passwordHash, req.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// password hashes are never written to the audit log
+	err = RecordAudit(ctx, tx, r, session, "change password", model.AuditEntityCustomer, req.CustomerId, before, before)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// every other session is ended and the caller continues in a new one
+	err = DeleteCustomerSessions(ctx, redisClient, req.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	sessionToken, err := CreateSession(ctx, redisClient, model.Session{CustomerId: req.CustomerId, MfaSatisfied: session.MfaSatisfied})
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	resp := model.ChangePasswordResponse{
+		SessionToken: sessionToken,
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func ChangeEmail(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client, mailSender mail.Sender, appBaseURL string) {
+	ctx := r.Context()
+	var req model.ChangeEmailRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := GetOwnSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+	req.CustomerId = session.CustomerId
+
+	// validate the request
+	req.NewEmail = NormalizeEmail(req.NewEmail)
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+	email := req.NewEmail
+
+	// changing the email needs two factor authentication when enabled
+	err = RequireMfa(ctx, r, db, redisClient, req.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// wrong passwords count towards the same lockout as logging in
+	err = VerifyPassword(ctx, db, redisClient, req.CustomerId, req.Password)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	customer, err := getCustomerById(ctx, db, req.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if NormalizeEmail(customer.Email) == email {
+		apierror.Write(w, r, apierror.BadRequest("New email is the same as the current email"))
+		return
+	}
+
+	existing, err := getCustomerByEmail(ctx, db, email)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if existing.Id > 0 {
+		apierror.Write(w, r, apierror.Conflict("User with same email already exists"))
+		return
+	}
+
+	err = sendEmailChangeEmail(ctx, redisClient, mailSender, appBaseURL, customer, email)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Verification email sent to the new email successfully"})
+}
+
+func ConfirmEmailChange(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client, mailSender mail.Sender) {
+	ctx := r.Context()
+	var req model.ConfirmEmailChangeRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	val, err := redisService.GetAndDeleteKey(ctx, redisClient, "EmailChange_"+HashToken(req.Token))
+	if err == redis.Nil {
+		apierror.Write(w, r, apierror.BadRequest("Verification link is invalid or has expired"))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	var change emailChange
+	err = json.Unmarshal([]byte(val), &change)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// validation: the email could have been taken since the link was sent
+	var existingId uint32
+	err = tx.QueryRowContext(ctx, queryToGetCustomerIdByEmail(), change.Email).Scan(&existingId)
+	if err != nil && err != sql.ErrNoRows {
+		apierror.Write(w, r, err)
+		return
+	}
+	if existingId > 0 {
+		apierror.Write(w, r, apierror.Conflict("User with same email already exists"))
+		return
+	}
+
+	before, err := getCustomerSnapshot(ctx, tx, change.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	_, err = tx.ExecContext(ctx, queryToUpdateCustomerEmail(), change.Email, change.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	after := before
+	after.Email = change.Email
+	after.EmailVerified = 1
+	err = RecordAudit(ctx, tx, r, model.Session{CustomerId: change.CustomerId}, "change email", model.AuditEntityCustomer, change.CustomerId, before, after)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// the previous email is told so that a takeover does not go unnoticed
+	err = mailSender.Send(ctx, mail.Message{
+		To:      before.Email,
+		Subject: "Your email address was changed",
+		Body:    fmt.Sprintf("Hi %s,\n\nThe email address of your account was changed to %s. If you did not do this, please contact support.\n", before.FirstName, change.Email),
+	})
+	if err != nil {
+		slog.ErrorContext(ctx, "error while sending email change notification", "error", err)
+	}
+
+	json.NewEncoder(w).Encode(map[string]string{"message": "Email changed successfully"})
+}
+
+func UpdateBillingAddress(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.UpdateBillingAddressRequest
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+	req.CustomerId = session.CustomerId
+
+	// validate the request
+	req.Zipcode = validation.NormalizePostalCode(req.Zipcode)
+	errs := validation.Validate(req)
+	if req.LocationId == 0 {
+		// a new address is needed when no existing location is given
+		if len(req.City) == 0 {
+			errs = append(errs, validation.FieldError{Field: "city", Message: "is required"})
+		}
+		if len(req.State) == 0 {
+			errs = append(errs, validation.FieldError{Field: "state", Message: "is required"})
+		}
+		if len(req.Zipcode) == 0 {
+			errs = append(errs, validation.FieldError{Field: "zipcode", Message: "is required"})
+		}
+		if len(req.Country) == 0 {
+			errs = append(errs, validation.FieldError{Field: "country", Message: "is required"})
+		}
+	}
+	if len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	before, err := getCustomerSnapshot(ctx, tx, req.CustomerId)
+	if err == sql.ErrNoRows {
+		apierror.Write(w, r, apierror.NotFound("Customer not found"))
+		return
+	}
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	locationId := req.LocationId
+	if locationId > 0 {
+		// validation: existing locations have to be one of the customer's service locations
+		var count uint32
+		err = tx.QueryRowContext(ctx, queryToCheckCustomerLocation(), req.CustomerId, locationId).Scan(&count)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		if count == 0 && locationId != before.BillingAddressId {
+			apierror.Write(w, r, apierror.BadRequest("Location is not one of your service locations"))
+			return
+		}
+	} else {
+		locationId, err = getOrAddLocation(ctx, tx, model.Location{
+			UnitNumber:    req.UnitNumber,
+			Street:        req.Street,
+			City:          req.City,
+			State:         req.State,
+			Zipcode:       string(req.Zipcode),
+			Country:       req.Country,
+			SquareFootage: req.SquareFootage,
+			BedroomsCount: req.BedroomsCount,
+		})
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, queryToUpdateCustomerBillingAddress(), locationId, req.CustomerId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	after := before
+	after.BillingAddressId = locationId
+	err = RecordAudit(ctx, tx, r, session, "update billing address", model.AuditEntityCustomer, req.CustomerId, before, after)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// respond with a success message
+	json.NewEncoder(w).Encode(map[string]string{"message": "Billing address updated successfully"})
+}
@@ -0,0 +1,601 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"shems/access"
+	"shems/apierror"
+	"shems/model"
+	redisService "shems/redis"
+	"shems/validation"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// The v2 API and the gRPC services both work on the service locations and
+// enrolled devices of the customer of the session. The functions below do the
+// work of both transports: they check access, take the locks, run the
+// transactions and compare the ETag the client sent, and return the resource
+// as it is afterwards. The transports only decode requests and encode the
+// resources and errors.
+
+// serviceLocationFilter selects the service locations of a list
+type serviceLocationFilter struct {
+	Status  string
+	City    string
+	State   string
+	Zipcode string
+}
+
+// listServiceLocationResources returns a page of the service locations the
+// customer is a member of, starting after the cursor
+func listServiceLocationResources(ctx context.Context, db *sql.DB, customerId uint32, filter serviceLocationFilter, cursor string, limit int) (model.ListServiceLocationsResponse, error) {
+	resp := model.ListServiceLocationsResponse{
+		ServiceLocations: []model.ServiceLocation{},
+	}
+
+	status, active, err := parseListStatus(filter.Status)
+	if err != nil {
+		return resp, apierror.BadRequest(err.Error())
+	}
+	afterId, err := decodeCursor(cursor)
+	if err != nil {
+		return resp, apierror.BadRequest(err.Error())
+	}
+	zipcode := validation.NormalizePostalCode(filter.Zipcode)
+
+	// one more than the page size tells if there is a next page
+	query := queryToListServiceLocations()
+	rows, err := db.QueryContext(ctx, query, customerId, status, active, filter.City, filter.City, filter.State, filter.State, zipcode, zipcode, afterId, limit+1)
+	if err != nil {
+		return resp, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		sl, err := scanServiceLocation(rows)
+		if err != nil {
+			return resp, err
+		}
+		resp.ServiceLocations = append(resp.ServiceLocations, sl)
+	}
+	if err = rows.Err(); err != nil {
+		return resp, err
+	}
+
+	if len(resp.ServiceLocations) > limit {
+		resp.ServiceLocations = resp.ServiceLocations[:limit]
+		resp.NextCursor = encodeCursor(resp.ServiceLocations[limit-1].Id)
+	}
+	return resp, nil
+}
+
+// addServiceLocationResource adds the service location of the request, which
+// has been validated, for its customer
+func addServiceLocationResource(ctx context.Context, conn *sql.DB, redisClient *redis.Client, r *http.Request, session model.Session, req model.ServiceLocation) (model.ServiceLocation, error) {
+	var sl model.ServiceLocation
+	redisKey := "AddServiceLocation_CustomerId_" + fmt.Sprint(req.CustomerId)
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return sl, err
+	}
+
+	defer func() {
+		// release redis lock
+		redisService.ReleaseLock(ctx, redisClient, redisKey)
+
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// take redis lock to avoid concurrent access or double clicking
+	err = TakeRedisLock(ctx, redisClient, redisKey)
+	if err != nil {
+		return sl, err
+	}
+
+	// add service location
+	serviceLocationId, err := addServiceLocation(ctx, tx, r, session, req)
+	if err != nil {
+		return sl, err
+	}
+
+	sl, err = getServiceLocation(ctx, tx, req.CustomerId, serviceLocationId)
+	if err != nil {
+		return sl, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return sl, err
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+	return sl, nil
+}
+
+// updateServiceLocationResource changes the service location of the request,
+// which has been validated, if it still has the ETag the client fetched
+func updateServiceLocationResource(ctx context.Context, conn *sql.DB, redisClient *redis.Client, r *http.Request, session model.Session, req model.ServiceLocation, ifMatch string) (model.ServiceLocation, error) {
+	var sl model.ServiceLocation
+	redisKey := "UpdateServiceLocation_CustomerId_" + fmt.Sprint(req.CustomerId)
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return sl, err
+	}
+
+	defer func() {
+		// release redis lock
+		redisService.ReleaseLock(ctx, redisClient, redisKey)
+
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// take redis lock to avoid concurrent access or double clicking
+	err = TakeRedisLock(ctx, redisClient, redisKey)
+	if err != nil {
+		return sl, err
+	}
+
+	// validation: check if customer can manage the service location
+	err = access.CheckServiceLocationPermission(ctx, tx, req.CustomerId, req.Id, access.ManageServiceLocation)
+	if err != nil {
+		return sl, err
+	}
+
+	// validation: the service location should not have changed since the
+	// client fetched it; the row stays locked until the update is committed
+	_, err = getServiceLocationSnapshot(ctx, tx, req.Id)
+	if err != nil {
+		return sl, err
+	}
+	before, err := getServiceLocation(ctx, tx, req.CustomerId, req.Id)
+	if err != nil {
+		return sl, err
+	}
+	err = checkETag(before, ifMatch)
+	if err != nil {
+		return sl, err
+	}
+
+	// update service location
+	err = updateServiceLocation(ctx, tx, r, session, req)
+	if err != nil {
+		return sl, err
+	}
+
+	sl, err = getServiceLocation(ctx, tx, req.CustomerId, req.Id)
+	if err != nil {
+		return sl, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return sl, err
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+	return sl, nil
+}
+
+// deleteServiceLocationResource deletes the service location if it still has
+// the ETag the client fetched, when one is given
+func deleteServiceLocationResource(ctx context.Context, conn *sql.DB, redisClient *redis.Client, r *http.Request, session model.Session, serviceLocationId uint32, ifMatch string) error {
+	customerId := session.CustomerId
+
+	// validation: check if customer can delete the service location
+	err := access.CheckServiceLocationPermission(ctx, conn, customerId, serviceLocationId, access.DeleteServiceLocation)
+	if err != nil {
+		return err
+	}
+
+	// deleting a service location needs two factor authentication when enabled
+	err = RequireMfa(ctx, r, conn, redisClient, customerId)
+	if err != nil {
+		return err
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// validation: when an ETag is given the service location should not have
+	// changed since the client fetched it
+	_, err = getServiceLocationSnapshot(ctx, tx, serviceLocationId)
+	if err != nil {
+		return err
+	}
+	before, err := getServiceLocation(ctx, tx, customerId, serviceLocationId)
+	if err != nil {
+		return err
+	}
+	err = checkETag(before, ifMatch)
+	if err != nil {
+		return err
+	}
+
+	// delete service location
+	err = deleteServiceLocation(ctx, tx, r, session, serviceLocationId)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+	return nil
+}
+
+// restoreServiceLocationResource restores a deleted service location
+func restoreServiceLocationResource(ctx context.Context, conn *sql.DB, r *http.Request, session model.Session, serviceLocationId uint32) (model.ServiceLocation, error) {
+	var sl model.ServiceLocation
+	customerId := session.CustomerId
+
+	// validation: check if customer can delete the service location
+	err := access.CheckServiceLocationPermission(ctx, conn, customerId, serviceLocationId, access.DeleteServiceLocation)
+	if err != nil {
+		return sl, err
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return sl, err
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// restore service location
+	err = restoreServiceLocation(ctx, tx, r, session, serviceLocationId)
+	if err != nil {
+		return sl, err
+	}
+
+	sl, err = getServiceLocation(ctx, tx, customerId, serviceLocationId)
+	if err != nil {
+		return sl, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return sl, err
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+	return sl, nil
+}
+
+// enrolledDeviceFilter selects the enrolled devices of a list
+type enrolledDeviceFilter struct {
+	Status     string
+	DeviceType string
+}
+
+// listEnrolledDeviceResources returns a page of the enrolled devices of a
+// service location the customer can view, starting after the cursor
+func listEnrolledDeviceResources(ctx context.Context, db *sql.DB, customerId, serviceLocationId uint32, filter enrolledDeviceFilter, cursor string, limit int) (model.ListEnrolledDevicesResponse, error) {
+	resp := model.ListEnrolledDevicesResponse{
+		EnrolledDevices: []model.EnrolledDevice{},
+	}
+
+	status, active, err := parseListStatus(filter.Status)
+	if err != nil {
+		return resp, apierror.BadRequest(err.Error())
+	}
+	afterId, err := decodeCursor(cursor)
+	if err != nil {
+		return resp, apierror.BadRequest(err.Error())
+	}
+
+	// validation: check if customer can view the service location
+	err = access.CheckServiceLocationPermission(ctx, db, customerId, serviceLocationId, access.ViewServiceLocation)
+	if err != nil {
+		return resp, err
+	}
+
+	// one more than the page size tells if there is a next page
+	query := queryToListEnrolledDevices()
+	rows, err := db.QueryContext(ctx, query, serviceLocationId, status, active, filter.DeviceType, filter.DeviceType, afterId, limit+1)
+	if err != nil {
+		return resp, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		ed, err := scanEnrolledDevice(rows)
+		if err != nil {
+			return resp, err
+		}
+		resp.EnrolledDevices = append(resp.EnrolledDevices, ed)
+	}
+	if err = rows.Err(); err != nil {
+		return resp, err
+	}
+
+	if len(resp.EnrolledDevices) > limit {
+		resp.EnrolledDevices = resp.EnrolledDevices[:limit]
+		resp.NextCursor = encodeCursor(resp.EnrolledDevices[limit-1].Id)
+	}
+	return resp, nil
+}
+
+// getEnrolledDeviceResource returns an enrolled device of a service location
+// the customer can view
+func getEnrolledDeviceResource(ctx context.Context, db *sql.DB, customerId, serviceLocationId, enrolledDeviceId uint32) (model.EnrolledDevice, error) {
+	// validation: check if customer can view the service location
+	err := access.CheckServiceLocationPermission(ctx, db, customerId, serviceLocationId, access.ViewServiceLocation)
+	if err != nil {
+		return model.EnrolledDevice{}, err
+	}
+	return getEnrolledDevice(ctx, db, serviceLocationId, enrolledDeviceId)
+}
+
+// addEnrolledDeviceResource enrolls the device of the request, which has been
+// validated, in its service location
+func addEnrolledDeviceResource(ctx context.Context, conn *sql.DB, redisClient *redis.Client, r *http.Request, session model.Session, req model.EnrolledDevice) (model.EnrolledDevice, error) {
+	var ed model.EnrolledDevice
+
+	// validation: check if customer can manage devices of the service location
+	err := access.CheckServiceLocationPermission(ctx, conn, req.CustomerId, req.ServiceLocationId, access.ManageDevices)
+	if err != nil {
+		return ed, err
+	}
+
+	redisKey := "AddEnrolledDevice_CustomerId_" + fmt.Sprint(req.CustomerId)
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return ed, err
+	}
+
+	defer func() {
+		// release redis lock
+		redisService.ReleaseLock(ctx, redisClient, redisKey)
+
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// take redis lock to avoid concurrent access or double clicking
+	err = TakeRedisLock(ctx, redisClient, redisKey)
+	if err != nil {
+		return ed, err
+	}
+
+	// enroll device
+	enrolledDeviceId, err := addEnrolledDevice(ctx, tx, r, session, req)
+	if err != nil {
+		return ed, err
+	}
+
+	ed, err = getEnrolledDevice(ctx, tx, req.ServiceLocationId, enrolledDeviceId)
+	if err != nil {
+		return ed, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return ed, err
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+	return ed, nil
+}
+
+// updateEnrolledDeviceResource changes the enrolled device of the request,
+// which has been validated, if it still has the ETag the client fetched. The
+// device is in serviceLocationId and moves to the service location of the
+// request when they differ.
+func updateEnrolledDeviceResource(ctx context.Context, conn *sql.DB, redisClient *redis.Client, r *http.Request, session model.Session, serviceLocationId uint32, req model.EnrolledDevice, ifMatch string) (model.EnrolledDevice, error) {
+	var ed model.EnrolledDevice
+	redisKey := "UpdateEnrolledDevice_CustomerId_" + fmt.Sprint(req.CustomerId)
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return ed, err
+	}
+
+	defer func() {
+		// release redis lock
+		redisService.ReleaseLock(ctx, redisClient, redisKey)
+
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// take redis lock to avoid concurrent access or double clicking
+	err = TakeRedisLock(ctx, redisClient, redisKey)
+	if err != nil {
+		return ed, err
+	}
+
+	// validation: check if customer can manage devices of the enrolled device's
+	// current service location and of the one it is moved to
+	err = access.CheckServiceLocationPermission(ctx, tx, req.CustomerId, serviceLocationId, access.ManageDevices)
+	if err != nil {
+		return ed, err
+	}
+	err = access.CheckServiceLocationPermission(ctx, tx, req.CustomerId, req.ServiceLocationId, access.ManageDevices)
+	if err != nil {
+		return ed, err
+	}
+
+	// validation: the enrolled device should not have changed since the client
+	// fetched it; the row stays locked until the update is committed
+	_, err = getEnrolledDeviceSnapshot(ctx, tx, req.Id)
+	if err == sql.ErrNoRows {
+		return ed, apierror.NotFound("Enrolled Device does not exist")
+	}
+	if err != nil {
+		return ed, err
+	}
+	before, err := getEnrolledDevice(ctx, tx, serviceLocationId, req.Id)
+	if err != nil {
+		return ed, err
+	}
+	err = checkETag(before, ifMatch)
+	if err != nil {
+		return ed, err
+	}
+
+	// update enrolled device
+	err = updateEnrolledDevice(ctx, tx, r, session, req)
+	if err != nil {
+		return ed, err
+	}
+
+	ed, err = getEnrolledDevice(ctx, tx, req.ServiceLocationId, req.Id)
+	if err != nil {
+		return ed, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return ed, err
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+	return ed, nil
+}
+
+// deleteEnrolledDeviceResource deletes the enrolled device if it still has
+// the ETag the client fetched, when one is given
+func deleteEnrolledDeviceResource(ctx context.Context, conn *sql.DB, r *http.Request, session model.Session, serviceLocationId, enrolledDeviceId uint32, ifMatch string) error {
+	customerId := session.CustomerId
+
+	// validation: check if customer can manage devices of the service location
+	err := access.CheckServiceLocationPermission(ctx, conn, customerId, serviceLocationId, access.ManageDevices)
+	if err != nil {
+		return err
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// validation: when an ETag is given the enrolled device should not have
+	// changed since the client fetched it
+	_, err = getEnrolledDeviceSnapshot(ctx, tx, enrolledDeviceId)
+	if err == sql.ErrNoRows {
+		return apierror.NotFound("Enrolled Device does not exist")
+	}
+	if err != nil {
+		return err
+	}
+	before, err := getEnrolledDevice(ctx, tx, serviceLocationId, enrolledDeviceId)
+	if err != nil {
+		return err
+	}
+	err = checkETag(before, ifMatch)
+	if err != nil {
+		return err
+	}
+
+	// delete enrolled device
+	deleted, err := deleteEnrolledDevice(ctx, tx, r, session, enrolledDeviceId, historyNow())
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return apierror.BadRequest("Enrolled device is already deleted")
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+	return nil
+}
+
+// restoreEnrolledDeviceResource restores a deleted enrolled device of the
+// service location
+func restoreEnrolledDeviceResource(ctx context.Context, conn *sql.DB, r *http.Request, session model.Session, serviceLocationId, enrolledDeviceId uint32) (model.EnrolledDevice, error) {
+	var ed model.EnrolledDevice
+	customerId := session.CustomerId
+
+	// validation: check if customer can manage devices of the service location
+	err := access.CheckServiceLocationPermission(ctx, conn, customerId, serviceLocationId, access.ManageDevices)
+	if err != nil {
+		return ed, err
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return ed, err
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// validation: the enrolled device should belong to the service location
+	_, err = getEnrolledDevice(ctx, tx, serviceLocationId, enrolledDeviceId)
+	if err != nil {
+		return ed, err
+	}
+
+	// restore enrolled device
+	err = restoreDeletedEnrolledDevice(ctx, tx, r, session, enrolledDeviceId)
+	if err != nil {
+		return ed, err
+	}
+
+	ed, err = getEnrolledDevice(ctx, tx, serviceLocationId, enrolledDeviceId)
+	if err != nil {
+		return ed, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return ed, err
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+	return ed, nil
+}
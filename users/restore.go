@@ -0,0 +1,210 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"shems/access"
+	"shems/apierror"
+	"shems/model"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// getListStatus reads the status filter of the list endpoints. Only active
+// entities are listed by default, "inactive" lists the deleted ones and "all"
+// lists both.
+func getListStatus(r *http.Request) (string, uint32, error) {
+	return parseListStatus(r.URL.Query().Get("status"))
+}
+
+func parseListStatus(status string) (string, uint32, error) {
+	switch status {
+	case "", "active":
+		return "active", 1, nil
+	case "inactive":
+		return status, 0, nil
+	case "all":
+		return status, 0, nil
+	}
+	return "", 0, fmt.Errorf("Status must be one of active, inactive or all")
+}
+
+// deleteEnrolledDevice soft-deletes an enrolled device and ends its history at
+// deletedAt. It returns false if the device was already deleted.
+func deleteEnrolledDevice(ctx context.Context, tx *sql.Tx, r *http.Request, session model.Session, enrolledDeviceId uint32, deletedAt string) (bool, error) {
+	before, err := getEnrolledDeviceSnapshot(ctx, tx, enrolledDeviceId)
+	if err != nil {
+		return false, err
+	}
+
+	query := queryToDeleteEnrolledDevice()
+	result, err := tx.ExecContext(ctx, query, deletedAt, enrolledDeviceId)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	// consumption after this is no longer attributed to the service location
+	err = endEnrolledDeviceHistory(ctx, tx, enrolledDeviceId, deletedAt)
+	if err != nil {
+		return false, err
+	}
+
+	after := before
+	after.Active = 0
+	err = RecordAudit(ctx, tx, r, session, "delete", model.AuditEntityEnrolledDevice, enrolledDeviceId, before, after)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// restoreEnrolledDevice undoes deleteEnrolledDevice, picking the device's last
+// history record back up.
+func restoreEnrolledDevice(ctx context.Context, tx *sql.Tx, r *http.Request, session model.Session, enrolledDeviceId uint32) error {
+	before, err := getEnrolledDeviceSnapshot(ctx, tx, enrolledDeviceId)
+	if err != nil {
+		return err
+	}
+
+	query := queryToRestoreEnrolledDevice()
+	_, err = tx.ExecContext(ctx, query, enrolledDeviceId)
+	if err != nil {
+		return err
+	}
+
+	err = reopenEnrolledDeviceHistory(ctx, tx, enrolledDeviceId)
+	if err != nil {
+		return err
+	}
+
+	after := before
+	after.Active = 1
+	return RecordAudit(ctx, tx, r, session, "restore", model.AuditEntityEnrolledDevice, enrolledDeviceId, before, after)
+}
+
+func RestoreEnrolledDevice(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	// the customer is the one the session belongs to
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+	customerId := session.CustomerId
+
+	// Get enrolled device id from query params
+	enrolledDeviceIdInt, err := GetIdFromQueryParams(r, "enrolledDeviceId", "Enrolled Device Id")
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+
+	// validation: check if customer can manage devices of the enrolled device's service location
+	err = access.CheckEnrolledDevicePermission(ctx, conn, customerId, uint32(enrolledDeviceIdInt), access.ManageDevices)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// restore enrolled device
+	err = restoreDeletedEnrolledDevice(ctx, tx, r, session, uint32(enrolledDeviceIdInt))
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// respond with a success message
+	json.NewEncoder(w).Encode(map[string]string{"message": "Enrolled device restored successfully"})
+}
+
+func RestoreServiceLocation(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	// the customer is the one the session belongs to
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+	customerId := session.CustomerId
+
+	// Get service location id from query params
+	serviceLocationIdInt, err := GetIdFromQueryParams(r, "serviceLocationId", "Service Location Id")
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+
+	// validation: check if customer can delete the service location
+	err = access.CheckServiceLocationPermission(ctx, conn, customerId, uint32(serviceLocationIdInt), access.DeleteServiceLocation)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
+	// restore service location
+	err = restoreServiceLocation(ctx, tx, r, session, uint32(serviceLocationIdInt))
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// respond with a success message
+	json.NewEncoder(w).Encode(map[string]string{"message": "Service location restored successfully"})
+}
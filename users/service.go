@@ -1,57 +1,125 @@
 package users
 
 import (
-	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"shems/access"
+	"shems/apierror"
+	"shems/mail"
 	"shems/model"
 	redisService "shems/redis"
-	"strconv"
+	"shems/validation"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 )
 
-func LoginUser(ctx context.Context, w http.ResponseWriter, r *http.Request, db *sql.DB) {
+func LoginUser(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
 	var req model.LoginUserRequest
 	w.Header().Set("Content-Type", "application/json")
 
 	// Parse the incoming JSON data from the request body
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		apierror.Write(w, r, apierror.InvalidJSON(err))
 		return
 	}
 
-	rows, err := db.Query("SELECT * FROM Customers WHERE email = ?", req.Email)
+	// emails are stored normalized, and lookups, lockouts and rate limits all
+	// use the same form
+	req.Email = NormalizeEmail(req.Email)
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	// rate limit login attempts per client ip
+	if isLoginRateLimited(ctx, redisClient, GetClientIp(r)) {
+		apierror.Write(w, r, apierror.TooManyRequests("Too many login attempts, please try again later"))
+		return
+	}
+
+	// reject attempts while the email is locked out after repeated failures
+	if isLoginLocked(ctx, redisClient, req.Email) {
+		apierror.Write(w, r, apierror.Locked("Too many failed login attempts, please try again later"))
+		return
+	}
+
+	rows, err := db.QueryContext(ctx, queryToGetCustomerByEmail(), req.Email)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
 	defer rows.Close()
 
 	var customer model.Customer
 	for rows.Next() {
-		err = rows.Scan(&customer.Id, &customer.FirstName, &customer.LastName, &customer.PhoneNumber, &customer.Email, &customer.BillingAddressId, &customer.Password)
+		err = rows.Scan(&customer.Id, &customer.FirstName, &customer.LastName, &customer.PhoneNumber, &customer.Email, &customer.BillingAddressId, &customer.Password, &customer.EmailVerified, &customer.Active)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			apierror.Write(w, r, err)
 			return
 		}
 	}
 
+	// unknown emails are checked against a dummy hash so that both cases take
+	// the same time and return the same message
+	passwordHash := customer.Password
 	if customer.Id == 0 {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(map[string]string{"message": "User does not exist"})
+		passwordHash = getDummyPasswordHash()
+	}
+	passwordMatches := CheckPasswordHash(req.Password, passwordHash)
+
+	if customer.Id == 0 || !passwordMatches {
+		recordFailedLogin(ctx, redisClient, req.Email)
+		apierror.Write(w, r, apierror.Unauthorized("Invalid email or password"))
+		return
+	}
+
+	// deactivated accounts are only told so once the password is correct
+	if customer.Active == 0 {
+		apierror.Write(w, r, apierror.Forbidden("This account has been deactivated, please contact support"))
+		return
+	}
+
+	clearFailedLogins(ctx, redisClient, req.Email)
+
+	// registering only finishes once the emailed link is followed, so that
+	// accounts cannot be opened with someone else's email
+	if customer.EmailVerified == 0 {
+		apierror.Write(w, r, apierror.Forbidden("Please verify your email before logging in"))
 		return
 	}
 
-	passwordMatches := CheckPasswordHash(req.Password, customer.Password)
+	// customers with two factor authentication get their details and session
+	// only after the second step
+	mfa, err := getCustomerMfa(ctx, db, customer.Id)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	if mfa.Enabled == 1 {
+		if isMfaLocked(ctx, redisClient, customer.Id) {
+			apierror.Write(w, r, mfaLockedError())
+			return
+		}
+		mfaToken, err := startMfaLogin(ctx, redisClient, customer.Id)
+		if err != nil {
+			apierror.Write(w, r, err)
+			return
+		}
+		json.NewEncoder(w).Encode(model.LoginUserResponse{MfaRequired: true, MfaToken: mfaToken})
+		return
+	}
 
-	if !passwordMatches {
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(map[string]string{"message": "Invalid password"})
+	sessionToken, err := CreateSession(ctx, redisClient, model.Session{CustomerId: customer.Id})
+	if err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
@@ -59,47 +127,94 @@ func LoginUser(ctx context.Context, w http.ResponseWriter, r *http.Request, db *
 	customer.Password = ""
 	resp := model.LoginUserResponse{
 		CustomerDetails: customer,
+		SessionToken:    sessionToken,
 	}
 	json.NewEncoder(w).Encode(resp)
 }
 
-func RegisterUser(ctx context.Context, w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+func RegisterUser(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client, mailSender mail.Sender, appBaseURL string) {
+	ctx := r.Context()
 	var req model.RegisterUserRequest
 	w.Header().Set("Content-Type", "application/json")
 
 	// Parse the incoming JSON data from the request body
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+
+	// emails are stored normalized, and lookups, lockouts and rate limits all
+	// use the same form
+	req.Email = NormalizeEmail(req.Email)
+
+	// validate the request
+	req.Zipcode = validation.NormalizePostalCode(req.Zipcode)
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	// registering sends an email whether or not the email is taken
+	if isAccountEmailRateLimited(ctx, redisClient, "Register", GetClientIp(r), req.Email) {
+		apierror.Write(w, r, apierror.TooManyRequests("Too many requests, please try again later"))
+		return
+	}
+
+	// the password is hashed before the email is looked up, so that taken
+	// emails take as long as new ones
+	passWordHash, err := GetPasswordHash(req.Password)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
+		}
+	}()
+
 	// Check if user with same email already exists
-	rows, err := db.Query("SELECT id FROM Customers WHERE email = ?", req.Email)
+	rows, err := tx.QueryContext(ctx, queryToGetCustomerByEmail(), req.Email)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
 	defer rows.Close()
 
-	var customerId uint32
+	var existing model.Customer
 	for rows.Next() {
-		err = rows.Scan(&customerId)
+		err = rows.Scan(&existing.Id, &existing.FirstName, &existing.LastName, &existing.PhoneNumber, &existing.Email, &existing.BillingAddressId, &existing.Password, &existing.EmailVerified, &existing.Active)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			apierror.Write(w, r, err)
 			return
 		}
 	}
-	if customerId > 0 {
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]string{"message": "User with same email already exists"})
+
+	// a taken email gets the same response as a new one, so that registering
+	// cannot tell who has an account. Its owner is told by email instead.
+	if existing.Id > 0 {
+		err = sendRegistrationNoticeEmail(ctx, mailSender, appBaseURL, existing)
+		if err != nil {
+			slog.ErrorContext(ctx, "error while sending registration notice email", "error", err)
+		}
+		writeRegistrationResponse(w)
 		return
 	}
 
 	// Check if same location already exists
-	rows, err = db.Query("SELECT id FROM Locations WHERE unit_number = ? AND street = ? AND city = ? AND state = ? AND zipcode = ? AND country = ?", req.UnitNumber, req.Street, req.City, req.State, req.Zipcode, req.Country)
+	rows, err = tx.QueryContext(ctx, "SELECT id FROM Locations WHERE unit_number = ? AND street = ? AND city = ? AND state = ? AND zipcode = ? AND country = ?", req.UnitNumber, req.Street, req.City, req.State, req.Zipcode, req.Country)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
 	defer rows.Close()
@@ -108,7 +223,7 @@ func RegisterUser(ctx context.Context, w http.ResponseWriter, r *http.Request, d
 	for rows.Next() {
 		err = rows.Scan(&locationId)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			apierror.Write(w, r, err)
 			return
 		}
 	}
@@ -116,16 +231,16 @@ func RegisterUser(ctx context.Context, w http.ResponseWriter, r *http.Request, d
 	// Create new location if this does not exist
 	if locationId == 0 {
 		// Create location
-		_, err = db.Exec("INSERT INTO Locations (unit_number, street, city, state, zipcode, country, square_footage, bedrooms_count) VALUES (?, ?, ?, ?, ?, ?, ?, ?)", req.UnitNumber, req.Street, req.City, req.State, req.Zipcode, req.Country, req.SquareFootage, req.BedroomsCount)
+		_, err = tx.ExecContext(ctx, "INSERT INTO Locations (unit_number, street, city, state, zipcode, country, square_footage, bedrooms_count) VALUES (?, ?, ?, ?, ?, ?, ?, ?)", req.UnitNumber, req.Street, req.City, req.State, req.Zipcode, req.Country, req.SquareFootage, req.BedroomsCount)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			apierror.Write(w, r, err)
 			return
 		}
 
 		// Get location id
-		rows, err = db.Query("SELECT id FROM Locations WHERE unit_number = ? AND street = ? AND city = ? AND state = ? AND zipcode = ? AND country = ?", req.UnitNumber, req.Street, req.City, req.State, req.Zipcode, req.Country)
+		rows, err = tx.QueryContext(ctx, "SELECT id FROM Locations WHERE unit_number = ? AND street = ? AND city = ? AND state = ? AND zipcode = ? AND country = ?", req.UnitNumber, req.Street, req.City, req.State, req.Zipcode, req.Country)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			apierror.Write(w, r, err)
 			return
 		}
 		defer rows.Close()
@@ -133,65 +248,83 @@ func RegisterUser(ctx context.Context, w http.ResponseWriter, r *http.Request, d
 		for rows.Next() {
 			err = rows.Scan(&locationId)
 			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
+				apierror.Write(w, r, err)
 				return
 			}
 		}
 	}
 
-	passWordHash, _ := GetPasswordHash(req.Password)
-
 	// Create user
-	_, err = db.Exec("INSERT INTO Customers (first_name, last_name, phone_number, email, billing_address_id, password) VALUES (?, ?, ?, ?, ?, ?)", req.FirstName, req.LastName, req.PhoneNumber, req.Email, locationId, passWordHash)
+	result, err := tx.ExecContext(ctx, "INSERT INTO Customers (first_name, last_name, phone_number, email, billing_address_id, password) VALUES (?, ?, ?, ?, ?, ?)", req.FirstName, req.LastName, req.PhoneNumber, req.Email, locationId, passWordHash)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	customerId64, err := result.LastInsertId()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	after, err := getCustomerSnapshot(ctx, tx, uint32(customerId64))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
+		return
+	}
+	err = RecordAudit(ctx, tx, r, model.Session{CustomerId: uint32(customerId64)}, "register", model.AuditEntityCustomer, customerId64, nil, after)
+	if err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
 	// Get user
-	rows, err = db.Query("SELECT * FROM Customers WHERE email = ?", req.Email)
+	rows, err = tx.QueryContext(ctx, queryToGetCustomerByEmail(), req.Email)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
 	defer rows.Close()
 
 	var customer model.Customer
 	for rows.Next() {
-		err = rows.Scan(&customer.Id, &customer.FirstName, &customer.LastName, &customer.PhoneNumber, &customer.Email, &customer.BillingAddressId, &customer.Password)
+		err = rows.Scan(&customer.Id, &customer.FirstName, &customer.LastName, &customer.PhoneNumber, &customer.Email, &customer.BillingAddressId, &customer.Password, &customer.EmailVerified, &customer.Active)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			apierror.Write(w, r, err)
 			return
 		}
 	}
 
-	// Return customer details in response
-	customer.Password = ""
-	resp := model.LoginUserResponse{
-		CustomerDetails: customer,
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
 	}
-	json.NewEncoder(w).Encode(resp)
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
+	// registration succeeds even if the email cannot be sent, it can be resent later
+	err = sendVerificationEmail(ctx, redisClient, mailSender, appBaseURL, customer)
+	if err != nil {
+		slog.ErrorContext(ctx, "error while sending verification email", "error", err)
+	}
+
+	writeRegistrationResponse(w)
+}
+
+func writeRegistrationResponse(w http.ResponseWriter) {
+	json.NewEncoder(w).Encode(map[string]string{"message": "Please check your email to finish registering"})
 }
 
-func GetDashboardData(ctx context.Context, w http.ResponseWriter, r *http.Request, db *sql.DB) {
+func GetDashboardData(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
 	w.Header().Set("Content-Type", "application/json")
 
-	// Get customer id from query params
-	var customerIdInt int
-	customerIdStr := r.URL.Query().Get("customerId")
-	if len(customerIdStr) == 0 {
-		http.Error(w, "Customer Id cannot be empty", http.StatusBadRequest)
-		return
-	}
-	customerIdInt, err := strconv.Atoi(customerIdStr)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-	if customerIdInt == 0 {
-		http.Error(w, "Customer Id cannot be 0", http.StatusBadRequest)
+	// the customer is the one the session belongs to
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
 		return
 	}
+	customerId := session.CustomerId
 
 	currentDate := r.URL.Query().Get("currentDate")
 	startDateTime, _ := GetStartOfMonth(currentDate)
@@ -201,34 +334,35 @@ func GetDashboardData(ctx context.Context, w http.ResponseWriter, r *http.Reques
 
 	// get energy consumption and costs by service locations
 	query := queryToFetchEnergyCostsByServiceLocations()
-	rows, err := db.Query(query, startDateTime, endDateTime, customerIdInt)
+	rows, err := db.QueryContext(ctx, query, startDateTime, startDateTime, endDateTime, customerId)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
 	defer rows.Close()
 
 	var serviceLocationCosts []model.ServiceLocationCost
 	var slc model.ServiceLocationCost
-	var totalEnergyCost, totalEnergyConsumption float32
+	var totalEnergyCost, totalEnergyConsumption, totalCarbonEmissions float32
 
 	for rows.Next() {
-		err = rows.Scan(&slc.LocationId, &slc.UnitNumber, &slc.Street, &slc.City, &slc.Zipcode, &slc.State, &slc.Country, &slc.EnergyConsumption, &slc.EnergyCost)
+		err = rows.Scan(&slc.LocationId, &slc.UnitNumber, &slc.Street, &slc.City, &slc.Zipcode, &slc.State, &slc.Country, &slc.EnergyConsumption, &slc.EnergyCost, &slc.CarbonEmissions)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			apierror.Write(w, r, err)
 			return
 		}
 
 		serviceLocationCosts = append(serviceLocationCosts, slc)
 		totalEnergyCost = float32(totalEnergyCost + slc.EnergyCost)
 		totalEnergyConsumption = float32(totalEnergyConsumption + slc.EnergyConsumption)
+		totalCarbonEmissions = float32(totalCarbonEmissions + slc.CarbonEmissions)
 	}
 
 	// get average energy consumption for similar locations
 	query = queryForAverageEnergyConsumptionForSimilarServiceLocations()
-	rows, err = db.Query(query, startDateTime, endDateTime)
+	rows, err = db.QueryContext(ctx, query, startDateTime, endDateTime)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
 	defer rows.Close()
@@ -239,7 +373,7 @@ func GetDashboardData(ctx context.Context, w http.ResponseWriter, r *http.Reques
 	for rows.Next() {
 		err = rows.Scan(&locationId, &avgEnergyConsumption)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			apierror.Write(w, r, err)
 			return
 		}
 
@@ -251,9 +385,9 @@ func GetDashboardData(ctx context.Context, w http.ResponseWriter, r *http.Reques
 
 	// get hourly prices
 	query = queryToGetHourlyPrices()
-	rows, err = db.Query(query)
+	rows, err = db.QueryContext(ctx, query)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
 	defer rows.Close()
@@ -263,7 +397,7 @@ func GetDashboardData(ctx context.Context, w http.ResponseWriter, r *http.Reques
 	for rows.Next() {
 		err = rows.Scan(&p.Zipcode, &p.Hour, &p.Value)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			apierror.Write(w, r, err)
 			return
 		}
 
@@ -272,9 +406,9 @@ func GetDashboardData(ctx context.Context, w http.ResponseWriter, r *http.Reques
 
 	// get energy consumption by devices
 	query = queryToFetchEnergyConsumptionByDevices()
-	rows, err = db.Query(query, startDateTime, endDateTime, customerIdInt)
+	rows, err = db.QueryContext(ctx, query, startDateTime, endDateTime, customerId)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
 	defer rows.Close()
@@ -283,23 +417,25 @@ func GetDashboardData(ctx context.Context, w http.ResponseWriter, r *http.Reques
 	for rows.Next() {
 		var edId uint32
 		var dType, dModelNumber, edAliasName string
-		var energyConsumption float32
+		var energyConsumption, carbonEmissions float32
 
-		err = rows.Scan(&edId, &dType, &dModelNumber, &edAliasName, &energyConsumption)
+		err = rows.Scan(&edId, &dType, &dModelNumber, &edAliasName, &energyConsumption, &carbonEmissions)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			apierror.Write(w, r, err)
 			return
 		}
 
 		enrolledDevices = append(enrolledDevices, model.EnrolledDevicesEnergyConsumption{
 			DeviceLabel:       fmt.Sprint(edAliasName, " (", dType, " - ", dModelNumber, ")"),
 			EnergyConsumption: energyConsumption,
+			CarbonEmissions:   carbonEmissions,
 		})
 	}
 
 	resp := model.DashboardDataResponse{
 		ServiceLocationCosts:   serviceLocationCosts,
 		TotalEnergyCost:        totalEnergyCost,
+		TotalCarbonEmissions:   totalCarbonEmissions,
 		HourlyPrices:           hourlyPrices,
 		EnrolledDevices:        enrolledDevices,
 		TotalEnergyConsumption: totalEnergyConsumption,
@@ -307,52 +443,52 @@ func GetDashboardData(ctx context.Context, w http.ResponseWriter, r *http.Reques
 	json.NewEncoder(w).Encode(resp)
 }
 
-func GetEnrolledDevices(ctx context.Context, w http.ResponseWriter, r *http.Request, db *sql.DB) {
+func GetEnrolledDevices(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
 	w.Header().Set("Content-Type", "application/json")
 
-	// Get customer id from query params
-	var customerIdInt int
-	customerIdStr := r.URL.Query().Get("customerId")
-	if len(customerIdStr) == 0 {
-		http.Error(w, "Customer Id cannot be empty", http.StatusBadRequest)
+	// the customer is the one the session belongs to
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
 		return
 	}
-	customerIdInt, err := strconv.Atoi(customerIdStr)
+	customerId := session.CustomerId
+
+	// Get status filter from query params
+	status, active, err := getListStatus(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-	if customerIdInt == 0 {
-		http.Error(w, "Customer Id cannot be 0", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
 		return
 	}
 
 	// get enrolled devices
 	query := queryToGetEnrolledDevices()
-	rows, err := db.Query(query, customerIdInt)
+	rows, err := db.QueryContext(ctx, query, customerId, status, active)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
 	defer rows.Close()
 
 	var enrolledDevices []model.EnrolledDevice
 	var ed model.EnrolledDevice
+	var edDeletedAt sql.NullString
 	for rows.Next() {
-		err = rows.Scan(&ed.Id, &ed.ServiceLocationId, &ed.DeviceId, &ed.AliasName, &ed.RoomNumber, &ed.Active)
+		err = rows.Scan(&ed.Id, &ed.ServiceLocationId, &ed.DeviceId, &ed.AliasName, &ed.RoomNumber, &ed.Active, &edDeletedAt)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			apierror.Write(w, r, err)
 			return
 		}
+		ed.DeletedAt = edDeletedAt.String
 
 		enrolledDevices = append(enrolledDevices, ed)
 	}
 
 	// get all devices
 	query = queryToGetAllDevices()
-	rows, err = db.Query(query)
+	rows, err = db.QueryContext(ctx, query)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
 	defer rows.Close()
@@ -362,30 +498,32 @@ func GetEnrolledDevices(ctx context.Context, w http.ResponseWriter, r *http.Requ
 	for rows.Next() {
 		err = rows.Scan(&d.Id, &d.Type, &d.ModelNumber)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			apierror.Write(w, r, err)
 			return
 		}
 
 		devices = append(devices, d)
 	}
 
-	// get all service locations
+	// get all service locations, deleted ones are needed to label deleted devices
 	query = queryToGetAllServiceLocations()
-	rows, err = db.Query(query, customerIdInt)
+	rows, err = db.QueryContext(ctx, query, customerId, "all", 0)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
 	defer rows.Close()
 
 	var serviceLocations []model.ServiceLocation
 	var sl model.ServiceLocation
+	var slDeletedAt sql.NullString
 	for rows.Next() {
-		err = rows.Scan(&sl.Id, &sl.CustomerId, &sl.DateTakenOver, &sl.OccupantsCount, &sl.UnitNumber, &sl.Street, &sl.City, &sl.State, &sl.Zipcode, &sl.Country, &sl.SquareFootage, &sl.BedroomsCount, &sl.Active)
+		err = rows.Scan(&sl.Id, &sl.CustomerId, &sl.DateTakenOver, &sl.OccupantsCount, &sl.UnitNumber, &sl.Street, &sl.City, &sl.State, &sl.Zipcode, &sl.Country, &sl.SquareFootage, &sl.BedroomsCount, &sl.Active, &slDeletedAt, &sl.Role)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			apierror.Write(w, r, err)
 			return
 		}
+		sl.DeletedAt = slDeletedAt.String
 
 		serviceLocations = append(serviceLocations, sl)
 	}
@@ -420,271 +558,265 @@ func GetEnrolledDevices(ctx context.Context, w http.ResponseWriter, r *http.Requ
 	json.NewEncoder(w).Encode(resp)
 }
 
-func DeleteEnrolledDevice(ctx context.Context, w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+func DeleteEnrolledDevice(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
 	w.Header().Set("Content-Type", "application/json")
 
-	// Get customer id from query params
-	var customerIdInt int
-	customerIdStr := r.URL.Query().Get("customerId")
-	if len(customerIdStr) == 0 {
-		http.Error(w, "Customer Id cannot be empty", http.StatusBadRequest)
-		return
-	}
-	customerIdInt, err := strconv.Atoi(customerIdStr)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-	if customerIdInt == 0 {
-		http.Error(w, "Customer Id cannot be 0", http.StatusBadRequest)
+	// the customer is the one the session belongs to
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
 		return
 	}
+	customerId := session.CustomerId
 
 	// Get enrolled device id from query params
-	var enrolledDeviceIdInt int
-	enrolledDeviceIdStr := r.URL.Query().Get("enrolledDeviceId")
-	if len(enrolledDeviceIdStr) == 0 {
-		http.Error(w, "Enrolled Device Id cannot be empty", http.StatusBadRequest)
-		return
-	}
-	enrolledDeviceIdInt, err = strconv.Atoi(enrolledDeviceIdStr)
+	enrolledDeviceIdInt, err := GetIdFromQueryParams(r, "enrolledDeviceId", "Enrolled Device Id")
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
 		return
 	}
-	if enrolledDeviceIdInt == 0 {
-		http.Error(w, "Enrolled Device Id cannot be 0", http.StatusBadRequest)
+
+	// validation: check if customer can manage devices of the enrolled device's service location
+	err = access.CheckEnrolledDevicePermission(ctx, conn, customerId, uint32(enrolledDeviceIdInt), access.ManageDevices)
+	if err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
-	// validation: check if enrolled device exists by same customer
-	query := queryToCheckIfEnrolledDeviceExists()
-	rows, err := db.Query(query, enrolledDeviceIdInt, customerIdInt)
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
-	defer rows.Close()
 
-	var checkId int
-	for rows.Next() {
-		err = rows.Scan(&checkId)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
 		}
+	}()
+
+	// delete enrolled device
+	deleted, err := deleteEnrolledDevice(ctx, tx, r, session, uint32(enrolledDeviceIdInt), historyNow())
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
 	}
-	if checkId != enrolledDeviceIdInt {
-		http.Error(w, "Enrolled Device does not exist", http.StatusBadRequest)
+	if !deleted {
+		apierror.Write(w, r, apierror.BadRequest("Enrolled device is already deleted"))
 		return
 	}
 
-	// delete enrolled device
-	query = queryToDeleteEnrolledDevice()
-	_, err = db.Exec(query, enrolledDeviceIdInt)
+	err = tx.Commit()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
 
 	// respond with a success message
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Enrolled device deleted successfully"))
+	json.NewEncoder(w).Encode(map[string]string{"message": "Enrolled device deleted successfully"})
 }
 
-func AddEnrolledDevice(ctx context.Context, w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+func AddEnrolledDevice(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
 	var req model.EnrolledDevice
 	w.Header().Set("Content-Type", "application/json")
 
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
 	// Parse the incoming JSON data from the request body
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		apierror.Write(w, r, apierror.InvalidJSON(err))
 		return
 	}
+	req.CustomerId = session.CustomerId
 
 	// validate the request
-	if req.CustomerId == 0 {
-		http.Error(w, "Customer Id cannot be 0", http.StatusBadRequest)
-		return
-	}
-	if req.ServiceLocationId == 0 {
-		http.Error(w, "Service Location Id cannot be 0", http.StatusBadRequest)
-		return
-	}
-	if req.DeviceId == 0 {
-		http.Error(w, "Device Id cannot be 0", http.StatusBadRequest)
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
 		return
 	}
 
-	// validation: check if service location exists by same customer
-	query := queryToCheckIfServiceLocationExists()
-	rows, err := db.Query(query, req.ServiceLocationId, req.CustomerId)
+	// validation: check if customer can manage devices of the service location
+	err = access.CheckServiceLocationPermission(ctx, conn, req.CustomerId, req.ServiceLocationId, access.ManageDevices)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
-	defer rows.Close()
 
-	var checkId uint32
-	for rows.Next() {
-		err = rows.Scan(&checkId)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-	}
-	if checkId != req.ServiceLocationId {
-		http.Error(w, "Service Location does not exist", http.StatusBadRequest)
+	redisKey := "AddEnrolledDevice_CustomerId_" + fmt.Sprint(req.CustomerId)
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
-	redisKey := "AddEnrolledDevice_CustomerId_" + fmt.Sprint(req.CustomerId)
 	defer func() {
-		// delete redis key
-		err = redisService.DeleteKey(ctx, redisClient, redisKey)
-		if err != nil {
-			fmt.Println("error while deleting redis key", err.Error())
+		// release redis lock
+		redisService.ReleaseLock(ctx, redisClient, redisKey)
+
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
 		}
 	}()
 
 	// take redis lock to avoid concurrent access or double clicking
-	redisErr := TakeRedisLock(ctx, redisClient, redisKey)
-	if len(redisErr) > 0 {
-		http.Error(w, redisErr, http.StatusBadRequest)
+	err = TakeRedisLock(ctx, redisClient, redisKey)
+	if err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
-	// insert query
-	query = queryToAddEnrolledDevice()
-	_, err = db.Exec(query, req.ServiceLocationId, req.DeviceId, req.AliasName, req.RoomNumber)
+	// enroll device
+	_, err = addEnrolledDevice(ctx, tx, r, session, req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
 
+	err = tx.Commit()
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
+
 	// respond with a success message
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Device enrolled successfully"))
+	json.NewEncoder(w).Encode(map[string]string{"message": "Device enrolled successfully"})
 }
 
-func UpdateEnrolledDevice(ctx context.Context, w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+func UpdateEnrolledDevice(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
 	var req model.EnrolledDevice
 	w.Header().Set("Content-Type", "application/json")
 
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
 	// Parse the incoming JSON data from the request body
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		apierror.Write(w, r, apierror.InvalidJSON(err))
 		return
 	}
+	req.CustomerId = session.CustomerId
 
 	// validate the request
-	if req.CustomerId == 0 {
-		http.Error(w, "Customer Id cannot be 0", http.StatusBadRequest)
-		return
-	}
+	errs := validation.Validate(req)
 	if req.Id == 0 {
-		http.Error(w, "Enrolled Device Id cannot be 0", http.StatusBadRequest)
-		return
+		errs = append(errs, validation.FieldError{Field: "id", Message: "is required"})
 	}
-	if req.ServiceLocationId == 0 {
-		http.Error(w, "Service Location Id cannot be 0", http.StatusBadRequest)
+	if len(errs) > 0 {
+		apierror.Write(w, r, errs)
 		return
 	}
-	if req.DeviceId == 0 {
-		http.Error(w, "Device Id cannot be 0", http.StatusBadRequest)
+
+	redisKey := "UpdateEnrolledDevice_CustomerId_" + fmt.Sprint(req.CustomerId)
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
-	redisKey := "UpdateEnrolledDevice_CustomerId_" + fmt.Sprint(req.CustomerId)
 	defer func() {
-		// delete redis key
-		err = redisService.DeleteKey(ctx, redisClient, redisKey)
-		if err != nil {
-			fmt.Println("error while deleting redis key", err.Error())
+		// release redis lock
+		redisService.ReleaseLock(ctx, redisClient, redisKey)
+
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
 		}
 	}()
 
 	// take redis lock to avoid concurrent access or double clicking
-	redisErr := TakeRedisLock(ctx, redisClient, redisKey)
-	if len(redisErr) > 0 {
-		http.Error(w, redisErr, http.StatusBadRequest)
+	err = TakeRedisLock(ctx, redisClient, redisKey)
+	if err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
-	// validation: check if enrolled device exists by same customer
-	query := queryToCheckIfEnrolledDeviceExists()
-	rows, err := db.Query(query, req.Id, req.CustomerId)
+	// validation: check if customer can manage devices of the enrolled device's
+	// current service location and of the one it is moved to
+	err = access.CheckEnrolledDevicePermission(ctx, tx, req.CustomerId, req.Id, access.ManageDevices)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
-	defer rows.Close()
-
-	var checkId int
-	for rows.Next() {
-		err = rows.Scan(&checkId)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-	}
-	if checkId != int(req.Id) {
-		http.Error(w, "Enrolled Device does not exist", http.StatusBadRequest)
+	err = access.CheckServiceLocationPermission(ctx, tx, req.CustomerId, req.ServiceLocationId, access.ManageDevices)
+	if err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
 	// update enrolled device
-	query = queryToUpdateEnrolledDevice()
-	_, err = db.Exec(query, req.ServiceLocationId, req.DeviceId, req.AliasName, req.RoomNumber, req.Id)
+	err = updateEnrolledDevice(ctx, tx, r, session, req)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	err = tx.Commit()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
 
 	// respond with a success message
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Enrolled device updated successfully"))
+	json.NewEncoder(w).Encode(map[string]string{"message": "Enrolled device updated successfully"})
 }
 
-func GetServiceLocations(ctx context.Context, w http.ResponseWriter, r *http.Request, db *sql.DB) {
+func GetServiceLocations(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
 	w.Header().Set("Content-Type", "application/json")
 
-	// Get customer id from query params
-	var customerIdInt int
-	customerIdStr := r.URL.Query().Get("customerId")
-	if len(customerIdStr) == 0 {
-		http.Error(w, "Customer Id cannot be empty", http.StatusBadRequest)
+	// the customer is the one the session belongs to
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
 		return
 	}
-	customerIdInt, err := strconv.Atoi(customerIdStr)
+	customerId := session.CustomerId
+
+	// Get status filter from query params
+	status, active, err := getListStatus(r)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-	if customerIdInt == 0 {
-		http.Error(w, "Customer Id cannot be 0", http.StatusBadRequest)
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
 		return
 	}
 
 	// get all service locations
 	query := queryToGetAllServiceLocations()
-	rows, err := db.Query(query, customerIdInt)
+	rows, err := db.QueryContext(ctx, query, customerId, status, active)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
 	defer rows.Close()
 
 	var serviceLocations []model.ServiceLocation
 	var sl model.ServiceLocation
+	var slDeletedAt sql.NullString
 	for rows.Next() {
-		err = rows.Scan(&sl.Id, &sl.CustomerId, &sl.DateTakenOver, &sl.OccupantsCount, &sl.UnitNumber, &sl.Street, &sl.City, &sl.State, &sl.Zipcode, &sl.Country, &sl.SquareFootage, &sl.BedroomsCount, &sl.Active)
+		err = rows.Scan(&sl.Id, &sl.CustomerId, &sl.DateTakenOver, &sl.OccupantsCount, &sl.UnitNumber, &sl.Street, &sl.City, &sl.State, &sl.Zipcode, &sl.Country, &sl.SquareFootage, &sl.BedroomsCount, &sl.Active, &slDeletedAt, &sl.Role)
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			apierror.Write(w, r, err)
 			return
 		}
+		sl.DeletedAt = slDeletedAt.String
 
 		serviceLocations = append(serviceLocations, sl)
 	}
@@ -695,92 +827,93 @@ func GetServiceLocations(ctx context.Context, w http.ResponseWriter, r *http.Req
 	json.NewEncoder(w).Encode(resp)
 }
 
-func DeleteServiceLocation(ctx context.Context, w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+func DeleteServiceLocation(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
 	w.Header().Set("Content-Type", "application/json")
 
-	// Get customer id from query params
-	var customerIdInt int
-	customerIdStr := r.URL.Query().Get("customerId")
-	if len(customerIdStr) == 0 {
-		http.Error(w, "Customer Id cannot be empty", http.StatusBadRequest)
-		return
-	}
-	customerIdInt, err := strconv.Atoi(customerIdStr)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
-		return
-	}
-	if customerIdInt == 0 {
-		http.Error(w, "Customer Id cannot be 0", http.StatusBadRequest)
+	// the customer is the one the session belongs to
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
 		return
 	}
+	customerId := session.CustomerId
 
 	// Get service location id from query params
-	var serviceLocationIdInt int
-	serviceLocationIdStr := r.URL.Query().Get("serviceLocationId")
-	if len(serviceLocationIdStr) == 0 {
-		http.Error(w, "Service Location Id cannot be empty", http.StatusBadRequest)
+	serviceLocationIdInt, err := GetIdFromQueryParams(r, "serviceLocationId", "Service Location Id")
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
 		return
 	}
-	serviceLocationIdInt, err = strconv.Atoi(serviceLocationIdStr)
+
+	// validation: check if customer can delete the service location
+	err = access.CheckServiceLocationPermission(ctx, conn, customerId, uint32(serviceLocationIdInt), access.DeleteServiceLocation)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		apierror.Write(w, r, err)
 		return
 	}
-	if serviceLocationIdInt == 0 {
-		http.Error(w, "Service Location Id cannot be 0", http.StatusBadRequest)
+
+	// deleting a service location needs two factor authentication when enabled
+	err = RequireMfa(ctx, r, conn, redisClient, customerId)
+	if err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
-	// validation: check if service location exists by same customer
-	query := queryToCheckIfServiceLocationExists()
-	rows, err := db.Query(query, serviceLocationIdInt, customerIdInt)
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
-	defer rows.Close()
 
-	var checkId int
-	for rows.Next() {
-		err = rows.Scan(&checkId)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
+	defer func() {
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
 		}
-	}
-	if checkId != serviceLocationIdInt {
-		http.Error(w, "Service Location does not exist", http.StatusBadRequest)
+	}()
+
+	// delete service location
+	err = deleteServiceLocation(ctx, tx, r, session, uint32(serviceLocationIdInt))
+	if err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
-	// delete service location
-	query = queryToDeleteServiceLocation()
-	_, err = db.Exec(query, serviceLocationIdInt)
+	err = tx.Commit()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
 
 	// respond with a success message
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Service location deleted successfully"))
+	json.NewEncoder(w).Encode(map[string]string{"message": "Service location deleted successfully"})
 }
 
-func AddServiceLocation(ctx context.Context, w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+func AddServiceLocation(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
 	var req model.ServiceLocation
 	w.Header().Set("Content-Type", "application/json")
 
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
 	// Parse the incoming JSON data from the request body
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		apierror.Write(w, r, apierror.InvalidJSON(err))
 		return
 	}
+	req.CustomerId = session.CustomerId
 
 	// validate the request
-	if req.CustomerId == 0 {
-		http.Error(w, "Customer Id cannot be 0", http.StatusBadRequest)
+	req.Zipcode = validation.NormalizePostalCode(req.Zipcode)
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
 		return
 	}
 
@@ -788,246 +921,122 @@ func AddServiceLocation(ctx context.Context, w http.ResponseWriter, r *http.Requ
 	rollback := true
 	tx, err := conn.BeginTx(ctx, nil)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
 
 	defer func() {
-		// delete redis key
-		err = redisService.DeleteKey(ctx, redisClient, redisKey)
-		if err != nil {
-			fmt.Println("error while deleting redis key", err.Error())
-		}
+		// release redis lock
+		redisService.ReleaseLock(ctx, redisClient, redisKey)
 
 		if rollback {
 			tx.Rollback()
-			fmt.Println("Transaction rolled back")
-		} else {
-			tx.Commit()
-			fmt.Println("Transaction committed")
+			slog.DebugContext(ctx, "transaction rolled back")
 		}
 	}()
 
 	// take redis lock to avoid concurrent access or double clicking
-	redisErr := TakeRedisLock(ctx, redisClient, redisKey)
-	if len(redisErr) > 0 {
-		http.Error(w, redisErr, http.StatusBadRequest)
-		return
-	}
-
-	// Check if same location already exists in locations table
-	rows, err := tx.Query("SELECT id FROM Locations WHERE unit_number = ? AND street = ? AND city = ? AND state = ? AND zipcode = ? AND country = ?", req.UnitNumber, req.Street, req.City, req.State, req.Zipcode, req.Country)
+	err = TakeRedisLock(ctx, redisClient, redisKey)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
-	defer rows.Close()
-
-	var locationId uint32
-	for rows.Next() {
-		err = rows.Scan(&locationId)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-	}
-
-	// Create new location if this does not exist
-	if locationId == 0 {
-		// Create location
-		_, err = tx.Exec("INSERT INTO Locations (unit_number, street, city, state, zipcode, country, square_footage, bedrooms_count) VALUES (?, ?, ?, ?, ?, ?, ?, ?)", req.UnitNumber, req.Street, req.City, req.State, req.Zipcode, req.Country, req.SquareFootage, req.BedroomsCount)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-
-		// Get location id
-		rows, err = tx.Query("SELECT id FROM Locations WHERE unit_number = ? AND street = ? AND city = ? AND state = ? AND zipcode = ? AND country = ?", req.UnitNumber, req.Street, req.City, req.State, req.Zipcode, req.Country)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		defer rows.Close()
-
-		for rows.Next() {
-			err = rows.Scan(&locationId)
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
-		}
-	}
 
-	// validation: check if service location exists by same customer
-	query := queryToCheckIfServiceLocationExistsByLocationId()
-	rows, err = tx.Query(query, locationId, req.CustomerId)
+	// add service location
+	_, err = addServiceLocation(ctx, tx, r, session, req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	defer rows.Close()
-
-	var serviceLocationId int
-	for rows.Next() {
-		err = rows.Scan(&serviceLocationId)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-	}
-	if serviceLocationId > 0 {
-		http.Error(w, "Service Location already exists", http.StatusBadRequest)
+		apierror.Write(w, r, err)
 		return
 	}
 
-	// insert query to add service location
-	query = queryToAddServiceLocation()
-	_, err = tx.Exec(query, req.CustomerId, locationId, req.DateTakenOver, req.OccupantsCount)
+	err = tx.Commit()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
-
 	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
 
 	// respond with a success message
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Service location added successfully"))
+	json.NewEncoder(w).Encode(map[string]string{"message": "Service location added successfully"})
 }
 
-func UpdateServiceLocation(ctx context.Context, w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+func UpdateServiceLocation(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
 	var req model.ServiceLocation
 	w.Header().Set("Content-Type", "application/json")
 
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
 	// Parse the incoming JSON data from the request body
 	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		apierror.Write(w, r, apierror.InvalidJSON(err))
 		return
 	}
+	req.CustomerId = session.CustomerId
 
 	// validate the request
+	req.Zipcode = validation.NormalizePostalCode(req.Zipcode)
+	errs := validation.Validate(req)
 	if req.Id == 0 {
-		http.Error(w, "Service Location Id cannot be 0", http.StatusBadRequest)
-		return
+		errs = append(errs, validation.FieldError{Field: "id", Message: "is required"})
 	}
-	if req.CustomerId == 0 {
-		http.Error(w, "Customer Id cannot be 0", http.StatusBadRequest)
+	if len(errs) > 0 {
+		apierror.Write(w, r, errs)
 		return
 	}
 
 	redisKey := "UpdateServiceLocation_CustomerId_" + fmt.Sprint(req.CustomerId)
-	defer func() {
-		// delete redis key
-		err = redisService.DeleteKey(ctx, redisClient, redisKey)
-		if err != nil {
-			fmt.Println("error while deleting redis key", err.Error())
-		}
-	}()
-
-	// take redis lock to avoid concurrent access or double clicking
-	redisErr := TakeRedisLock(ctx, redisClient, redisKey)
-	if len(redisErr) > 0 {
-		http.Error(w, redisErr, http.StatusBadRequest)
-		return
-	}
-
-	// Check if same location already exists in locations table
-	rows, err := db.Query("SELECT id FROM Locations WHERE unit_number = ? AND street = ? AND city = ? AND state = ? AND zipcode = ? AND country = ?", req.UnitNumber, req.Street, req.City, req.State, req.Zipcode, req.Country)
+	rollback := true
+	tx, err := conn.BeginTx(ctx, nil)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
-	defer rows.Close()
-
-	var locationId uint32
-	for rows.Next() {
-		err = rows.Scan(&locationId)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-	}
 
-	// Create new location if this does not exist
-	if locationId == 0 {
-		// Create location
-		_, err = db.Exec("INSERT INTO Locations (unit_number, street, city, state, zipcode, country, square_footage, bedrooms_count) VALUES (?, ?, ?, ?, ?, ?, ?, ?)", req.UnitNumber, req.Street, req.City, req.State, req.Zipcode, req.Country, req.SquareFootage, req.BedroomsCount)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-
-		// Get location id
-		rows, err = db.Query("SELECT id FROM Locations WHERE unit_number = ? AND street = ? AND city = ? AND state = ? AND zipcode = ? AND country = ?", req.UnitNumber, req.Street, req.City, req.State, req.Zipcode, req.Country)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-		defer rows.Close()
+	defer func() {
+		// release redis lock
+		redisService.ReleaseLock(ctx, redisClient, redisKey)
 
-		for rows.Next() {
-			err = rows.Scan(&locationId)
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
+		if rollback {
+			tx.Rollback()
+			slog.DebugContext(ctx, "transaction rolled back")
 		}
-	}
+	}()
 
-	// validation: check if service location exists by same customer
-	query := queryToCheckIfServiceLocationExistsByLocationId()
-	rows, err = db.Query(query, locationId, req.CustomerId)
+	// take redis lock to avoid concurrent access or double clicking
+	err = TakeRedisLock(ctx, redisClient, redisKey)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	defer rows.Close()
-
-	var serviceLocationId int
-	for rows.Next() {
-		err = rows.Scan(&serviceLocationId)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-	}
-	if serviceLocationId > 0 && serviceLocationId != int(req.Id) {
-		http.Error(w, "Service Location with same address already exists", http.StatusBadRequest)
+		apierror.Write(w, r, err)
 		return
 	}
 
-	// validation: check if service location id in request exists by same customer
-	query = queryToCheckIfServiceLocationExists()
-	rows, err = db.Query(query, req.Id, req.CustomerId)
+	// validation: check if customer can manage the service location in request
+	err = access.CheckServiceLocationPermission(ctx, tx, req.CustomerId, req.Id, access.ManageServiceLocation)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
-	defer rows.Close()
 
-	var checkId int
-	for rows.Next() {
-		err = rows.Scan(&checkId)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
-			return
-		}
-	}
-	if checkId != int(req.Id) {
-		http.Error(w, "Service location does not exist", http.StatusBadRequest)
+	// update service location
+	err = updateServiceLocation(ctx, tx, r, session, req)
+	if err != nil {
+		apierror.Write(w, r, err)
 		return
 	}
 
-	// update service location
-	query = queryToUpdateServiceLocation()
-	_, err = db.Exec(query, locationId, req.DateTakenOver, req.OccupantsCount, req.Id)
+	err = tx.Commit()
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apierror.Write(w, r, err)
 		return
 	}
+	rollback = false
+	slog.DebugContext(ctx, "transaction committed")
 
 	// respond with a success message
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte("Service location updated successfully"))
+	json.NewEncoder(w).Encode(map[string]string{"message": "Service location updated successfully"})
 }
@@ -0,0 +1,110 @@
+package users
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"shems/logging"
+	"shems/model"
+	redisService "shems/redis"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const sessionExpiry = 24 * time.Hour
+
+// Sessions opened by support admins on behalf of a customer are kept short
+const ImpersonationSessionExpiry = time.Hour
+
+var ErrNoSession = errors.New("Session is missing or has expired")
+
+func CreateSession(ctx context.Context, redisClient *redis.Client, session model.Session) (string, error) {
+	session.CreatedAt = time.Now().Format(time.RFC3339)
+	value, err := json.Marshal(session)
+	if err != nil {
+		return "", err
+	}
+
+	expiry := sessionExpiry
+	if session.ImpersonatedBy > 0 {
+		expiry = ImpersonationSessionExpiry
+	}
+
+	token := GenerateToken()
+	sessionKey := "Session_" + HashToken(token)
+	err = redisService.SetKeyWithExpiry(ctx, redisClient, sessionKey, value, expiry)
+	if err != nil {
+		return "", err
+	}
+
+	// sessions of a customer are indexed so that they can all be ended at once
+	err = redisService.AddToSet(ctx, redisClient, "CustomerSessions_"+fmt.Sprint(session.CustomerId), sessionKey, sessionExpiry)
+	return token, err
+}
+
+func getSessionToken(r *http.Request) string {
+	authorization := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer "))
+}
+
+// GetSession returns the session of the bearer token in the request
+func GetSession(ctx context.Context, redisClient *redis.Client, r *http.Request) (model.Session, error) {
+	var session model.Session
+	token := getSessionToken(r)
+	if len(token) == 0 {
+		return session, ErrNoSession
+	}
+
+	value, err := redisService.GetKey(ctx, redisClient, "Session_"+HashToken(token))
+	if err == redis.Nil {
+		return session, ErrNoSession
+	}
+	if err != nil {
+		return session, err
+	}
+
+	err = json.Unmarshal([]byte(value), &session)
+	if err != nil {
+		return session, err
+	}
+	logging.SetCustomerId(ctx, session.CustomerId)
+	return session, nil
+}
+
+// updateSession overwrites the session of the bearer token, keeping its expiry
+func updateSession(ctx context.Context, redisClient *redis.Client, r *http.Request, session model.Session) error {
+	value, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return redisService.SetKeyKeepTTL(ctx, redisClient, "Session_"+HashToken(getSessionToken(r)), value)
+}
+
+// DeleteSession logs out the bearer token of the request
+func DeleteSession(ctx context.Context, redisClient *redis.Client, r *http.Request) error {
+	return redisService.DeleteKey(ctx, redisClient, "Session_"+HashToken(getSessionToken(r)))
+}
+
+// DeleteCustomerSessions logs the customer out everywhere
+func DeleteCustomerSessions(ctx context.Context, redisClient *redis.Client, customerId uint32) error {
+	indexKey := "CustomerSessions_" + fmt.Sprint(customerId)
+	sessionKeys, err := redisService.GetSetMembers(ctx, redisClient, indexKey)
+	if err != nil {
+		return err
+	}
+
+	for _, sessionKey := range sessionKeys {
+		err = redisService.DeleteKey(ctx, redisClient, sessionKey)
+		if err != nil {
+			return err
+		}
+	}
+	return redisService.DeleteKey(ctx, redisClient, indexKey)
+}
@@ -1,5 +1,112 @@
 package users
 
+import "shems/tracing"
+
+func init() {
+	tracing.RegisterQueries(
+		queryToGetCustomerByEmail,
+		queryToVerifyCustomerEmail,
+		queryToUpdateCustomerPassword,
+		queryToFetchEnergyCostsByServiceLocations,
+		queryForAverageEnergyConsumptionForSimilarServiceLocations,
+		queryToGetEnrolledDevices,
+		queryToGetAllDevices,
+		queryToGetAllServiceLocations,
+		queryToAddEnrolledDevice,
+		queryToUpdateEnrolledDevice,
+		queryToDeleteEnrolledDevice,
+		queryToDeleteServiceLocation,
+		queryToAddServiceLocation,
+		queryToCheckIfServiceLocationExistsByLocationId,
+		queryToUpdateServiceLocation,
+		queryToGetHourlyPrices,
+		queryToFetchEnergyConsumptionByDevices,
+		queryToGetCustomerById,
+		queryToGetCustomerMfa,
+		queryToUpsertCustomerMfa,
+		queryToEnableCustomerMfa,
+		queryToDeleteCustomerMfa,
+		queryToGetRecoveryCodes,
+		queryToUseRecoveryCode,
+		queryToAddRecoveryCode,
+		queryToDeleteRecoveryCodes,
+		queryToAddServiceLocationMember,
+		queryToGetServiceLocationMembers,
+		queryToCountServiceLocationOwners,
+		queryToUpdateServiceLocationMemberRole,
+		queryToDeleteServiceLocationMember,
+		queryToAddServiceLocationInvitation,
+		queryToGetPendingServiceLocationInvitations,
+		queryToGetServiceLocationInvitationByToken,
+		queryToGetServiceLocationInvitation,
+		queryToAcceptServiceLocationInvitation,
+		queryToDeleteServiceLocationInvitation,
+		queryToGetEnrolledDeviceForUpdate,
+		queryToGetServiceLocationForUpdate,
+		queryToGetCustomerForUpdate,
+		queryToAddEnrolledDeviceHistory,
+		queryToCloseEnrolledDeviceHistory,
+		queryToAddServiceLocationHistory,
+		queryToCloseServiceLocationHistory,
+		queryToUpdateServiceLocationHistoryStart,
+		queryToGetActiveEnrolledDeviceIdsByServiceLocation,
+		queryToGetDeletedEnrolledDeviceIdsByServiceLocation,
+		queryToGetServiceLocationDeletedAt,
+		queryToRestoreEnrolledDevice,
+		queryToRestoreServiceLocation,
+		queryToReopenEnrolledDeviceHistory,
+		queryToReopenServiceLocationHistory,
+		queryToGetLocation,
+		queryToGetLocationIdByAddress,
+		queryToAddLocation,
+		queryToCheckCustomerLocation,
+		queryToGetCustomerIdByEmail,
+		queryToUpdateCustomerProfile,
+		queryToUpdateCustomerEmail,
+		queryToUpdateCustomerBillingAddress,
+		queryToListServiceLocations,
+		queryToGetServiceLocation,
+		queryToListEnrolledDevices,
+		queryToGetEnrolledDevice,
+	)
+}
+
+func queryToGetCustomerByEmail() string {
+	sqlQuery := `
+	SELECT
+		id, first_name, last_name, phone_number, email, billing_address_id, password, email_verified, active
+	FROM
+		Customers
+	WHERE
+		email = ?;
+	`
+	return sqlQuery
+}
+
+func queryToVerifyCustomerEmail() string {
+	sqlQuery := `
+				UPDATE
+					Customers
+				SET
+					email_verified = 1
+				WHERE
+					id = ?;
+				`
+	return sqlQuery
+}
+
+func queryToUpdateCustomerPassword() string {
+	sqlQuery := `
+				UPDATE
+					Customers
+				SET
+					password = ?
+				WHERE
+					id = ?;
+				`
+	return sqlQuery
+}
+
 func queryToFetchEnergyCostsByServiceLocations() string {
 	sqlQuery := `
 		SELECT
@@ -11,19 +118,26 @@ func queryToFetchEnergyCostsByServiceLocations() string {
 			l.state,
 			l.country,
 			SUM(CASE WHEN e.value IS NOT NULL THEN e.value ELSE 0 END) AS total_energy_consumption,
-			SUM(CASE WHEN e.value IS NOT NULL THEN e.value * p.value ELSE 0 END) AS total_energy_cost
+			SUM(CASE WHEN e.value IS NOT NULL THEN e.value * p.value ELSE 0 END) AS total_energy_cost,
+			SUM(CASE WHEN e.value IS NOT NULL AND ci.value IS NOT NULL THEN e.value * ci.value ELSE 0 END) / 1000 AS total_carbon_emissions
 		FROM
 			Service_Locations sl
+		INNER JOIN
+			Service_Location_History slh ON slh.service_location_id = sl.id AND (slh.valid_to IS NULL OR slh.valid_to > ?)
+		INNER JOIN
+			Locations l ON l.id = slh.location_id
 		LEFT JOIN
-			Locations l ON l.id = sl.location_id
+			Enrolled_Device_History edh ON edh.service_location_id = sl.id
 		LEFT JOIN
-			Enrolled_Devices ed ON ed.service_location_id = sl.id
-		LEFT JOIN
-			Events e ON e.enrolled_device_id = ed.id AND e.label = 'energy use' AND e.created_at >= ? AND e.created_at <= ?
+			Events e ON e.enrolled_device_id = edh.enrolled_device_id AND e.label = 'energy use' AND e.created_at >= ? AND e.created_at <= ?
+				AND e.created_at >= edh.valid_from AND (edh.valid_to IS NULL OR e.created_at < edh.valid_to)
+				AND e.created_at >= slh.valid_from AND (slh.valid_to IS NULL OR e.created_at < slh.valid_to)
 		LEFT JOIN
 			Prices p ON p.zipcode = l.zipcode AND p.hour = HOUR(e.created_at) + 1
+		LEFT JOIN
+			Carbon_Intensities ci ON ci.zipcode = l.zipcode AND ci.hour = HOUR(e.created_at) + 1
 		WHERE
-			sl.customer_id = ?
+			sl.id IN (SELECT service_location_id FROM Service_Location_Members WHERE customer_id = ?)
 		GROUP BY
 			1, 2, 3, 4, 5, 6, 7;
 	`
@@ -40,11 +154,13 @@ func queryForAverageEnergyConsumptionForSimilarServiceLocations() string {
 	INNER JOIN
 		Locations l3 ON l3.square_footage >= l2.square_footage * 0.95 AND l3.square_footage <= l2.square_footage * 1.05
 	LEFT JOIN
-		Service_Locations sl2 ON sl2.location_id = l3.id
+		Service_Location_History slh2 ON slh2.location_id = l3.id
 	LEFT JOIN
-		Enrolled_Devices ed2 ON ed2.service_location_id = sl2.id
+		Enrolled_Device_History edh2 ON edh2.service_location_id = slh2.service_location_id
 	LEFT JOIN
-		Events e2 ON e2.enrolled_device_id = ed2.id AND e2.label = 'energy use' AND e2.created_at >= ? AND e2.created_at <= ?
+		Events e2 ON e2.enrolled_device_id = edh2.enrolled_device_id AND e2.label = 'energy use' AND e2.created_at >= ? AND e2.created_at <= ?
+			AND e2.created_at >= edh2.valid_from AND (edh2.valid_to IS NULL OR e2.created_at < edh2.valid_to)
+			AND e2.created_at >= slh2.valid_from AND (slh2.valid_to IS NULL OR e2.created_at < slh2.valid_to)
 	GROUP BY
 		l2.id;
 	`
@@ -54,13 +170,14 @@ func queryForAverageEnergyConsumptionForSimilarServiceLocations() string {
 func queryToGetEnrolledDevices() string {
 	sqlQuery := `
 	SELECT
-		ed.*
+		ed.id, ed.service_location_id, ed.device_id, ed.alias_name, ed.room_number, ed.active, ed.deleted_at
 	FROM
 		Enrolled_Devices ed
 	INNER JOIN
 		Service_Locations sl ON sl.id = ed.service_location_id
 	WHERE
-		sl.customer_id = ?
+		sl.id IN (SELECT service_location_id FROM Service_Location_Members WHERE customer_id = ?)
+		AND (? = 'all' OR ed.active = ?)
 	ORDER BY
 		sl.id, ed.id;
 	`
@@ -80,28 +197,16 @@ func queryToGetAllDevices() string {
 func queryToGetAllServiceLocations() string {
 	sqlQuery := `
 	SELECT
-		sl.id, sl.customer_id, sl.date_taken_over, sl.occupants_count, l.unit_number, l.street, l.city, l.state, l.zipcode, l.country, l.square_footage, l.bedrooms_count, sl.active
+		sl.id, sl.customer_id, sl.date_taken_over, sl.occupants_count, l.unit_number, l.street, l.city, l.state, l.zipcode, l.country, l.square_footage, l.bedrooms_count, sl.active, sl.deleted_at, slm.role
 	FROM
 		service_locations sl
 	INNER JOIN
 		Locations l ON l.id = sl.location_id
-	WHERE
-		customer_id = ?;
-	`
-	return sqlQuery
-}
-
-func queryToCheckIfEnrolledDeviceExists() string {
-	sqlQuery := `
-	SELECT
-		ed.id
-	FROM
-		Enrolled_Devices ed
 	INNER JOIN
-		Service_Locations sl ON ed.service_location_id = sl.id
+		Service_Location_Members slm ON slm.service_location_id = sl.id
 	WHERE
-		ed.id = ?
-		AND sl.customer_id = ?;
+		slm.customer_id = ?
+		AND (? = 'all' OR sl.active = ?);
 	`
 	return sqlQuery
 }
@@ -136,34 +241,25 @@ func queryToDeleteEnrolledDevice() string {
 				Update
 					Enrolled_Devices
 				SET
-					Active = 0
+					Active = 0,
+					deleted_at = ?
 				WHERE
-					id = ?;
+					id = ?
+					AND active = 1;
 				`
 	return sqlQuery
 }
 
-func queryToCheckIfServiceLocationExists() string {
-	sqlQuery := `
-	SELECT
-		id
-	FROM
-		Service_Locations
-	WHERE
-		id = ?
-		AND customer_id = ?;
-	`
-	return sqlQuery
-}
-
 func queryToDeleteServiceLocation() string {
 	sqlQuery := `
 				UPDATE
 					Service_Locations
 				SET
-					Active = 0
+					Active = 0,
+					deleted_at = ?
 				WHERE
-					id = ?;
+					id = ?
+					AND active = 1;
 				`
 	return sqlQuery
 }
@@ -181,12 +277,12 @@ func queryToAddServiceLocation() string {
 func queryToCheckIfServiceLocationExistsByLocationId() string {
 	sqlQuery := `
 	SELECT
-		Id
+		sl.id
 	FROM
-		Service_Locations
+		Service_Locations sl
 	WHERE
-		location_id = ?
-		AND customer_id = ?;
+		sl.location_id = ?
+		AND sl.id IN (SELECT service_location_id FROM Service_Location_Members WHERE customer_id = ?);
 	`
 	return sqlQuery
 }
@@ -217,19 +313,658 @@ func queryToFetchEnergyConsumptionByDevices() string {
 		d.type,
 		d.model_number,
 		ed.alias_name,
-		SUM(CASE WHEN e.value IS NOT NULL THEN e.value ELSE 0 END) AS total_energy_consumption
+		SUM(CASE WHEN e.value IS NOT NULL THEN e.value ELSE 0 END) AS total_energy_consumption,
+		SUM(CASE WHEN e.value IS NOT NULL AND ci.value IS NOT NULL THEN e.value * ci.value ELSE 0 END) / 1000 AS total_carbon_emissions
 	FROM
 		Enrolled_Devices ed
 	INNER JOIN
-		Service_Locations sl ON sl.id = ed.service_location_id
+		Enrolled_Device_History edh ON edh.enrolled_device_id = ed.id
+	INNER JOIN
+		Service_Locations sl ON sl.id = edh.service_location_id
+	INNER JOIN
+		Service_Location_History slh ON slh.service_location_id = sl.id
+	INNER JOIN
+		Locations l ON l.id = slh.location_id
 	INNER JOIN
 		Devices d ON d.id = ed.device_id
 	LEFT JOIN
 		Events e ON e.enrolled_device_id = ed.id AND e.label = 'energy use' AND e.created_at >= ? AND e.created_at <= ?
+			AND e.created_at >= edh.valid_from AND (edh.valid_to IS NULL OR e.created_at < edh.valid_to)
+			AND e.created_at >= slh.valid_from AND (slh.valid_to IS NULL OR e.created_at < slh.valid_to)
+	LEFT JOIN
+		Carbon_Intensities ci ON ci.zipcode = l.zipcode AND ci.hour = HOUR(e.created_at) + 1
 	WHERE
-		sl.customer_id = ?
+		sl.id IN (SELECT service_location_id FROM Service_Location_Members WHERE customer_id = ?)
 	GROUP BY
 		1, 2, 3, 4;
 	`
 	return sqlQuery
 }
+
+func queryToGetCustomerById() string {
+	sqlQuery := `
+	SELECT
+		id, first_name, last_name, phone_number, email, billing_address_id, password, email_verified, active
+	FROM
+		Customers
+	WHERE
+		id = ?;
+	`
+	return sqlQuery
+}
+
+func queryToGetCustomerMfa() string {
+	sqlQuery := `
+	SELECT
+		secret, enabled
+	FROM
+		Customer_Mfa
+	WHERE
+		customer_id = ?;
+	`
+	return sqlQuery
+}
+
+func queryToUpsertCustomerMfa() string {
+	sqlQuery := `
+				INSERT INTO Customer_Mfa
+					(customer_id, secret, enabled)
+				VALUES
+					(?, ?, 0)
+				ON DUPLICATE KEY UPDATE
+					secret = VALUES(secret),
+					enabled = 0,
+					created_at = CURRENT_TIMESTAMP;
+				`
+	return sqlQuery
+}
+
+func queryToEnableCustomerMfa() string {
+	sqlQuery := `
+				UPDATE
+					Customer_Mfa
+				SET
+					enabled = 1
+				WHERE
+					customer_id = ?;
+				`
+	return sqlQuery
+}
+
+func queryToDeleteCustomerMfa() string {
+	sqlQuery := `
+				DELETE FROM
+					Customer_Mfa
+				WHERE
+					customer_id = ?;
+				`
+	return sqlQuery
+}
+
+func queryToGetRecoveryCodes() string {
+	sqlQuery := `
+	SELECT
+		id, code_hash
+	FROM
+		Mfa_Recovery_Codes
+	WHERE
+		customer_id = ?
+		AND used_at IS NULL;
+	`
+	return sqlQuery
+}
+
+func queryToUseRecoveryCode() string {
+	sqlQuery := `
+				UPDATE
+					Mfa_Recovery_Codes
+				SET
+					used_at = CURRENT_TIMESTAMP
+				WHERE
+					id = ?
+					AND used_at IS NULL;
+				`
+	return sqlQuery
+}
+
+func queryToAddRecoveryCode() string {
+	sqlQuery := `
+				INSERT INTO Mfa_Recovery_Codes
+					(customer_id, code_hash)
+				VALUES
+					(?, ?);
+				`
+	return sqlQuery
+}
+
+func queryToDeleteRecoveryCodes() string {
+	sqlQuery := `
+				DELETE FROM
+					Mfa_Recovery_Codes
+				WHERE
+					customer_id = ?;
+				`
+	return sqlQuery
+}
+
+func queryToAddServiceLocationMember() string {
+	sqlQuery := `
+				INSERT INTO Service_Location_Members
+					(service_location_id, customer_id, role)
+				VALUES
+					(?, ?, ?);
+				`
+	return sqlQuery
+}
+
+func queryToGetServiceLocationMembers() string {
+	sqlQuery := `
+	SELECT
+		c.id, c.first_name, c.last_name, c.email, slm.role, slm.created_at
+	FROM
+		Service_Location_Members slm
+	INNER JOIN
+		Customers c ON c.id = slm.customer_id
+	WHERE
+		slm.service_location_id = ?
+	ORDER BY
+		slm.created_at, c.id;
+	`
+	return sqlQuery
+}
+
+func queryToCountServiceLocationOwners() string {
+	sqlQuery := `
+	SELECT
+		COUNT(*)
+	FROM
+		Service_Location_Members
+	WHERE
+		service_location_id = ?
+		AND role = 'owner';
+	`
+	return sqlQuery
+}
+
+func queryToUpdateServiceLocationMemberRole() string {
+	sqlQuery := `
+				UPDATE
+					Service_Location_Members
+				SET
+					role = ?
+				WHERE
+					service_location_id = ?
+					AND customer_id = ?;
+				`
+	return sqlQuery
+}
+
+func queryToDeleteServiceLocationMember() string {
+	sqlQuery := `
+				DELETE FROM
+					Service_Location_Members
+				WHERE
+					service_location_id = ?
+					AND customer_id = ?;
+				`
+	return sqlQuery
+}
+
+func queryToAddServiceLocationInvitation() string {
+	sqlQuery := `
+				INSERT INTO Service_Location_Invitations
+					(service_location_id, email, role, token_hash, invited_by, expires_at)
+				VALUES
+					(?, ?, ?, ?, ?, DATE_ADD(NOW(), INTERVAL ? DAY));
+				`
+	return sqlQuery
+}
+
+func queryToGetPendingServiceLocationInvitations() string {
+	sqlQuery := `
+	SELECT
+		id, email, role, invited_by, created_at, expires_at
+	FROM
+		Service_Location_Invitations
+	WHERE
+		service_location_id = ?
+		AND accepted_at IS NULL
+		AND expires_at > NOW()
+	ORDER BY
+		created_at;
+	`
+	return sqlQuery
+}
+
+func queryToGetServiceLocationInvitationByToken() string {
+	sqlQuery := `
+	SELECT
+		id, service_location_id, email, role
+	FROM
+		Service_Location_Invitations
+	WHERE
+		token_hash = ?
+		AND accepted_at IS NULL
+		AND expires_at > NOW();
+	`
+	return sqlQuery
+}
+
+func queryToGetServiceLocationInvitation() string {
+	sqlQuery := `
+	SELECT
+		service_location_id, email, role
+	FROM
+		Service_Location_Invitations
+	WHERE
+		id = ?
+		AND accepted_at IS NULL;
+	`
+	return sqlQuery
+}
+
+func queryToAcceptServiceLocationInvitation() string {
+	sqlQuery := `
+				UPDATE
+					Service_Location_Invitations
+				SET
+					accepted_at = CURRENT_TIMESTAMP
+				WHERE
+					id = ?
+					AND accepted_at IS NULL;
+				`
+	return sqlQuery
+}
+
+func queryToDeleteServiceLocationInvitation() string {
+	sqlQuery := `
+				DELETE FROM
+					Service_Location_Invitations
+				WHERE
+					id = ?;
+				`
+	return sqlQuery
+}
+
+func queryToGetEnrolledDeviceForUpdate() string {
+	sqlQuery := `
+	SELECT
+		id, service_location_id, device_id, alias_name, room_number, active
+	FROM
+		Enrolled_Devices
+	WHERE
+		id = ?
+	FOR UPDATE;
+	`
+	return sqlQuery
+}
+
+func queryToGetServiceLocationForUpdate() string {
+	sqlQuery := `
+	SELECT
+		id, location_id, date_taken_over, occupants_count, active
+	FROM
+		Service_Locations
+	WHERE
+		id = ?
+	FOR UPDATE;
+	`
+	return sqlQuery
+}
+
+func queryToGetCustomerForUpdate() string {
+	sqlQuery := `
+	SELECT
+		id, first_name, last_name, phone_number, email, billing_address_id, email_verified, active
+	FROM
+		Customers
+	WHERE
+		id = ?
+	FOR UPDATE;
+	`
+	return sqlQuery
+}
+
+func queryToAddEnrolledDeviceHistory() string {
+	sqlQuery := `
+				INSERT INTO Enrolled_Device_History
+					(enrolled_device_id, service_location_id, valid_from)
+				VALUES
+					(?, ?, ?);
+				`
+	return sqlQuery
+}
+
+func queryToCloseEnrolledDeviceHistory() string {
+	sqlQuery := `
+				UPDATE
+					Enrolled_Device_History
+				SET
+					valid_to = ?
+				WHERE
+					enrolled_device_id = ?
+					AND valid_to IS NULL;
+				`
+	return sqlQuery
+}
+
+func queryToAddServiceLocationHistory() string {
+	sqlQuery := `
+				INSERT INTO Service_Location_History
+					(service_location_id, location_id, occupants_count, valid_from)
+				VALUES
+					(?, ?, ?, ?);
+				`
+	return sqlQuery
+}
+
+func queryToCloseServiceLocationHistory() string {
+	sqlQuery := `
+				UPDATE
+					Service_Location_History
+				SET
+					valid_to = ?
+				WHERE
+					service_location_id = ?
+					AND valid_to IS NULL;
+				`
+	return sqlQuery
+}
+
+func queryToUpdateServiceLocationHistoryStart() string {
+	sqlQuery := `
+				UPDATE
+					Service_Location_History h
+				JOIN
+					(SELECT id FROM Service_Location_History WHERE service_location_id = ? ORDER BY valid_from LIMIT 1) oldest ON oldest.id = h.id
+				SET
+					h.valid_from = ?
+				WHERE
+					h.valid_to IS NULL OR h.valid_to > ?;
+				`
+	return sqlQuery
+}
+
+func queryToGetActiveEnrolledDeviceIdsByServiceLocation() string {
+	sqlQuery := `
+	SELECT
+		id
+	FROM
+		Enrolled_Devices
+	WHERE
+		service_location_id = ?
+		AND active = 1
+	FOR UPDATE;
+	`
+	return sqlQuery
+}
+
+func queryToGetDeletedEnrolledDeviceIdsByServiceLocation() string {
+	sqlQuery := `
+	SELECT
+		id
+	FROM
+		Enrolled_Devices
+	WHERE
+		service_location_id = ?
+		AND active = 0
+		AND deleted_at = ?
+	FOR UPDATE;
+	`
+	return sqlQuery
+}
+
+func queryToGetServiceLocationDeletedAt() string {
+	sqlQuery := `
+	SELECT
+		deleted_at
+	FROM
+		Service_Locations
+	WHERE
+		id = ?
+	FOR UPDATE;
+	`
+	return sqlQuery
+}
+
+func queryToRestoreEnrolledDevice() string {
+	sqlQuery := `
+				UPDATE
+					Enrolled_Devices
+				SET
+					active = 1,
+					deleted_at = NULL
+				WHERE
+					id = ?
+					AND active = 0;
+				`
+	return sqlQuery
+}
+
+func queryToRestoreServiceLocation() string {
+	sqlQuery := `
+				UPDATE
+					Service_Locations
+				SET
+					active = 1,
+					deleted_at = NULL
+				WHERE
+					id = ?
+					AND active = 0;
+				`
+	return sqlQuery
+}
+
+func queryToReopenEnrolledDeviceHistory() string {
+	sqlQuery := `
+				UPDATE
+					Enrolled_Device_History
+				SET
+					valid_to = NULL
+				WHERE
+					enrolled_device_id = ?
+				ORDER BY
+					valid_from DESC
+				LIMIT 1;
+				`
+	return sqlQuery
+}
+
+func queryToReopenServiceLocationHistory() string {
+	sqlQuery := `
+				UPDATE
+					Service_Location_History
+				SET
+					valid_to = NULL
+				WHERE
+					service_location_id = ?
+				ORDER BY
+					valid_from DESC
+				LIMIT 1;
+				`
+	return sqlQuery
+}
+
+func queryToGetLocation() string {
+	sqlQuery := `
+	SELECT
+		id, unit_number, street, city, state, zipcode, country, square_footage, bedrooms_count
+	FROM
+		Locations
+	WHERE
+		id = ?;
+	`
+	return sqlQuery
+}
+
+func queryToGetLocationIdByAddress() string {
+	sqlQuery := `
+	SELECT
+		id
+	FROM
+		Locations
+	WHERE
+		unit_number = ?
+		AND street = ?
+		AND city = ?
+		AND state = ?
+		AND zipcode = ?
+		AND country = ?;
+	`
+	return sqlQuery
+}
+
+func queryToAddLocation() string {
+	sqlQuery := `
+				INSERT INTO Locations
+					(unit_number, street, city, state, zipcode, country, square_footage, bedrooms_count)
+				VALUES
+					(?, ?, ?, ?, ?, ?, ?, ?);
+				`
+	return sqlQuery
+}
+
+// Customers can use the address of any service location they are a member of
+// as their billing address
+func queryToCheckCustomerLocation() string {
+	sqlQuery := `
+	SELECT
+		COUNT(*)
+	FROM
+		Service_Locations sl
+	INNER JOIN
+		Service_Location_Members slm ON slm.service_location_id = sl.id
+	WHERE
+		slm.customer_id = ?
+		AND sl.location_id = ?;
+	`
+	return sqlQuery
+}
+
+func queryToGetCustomerIdByEmail() string {
+	sqlQuery := `
+	SELECT
+		id
+	FROM
+		Customers
+	WHERE
+		email = ?
+	FOR UPDATE;
+	`
+	return sqlQuery
+}
+
+func queryToUpdateCustomerProfile() string {
+	sqlQuery := `
+				UPDATE
+					Customers
+				SET
+					first_name = ?,
+					last_name = ?,
+					phone_number = ?
+				WHERE
+					id = ?;
+				`
+	return sqlQuery
+}
+
+func queryToUpdateCustomerEmail() string {
+	sqlQuery := `
+				UPDATE
+					Customers
+				SET
+					email = ?,
+					email_verified = 1
+				WHERE
+					id = ?;
+				`
+	return sqlQuery
+}
+
+func queryToUpdateCustomerBillingAddress() string {
+	sqlQuery := `
+				UPDATE
+					Customers
+				SET
+					billing_address_id = ?
+				WHERE
+					id = ?;
+				`
+	return sqlQuery
+}
+
+func queryToListServiceLocations() string {
+	sqlQuery := `
+	SELECT
+		sl.id, sl.customer_id, sl.date_taken_over, sl.occupants_count, l.unit_number, l.street, l.city, l.state, l.zipcode, l.country, l.square_footage, l.bedrooms_count, sl.active, sl.deleted_at, slm.role
+	FROM
+		Service_Locations sl
+	INNER JOIN
+		Locations l ON l.id = sl.location_id
+	INNER JOIN
+		Service_Location_Members slm ON slm.service_location_id = sl.id
+	WHERE
+		slm.customer_id = ?
+		AND (? = 'all' OR sl.active = ?)
+		AND (? = '' OR l.city = ?)
+		AND (? = '' OR l.state = ?)
+		AND (? = '' OR l.zipcode = ?)
+		AND sl.id > ?
+	ORDER BY
+		sl.id
+	LIMIT ?;
+	`
+	return sqlQuery
+}
+
+func queryToGetServiceLocation() string {
+	sqlQuery := `
+	SELECT
+		sl.id, sl.customer_id, sl.date_taken_over, sl.occupants_count, l.unit_number, l.street, l.city, l.state, l.zipcode, l.country, l.square_footage, l.bedrooms_count, sl.active, sl.deleted_at, slm.role
+	FROM
+		Service_Locations sl
+	INNER JOIN
+		Locations l ON l.id = sl.location_id
+	INNER JOIN
+		Service_Location_Members slm ON slm.service_location_id = sl.id
+	WHERE
+		slm.customer_id = ?
+		AND sl.id = ?;
+	`
+	return sqlQuery
+}
+
+func queryToListEnrolledDevices() string {
+	sqlQuery := `
+	SELECT
+		ed.id, ed.service_location_id, ed.device_id, ed.alias_name, ed.room_number, ed.active, ed.deleted_at, d.type, d.model_number
+	FROM
+		Enrolled_Devices ed
+	INNER JOIN
+		Devices d ON d.id = ed.device_id
+	WHERE
+		ed.service_location_id = ?
+		AND (? = 'all' OR ed.active = ?)
+		AND (? = '' OR d.type = ?)
+		AND ed.id > ?
+	ORDER BY
+		ed.id
+	LIMIT ?;
+	`
+	return sqlQuery
+}
+
+func queryToGetEnrolledDevice() string {
+	sqlQuery := `
+	SELECT
+		ed.id, ed.service_location_id, ed.device_id, ed.alias_name, ed.room_number, ed.active, ed.deleted_at, d.type, d.model_number
+	FROM
+		Enrolled_Devices ed
+	INNER JOIN
+		Devices d ON d.id = ed.device_id
+	WHERE
+		ed.service_location_id = ?
+		AND ed.id = ?;
+	`
+	return sqlQuery
+}
@@ -0,0 +1,153 @@
+package users_test
+
+import (
+	"context"
+	"net"
+	"shems/users"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// The secret of the RFC 6238 test vectors, "12345678901234567890" in base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// Codes are the last 6 digits of the 8 digit SHA-1 codes in RFC 6238 appendix B
+var rfcVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestGetTotpCode(t *testing.T) {
+	for _, v := range rfcVectors {
+		code, err := users.GetTotpCode(rfcSecret, uint64(v.unix/30))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if code != v.code {
+			t.Errorf("code at %d: got %s, want %s", v.unix, code, v.code)
+		}
+	}
+
+	// secrets are typed in by hand, so lower case is accepted
+	code, err := users.GetTotpCode(strings.ToLower(rfcSecret), 1)
+	if err != nil || code != rfcVectors[0].code {
+		t.Errorf("lower case secret: got %s, %v, want %s", code, err, rfcVectors[0].code)
+	}
+
+	_, err = users.GetTotpCode("not base32!", 1)
+	if err == nil {
+		t.Error("invalid secret: got no error")
+	}
+}
+
+func TestValidateTotpCode(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := uint64(now.Unix() / 30)
+	codeAt := func(counter uint64) string {
+		code, err := users.GetTotpCode(rfcSecret, counter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name string
+		code string
+		want uint64
+	}{
+		{name: "current step", code: codeAt(current), want: current},
+		{name: "previous step", code: codeAt(current - 1), want: current - 1},
+		{name: "next step", code: codeAt(current + 1), want: current + 1},
+		{name: "spaces are ignored", code: " " + codeAt(current)[:3] + " " + codeAt(current)[3:] + " ", want: current},
+		{name: "two steps ago", code: codeAt(current - 2)},
+		{name: "two steps ahead", code: codeAt(current + 2)},
+		{name: "too short", code: codeAt(current)[:5]},
+		{name: "too long", code: codeAt(current) + "0"},
+		{name: "empty", code: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := users.ValidateTotpCode(rfcSecret, tt.code, now); got != tt.want {
+				t.Errorf("got step %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeSetNX answers SET NX commands from memory so that no redis server is
+// needed, every other command fails
+type fakeSetNX struct {
+	mu   sync.Mutex
+	keys map[string]bool
+}
+
+func (f *fakeSetNX) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, context.Canceled
+	}
+}
+
+func (f *fakeSetNX) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		boolCmd, ok := cmd.(*redis.BoolCmd)
+		if !ok || cmd.Name() != "set" {
+			return next(ctx, cmd)
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		key := cmd.Args()[1].(string)
+		boolCmd.SetVal(!f.keys[key])
+		f.keys[key] = true
+		return nil
+	}
+}
+
+func (f *fakeSetNX) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func TestVerifyTotpCodeRejectsReplay(t *testing.T) {
+	redisClient := redis.NewClient(&redis.Options{})
+	defer redisClient.Close()
+	redisClient.AddHook(&fakeSetNX{keys: make(map[string]bool)})
+	ctx := context.Background()
+
+	code, err := users.GetTotpCode(rfcSecret, uint64(time.Now().Unix()/30))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verified, err := users.VerifyTotpCode(ctx, redisClient, 1, rfcSecret, code)
+	if err != nil || !verified {
+		t.Fatalf("first use: got %v, %v, want the code to be accepted", verified, err)
+	}
+	verified, err = users.VerifyTotpCode(ctx, redisClient, 1, rfcSecret, code)
+	if err != nil || verified {
+		t.Errorf("replay: got %v, %v, want the code to be rejected", verified, err)
+	}
+
+	// time steps are claimed per customer
+	verified, err = users.VerifyTotpCode(ctx, redisClient, 2, rfcSecret, code)
+	if err != nil || !verified {
+		t.Errorf("other customer: got %v, %v, want the code to be accepted", verified, err)
+	}
+
+	// wrong codes are rejected without claiming anything
+	verified, err = users.VerifyTotpCode(ctx, redisClient, 3, rfcSecret, "000000")
+	if err != nil || verified {
+		t.Errorf("wrong code: got %v, %v, want the code to be rejected", verified, err)
+	}
+}
@@ -2,6 +2,10 @@ package users
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"shems/apierror"
+	"shems/metrics"
 	redisService "shems/redis"
 	"strconv"
 	"time"
@@ -15,6 +19,13 @@ func GetPasswordHash(password string) (string, error) {
 	return string(bytes), err
 }
 
+// Recovery codes are random and there are several per customer, so they use
+// the default cost to keep verification quick
+func getRecoveryCodeHash(code string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	return string(bytes), err
+}
+
 func CheckPasswordHash(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
@@ -24,7 +35,7 @@ func GetStartOfMonth(dateString string) (time.Time, error) {
 	// Parse the date string
 	date, err := time.Parse("01/02/2006", dateString)
 	if err != nil {
-		return time.Time{}, err
+		return time.Time{}, fmt.Errorf("Current Date must be in MM/DD/YYYY format")
 	}
 
 	// Get the start of the month
@@ -33,32 +44,52 @@ func GetStartOfMonth(dateString string) (time.Time, error) {
 	return startOfMonth, nil
 }
 
-func TakeRedisLock(ctx context.Context, redisClient *redis.Client, key string) string {
+// TakeRedisLock fails with a conflict while another request holds the lock.
+// Locks expire after an hour, which only frees the locks of requests that
+// never released them, so they are released with redisService.ReleaseLock,
+// which is not canceled along with the request.
+func TakeRedisLock(ctx context.Context, redisClient *redis.Client, key string) error {
 	// first check if redis lock already exists
 	val, err := redisService.GetKey(ctx, redisClient, key)
 
 	// redis.Nil error indicates that key is not present in redis
 	if err != nil && err != redis.Nil {
-		return "error while getting key from redis"
+		return fmt.Errorf("error while getting key from redis: %w", err)
 	}
 
 	// parse redis value
 	if len(val) > 0 {
 		boolVal, err := strconv.ParseBool(val)
 		if err != nil {
-			return "error while parsing redis value"
+			return fmt.Errorf("error while parsing redis value: %w", err)
 		}
 
 		// lock already exists
 		if boolVal {
-			return "redis lock already exists"
+			metrics.LockConflict(key)
+			return apierror.Conflict("Another request is already in progress, please try again")
 		}
 	}
 
 	// set key in redis to take lock
 	err = redisService.SetKey(ctx, redisClient, key, true)
 	if err != nil {
-		return "error while setting redis key"
+		return fmt.Errorf("error while setting redis key: %w", err)
+	}
+	return nil
+}
+
+func GetIdFromQueryParams(r *http.Request, key string, name string) (int, error) {
+	idStr := r.URL.Query().Get(key)
+	if len(idStr) == 0 {
+		return 0, fmt.Errorf("%s cannot be empty", name)
+	}
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a number", name)
+	}
+	if id == 0 {
+		return 0, fmt.Errorf("%s cannot be 0", name)
 	}
-	return ""
+	return int(id), nil
 }
@@ -0,0 +1,608 @@
+package users
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"shems/access"
+	"shems/apierror"
+	"shems/model"
+	"shems/validation"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/redis/go-redis/v9"
+)
+
+// The v2 API is resource oriented. The customer is the one of the bearer
+// session instead of an id in the request, list endpoints are paginated with
+// an opaque cursor, and updates need the ETag of the resource in If-Match so
+// that changes made in between are not overwritten.
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func getIdFromPath(r *http.Request, key string, name string) (uint32, error) {
+	id, err := strconv.ParseUint(mux.Vars(r)[key], 10, 32)
+	if err != nil || id == 0 {
+		return 0, apierror.NotFound(fmt.Sprintf("%s does not exist", name))
+	}
+	return uint32(id), nil
+}
+
+func encodeCursor(id uint32) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprint(id)))
+}
+
+// getPageLimit reads the page size of the list endpoints
+func getPageLimit(r *http.Request) (int, error) {
+	limit := defaultPageSize
+	limitStr := r.URL.Query().Get("limit")
+	if len(limitStr) > 0 {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			return 0, fmt.Errorf("Limit must be between 1 and %d", maxPageSize)
+		}
+	}
+	return limit, nil
+}
+
+// decodeCursor returns the id after which the page starts, 0 for the first
+// page. The cursor is the id of the last item of the previous page.
+func decodeCursor(cursor string) (uint32, error) {
+	if len(cursor) == 0 {
+		return 0, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("Cursor is invalid")
+	}
+	afterId, err := strconv.ParseUint(string(decoded), 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("Cursor is invalid")
+	}
+	return uint32(afterId), nil
+}
+
+// getETag identifies the current state of a resource representation
+func getETag(v interface{}) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return fmt.Sprintf(`"%x"`, sum[:16]), nil
+}
+
+// getIfMatch returns the If-Match header, with which the client changes the
+// resource in the state it last fetched. It is mandatory when required,
+// otherwise it is only checked when given.
+func getIfMatch(r *http.Request, required bool) (string, error) {
+	ifMatch := r.Header.Get("If-Match")
+	if len(ifMatch) == 0 && required {
+		return "", apierror.PreconditionRequired("If-Match header is required")
+	}
+	return ifMatch, nil
+}
+
+// checkETag compares the ETags the client sent, if any, with the one of the
+// current state of the resource
+func checkETag(resource interface{}, ifMatch string) error {
+	etag, err := getETag(resource)
+	if err != nil {
+		return err
+	}
+	return matchETag(ifMatch, etag)
+}
+
+// matchETag compares the comma separated ETags the client sent, if any, with
+// the current one
+func matchETag(ifMatch string, etag string) error {
+	if len(ifMatch) == 0 {
+		return nil
+	}
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == etag {
+			return nil
+		}
+	}
+	return apierror.PreconditionFailed("Resource has been changed since it was fetched")
+}
+
+// writeResource responds with a resource and its ETag
+func writeResource(w http.ResponseWriter, r *http.Request, status int, resource interface{}) {
+	etag, err := getETag(resource)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	w.Header().Set("ETag", etag)
+
+	// the client already has this state of the resource
+	if status == http.StatusOK && r.Method == http.MethodGet && r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resource)
+}
+
+func scanServiceLocation(row rowScanner) (model.ServiceLocation, error) {
+	var sl model.ServiceLocation
+	var deletedAt sql.NullString
+	err := row.Scan(&sl.Id, &sl.CustomerId, &sl.DateTakenOver, &sl.OccupantsCount, &sl.UnitNumber, &sl.Street, &sl.City, &sl.State, &sl.Zipcode, &sl.Country, &sl.SquareFootage, &sl.BedroomsCount, &sl.Active, &deletedAt, &sl.Role)
+	sl.DeletedAt = deletedAt.String
+	return sl, err
+}
+
+// getServiceLocation returns the service location as seen by the customer,
+// reporting service locations the customer is not a member of as not existing
+func getServiceLocation(ctx context.Context, db access.Querier, customerId, serviceLocationId uint32) (model.ServiceLocation, error) {
+	sl, err := scanServiceLocation(db.QueryRowContext(ctx, queryToGetServiceLocation(), customerId, serviceLocationId))
+	if err == sql.ErrNoRows {
+		return sl, apierror.NotFound("Service Location does not exist")
+	}
+	return sl, err
+}
+
+func scanEnrolledDevice(row rowScanner) (model.EnrolledDevice, error) {
+	var ed model.EnrolledDevice
+	var deletedAt sql.NullString
+	var modelNumber string
+	err := row.Scan(&ed.Id, &ed.ServiceLocationId, &ed.DeviceId, &ed.AliasName, &ed.RoomNumber, &ed.Active, &deletedAt, &ed.DeviceType, &modelNumber)
+	ed.DeletedAt = deletedAt.String
+	ed.Device = fmt.Sprint(modelNumber, " (", ed.DeviceType, ")")
+	return ed, err
+}
+
+// getEnrolledDevice returns the enrolled device if it belongs to the service
+// location
+func getEnrolledDevice(ctx context.Context, db access.Querier, serviceLocationId, enrolledDeviceId uint32) (model.EnrolledDevice, error) {
+	ed, err := scanEnrolledDevice(db.QueryRowContext(ctx, queryToGetEnrolledDevice(), serviceLocationId, enrolledDeviceId))
+	if err == sql.ErrNoRows {
+		return ed, apierror.NotFound("Enrolled Device does not exist")
+	}
+	return ed, err
+}
+
+func ListServiceLocationsV2(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	// Get filters and page from query params
+	limit, err := getPageLimit(r)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+	query := r.URL.Query()
+	filter := serviceLocationFilter{
+		Status:  query.Get("status"),
+		City:    query.Get("city"),
+		State:   query.Get("state"),
+		Zipcode: query.Get("zipcode"),
+	}
+
+	resp, err := listServiceLocationResources(ctx, db, session.CustomerId, filter, query.Get("cursor"), limit)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func GetServiceLocationV2(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	serviceLocationId, err := getIdFromPath(r, "id", "Service Location")
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	sl, err := getServiceLocation(ctx, db, session.CustomerId, serviceLocationId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	writeResource(w, r, http.StatusOK, sl)
+}
+
+func AddServiceLocationV2(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.ServiceLocation
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+	req.CustomerId = session.CustomerId
+
+	// validate the request
+	req.Zipcode = validation.NormalizePostalCode(req.Zipcode)
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	// add service location
+	sl, err := addServiceLocationResource(ctx, conn, redisClient, r, session, req)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// respond with the created service location
+	w.Header().Set("Location", fmt.Sprintf("/v2/service-locations/%d", sl.Id))
+	writeResource(w, r, http.StatusCreated, sl)
+}
+
+func UpdateServiceLocationV2(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.ServiceLocation
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	serviceLocationId, err := getIdFromPath(r, "id", "Service Location")
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+	req.Id = serviceLocationId
+	req.CustomerId = session.CustomerId
+
+	// validate the request
+	req.Zipcode = validation.NormalizePostalCode(req.Zipcode)
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+	ifMatch, err := getIfMatch(r, true)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// update service location
+	sl, err := updateServiceLocationResource(ctx, conn, redisClient, r, session, req, ifMatch)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// respond with the updated service location
+	writeResource(w, r, http.StatusOK, sl)
+}
+
+func DeleteServiceLocationV2(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	serviceLocationId, err := getIdFromPath(r, "id", "Service Location")
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	ifMatch, err := getIfMatch(r, false)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// delete service location
+	err = deleteServiceLocationResource(ctx, conn, redisClient, r, session, serviceLocationId, ifMatch)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func RestoreServiceLocationV2(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	serviceLocationId, err := getIdFromPath(r, "id", "Service Location")
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// restore service location
+	sl, err := restoreServiceLocationResource(ctx, conn, r, session, serviceLocationId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// respond with the restored service location
+	writeResource(w, r, http.StatusOK, sl)
+}
+
+func ListEnrolledDevicesV2(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	serviceLocationId, err := getIdFromPath(r, "id", "Service Location")
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// Get filters and page from query params
+	limit, err := getPageLimit(r)
+	if err != nil {
+		apierror.Write(w, r, apierror.BadRequest(err.Error()))
+		return
+	}
+	query := r.URL.Query()
+	filter := enrolledDeviceFilter{
+		Status:     query.Get("status"),
+		DeviceType: query.Get("deviceType"),
+	}
+
+	resp, err := listEnrolledDeviceResources(ctx, db, session.CustomerId, serviceLocationId, filter, query.Get("cursor"), limit)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	json.NewEncoder(w).Encode(resp)
+}
+
+func GetEnrolledDeviceV2(w http.ResponseWriter, r *http.Request, db *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	serviceLocationId, err := getIdFromPath(r, "id", "Service Location")
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	enrolledDeviceId, err := getIdFromPath(r, "deviceId", "Enrolled Device")
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	ed, err := getEnrolledDeviceResource(ctx, db, session.CustomerId, serviceLocationId, enrolledDeviceId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	writeResource(w, r, http.StatusOK, ed)
+}
+
+func AddEnrolledDeviceV2(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.EnrolledDevice
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	serviceLocationId, err := getIdFromPath(r, "id", "Service Location")
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+	req.ServiceLocationId = serviceLocationId
+	req.CustomerId = session.CustomerId
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+
+	// enroll device
+	ed, err := addEnrolledDeviceResource(ctx, conn, redisClient, r, session, req)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// respond with the created enrolled device
+	w.Header().Set("Location", fmt.Sprintf("/v2/service-locations/%d/devices/%d", ed.ServiceLocationId, ed.Id))
+	writeResource(w, r, http.StatusCreated, ed)
+}
+
+func UpdateEnrolledDeviceV2(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	var req model.EnrolledDevice
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	serviceLocationId, err := getIdFromPath(r, "id", "Service Location")
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	enrolledDeviceId, err := getIdFromPath(r, "deviceId", "Enrolled Device")
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// Parse the incoming JSON data from the request body
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		apierror.Write(w, r, apierror.InvalidJSON(err))
+		return
+	}
+	req.Id = enrolledDeviceId
+	req.CustomerId = session.CustomerId
+
+	// the device stays in its service location unless another one is given
+	if req.ServiceLocationId == 0 {
+		req.ServiceLocationId = serviceLocationId
+	}
+
+	// validate the request
+	if errs := validation.Validate(req); len(errs) > 0 {
+		apierror.Write(w, r, errs)
+		return
+	}
+	ifMatch, err := getIfMatch(r, true)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// update enrolled device
+	ed, err := updateEnrolledDeviceResource(ctx, conn, redisClient, r, session, serviceLocationId, req, ifMatch)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// respond with the updated enrolled device
+	if ed.ServiceLocationId != serviceLocationId {
+		w.Header().Set("Location", fmt.Sprintf("/v2/service-locations/%d/devices/%d", ed.ServiceLocationId, ed.Id))
+	}
+	writeResource(w, r, http.StatusOK, ed)
+}
+
+func DeleteEnrolledDeviceV2(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	serviceLocationId, err := getIdFromPath(r, "id", "Service Location")
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	enrolledDeviceId, err := getIdFromPath(r, "deviceId", "Enrolled Device")
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	ifMatch, err := getIfMatch(r, false)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// delete enrolled device
+	err = deleteEnrolledDeviceResource(ctx, conn, r, session, serviceLocationId, enrolledDeviceId, ifMatch)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func RestoreEnrolledDeviceV2(w http.ResponseWriter, r *http.Request, conn *sql.DB, redisClient *redis.Client) {
+	ctx := r.Context()
+	w.Header().Set("Content-Type", "application/json")
+
+	session, ok := GetSessionOrRespond(ctx, w, r, redisClient)
+	if !ok {
+		return
+	}
+
+	serviceLocationId, err := getIdFromPath(r, "id", "Service Location")
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+	enrolledDeviceId, err := getIdFromPath(r, "deviceId", "Enrolled Device")
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// restore enrolled device
+	ed, err := restoreEnrolledDeviceResource(ctx, conn, r, session, serviceLocationId, enrolledDeviceId)
+	if err != nil {
+		apierror.Write(w, r, err)
+		return
+	}
+
+	// respond with the restored enrolled device
+	writeResource(w, r, http.StatusOK, ed)
+}
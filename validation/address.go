@@ -0,0 +1,85 @@
+package validation
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+)
+
+// Phone numbers have 7 to 15 digits with an optional leading +, and may be
+// formatted with spaces, dashes, dots and parentheses
+var phoneNumberPattern = regexp.MustCompile(`^\+?[0-9(][0-9 ().-]{5,20}[0-9]$`)
+
+// Postal code formats of the countries we serve, keyed by ISO 3166 code.
+// Codes of other countries only need to look like a postal code.
+var postalCodePatterns = map[string]*regexp.Regexp{
+	"US": regexp.MustCompile(`^\d{5}(-\d{4})?$`),
+	"CA": regexp.MustCompile(`^[A-Z]\d[A-Z] ?\d[A-Z]\d$`),
+	"GB": regexp.MustCompile(`^[A-Z]{1,2}\d[A-Z\d]? ?\d[A-Z]{2}$`),
+	"IN": regexp.MustCompile(`^[1-9]\d{5}$`),
+	"DE": regexp.MustCompile(`^\d{5}$`),
+	"FR": regexp.MustCompile(`^\d{5}$`),
+	"AU": regexp.MustCompile(`^\d{4}$`),
+	"JP": regexp.MustCompile(`^\d{3}-?\d{4}$`),
+	"NL": regexp.MustCompile(`^\d{4} ?[A-Z]{2}$`),
+}
+
+var genericPostalCodePattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9 -]{1,14}$`)
+
+// Countries are stored as entered, so the common spellings are mapped to
+// their ISO 3166 code
+var countryCodes = map[string]string{
+	"USA":                      "US",
+	"UNITED STATES":            "US",
+	"UNITED STATES OF AMERICA": "US",
+	"CANADA":                   "CA",
+	"UK":                       "GB",
+	"UNITED KINGDOM":           "GB",
+	"GREAT BRITAIN":            "GB",
+	"INDIA":                    "IN",
+	"GERMANY":                  "DE",
+	"FRANCE":                   "FR",
+	"AUSTRALIA":                "AU",
+	"JAPAN":                    "JP",
+	"NETHERLANDS":              "NL",
+}
+
+func IsValidEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	return err == nil && address.Address == email
+}
+
+func IsValidPhoneNumber(phoneNumber string) bool {
+	if !phoneNumberPattern.MatchString(phoneNumber) {
+		return false
+	}
+	digits := 0
+	for _, c := range phoneNumber {
+		if c >= '0' && c <= '9' {
+			digits++
+		}
+	}
+	return digits >= 7 && digits <= 15
+}
+
+// CountryCode returns the ISO 3166 code of a country name or code
+func CountryCode(country string) string {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if code, ok := countryCodes[country]; ok {
+		return code
+	}
+	return country
+}
+
+// NormalizePostalCode is the form postal codes are stored and compared in
+func NormalizePostalCode[T ~string](postalCode T) T {
+	return T(strings.ToUpper(strings.TrimSpace(string(postalCode))))
+}
+
+func IsValidPostalCode(country, postalCode string) bool {
+	postalCode = NormalizePostalCode(postalCode)
+	if pattern, ok := postalCodePatterns[CountryCode(country)]; ok {
+		return pattern.MatchString(postalCode)
+	}
+	return genericPostalCodePattern.MatchString(postalCode)
+}
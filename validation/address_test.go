@@ -0,0 +1,104 @@
+package validation_test
+
+import (
+	"shems/validation"
+	"testing"
+)
+
+func TestIsValidPostalCode(t *testing.T) {
+	tests := []struct {
+		country    string
+		postalCode string
+		want       bool
+	}{
+		{"US", "10001", true},
+		{"US", "10001-1234", true},
+		{"United States", "10001", true},
+		{"usa", " 10001 ", true},
+		{"US", "1000", false},
+		{"US", "10001-12", false},
+		{"CA", "K1A 0B1", true},
+		{"Canada", "k1a0b1", true},
+		{"CA", "12345", false},
+		{"GB", "SW1A 1AA", true},
+		{"UK", "m1 1ae", true},
+		{"GB", "SW1A", false},
+		{"IN", "110001", true},
+		{"India", "010001", false},
+		{"DE", "10115", true},
+		{"FR", "7500", false},
+		{"AU", "2000", true},
+		{"JP", "100-0001", true},
+		{"Japan", "1000001", true},
+		{"NL", "1012 AB", true},
+		{"NL", "1012", false},
+		{"Brazil", "01310-100", true},
+		{"Brazil", "!", false},
+		{"", "A", false},
+	}
+
+	for _, tt := range tests {
+		if got := validation.IsValidPostalCode(tt.country, tt.postalCode); got != tt.want {
+			t.Errorf("IsValidPostalCode(%q, %q) = %v, want %v", tt.country, tt.postalCode, got, tt.want)
+		}
+	}
+}
+
+func TestCountryCode(t *testing.T) {
+	tests := []struct {
+		country string
+		want    string
+	}{
+		{"US", "US"},
+		{" united states of america ", "US"},
+		{"Great Britain", "GB"},
+		{"nl", "NL"},
+		{"Brazil", "BRAZIL"},
+	}
+
+	for _, tt := range tests {
+		if got := validation.CountryCode(tt.country); got != tt.want {
+			t.Errorf("CountryCode(%q) = %q, want %q", tt.country, got, tt.want)
+		}
+	}
+}
+
+func TestIsValidPhoneNumber(t *testing.T) {
+	tests := []struct {
+		phoneNumber string
+		want        bool
+	}{
+		{"5550100", true},
+		{"+1 (555) 010-0100", true},
+		{"+44 20.7946.0018", true},
+		{"555-01", false},
+		{"1234567890123456", false},
+		{"555 0100 ext", false},
+		{"-5550100", false},
+	}
+
+	for _, tt := range tests {
+		if got := validation.IsValidPhoneNumber(tt.phoneNumber); got != tt.want {
+			t.Errorf("IsValidPhoneNumber(%q) = %v, want %v", tt.phoneNumber, got, tt.want)
+		}
+	}
+}
+
+func TestIsValidEmail(t *testing.T) {
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{"jane@example.com", true},
+		{"jane.doe+home@example.co.uk", true},
+		{"jane", false},
+		{"Jane <jane@example.com>", false},
+		{"jane@example.com ", false},
+	}
+
+	for _, tt := range tests {
+		if got := validation.IsValidEmail(tt.email); got != tt.want {
+			t.Errorf("IsValidEmail(%q) = %v, want %v", tt.email, got, tt.want)
+		}
+	}
+}
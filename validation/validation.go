@@ -0,0 +1,223 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Rules are declared on request structs with the validate tag, separated by
+// commas, for example `validate:"required,max=64"`. Supported rules are
+//
+//	required          the field must not be empty or zero
+//	min=N, max=N      bounds of a number, or of the length of a string or list
+//	email             an email address
+//	phone             a phone number
+//	oneof=A B C       one of the space separated values
+//	datetime=LAYOUT   a date and time in the given time layout, alternative
+//	                  layouts are separated by |
+//	postalcode=Field  a postal code of the country held in Field
+//	dive              the rules after it apply to each item of a list
+//
+// Format rules are skipped for empty values, so optional fields only need to
+// be valid when they are given. Nested structs and lists of structs are
+// always validated.
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+type Errors []FieldError
+
+func (errs Errors) Error() string {
+	messages := make([]string, len(errs))
+	for i, e := range errs {
+		messages[i] = e.Field + " " + e.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Validate checks v, a struct or a pointer to one, against the rules in its
+// validate tags and returns every failed rule
+func Validate(v interface{}) Errors {
+	var errs Errors
+	validateStruct(reflect.Indirect(reflect.ValueOf(v)), "", &errs)
+	return errs
+}
+
+func validateStruct(rv reflect.Value, prefix string, errs *Errors) {
+	if rv.Kind() != reflect.Struct {
+		return
+	}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get("validate")
+		if tag == "-" {
+			continue
+		}
+		path := prefix + fieldName(sf)
+		field := rv.Field(i)
+
+		rules, itemRules := splitRules(tag)
+		for _, rule := range rules {
+			if message := checkRule(rule, field, rv); message != "" {
+				*errs = append(*errs, FieldError{Field: path, Message: message})
+			}
+		}
+
+		switch field.Kind() {
+		case reflect.Struct:
+			validateStruct(field, path+".", errs)
+		case reflect.Ptr:
+			if !field.IsNil() {
+				validateStruct(field.Elem(), path+".", errs)
+			}
+		case reflect.Slice, reflect.Array:
+			for j := 0; j < field.Len(); j++ {
+				item := reflect.Indirect(field.Index(j))
+				itemPath := fmt.Sprintf("%s[%d]", path, j)
+				for _, rule := range itemRules {
+					if message := checkRule(rule, item, rv); message != "" {
+						*errs = append(*errs, FieldError{Field: itemPath, Message: message})
+					}
+				}
+				validateStruct(item, itemPath+".", errs)
+			}
+		}
+	}
+}
+
+// fieldName is the name of the field in the request body
+func fieldName(sf reflect.StructField) string {
+	if name := strings.Split(sf.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+		return name
+	}
+	r, size := utf8.DecodeRuneInString(sf.Name)
+	return string(unicode.ToLower(r)) + sf.Name[size:]
+}
+
+// splitRules separates the rules of a field from the rules of its items
+func splitRules(tag string) ([]string, []string) {
+	if tag == "" {
+		return nil, nil
+	}
+	rules := strings.Split(tag, ",")
+	for i, rule := range rules {
+		if rule == "dive" {
+			return rules[:i], rules[i+1:]
+		}
+	}
+	return rules, nil
+}
+
+// checkRule returns the error message of a failed rule, or an empty string
+func checkRule(rule string, field, parent reflect.Value) string {
+	name, param, _ := strings.Cut(rule, "=")
+	switch name {
+	case "required":
+		if isEmpty(field) {
+			return "is required"
+		}
+	case "min":
+		return checkBound(field, param, true)
+	case "max":
+		return checkBound(field, param, false)
+	case "email":
+		if s := field.String(); s != "" && !IsValidEmail(s) {
+			return "must be a valid email address"
+		}
+	case "phone":
+		if s := field.String(); s != "" && !IsValidPhoneNumber(s) {
+			return "must be a valid phone number"
+		}
+	case "oneof":
+		if s := field.String(); s != "" {
+			values := strings.Fields(param)
+			for _, value := range values {
+				if s == value {
+					return ""
+				}
+			}
+			return "must be one of " + strings.Join(values, ", ")
+		}
+	case "datetime":
+		if s := field.String(); s != "" {
+			layouts := strings.Split(param, "|")
+			for _, layout := range layouts {
+				if _, err := time.Parse(layout, s); err == nil {
+					return ""
+				}
+			}
+			return "must be a date in the format " + strings.Join(layouts, " or ")
+		}
+	case "postalcode":
+		country := parent.FieldByName(param)
+		if s := field.String(); s != "" && country.IsValid() && !IsValidPostalCode(country.String(), s) {
+			return "must be a valid postal code for " + country.String()
+		}
+	default:
+		panic("validation: unknown rule " + rule)
+	}
+	return ""
+}
+
+func isEmpty(field reflect.Value) bool {
+	switch field.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(field.String()) == ""
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return field.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return field.IsNil()
+	}
+	return field.IsZero()
+}
+
+func checkBound(field reflect.Value, param string, isMin bool) string {
+	bound, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		panic("validation: invalid bound " + param)
+	}
+
+	var value float64
+	var unit string
+	switch field.Kind() {
+	case reflect.String:
+		value = float64(utf8.RuneCountInString(field.String()))
+		unit = " characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		value = float64(field.Len())
+		unit = " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = float64(field.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = float64(field.Uint())
+	case reflect.Float32, reflect.Float64:
+		value = field.Float()
+	default:
+		return ""
+	}
+
+	if isMin && value < bound {
+		if unit != "" {
+			return "must have at least " + param + unit
+		}
+		return "must be at least " + param
+	}
+	if !isMin && value > bound {
+		if unit != "" {
+			return "must have at most " + param + unit
+		}
+		return "must be at most " + param
+	}
+	return ""
+}
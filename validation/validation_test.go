@@ -0,0 +1,174 @@
+package validation_test
+
+import (
+	"reflect"
+	"shems/validation"
+	"testing"
+)
+
+type item struct {
+	Name string `json:"name" validate:"required"`
+}
+
+type request struct {
+	Id        uint32   `json:"id" validate:"required"`
+	Name      string   `json:"name" validate:"required,max=5"`
+	Count     int      `json:"count" validate:"min=1,max=10"`
+	Tags      []string `json:"tags" validate:"max=2,dive,oneof=a b"`
+	Email     string   `json:"email" validate:"email"`
+	Phone     string   `json:"phone" validate:"phone"`
+	Level     string   `json:"level" validate:"oneof=low high"`
+	Date      string   `json:"date" validate:"datetime=2006-01-02|2006-01-02 15:04:05"`
+	Zipcode   string   `json:"zipcode" validate:"postalcode=Country"`
+	Country   string   `json:"country"`
+	Items     []item   `json:"items" validate:"dive"`
+	Nested    item     `json:"nested"`
+	Internal  string   `validate:"-"`
+	CreatedBy uint32   `json:"-" validate:"required"`
+}
+
+func validRequest() request {
+	return request{
+		Id:        1,
+		Name:      "Home",
+		Count:     3,
+		Tags:      []string{"a"},
+		Email:     "jane@example.com",
+		Phone:     "+1 (555) 010-0100",
+		Level:     "low",
+		Date:      "2024-03-10",
+		Zipcode:   "10001",
+		Country:   "USA",
+		Items:     []item{{Name: "fridge"}},
+		Nested:    item{Name: "oven"},
+		CreatedBy: 7,
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(r *request)
+		want   validation.Errors
+	}{
+		{
+			name:   "valid request",
+			modify: func(r *request) {},
+		},
+		{
+			name: "required fields",
+			modify: func(r *request) {
+				r.Id = 0
+				r.Name = "  "
+				r.CreatedBy = 0
+			},
+			want: validation.Errors{
+				{Field: "id", Message: "is required"},
+				{Field: "name", Message: "is required"},
+				{Field: "createdBy", Message: "is required"},
+			},
+		},
+		{
+			name: "bounds of strings, numbers and lists",
+			modify: func(r *request) {
+				r.Name = "Cottage"
+				r.Count = 0
+				r.Tags = []string{"a", "b", "a"}
+			},
+			want: validation.Errors{
+				{Field: "name", Message: "must have at most 5 characters"},
+				{Field: "count", Message: "must be at least 1"},
+				{Field: "tags", Message: "must have at most 2 items"},
+			},
+		},
+		{
+			name: "upper bound of a number",
+			modify: func(r *request) {
+				r.Count = 11
+			},
+			want: validation.Errors{
+				{Field: "count", Message: "must be at most 10"},
+			},
+		},
+		{
+			name: "format rules",
+			modify: func(r *request) {
+				r.Email = "jane"
+				r.Phone = "12345"
+				r.Level = "medium"
+				r.Date = "10/03/2024"
+			},
+			want: validation.Errors{
+				{Field: "email", Message: "must be a valid email address"},
+				{Field: "phone", Message: "must be a valid phone number"},
+				{Field: "level", Message: "must be one of low, high"},
+				{Field: "date", Message: "must be a date in the format 2006-01-02 or 2006-01-02 15:04:05"},
+			},
+		},
+		{
+			name: "format rules skip empty values",
+			modify: func(r *request) {
+				r.Email = ""
+				r.Phone = ""
+				r.Level = ""
+				r.Date = ""
+				r.Zipcode = ""
+			},
+		},
+		{
+			name: "postal code of the country field",
+			modify: func(r *request) {
+				r.Zipcode = "1000"
+			},
+			want: validation.Errors{
+				{Field: "zipcode", Message: "must be a valid postal code for USA"},
+			},
+		},
+		{
+			name: "items of lists and nested structs",
+			modify: func(r *request) {
+				r.Tags = []string{"c"}
+				r.Items = []item{{Name: "fridge"}, {}}
+				r.Nested = item{}
+			},
+			want: validation.Errors{
+				{Field: "tags[0]", Message: "must be one of a, b"},
+				{Field: "items[1].name", Message: "is required"},
+				{Field: "nested.name", Message: "is required"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := validRequest()
+			tt.modify(&r)
+			got := validation.Validate(&r)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Validate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateUnknownRule(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Validate() did not panic on an unknown rule")
+		}
+	}()
+	validation.Validate(struct {
+		Name string `validate:"unknown"`
+	}{})
+}
+
+func TestErrorsError(t *testing.T) {
+	errs := validation.Errors{
+		{Field: "name", Message: "is required"},
+		{Field: "count", Message: "must be at least 1"},
+	}
+	want := "name is required; count must be at least 1"
+	if got := errs.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}